@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// bearerAuthorized reports whether r carries the named secret as a bearer
+// token. Requests are refused when no secret is configured.
+func bearerAuthorized(r *http.Request, name string) bool {
+	secret, err := getSecret(name)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to load secret", "name", name, "err", err)
+		return false
+	}
+	if secret == "" {
+		return false
+	}
+
+	got := []byte(r.Header.Get("Authorization"))
+	want := []byte("Bearer " + secret)
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// adminRequest checks an admin request uses method and carries ADMIN_TOKEN,
+// writing an error response and returning false if not
+func adminRequest(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return false
+	}
+	if !bearerAuthorized(r, "ADMIN_TOKEN") {
+		writeProblem(w, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
+	return true
+}
+
+// purgeSkipLocations clears the cached and stale skip locations. The
+// last-known-good snapshot and geocodes are kept, as neither goes out of
+// date when the council corrects its page.
+func purgeSkipLocations(ctx context.Context) error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	for _, key := range []string{cacheKey, cacheVersionKey, staleCacheKey} {
+		if err := activeCache.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	invalidateDerived()
+	return nil
+}
+
+// HandleAdminPurge handles POST /admin/purge, clearing cached skip locations
+// so the next request scrapes the council website
+func HandleAdminPurge(w http.ResponseWriter, r *http.Request) {
+	if !adminRequest(w, r, http.MethodPost) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := purgeSkipLocations(r.Context()); err != nil {
+		slog.ErrorContext(r.Context(), "Cache purge failed", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to purge cache")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Cache purged by admin request")
+	json.NewEncoder(w).Encode(map[string]bool{"purged": true})
+}
+
+// HandleAdminRefresh handles POST /admin/refresh, re-scraping the council
+// website immediately rather than waiting for the cache to expire
+func HandleAdminRefresh(w http.ResponseWriter, r *http.Request) {
+	if !adminRequest(w, r, http.MethodPost) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	if err := purgeSkipLocations(ctx); err != nil {
+		slog.ErrorContext(r.Context(), "Cache purge failed", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to purge cache")
+		return
+	}
+
+	// Don't let the client disconnecting abandon the scrape half way
+	locations, err := refreshSkipLocations(context.WithoutCancel(ctx))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Admin refresh failed", "err", err)
+		writeProblem(w, http.StatusBadGateway, "Failed to refresh skip locations")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Refreshed skip locations by admin request", "locations", len(locations))
+	json.NewEncoder(w).Encode(struct {
+		Locations   int       `json:"locations"`
+		RefreshedAt time.Time `json:"refreshedAt"`
+	}{len(locations), time.Now().UTC()})
+}
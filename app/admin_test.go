@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminRequestRequiresPostAndToken(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "s3cret")
+
+	tests := []struct {
+		method, auth string
+		want         int
+	}{
+		{"GET", "Bearer s3cret", http.StatusMethodNotAllowed},
+		{"POST", "", http.StatusUnauthorized},
+		{"POST", "Bearer wrong", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/admin/purge", nil)
+		req.Header.Set("Authorization", tt.auth)
+		w := httptest.NewRecorder()
+		HandleAdminPurge(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s with %q: got %d, want %d", tt.method, tt.auth, w.Code, tt.want)
+		}
+	}
+}
+
+func TestHandleAdminPurge(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+	t.Setenv("ADMIN_TOKEN", "s3cret")
+
+	ctx := context.Background()
+	locations := []SkipLocation{{Address: "Cached Road"}}
+	for _, key := range []string{cacheKey, staleCacheKey, snapshotCacheKey} {
+		activeCache.Set(ctx, key, locations, time.Hour)
+	}
+
+	req := httptest.NewRequest("POST", "/admin/purge", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	HandleAdminPurge(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+
+	for _, key := range []string{cacheKey, staleCacheKey} {
+		if got, _ := activeCache.Get(ctx, key); got != nil {
+			t.Errorf("Expected %s to be purged, got %+v", key, got)
+		}
+	}
+	if got, _ := activeCache.Get(ctx, snapshotCacheKey); got == nil {
+		t.Error("The snapshot should survive a purge")
+	}
+}
+
+func TestAuditedRecordsAdminCalls(t *testing.T) {
+	useTestSkips(t, nil)
+	t.Setenv("ADMIN_TOKEN", "s3cret")
+
+	var received string
+	handler := audited(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	body := `{"url":"https://hooks.example.com","secret":"hook-secret"}`
+	req := httptest.NewRequest(http.MethodPost, "/admin/webhooks", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set("X-Admin-Actor", "ops@example.com")
+	handler(httptest.NewRecorder(), req)
+	if received != body {
+		t.Errorf("Handler should still get the whole body, got %q", received)
+	}
+
+	// Unauthorized calls aren't recorded
+	req = httptest.NewRequest(http.MethodPost, "/admin/purge", nil)
+	handler(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	HandleAdminAudit(rec, req)
+
+	var entries []auditEntry
+	if err := json.NewDecoder(rec.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected one entry, got %+v", entries)
+	}
+	e := entries[0]
+	if e.Actor != "ops@example.com" || e.Method != http.MethodPost || e.Path != "/admin/webhooks" || e.Status != http.StatusCreated {
+		t.Errorf("Unexpected entry %+v", e)
+	}
+	if strings.Contains(string(e.Payload), "hook-secret") || !strings.Contains(string(e.Payload), "hooks.example.com") {
+		t.Errorf("Payload should be kept with secrets redacted, got %s", e.Payload)
+	}
+}
+
+func TestHandleAdminAuditFilters(t *testing.T) {
+	useTestSkips(t, nil)
+	t.Setenv("ADMIN_TOKEN", "s3cret")
+	ctx := context.Background()
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for i, e := range []auditEntry{
+		{Actor: "ops@example.com", Path: "/admin/purge"},
+		{Actor: "dev@example.com", Path: "/admin/webhooks"},
+		{Actor: "ops@example.com", Path: "/admin/refresh"},
+		{Actor: "ops@example.com", Path: "/admin/purge"},
+	} {
+		e.Time = start.Add(time.Duration(i) * time.Hour)
+		appendAudit(ctx, e)
+	}
+
+	audit := func(query string) (int, []auditEntry) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit?"+query, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		HandleAdminAudit(rec, req)
+		var entries []auditEntry
+		json.NewDecoder(rec.Body).Decode(&entries)
+		return rec.Code, entries
+	}
+
+	if _, got := audit("actor=ops@example.com&path=/admin/purge"); len(got) != 2 || !got[0].Time.After(got[1].Time) {
+		t.Errorf("Expected both purges by ops, newest first, got %+v", got)
+	}
+	if _, got := audit("since=" + start.Add(time.Hour).Format(time.RFC3339) + "&limit=2"); len(got) != 2 || got[1].Path != "/admin/refresh" {
+		t.Errorf("Expected the latest two since the second call, got %+v", got)
+	}
+	for _, bad := range []string{"since=yesterday", "limit=0"} {
+		if code, _ := audit(bad); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, code)
+		}
+	}
+}
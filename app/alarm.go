@@ -0,0 +1,32 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxAlarms caps the ?alarm= reminders on a feed, which is plenty for
+// "the day before" and "an hour before" without bloating every event
+const maxAlarms = 5
+
+// alarmPattern matches the RFC 5545 durations taken by ?alarm=, such as
+// P1D, PT2H or P1DT12H. Weeks can't be mixed with other units.
+var alarmPattern = regexp.MustCompile(`^P(\d+W|(\d+D)?(T(\d+H)?(\d+M)?(\d+S)?)?)$`)
+
+// parseAlarms validates ?alarm= values, each a duration before the event
+// starts at which subscribers are reminded
+func parseAlarms(values []string) ([]string, error) {
+	if len(values) > maxAlarms {
+		return nil, fmt.Errorf("at most %d alarms can be set", maxAlarms)
+	}
+	for _, value := range values {
+		// The pattern allows every part to be empty, so P, PT and a
+		// trailing T are ruled out separately
+		if !alarmPattern.MatchString(value) || value == "P" || strings.HasSuffix(value, "T") {
+			return nil, errors.New("alarm must be an ISO 8601 duration before the event, e.g. P1D or PT2H")
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseAlarms(t *testing.T) {
+	for _, valid := range []string{"P1D", "PT2H", "PT30M", "P1DT12H", "P2W", "PT90S"} {
+		if _, err := parseAlarms([]string{valid}); err != nil {
+			t.Errorf("parseAlarms(%q) error = %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "P", "PT", "P1DT", "1D", "-P1D", "P1W2D", "p1d", "P1H"} {
+		if _, err := parseAlarms([]string{invalid}); err == nil {
+			t.Errorf("parseAlarms(%q) should fail", invalid)
+		}
+	}
+	if _, err := parseAlarms(make([]string, maxAlarms+1)); err == nil {
+		t.Error("Expected too many alarms to fail")
+	}
+}
+
+func TestCalendarAlarmParameter(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{
+		Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), Type: EventMegaSkip,
+	}})
+
+	w := httptest.NewRecorder()
+	HandleCalendarDefault(w, httptest.NewRequest(http.MethodGet, "/calendar.ics?alarm=tomorrow", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid alarm, got %d", w.Code)
+	}
+}
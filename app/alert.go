@@ -0,0 +1,219 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultAlertAfterFailures is how many scrapes in a row must fail before an
+// alert is sent, so a single blip doesn't page anyone
+const defaultAlertAfterFailures = 3
+
+// notifier delivers an alert to whoever is watching the deployment
+type notifier interface {
+	Notify(ctx context.Context, subject, message string) error
+}
+
+// webhookNotifier posts alerts as JSON to ALERT_WEBHOOK_URL
+type webhookNotifier struct {
+	url string
+}
+
+func (n webhookNotifier) Notify(ctx context.Context, subject, message string) error {
+	body, err := json.Marshal(map[string]string{"subject": subject, "message": message})
+	if err != nil {
+		return err
+	}
+	return postAlert(ctx, n.url, "application/json", body, nil)
+}
+
+// ntfyNotifier publishes alerts to an ntfy topic
+type ntfyNotifier struct {
+	topicURL string
+}
+
+func (n ntfyNotifier) Notify(ctx context.Context, subject, message string) error {
+	return postAlert(ctx, n.topicURL, "text/plain; charset=utf-8", []byte(message), map[string]string{
+		"Title":    subject,
+		"Priority": "high",
+		"Tags":     "warning",
+	})
+}
+
+// emailNotifier sends alerts through the SMTP server used for invites
+type emailNotifier struct {
+	smtp smtpConfig
+	to   *mail.Address
+}
+
+func (n emailNotifier) Notify(ctx context.Context, subject, message string) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.smtp.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", n.to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(message, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	return sendMail(n.smtp.addr, n.smtp.auth(), n.smtp.from.Address, []string{n.to.Address}, msg.Bytes())
+}
+
+// multiNotifier sends each alert through every configured notifier
+type multiNotifier []notifier
+
+func (m multiNotifier) Notify(ctx context.Context, subject, message string) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, subject, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// postAlert posts body to url with the given extra headers
+func postAlert(ctx context.Context, url, contentType string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// configuredNotifier builds a notifier from ALERT_WEBHOOK_URL,
+// ALERT_NTFY_TOPIC and ALERT_EMAIL, or returns nil if none are set
+func configuredNotifier() notifier {
+	var notifiers multiNotifier
+
+	webhookURL, err := getSecret("ALERT_WEBHOOK_URL")
+	if err != nil {
+		slog.Warn("Failed to load ALERT_WEBHOOK_URL", "err", err)
+	}
+	if webhookURL != "" {
+		notifiers = append(notifiers, webhookNotifier{url: webhookURL})
+	}
+
+	if topic := os.Getenv("ALERT_NTFY_TOPIC"); topic != "" {
+		// A bare topic name is published on the public ntfy.sh server
+		if !strings.Contains(topic, "://") {
+			topic = "https://ntfy.sh/" + topic
+		}
+		notifiers = append(notifiers, ntfyNotifier{topicURL: topic})
+	}
+
+	if address := os.Getenv("ALERT_EMAIL"); address != "" {
+		to, err := mail.ParseAddress(address)
+		cfg, ok := loadSMTPConfig()
+		switch {
+		case err != nil:
+			slog.Warn("Invalid ALERT_EMAIL address", "err", err)
+		case !ok:
+			slog.Warn("ALERT_EMAIL is set but SMTP is not configured")
+		default:
+			notifiers = append(notifiers, emailNotifier{smtp: cfg, to: to})
+		}
+	}
+
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return notifiers
+}
+
+// scrapeAlerts tracks scrape outcomes and raises an alert once per incident:
+// when scraping has failed alertAfter times in a row, or when a scrape
+// succeeds but finds no upcoming locations, which usually means the council
+// page layout changed. Counts are per instance.
+type scrapeAlerts struct {
+	mu         sync.Mutex
+	failures   int
+	alerted    bool
+	emptyAlert bool
+
+	notifier   func() notifier
+	alertAfter int
+}
+
+var scrapeAlerter = &scrapeAlerts{
+	notifier:   sync.OnceValue(configuredNotifier),
+	alertAfter: defaultAlertAfterFailures,
+}
+
+// failed records a failed scrape
+func (a *scrapeAlerts) failed(ctx context.Context, err error) {
+	a.mu.Lock()
+	a.failures++
+	send := a.failures >= a.alertAfter && !a.alerted
+	if send {
+		a.alerted = true
+	}
+	failures := a.failures
+	a.mu.Unlock()
+
+	if send {
+		subject := "Skip scraping is failing"
+		if errors.Is(err, errParserDrift) {
+			subject = "Skip page layout has changed"
+		}
+		a.send(ctx, subject,
+			fmt.Sprintf("Scraping %s has failed %d times in a row.\n\nLast error: %v", councilURL, failures, err))
+	}
+}
+
+// succeeded records a successful scrape that found count upcoming locations
+func (a *scrapeAlerts) succeeded(ctx context.Context, count int) {
+	a.mu.Lock()
+	a.failures, a.alerted = 0, false
+	send := count == 0 && !a.emptyAlert
+	a.emptyAlert = count == 0
+	a.mu.Unlock()
+
+	if send {
+		a.send(ctx, "Skip scraping found no locations",
+			fmt.Sprintf("Scraping %s succeeded but found no upcoming skip locations. The page layout may have changed.", councilURL))
+	}
+}
+
+// send delivers an alert in the background so scraping isn't held up
+func (a *scrapeAlerts) send(ctx context.Context, subject, message string) {
+	slog.WarnContext(ctx, "Raising scrape alert", "subject", subject)
+
+	n := a.notifier()
+	if n == nil {
+		return
+	}
+	goSafe(ctx, "scrape alert", func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if err := n.Notify(ctx, subject, message); err != nil {
+			slog.WarnContext(ctx, "Failed to send scrape alert", "err", err)
+		}
+	})
+}
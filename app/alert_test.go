@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingNotifier passes alert subjects to a channel
+type recordingNotifier chan string
+
+func (n recordingNotifier) Notify(ctx context.Context, subject, message string) error {
+	n <- subject
+	return nil
+}
+
+func expectAlert(t *testing.T, alerts recordingNotifier, want string) {
+	t.Helper()
+	select {
+	case got := <-alerts:
+		if got != want {
+			t.Errorf("Alert = %q, want %q", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected alert %q", want)
+	}
+}
+
+func expectNoAlert(t *testing.T, alerts recordingNotifier) {
+	t.Helper()
+	select {
+	case got := <-alerts:
+		t.Errorf("Unexpected alert %q", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestScrapeAlertsAfterRepeatedFailures(t *testing.T) {
+	alerts := make(recordingNotifier, 5)
+	a := &scrapeAlerts{notifier: func() notifier { return alerts }, alertAfter: 3}
+	ctx := context.Background()
+
+	a.failed(ctx, errors.New("timeout"))
+	a.failed(ctx, errors.New("timeout"))
+	expectNoAlert(t, alerts)
+
+	a.failed(ctx, errors.New("timeout"))
+	expectAlert(t, alerts, "Skip scraping is failing")
+
+	// One alert per incident
+	a.failed(ctx, errors.New("timeout"))
+	expectNoAlert(t, alerts)
+
+	// Recovery resets the streak
+	a.succeeded(ctx, 10)
+	for i := 0; i < 3; i++ {
+		a.failed(ctx, errors.New("timeout"))
+	}
+	expectAlert(t, alerts, "Skip scraping is failing")
+}
+
+func TestScrapeAlertsOnParserDrift(t *testing.T) {
+	alerts := make(recordingNotifier, 5)
+	a := &scrapeAlerts{notifier: func() notifier { return alerts }, alertAfter: 1}
+
+	a.failed(context.Background(), &driftError{url: councilURL})
+	expectAlert(t, alerts, "Skip page layout has changed")
+}
+
+func TestScrapeAlertsOnEmptyScrape(t *testing.T) {
+	alerts := make(recordingNotifier, 5)
+	a := &scrapeAlerts{notifier: func() notifier { return alerts }, alertAfter: 3}
+	ctx := context.Background()
+
+	a.succeeded(ctx, 0)
+	expectAlert(t, alerts, "Skip scraping found no locations")
+
+	a.succeeded(ctx, 0)
+	expectNoAlert(t, alerts)
+
+	a.succeeded(ctx, 4)
+	a.succeeded(ctx, 0)
+	expectAlert(t, alerts, "Skip scraping found no locations")
+}
+
+func TestConfiguredNotifier(t *testing.T) {
+	t.Setenv("ALERT_WEBHOOK_URL", "")
+	t.Setenv("ALERT_NTFY_TOPIC", "")
+	t.Setenv("ALERT_EMAIL", "")
+	if n := configuredNotifier(); n != nil {
+		t.Errorf("Expected no notifier, got %#v", n)
+	}
+
+	t.Setenv("ALERT_NTFY_TOPIC", "wheremegaskip-alerts")
+	n, ok := configuredNotifier().(multiNotifier)
+	if !ok || len(n) != 1 || n[0].(ntfyNotifier).topicURL != "https://ntfy.sh/wheremegaskip-alerts" {
+		t.Errorf("Expected an ntfy.sh notifier, got %#v", n)
+	}
+}
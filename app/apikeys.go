@@ -0,0 +1,143 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// apiKeyHeader carries an API key; ?api_key= works too, for calendar apps
+// that can only be given a URL
+const apiKeyHeader = "X-API-Key"
+
+// apiKey lets a heavy consumer, such as a bot or aggregator, use the API
+// under its own limits instead of the anonymous per-IP ones
+type apiKey struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	// PerMinute and Burst are the key's rate limit; a PerMinute of 0 means
+	// unlimited
+	PerMinute int `json:"perMinute"`
+	Burst     int `json:"burst"`
+	// Disabled cuts the key off without forgetting it
+	Disabled bool `json:"disabled,omitempty"`
+
+	limiter *rateLimiter
+}
+
+// APIKeyUsage counts one key's requests on this instance
+type APIKeyUsage struct {
+	Name     string     `json:"name"`
+	Requests int64      `json:"requests"`
+	Limited  int64      `json:"limited"`
+	Disabled bool       `json:"disabled,omitempty"`
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
+}
+
+var (
+	// apiKeys maps each configured key to its settings
+	apiKeys map[string]*apiKey
+
+	apiKeyUsageMu sync.Mutex
+	apiKeyUsage   = make(map[string]*APIKeyUsage)
+)
+
+// configureAPIKeys loads API_KEYS, a JSON list of keys. It is read with
+// getSecret, so it can also come from a file or Vault.
+func configureAPIKeys() {
+	value, err := getSecret("API_KEYS")
+	if err != nil {
+		slog.Warn("Failed to load API keys", "err", err)
+		return
+	}
+	if value == "" {
+		return
+	}
+	keys, err := parseAPIKeys([]byte(value))
+	if err != nil {
+		slog.Warn("Ignoring invalid API_KEYS", "err", err)
+		return
+	}
+	apiKeys = keys
+	slog.Info("API keys loaded", "keys", len(keys))
+}
+
+// parseAPIKeys parses a JSON list of keys, indexed by key
+func parseAPIKeys(data []byte) (map[string]*apiKey, error) {
+	var list []*apiKey
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*apiKey, len(list))
+	for _, k := range list {
+		if k.Name == "" || k.Key == "" {
+			return nil, fmt.Errorf("every key needs a name and a key")
+		}
+		if _, dup := keys[k.Key]; dup {
+			return nil, fmt.Errorf("key %s is listed twice", k.Name)
+		}
+		k.limiter = newRateLimiter(k.PerMinute, max(k.Burst, k.PerMinute))
+		keys[k.Key] = k
+	}
+	return keys, nil
+}
+
+// requestAPIKey returns the API key sent with r, if any
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// recordAPIKeyUse counts a request made with k
+func recordAPIKeyUse(k *apiKey, limited bool, now time.Time) {
+	apiKeyUsageMu.Lock()
+	defer apiKeyUsageMu.Unlock()
+
+	usage, ok := apiKeyUsage[k.Name]
+	if !ok {
+		usage = &APIKeyUsage{Name: k.Name}
+		apiKeyUsage[k.Name] = usage
+	}
+	usage.Requests++
+	if limited {
+		usage.Limited++
+	}
+	usage.LastUsed = &now
+}
+
+// apiKeyUsageReport lists every configured key with its usage, by name
+func apiKeyUsageReport() []APIKeyUsage {
+	apiKeyUsageMu.Lock()
+	defer apiKeyUsageMu.Unlock()
+
+	report := make([]APIKeyUsage, 0, len(apiKeys))
+	for _, k := range apiKeys {
+		usage := APIKeyUsage{Name: k.Name}
+		if u, ok := apiKeyUsage[k.Name]; ok {
+			usage = *u
+		}
+		usage.Disabled = k.Disabled
+		report = append(report, usage)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Name < report[j].Name })
+	return report
+}
+
+// HandleAdminAPIKeys handles GET /admin/apikeys, reporting each key's usage
+// on this instance
+func HandleAdminAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if !adminRequest(w, r, http.MethodGet) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(apiKeyUsageReport())
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := parseAPIKeys([]byte(`[
+		{"name": "aggregator", "key": "k1", "perMinute": 600},
+		{"name": "bot", "key": "k2", "perMinute": 0, "disabled": true}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys["k1"].Name != "aggregator" || keys["k1"].limiter == nil || keys["k1"].limiter.burst != 600 {
+		t.Errorf("Unexpected key %+v", keys["k1"])
+	}
+	if keys["k2"].limiter != nil || !keys["k2"].Disabled {
+		t.Errorf("Expected an unlimited, disabled key, got %+v", keys["k2"])
+	}
+
+	for _, bad := range []string{
+		`{"name": "bot"}`,
+		`[{"name": "bot"}]`,
+		`[{"name": "a", "key": "k"}, {"name": "b", "key": "k"}]`,
+	} {
+		if _, err := parseAPIKeys([]byte(bad)); err == nil {
+			t.Errorf("Expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestRateLimitAPIKeys(t *testing.T) {
+	previousLimiter, previousKeys := apiLimiter, apiKeys
+	apiLimiter = newRateLimiter(1, 1)
+	defer func() {
+		apiLimiter, apiKeys = previousLimiter, previousKeys
+		apiKeyUsage = make(map[string]*APIKeyUsage)
+	}()
+
+	keys, err := parseAPIKeys([]byte(`[
+		{"name": "aggregator", "key": "k1", "perMinute": 3, "burst": 3},
+		{"name": "abuser", "key": "k2", "perMinute": 600, "disabled": true}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiKeys = keys
+
+	handler := rateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(path, key string) int {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			r.Header.Set(apiKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// The key gets its own, higher quota, unaffected by the anonymous one
+	serve("/api/skips", "")
+	if code := serve("/api/skips", ""); code != http.StatusTooManyRequests {
+		t.Fatalf("Expected anonymous requests to be limited, got %d", code)
+	}
+	for i := range 3 {
+		if code := serve("/api/skips", "k1"); code != http.StatusOK {
+			t.Fatalf("Keyed request %d got %d", i, code)
+		}
+	}
+	if code := serve("/calendar.ics?api_key=k1", ""); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the key's quota to run out, got %d", code)
+	}
+
+	if code := serve("/api/skips", "nope"); code != http.StatusUnauthorized {
+		t.Errorf("Expected unknown keys to be refused, got %d", code)
+	}
+	if code := serve("/api/skips", "k2"); code != http.StatusForbidden {
+		t.Errorf("Expected disabled keys to be refused, got %d", code)
+	}
+
+	t.Setenv("ADMIN_TOKEN", "secret")
+	r := httptest.NewRequest(http.MethodGet, "/admin/apikeys", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	HandleAdminAPIKeys(w, r)
+
+	var report []APIKeyUsage
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 || report[1].Name != "aggregator" || report[1].Requests != 4 || report[1].Limited != 1 || report[1].LastUsed == nil {
+		t.Errorf("Unexpected usage %+v", report)
+	}
+	if report[0].Name != "abuser" || !report[0].Disabled || report[0].Requests != 0 {
+		t.Errorf("Unexpected usage %+v", report[0])
+	}
+}
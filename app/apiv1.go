@@ -0,0 +1,130 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// apiVersion is the version of the /api/v1 contract. Within v1, fields
+// may be added to the envelope and to data, but are never renamed,
+// retyped or removed.
+const apiVersion = "v1"
+
+// V1Response is the envelope of every /api/v1 JSON response. Exactly one
+// of Data and Errors is set.
+type V1Response struct {
+	Data   json.RawMessage `json:"data"`
+	Meta   V1Meta          `json:"meta"`
+	Errors []V1Error       `json:"errors,omitempty"`
+}
+
+// V1Meta describes a response rather than the skips in it
+type V1Meta struct {
+	APIVersion string `json:"apiVersion"`
+	RequestID  string `json:"requestId,omitempty"`
+	// Stale is set when the data is a snapshot served because the council
+	// website couldn't be reached
+	Stale bool `json:"stale,omitempty"`
+	// Total, Offset, Limit and NextOffset are set on paged responses
+	Total      *int `json:"total,omitempty"`
+	Offset     *int `json:"offset,omitempty"`
+	Limit      *int `json:"limit,omitempty"`
+	NextOffset *int `json:"nextOffset,omitempty"`
+}
+
+// V1Error is one reason a request failed
+type V1Error struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// apiV1 serves an unversioned JSON handler under /api/v1, wrapping its
+// response in a V1Response. The handler sees the unversioned path, so
+// /api/v1/skips/{id} is handled as /api/skips/{id}. Responses that aren't
+// JSON or problem+json, such as calendars, are passed through untouched.
+func apiV1(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inner := r.Clone(r.Context())
+		inner.URL.Path = "/api" + strings.TrimPrefix(r.URL.Path, "/api/v1")
+		inner.URL.RawPath = ""
+		// The body is rewritten, so must not arrive compressed
+		inner.Header.Del("Accept-Encoding")
+
+		rec := &v1Recorder{header: w.Header().Clone(), status: http.StatusOK}
+		next(rec, inner)
+
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(rec.header.Get("Content-Type"))
+		if mediaType != "application/json" && mediaType != problemContentType {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		resp := v1Envelope(rec.status, rec.body.Bytes())
+		resp.Meta.RequestID = rec.header.Get(requestIDHeader)
+		resp.Meta.Stale = rec.header.Get("X-Data-Stale") == "true"
+
+		// Problems are enveloped like any other error, so the envelope is
+		// always plain JSON
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Del("Content-Length")
+		w.WriteHeader(rec.status)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// v1Envelope wraps an unversioned JSON body. Problem bodies, as written by
+// writeProblem, become Errors; paged bodies have their paging moved to
+// Meta.
+func v1Envelope(status int, body []byte) V1Response {
+	resp := V1Response{Meta: V1Meta{APIVersion: apiVersion}}
+
+	if status >= http.StatusBadRequest {
+		var p Problem
+		if json.Unmarshal(body, &p) != nil || p.Detail == "" {
+			p.Detail = http.StatusText(status)
+		}
+		resp.Data = json.RawMessage("null")
+		resp.Errors = []V1Error{{Status: status, Message: p.Detail}}
+		return resp
+	}
+
+	var page struct {
+		Total      *int            `json:"total"`
+		Offset     *int            `json:"offset"`
+		Limit      *int            `json:"limit"`
+		NextOffset *int            `json:"nextOffset"`
+		Data       json.RawMessage `json:"data"`
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) && json.Unmarshal(body, &page) == nil && page.Total != nil && page.Data != nil {
+		resp.Data = page.Data
+		resp.Meta.Total, resp.Meta.Offset, resp.Meta.Limit, resp.Meta.NextOffset = page.Total, page.Offset, page.Limit, page.NextOffset
+		return resp
+	}
+
+	resp.Data = json.RawMessage(bytes.TrimSpace(body))
+	if len(resp.Data) == 0 {
+		resp.Data = json.RawMessage("null")
+	}
+	return resp
+}
+
+// v1Recorder buffers an unversioned response so apiV1 can wrap it
+type v1Recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *v1Recorder) Header() http.Header { return r.header }
+
+func (r *v1Recorder) WriteHeader(status int) { r.status = status }
+
+func (r *v1Recorder) Write(b []byte) (int, error) { return r.body.Write(b) }
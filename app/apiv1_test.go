@@ -0,0 +1,63 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIV1(t *testing.T) {
+	handler := apiV1(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/skips":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Data-Stale", "true")
+			w.Write([]byte(`[{"address":"Pountney Road"}]` + "\n"))
+		case "/api/skips/paged":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"total":3,"offset":0,"limit":2,"nextOffset":2,"data":[{},{}]}`))
+		case "/api/skips/calendar.ics":
+			w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+			w.Write([]byte("BEGIN:VCALENDAR\r\n"))
+		default:
+			writeProblem(w, http.StatusNotFound, "Skip not found")
+		}
+	})
+
+	get := func(path string) (*httptest.ResponseRecorder, V1Response) {
+		w := httptest.NewRecorder()
+		w.Header().Set(requestIDHeader, "req-1")
+		handler(w, httptest.NewRequest("GET", path, nil))
+		var resp V1Response
+		if w.Header().Get("Content-Type") == "application/json" {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+		}
+		return w, resp
+	}
+
+	w, resp := get("/api/v1/skips")
+	if w.Code != http.StatusOK || string(resp.Data) != `[{"address":"Pountney Road"}]` || resp.Errors != nil {
+		t.Errorf("Expected the skips as data, got %d %s", w.Code, resp.Data)
+	}
+	if resp.Meta.APIVersion != "v1" || resp.Meta.RequestID != "req-1" || !resp.Meta.Stale {
+		t.Errorf("Unexpected meta %+v", resp.Meta)
+	}
+
+	_, resp = get("/api/v1/skips/paged")
+	if string(resp.Data) != `[{},{}]` || resp.Meta.Total == nil || *resp.Meta.Total != 3 || *resp.Meta.NextOffset != 2 {
+		t.Errorf("Expected paging in meta, got %s %+v", resp.Data, resp.Meta)
+	}
+
+	w, resp = get("/api/v1/skips/missing")
+	if w.Code != http.StatusNotFound || string(resp.Data) != "null" || len(resp.Errors) != 1 || resp.Errors[0].Message != "Skip not found" {
+		t.Errorf("Expected an error envelope, got %d %+v", w.Code, resp)
+	}
+
+	w, _ = get("/api/v1/skips/calendar.ics")
+	if w.Body.String() != "BEGIN:VCALENDAR\r\n" {
+		t.Errorf("Expected calendars to pass through, got %q", w.Body)
+	}
+}
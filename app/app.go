@@ -2,32 +2,82 @@ package app
 
 import (
 	"context"
-	_ "embed"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-//go:embed index.html
-var htmlTemplate string
+// indexTemplate is parsed once at startup; only the per-request data
+// changes between renders
+var indexTemplate = mustParsePage("index.html")
+
+// indexPage is the data rendered into the index template
+type indexPage struct {
+	StylesheetURL string
+	ScriptURL     string
+	// LeafletStylesheetURL and LeafletScriptURL are self-hosted once
+	// Leaflet is vendored; see assets.go
+	LeafletStylesheetURL string
+	LeafletScriptURL     string
+	Nonce                string
+	Tenant               Tenant
+	// Events are the upcoming skip days as schema.org JSON-LD
+	Events []schemaEvent
+	// Skip is the location a /skip/{id} page opens on
+	Skip *SkipLocation
+	// Postcode is the ?postcode= searched for, and Nearest the skip found
+	// for it server-side, so the search works without JavaScript.
+	// NearestError explains why it couldn't be answered.
+	Postcode     string
+	Nearest      *nearestSkip
+	NearestError string
+	// Remembered is set when the postcode is kept in the postcode cookie,
+	// to offer to forget it
+	Remembered bool
+	// Days lists the upcoming skips for browsers without JavaScript
+	Days []listedDay
+	// TurnstileSiteKey shows a Turnstile challenge on the invite form
+	TurnstileSiteKey string
+}
 
 // SkipLocation represents a megaskip location with its details
 type SkipLocation struct {
+	// ID is stable across refreshes; see skipID
+	ID        string    `json:"id,omitempty"`
 	Address   string    `json:"address"`
 	Postcode  string    `json:"postcode"`
 	Date      time.Time `json:"date"`
 	DateStr   string    `json:"dateStr"` // Human-readable date
 	Latitude  float64   `json:"lat"`
 	Longitude float64   `json:"lng"`
+	Type      EventType `json:"type"`
+	// Borough is the Name of the Scraper the location came from
+	Borough string `json:"borough,omitempty"`
+	// OpensAt and ClosesAt are London times as "15:04", when the council
+	// page gives them; see hours for the defaults
+	OpensAt  string `json:"opensAt,omitempty"`
+	ClosesAt string `json:"closesAt,omitempty"`
+	// AcceptedItems and ProhibitedItems are what can and can't be brought,
+	// as listed on the council page
+	AcceptedItems   []string `json:"acceptedItems,omitempty"`
+	ProhibitedItems []string `json:"prohibitedItems,omitempty"`
+	// Status is StatusCancelled or StatusPostponed when the council page
+	// says the skip won't go ahead, and empty otherwise
+	Status string `json:"status,omitempty"`
+	// GeocodeAccuracy is AccuracyStreet, AccuracyPostcode, or AccuracyOutcode
+	// when only the centre of the postcode area is known
+	GeocodeAccuracy string `json:"geocodeAccuracy,omitempty"`
 }
 
 const cacheKey = "skip_locations"
@@ -36,135 +86,357 @@ var (
 	activeCache Cacher
 	cacheTTL    = 3 * time.Hour
 	cacheMu     sync.RWMutex
+	// initOnce keeps warm serverless instances from rebuilding the cache
+	// on every request
+	initOnce sync.Once
+
+	// refreshTimeout bounds a full scrape and geocode cycle
+	refreshTimeout = 30 * time.Second
+	// geocodeWorkers is the number of concurrent geocoding requests. Only
+	// providers without a strict rate limit, such as postcodes.io, are
+	// really concurrent, so one worker is used when every geocoder is
+	// throttled, as Nominatim is.
+	geocodeWorkers = 4
+	// geocodeBudget is how long a refresh waits for geocoding before
+	// returning and leaving the rest to background geocoding
+	geocodeBudget = 10 * time.Second
 )
 
-// InitCache sets up the cache based on environment configuration
+// InitCache sets up the cache based on environment configuration. It is
+// safe to call on every request; only the first call has any effect.
 func InitCache() {
+	initOnce.Do(initCache)
+}
+
+func initCache() {
+	configureLogging()
+	configureErrorReporting()
+
 	// Configure TTL
 	if ttl := os.Getenv("CACHE_TTL_MINUTES"); ttl != "" {
 		if minutes, err := time.ParseDuration(ttl + "m"); err == nil {
 			cacheTTL = minutes
-			log.Printf("Cache TTL set to %v", cacheTTL)
+			slog.Info("Cache TTL set", "ttl", cacheTTL)
+		}
+	}
+	if dir := os.Getenv("TEMPLATE_DIR"); dir != "" {
+		templateDir = dir
+		slog.Info("Reloading templates from disk", "dir", templateDir)
+	}
+	snapshotPath = os.Getenv("SNAPSHOT_PATH")
+	overridesPath = os.Getenv("OVERRIDES_PATH")
+	archivePath = os.Getenv("SCRAPE_ARCHIVE_PATH")
+	if keep := os.Getenv("SCRAPE_ARCHIVE_KEEP"); keep != "" {
+		if n, err := strconv.Atoi(keep); err == nil && n > 0 {
+			archiveKeep = n
+		}
+	}
+	if os.Getenv("CACHE_TTL_MODE") == "adaptive" {
+		adaptiveTTL = true
+		slog.Info("Cache TTL adapts to the next skip day")
+	}
+
+	if bounds := os.Getenv("GEOCODE_BOUNDS"); bounds != "" {
+		if polygon, err := parseGeocodeBounds(bounds); err == nil {
+			geocodeBounds = polygon
+		} else {
+			slog.Warn("Ignoring invalid GEOCODE_BOUNDS", "err", err)
+		}
+	}
+
+	// Configure refresh concurrency and deadline
+	if workers := os.Getenv("GEOCODE_WORKERS"); workers != "" {
+		if n, err := strconv.Atoi(workers); err == nil && n > 0 {
+			geocodeWorkers = n
+		}
+	}
+	if timeout := os.Getenv("REFRESH_TIMEOUT_SECONDS"); timeout != "" {
+		if seconds, err := time.ParseDuration(timeout + "s"); err == nil && seconds > 0 {
+			refreshTimeout = seconds
+		}
+	}
+	if stale := os.Getenv("CACHE_MAX_STALE_MINUTES"); stale != "" {
+		if minutes, err := time.ParseDuration(stale + "m"); err == nil && minutes >= 0 {
+			maxStaleness = minutes
+		}
+	}
+	if failures := os.Getenv("ALERT_AFTER_FAILURES"); failures != "" {
+		if n, err := strconv.Atoi(failures); err == nil && n > 0 {
+			scrapeAlerter.alertAfter = n
+		}
+	}
+	if budget := os.Getenv("GEOCODE_BUDGET_SECONDS"); budget != "" {
+		if seconds, err := time.ParseDuration(budget + "s"); err == nil && seconds > 0 {
+			geocodeBudget = seconds
 		}
 	}
 
+	// Point the scraper at the council site or a mirror
+	configureScraper()
+
+	// Set how long browsers and the CDN cache responses
+	configureHTTPCaching()
+
+	// Let browser apps on other sites call the API
+	configureCORS()
+
+	// Limit how often each client can call the API and calendars, and give
+	// API keys their own quotas
+	configureRateLimit()
+	configureAPIKeys()
+
+	// Push feed updates to subscribers through a WebSub hub
+	configureWebSub()
+
+	// Tell registered webhooks about changes to the skips
+	configureWebhooks()
+
+	// Sign Wallet passes if a Pass Type ID certificate is configured
+	configureWallet()
+
+	// Sign the cookie that remembers a visitor's postcode
+	configureCookies()
+
+	// Challenge email invite requests if Turnstile is configured
+	configureTurnstile()
+
+	// Select geocoding providers
+	configureGeocoder(os.Getenv("GEOCODERS"))
+
+	// Export traces if an OTLP collector is configured
+	configureTracing()
+
 	// Select cache implementation based on CACHE_TYPE
 	cacheType := os.Getenv("CACHE_TYPE")
-	redisURL := os.Getenv("UPSTASH_REDIS_REST_URL")
-	redisToken := os.Getenv("UPSTASH_REDIS_REST_TOKEN")
-
-	if cacheType == "redis" && redisURL != "" && redisToken != "" {
-		activeCache = NewRedisCache(redisURL, redisToken)
-		log.Println("Using Redis cache (Upstash)")
-	} else {
+	shared, name := sharedCache()
+
+	switch {
+	case cacheType == "redis" && shared != nil:
+		activeCache = shared
+		slog.Info("Using Redis cache", "backend", name)
+	case cacheType == "tiered" && shared != nil:
+		activeCache = NewTieredCache(shared)
+		slog.Info("Using tiered cache", "backend", name)
+	default:
 		activeCache = NewMemoryCache()
-		log.Println("Using in-memory cache")
+		slog.Info("Using in-memory cache")
+	}
+	if tracerProvider != nil {
+		activeCache = tracedCache{activeCache}
+	}
+}
+
+// sharedCache returns the configured Redis backend and a name for it: a
+// native Redis connection if REDIS_URL is set, otherwise Upstash's REST API.
+// It returns nil if neither is configured.
+func sharedCache() (Cacher, string) {
+	redisURL, err := getSecret("REDIS_URL")
+	if err != nil {
+		slog.Warn("Failed to load Redis URL", "err", err)
+	}
+	if redisURL != "" {
+		cache, err := NewNativeRedisCache(redisURL)
+		if err != nil {
+			slog.Warn("Invalid REDIS_URL", "err", err)
+			return nil, ""
+		}
+		return cache, "Redis protocol"
 	}
+
+	restURL := os.Getenv("UPSTASH_REDIS_REST_URL")
+	restToken, err := getSecret("UPSTASH_REDIS_REST_TOKEN")
+	if err != nil {
+		slog.Warn("Failed to load Redis token", "err", err)
+	}
+	if restURL != "" && restToken != "" {
+		return NewRedisCache(restURL, restToken), "Upstash"
+	}
+	return nil, ""
 }
 
-// HandleIndex handles the main page request - serves static HTML
+// HandleIndex handles the main page request. With ?postcode=, or a postcode
+// remembered from an earlier search, it shows the nearest skip to that
+// postcode. Postcodes given with ?postcode= are remembered once found.
 func HandleIndex(w http.ResponseWriter, r *http.Request) {
-	// Set security headers
-	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Header().Set("X-Frame-Options", "DENY")
-	w.Header().Set("X-XSS-Protection", "1; mode=block")
-	w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-	w.Header().Set("Content-Security-Policy",
-		"default-src 'self'; "+
-			"script-src 'self' 'unsafe-inline' https://unpkg.com; "+
-			"style-src 'self' 'unsafe-inline' https://unpkg.com; "+
-			"img-src 'self' data: https://*.openstreetmap.org https://*.tile.openstreetmap.org; "+
-			"connect-src 'self' https://nominatim.openstreetmap.org; "+
-			"font-src 'self' data:;")
-
-	// Serve static HTML template directly
+	page := indexPage{Events: indexStructuredData(r.Context())}
+	page.Postcode = strings.TrimSpace(r.URL.Query().Get("postcode"))
+	if page.Postcode == "" {
+		page.Postcode, page.Remembered = rememberedPostcode(r)
+	}
+
+	if page.Postcode != "" {
+		nearest, err := queryNearestSkip(r.Context(), page.Postcode, time.Now())
+		if err != nil {
+			if queryErrorStatus(err) == http.StatusInternalServerError {
+				slog.ErrorContext(r.Context(), "Error finding nearest skip", "err", err)
+			}
+			page.NearestError = describeQueryError(err)
+		} else {
+			page.Nearest = &nearest
+			if !page.Remembered {
+				rememberPostcode(w, r, page.Postcode)
+				page.Remembered = true
+			}
+		}
+	}
+	writeIndexPage(w, r, page)
+}
+
+// writeIndexPage renders the page, filling in the assets, nonce and tenant
+func writeIndexPage(w http.ResponseWriter, r *http.Request, page indexPage) {
+	page.StylesheetURL = stylesheet.url
+	page.ScriptURL = script.url
+	page.LeafletStylesheetURL = assetURL(leafletStylesheet, leafletCDN+"leaflet.css")
+	page.LeafletScriptURL = assetURL(leafletScript, leafletCDN+"leaflet.js")
+	page.Nonce = cspNonce(r.Context())
+	page.Tenant = currentTenant()
+	page.Days = indexListing(r.Context(), page.Nearest)
+	page.TurnstileSiteKey = turnstileSiteKey
+
+	// The page carries this request's CSP nonce, which is only secret if
+	// no cache hands the same page to anyone else
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(htmlTemplate))
+	personalCaching.apply(w)
+	err := writeRendered(w, func(buf io.Writer) error {
+		return indexTemplate.Execute(buf, page)
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering index", "err", err)
+		reportError(r.Context(), err, map[string]string{"template": "index"})
+		writeProblem(w, http.StatusInternalServerError, "Failed to render page")
+	}
 }
 
 // HandleSkipsAPI handles the API endpoint for skip data
 func HandleSkipsAPI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	locations, err := getSkipLocations()
+	types, err := parseEventTypes(r.URL.Query().Get("type"))
 	if err != nil {
-		log.Printf("Error getting skip locations: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch skip locations"})
+		writeProblem(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(locations); err != nil {
-		log.Printf("Error encoding JSON: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to encode response"})
+	boroughIDs, err := parseBoroughs(r.URL.Query().Get("borough"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
 	}
-}
 
-func getSkipLocations() ([]SkipLocation, error) {
-	ctx := context.Background()
+	near, err := parseNearbyQuery(r.Context(), r.URL.Query())
+	if errors.Is(err, errBadNearbyQuery) {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		status := queryErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			slog.ErrorContext(r.Context(), "Error locating postcode", "err", err)
+		}
+		writeProblem(w, status, describeQueryError(err))
+		return
+	}
 
-	// Try to get from cache
-	cacheMu.RLock()
-	locations, err := activeCache.Get(ctx, cacheKey)
-	cacheMu.RUnlock()
+	// Skips near a postcode are the ones its own council runs, unless
+	// ?borough= asks for others
+	if near != nil && near.Borough != nil && len(boroughIDs) == 0 {
+		boroughIDs = []string{near.Borough.ID}
+	}
 
+	page, err := parseSkipsPage(r.URL.Query(), near)
 	if err != nil {
-		log.Printf("Cache get error: %v", err)
-	} else if locations != nil {
-		log.Println("Serving from cache")
-		return locations, nil
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Need to fetch fresh data
-	cacheMu.Lock()
-	defer cacheMu.Unlock()
-
-	// Double-check after acquiring write lock
-	locations, err = activeCache.Get(ctx, cacheKey)
-	if err == nil && locations != nil {
-		return locations, nil
+	format, err := negotiateSkipsFormat(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	if format != skipsFormatJSON {
+		handleSkipsFormat(w, r, format, types, boroughIDs)
+		return
 	}
 
-	log.Println("Fetching fresh data from council website")
-	locations, err = scrapeCouncilWebsite()
+	payload, err := skipsPayloadFor(r.Context(), types, boroughIDs, near, page)
 	if err != nil {
-		return nil, fmt.Errorf("scraping failed: %w", err)
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
 	}
 
-	if err := activeCache.Set(ctx, cacheKey, locations, cacheTTL); err != nil {
-		log.Printf("Cache set error: %v", err)
+	setSnapshotHeaders(w)
+	apiCaching.apply(w)
+	var modified time.Time
+	if version, err := currentDataset.get(r.Context()); err == nil {
+		modified = version.modified
 	}
+	if notModified(w, r, payload.etag, modified) {
+		return
+	}
+	payload.writeTo(w, r)
+}
 
+func getSkipLocations(ctx context.Context) ([]SkipLocation, error) {
+	ctx, span := startSpan(ctx, "getSkipLocations")
+	defer span.End()
+
+	locations, err := loadSkipLocations(ctx)
+	if err != nil {
+		recordSpanError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("locations", len(locations)))
+
+	startBackgroundGeocoding(locations)
 	return locations, nil
 }
 
-func scrapeCouncilWebsite() ([]SkipLocation, error) {
-	url := "https://www.wandsworth.gov.uk/mega-skip-days"
+func loadSkipLocations(ctx context.Context) ([]SkipLocation, error) {
+	// Keep the caller's trace, but a scrape shared with other requests
+	// mustn't be cancelled when this one goes away
+	ctx = context.WithoutCancel(ctx)
+
+	// Try to get from cache
+	cacheMu.RLock()
+	locations, err := activeCache.Get(ctx, cacheKey)
+	cacheMu.RUnlock()
 
-	// Fetch the page
-	res, err := http.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch page: %w", err)
+		slog.WarnContext(ctx, "Cache get error", "err", err)
+	} else if locations != nil {
+		slog.DebugContext(ctx, "Serving from cache")
+		return locations, nil
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("bad status code: %d", res.StatusCode)
+	// Serve expired data straight away and refresh it in the background
+	if stale := loadStaleSkipLocations(ctx); stale != nil {
+		slog.InfoContext(ctx, "Serving stale data while refreshing")
+		startBackgroundRefresh()
+		return stale, nil
 	}
 
-	// Parse HTML
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+	// Need to fetch fresh data, or fall back to the last known good copy
+	locations, err = refreshSkipLocations(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		return serveSnapshot(ctx, err)
 	}
+	return locations, nil
+}
 
+// parseCouncilPage extracts every skip location listed on the council page
+func parseCouncilPage(doc *goquery.Document, now time.Time) []SkipLocation {
 	var locations []SkipLocation
-	now := time.Now()
+	pageHours := pageOpeningHours(doc)
+	accepted, prohibited := pageItems(doc)
 
 	// Find all h3 elements that contain dates (e.g., "Saturday 31 January")
 	doc.Find("h3").Each(func(i int, s *goquery.Selection) {
-		dateText := s.Text()
+		// Cancellations may be marked on the heading, e.g. "Saturday 31
+		// January - CANCELLED"
+		dateText, status := cutStatus(s.Text())
 
 		// Try to parse the date
 		date, err := parseSkipDate(dateText, now.Year())
@@ -175,6 +447,7 @@ func scrapeCouncilWebsite() ([]SkipLocation, error) {
 
 		// Find the next sibling or nearby elements containing the location list
 		// Look for the next paragraph or list
+		var day []SkipLocation
 		nextEl := s.Next()
 		for nextEl.Length() > 0 {
 			// Check if this is a list or contains location info
@@ -183,60 +456,86 @@ func scrapeCouncilWebsite() ([]SkipLocation, error) {
 				break
 			}
 
-			// Parse locations from this element
-			locs := parseLocations(nextEl, date, dateText)
-			locations = append(locations, locs...)
+			// Parse locations from this element, taking hours from the
+			// location, then the heading, then the page
+			found := parseLocations(nextEl, date, dateText)
+			for _, loc := range found {
+				loc = withOpeningHours(loc, dateText, pageHours)
+				day = append(day, withItems(loc, accepted, prohibited))
+			}
+
+			// A notice such as "This skip day has been postponed" applies
+			// to the whole date
+			if _, notice := cutStatus(text); len(found) == 0 && notice != StatusScheduled {
+				status = notice
+			}
 
 			nextEl = nextEl.Next()
 		}
-	})
 
-	// Filter to only upcoming dates
-	filtered := []SkipLocation{}
-	for _, loc := range locations {
-		if loc.Date.After(now) || loc.Date.Equal(now.Truncate(24*time.Hour)) {
-			filtered = append(filtered, loc)
+		for _, loc := range day {
+			locations = append(locations, withStatus(loc, status))
 		}
-	}
+	})
 
-	// Geocode each location
-	log.Printf("Geocoding %d locations...", len(filtered))
-	for i := range filtered {
-		lat, lng, err := geocodePostcode(filtered[i].Postcode)
-		if err != nil {
-			log.Printf("Failed to geocode %s: %v", filtered[i].Postcode, err)
-			continue
-		}
-		filtered[i].Latitude = lat
-		filtered[i].Longitude = lng
-		log.Printf("Geocoded %s: %.4f, %.4f", filtered[i].Postcode, lat, lng)
+	return locations
+}
 
-		// Respect Nominatim rate limit (1 request per second recommended)
-		if i < len(filtered)-1 {
-			time.Sleep(200 * time.Millisecond)
-		}
+var (
+	// ordinalSuffix matches the suffix of "31st", "2nd" and so on
+	ordinalSuffix = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)\b`)
+
+	// dateRangeSeparator splits "7 to 8 March" or "Saturday 7 – Sunday 8
+	// March" into its first and last days
+	dateRangeSeparator = regexp.MustCompile(`(?i)\s+(?:to|until|and)\s+|\s*[-–—&]\s*`)
+
+	// headingYear matches a year at the end of a heading
+	headingYear = regexp.MustCompile(`\s(\d{4})$`)
+
+	// monthName matches a full or abbreviated month name
+	monthName = regexp.MustCompile(`(?i)\b(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\b`)
+
+	// skipDateFormats are the layouts tried for a heading, once normalised
+	skipDateFormats = []string{
+		"Monday 2 January 2006",
+		"Mon 2 January 2006",
+		"Monday 2 Jan 2006",
+		"Mon 2 Jan 2006",
+		"2 January 2006",
+		"2 Jan 2006",
 	}
-	log.Println("Geocoding complete")
-
-	return filtered, nil
-}
+)
 
+// parseSkipDate parses a date heading such as "Saturday 31 January",
+// "Sat 31st Jan" or "Saturday 31 January 2026". Headings without a year are
+// taken to be in year. A range such as "7 to 8 March" gives its first day.
 func parseSkipDate(dateStr string, year int) (time.Time, error) {
-	// Try to parse dates like "Saturday 31 January"
-	// We'll try multiple formats
-	formats := []string{
-		"Monday 2 January",
-		"Monday 02 January",
-		"2 January",
-		"02 January",
+	dateStr = strings.Join(strings.Fields(dateStr), " ")
+	dateStr = strings.Trim(dateStr, ":.")
+	dateStr = strings.ReplaceAll(dateStr, ",", "")
+	dateStr = ordinalSuffix.ReplaceAllString(dateStr, "$1")
+
+	// For a range, parse the first day, borrowing the month and year from
+	// the last day if it shares them ("7 to 8 March 2026")
+	if parts := dateRangeSeparator.Split(dateStr, -1); len(parts) > 1 {
+		first, last := parts[0], parts[len(parts)-1]
+		if !monthName.MatchString(first) {
+			if month := monthName.FindString(last); month != "" {
+				first += " " + month
+			}
+		}
+		if m := headingYear.FindStringSubmatch(last); m != nil && !headingYear.MatchString(first) {
+			first += " " + m[1]
+		}
+		dateStr = first
 	}
 
-	dateStr = strings.TrimSpace(dateStr)
-	dateStr = fmt.Sprintf("%s %d", dateStr, year)
+	if !headingYear.MatchString(dateStr) {
+		dateStr = fmt.Sprintf("%s %d", dateStr, year)
+	}
 
-	for _, format := range formats {
-		formatWithYear := format + " 2006"
-		t, err := time.Parse(formatWithYear, dateStr)
+	for _, format := range skipDateFormats {
+		t, err := time.Parse(format, dateStr)
 		if err == nil {
 			return t, nil
 		}
@@ -250,118 +549,192 @@ func parseLocations(el *goquery.Selection, date time.Time, dateStr string) []Ski
 
 	// Look for bullet points or list items
 	el.Find("li").Each(func(i int, s *goquery.Selection) {
-		text := s.Text()
+		text, status := cutStatus(s.Text())
 		loc := parseLocationLine(text, date, dateStr)
 		if loc.Address != "" {
-			locations = append(locations, loc)
+			locations = append(locations, withStatus(withOpeningHours(loc, text), status))
 		}
 	})
 
 	// If no list items found, try parsing text lines
 	if len(locations) == 0 {
-		text := el.Text()
+		text, status := cutStatus(el.Text())
 		// Try parsing the whole text as one location
 		if loc := parseLocationLine(text, date, dateStr); loc.Address != "" {
-			locations = append(locations, loc)
+			locations = append(locations, withStatus(withOpeningHours(loc, text), status))
 		}
 	}
 
 	return locations
 }
 
-func parseLocationLine(line string, date time.Time, dateStr string) SkipLocation {
-	// Format is typically: "Location Name, POSTCODE"
-	// Example: "Pountney Road, SW11 5TU"
+var (
+	// listMarker matches a bullet, dash or number at the start of a line,
+	// such as "•", "–" or "2."
+	listMarker = regexp.MustCompile(`^(?:[\p{Pd}•·‣◦▪*]+|\d+[.)])\s*`)
 
-	// Remove bullet points, numbered prefixes, and trim
-	line = strings.TrimSpace(line)
-	line = strings.TrimPrefix(line, "•")
-	line = strings.TrimPrefix(line, "-")
-	line = strings.TrimPrefix(line, "*")
-	line = strings.TrimSpace(line)
+	// trailingPostcode matches a full postcode at the end of a line, with
+	// or without the space, and any trailing punctuation
+	trailingPostcode = regexp.MustCompile(`(?i)\b([A-Z]{1,2}\d[A-Z\d]?)\s*(\d[A-Z]{2})\W*$`)
+)
 
-	// Strip numbered prefixes like "1. ", "2.  "
-	numberedPrefix := regexp.MustCompile(`^\d+\.\s+`)
-	line = numberedPrefix.ReplaceAllString(line, "")
+// parseLocationLine parses a line such as "Pountney Road, SW11 5TU" into a
+// location. Lines with no postcode, or only an outcode after the last comma,
+// give an empty location, so every location returned has an address and
+// either a full postcode or an outcode.
+func parseLocationLine(line string, date time.Time, dateStr string) SkipLocation {
+	// Remove bullet points and numbered prefixes, which may be stacked
 	line = strings.TrimSpace(line)
-
-	if line == "" {
-		return SkipLocation{}
+	for range 3 {
+		line = strings.TrimSpace(listMarker.ReplaceAllString(line, ""))
 	}
 
-	// Split by comma to separate address from postcode
-	parts := strings.Split(line, ",")
-	if len(parts) < 2 {
+	var rest, postcode string
+	if m := trailingPostcode.FindStringSubmatchIndex(line); m != nil {
+		// "Larch Close, SW12 9SY", also without the comma or the space
+		rest = line[:m[0]]
+		postcode = strings.ToUpper(line[m[2]:m[3]] + " " + line[m[4]:m[5]])
+	} else if i := strings.LastIndex(line, ","); i >= 0 && isOutcode(line[i+1:]) {
+		// "Tooting Bec Gardens, SW16"
+		rest = line[:i]
+		postcode = strings.ToUpper(strings.TrimSpace(line[i+1:]))
+	} else {
 		return SkipLocation{}
 	}
 
-	address := strings.TrimSpace(parts[0])
-	postcode := strings.TrimSpace(strings.Join(parts[1:], ","))
-
-	// UK postcode pattern validation (basic)
-	postcodePattern := regexp.MustCompile(`^[A-Z]{1,2}\d{1,2}[A-Z]?\s?\d[A-Z]{2}$`)
-	if !postcodePattern.MatchString(strings.ToUpper(postcode)) {
-		// Try to extract postcode from the end of the string
-		words := strings.Fields(postcode)
-		if len(words) >= 2 {
-			// Last two words might be postcode
-			potentialPostcode := strings.Join(words[len(words)-2:], " ")
-			if postcodePattern.MatchString(strings.ToUpper(potentialPostcode)) {
-				postcode = potentialPostcode
-			}
-		}
+	// The address is the first part; later parts describe where on it
+	address, _, _ := strings.Cut(rest, ",")
+	address = strings.Trim(address, " \t-–—(")
+	if address == "" {
+		return SkipLocation{}
 	}
 
 	return SkipLocation{
 		Address:  address,
-		Postcode: strings.ToUpper(postcode),
+		Postcode: postcode,
 		Date:     date,
 		DateStr:  dateStr,
+		Type:     EventMegaSkip,
 	}
 }
 
-// geocodePostcode calls Nominatim API to get lat/lng for a postcode
-func geocodePostcode(postcode string) (float64, float64, error) {
-	apiURL := fmt.Sprintf("https://nominatim.openstreetmap.org/search?q=%s+London+UK&format=json&limit=1&countrycodes=gb",
-		url.QueryEscape(postcode))
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("User-Agent", "WhereMegaSkip/1.0 (https://github.com/JosephSalisbury/wheremegaskip)")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to fetch geocode: %w", err)
+// geocodeLocations fills in coordinates for locations in place, geocoding
+// each distinct address and postcode once using a bounded pool of workers.
+// Locations that already have coordinates are left alone, and those that
+// cannot be geocoded before ctx expires are left at 0,0.
+func geocodeLocations(ctx context.Context, locations []SkipLocation) {
+	ctx, span := startSpan(ctx, "geocodeLocations")
+	defer span.End()
+
+	// Start from locations geocoded by earlier refreshes
+	applyCoordinates(locations, cachedCoordinates(ctx))
+
+	// Many skips share a place across dates, so only look each up once
+	keys := missingCoordinates(locations)
+	span.SetAttributes(attribute.Int("locations", len(keys)))
+	if len(keys) == 0 {
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return 0, 0, fmt.Errorf("geocode API returned status %d", resp.StatusCode)
+	byKey := make(map[string]SkipLocation, len(keys))
+	for _, loc := range locations {
+		if _, ok := byKey[geocodeKey(loc)]; !ok {
+			byKey[geocodeKey(loc)] = loc
+		}
 	}
 
-	var results []struct {
-		Lat string `json:"lat"`
-		Lon string `json:"lon"`
+	workers := geocodeWorkers
+	if geocoderThrottled(activeGeocoder) {
+		workers = 1
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return 0, 0, fmt.Errorf("failed to decode geocode response: %w", err)
+	slog.InfoContext(ctx, "Geocoding locations", "locations", len(keys), "workers", workers)
+
+	var (
+		mu      sync.Mutex
+		results = make(map[string]geocode)
+		wg      sync.WaitGroup
+		jobs    = make(chan SkipLocation)
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		goSafe(ctx, "geocoding", func() {
+			defer wg.Done()
+			for loc := range jobs {
+				g, ok := geocodeLocation(ctx, loc)
+				if !ok {
+					continue
+				}
+				slog.DebugContext(ctx, "Geocoded", "address", loc.Address, "postcode", loc.Postcode, "lat", g.Latitude, "lng", g.Longitude, "accuracy", g.Accuracy)
+
+				mu.Lock()
+				results[geocodeKey(loc)] = g
+				mu.Unlock()
+			}
+		})
 	}
 
-	if len(results) == 0 {
-		return 0, 0, fmt.Errorf("no geocode results for postcode %s", postcode)
+feed:
+	for _, key := range keys {
+		select {
+		case jobs <- byKey[key]:
+		case <-ctx.Done():
+			slog.WarnContext(ctx, "Geocoding stopped early", "err", ctx.Err())
+			break feed
+		}
 	}
+	close(jobs)
+	wg.Wait()
 
-	var lat, lng float64
-	if _, err := fmt.Sscanf(results[0].Lat, "%f", &lat); err != nil {
-		return 0, 0, fmt.Errorf("failed to parse latitude: %w", err)
+	applyCoordinates(locations, results)
+	cacheCoordinates(context.WithoutCancel(ctx), results)
+	slog.InfoContext(ctx, "Geocoding complete")
+}
+
+// geocodeLocation places a skip on its street, such as "Pountney Road,
+// SW11 5TU, London", falling back to its postcode when the street can't be
+// found or is out of bounds
+func geocodeLocation(ctx context.Context, loc SkipLocation) (geocode, bool) {
+	g := geocode{Address: loc.Address, Postcode: loc.Postcode}
+
+	if loc.Address != "" {
+		lat, lng, err := geocodeQuery(ctx, loc.Address+", "+loc.Postcode+", London")
+		if err == nil && inGeocodeBounds(Coordinates{lat, lng}) {
+			g.Coordinates, g.Accuracy = Coordinates{lat, lng}, AccuracyStreet
+			return g, true
+		}
+		slog.DebugContext(ctx, "Street not found, geocoding the postcode", "address", loc.Address, "postcode", loc.Postcode, "err", err)
 	}
-	if _, err := fmt.Sscanf(results[0].Lon, "%f", &lng); err != nil {
-		return 0, 0, fmt.Errorf("failed to parse longitude: %w", err)
+
+	lat, lng, err := geocodePostcode(ctx, loc.Postcode)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to geocode", "postcode", loc.Postcode, "err", err)
+		return geocode{}, false
 	}
+	checked, ok := checkGeocode(ctx, loc.Postcode, Coordinates{lat, lng})
+	g.Coordinates, g.Accuracy = checked.Coordinates, checked.Accuracy
+	return g, ok
+}
+
+// geocodePostcode converts a UK postcode to lat/lng using the configured geocoder
+func geocodePostcode(ctx context.Context, postcode string) (float64, float64, error) {
+	ctx, span := startSpan(ctx, "geocodePostcode")
+	defer span.End()
+	span.SetAttributes(attribute.String("postcode", postcode))
 
-	return lat, lng, nil
+	lat, lng, err := activeGeocoder.Geocode(ctx, postcode)
+	recordSpanError(span, err)
+	return lat, lng, err
 }
 
+// geocodeQuery converts a street address to lat/lng using the configured
+// geocoder
+func geocodeQuery(ctx context.Context, query string) (float64, float64, error) {
+	ctx, span := startSpan(ctx, "geocodeQuery")
+	defer span.End()
+	span.SetAttributes(attribute.String("query", query))
+
+	lat, lng, err := activeGeocoder.Geocode(ctx, query)
+	recordSpanError(span, err)
+	return lat, lng, err
+}
@@ -1,10 +1,65 @@
 package app
 
 import (
+	"context"
+	"html"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
+// councilFixture is a saved copy of the council's mega skip days page
+const councilFixture = "testdata/wandsworth/mega-skip-days.html"
+
+// fixtureSeeds returns the heading and list item texts from the council
+// fixture, for seeding fuzz targets and benchmarks with realistic input
+func fixtureSeeds(tb testing.TB) (headings, items []string) {
+	tb.Helper()
+
+	f, err := os.Open(councilFixture)
+	if err != nil {
+		tb.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		tb.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	doc.Find("h2, h3").Each(func(i int, s *goquery.Selection) {
+		headings = append(headings, s.Text())
+	})
+	doc.Find("li, p").Each(func(i int, s *goquery.Selection) {
+		items = append(items, s.Text())
+	})
+	return headings, items
+}
+
+// useTestSkips serves locations from a fresh memory cache for the rest of
+// the test. Give them coordinates, or background geocoding will run; it is
+// stopped before the cache is put back.
+func useTestSkips(tb testing.TB, locations []SkipLocation) {
+	tb.Helper()
+
+	stopBackgroundGeocoding()
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	invalidateDerived()
+	tb.Cleanup(func() {
+		stopBackgroundGeocoding()
+		activeCache = previous
+		invalidateDerived()
+	})
+	setSkipLocations(context.Background(), locations, time.Now().Add(time.Hour))
+}
+
 func TestParseSkipDate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -37,6 +92,54 @@ func TestParseSkipDate(t *testing.T) {
 			year:  2026,
 			want:  time.Date(2026, time.April, 5, 0, 0, 0, 0, time.UTC),
 		},
+		{
+			name:  "ordinal day",
+			input: "Saturday 31st January",
+			year:  2026,
+			want:  time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "abbreviated day and month",
+			input: "Sat 7 Mar",
+			year:  2026,
+			want:  time.Date(2026, time.March, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "abbreviated with ordinal and comma",
+			input: "Sun, 1st Feb:",
+			year:  2026,
+			want:  time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "heading with year",
+			input: "Saturday 2 January 2027",
+			year:  2026,
+			want:  time.Date(2027, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "range within a month",
+			input: "7 to 8 March",
+			year:  2026,
+			want:  time.Date(2026, time.March, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "range across months with year",
+			input: "Saturday 31 January – Sunday 1 February 2027",
+			year:  2026,
+			want:  time.Date(2027, time.January, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "range with weekdays",
+			input: "Saturday 7 and Sunday 8 March",
+			year:  2026,
+			want:  time.Date(2026, time.March, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid: no month",
+			input:   "Saturday 31",
+			year:    2026,
+			wantErr: true,
+		},
 		{
 			name:    "invalid: random text",
 			input:   "Dates and locations",
@@ -115,6 +218,57 @@ func TestParseLocationLine(t *testing.T) {
 			input:       "Some random text",
 			wantAddress: "",
 		},
+		{
+			name:         "unicode dash bullet",
+			input:        "– Larch Close, SW12 9SY",
+			wantAddress:  "Larch Close",
+			wantPostcode: "SW12 9SY",
+		},
+		{
+			name:         "stacked bullet and number",
+			input:        "• 3) Larch Close, SW12 9SY",
+			wantAddress:  "Larch Close",
+			wantPostcode: "SW12 9SY",
+		},
+		{
+			name:         "missing comma",
+			input:        "Larch Close SW12 9SY",
+			wantAddress:  "Larch Close",
+			wantPostcode: "SW12 9SY",
+		},
+		{
+			name:         "postcode without space in lower case",
+			input:        "Larch Close, sw129sy.",
+			wantAddress:  "Larch Close",
+			wantPostcode: "SW12 9SY",
+		},
+		{
+			name:         "postcode in brackets",
+			input:        "Morden Road (SW19 3BJ)",
+			wantAddress:  "Morden Road",
+			wantPostcode: "SW19 3BJ",
+		},
+		{
+			name:         "outcode only",
+			input:        "Tooting Bec Gardens, SW16",
+			wantAddress:  "Tooting Bec Gardens",
+			wantPostcode: "SW16",
+		},
+		{
+			name:        "malformed postcode",
+			input:       "Larch Close, SW12 Z9Y",
+			wantAddress: "",
+		},
+		{
+			name:        "not a location",
+			input:       "Skips will be removed at 12 noon, or when full",
+			wantAddress: "",
+		},
+		{
+			name:        "postcode with no address",
+			input:       "• SW12 9SY",
+			wantAddress: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -129,3 +283,148 @@ func TestParseLocationLine(t *testing.T) {
 		})
 	}
 }
+
+func FuzzParseSkipDate(f *testing.F) {
+	headings, _ := fixtureSeeds(f)
+	for _, h := range headings {
+		f.Add(h, 2026)
+	}
+	for _, seed := range []string{"Saturday 31st January", "Sat 31 Jan", "31/01", "  7 March  ", "Saturday – 7 March", "Saturday 29 February"} {
+		f.Add(seed, 2026)
+	}
+
+	f.Fuzz(func(t *testing.T, input string, year int) {
+		if year < 1 || year > 9999 {
+			t.Skip()
+		}
+
+		got, err := parseSkipDate(input, year)
+		if err != nil {
+			return
+		}
+		// Headings may give their own year
+		if got.Year() != year && !strings.Contains(input, strconv.Itoa(got.Year())) {
+			t.Errorf("parseSkipDate(%q, %d) = %v, year does not match", input, year, got)
+		}
+		if got.Location() != time.UTC || got.Hour() != 0 || got.Minute() != 0 || got.Second() != 0 {
+			t.Errorf("parseSkipDate(%q, %d) = %v, want midnight UTC", input, year, got)
+		}
+	})
+}
+
+func FuzzParseLocationLine(f *testing.F) {
+	_, items := fixtureSeeds(f)
+	for _, item := range items {
+		f.Add(item)
+	}
+	for _, seed := range []string{
+		"– Larch Close, SW12 9SY",
+		"—— Larch Close — SW12 9SY",
+		"‣ 12) Larch Close,,SW12 9SY",
+		"Larch Close SW129SY",
+		"Larch Close, sw12 9sy!!",
+		"Larch Close, SW12",
+		"Larch Close, SW12 Z9Y",
+		"Larch Close, ",
+		", SW12 9SY",
+		"Ｌarch Close，ＳＷ12 9SY",
+	} {
+		f.Add(seed)
+	}
+
+	date := time.Date(2026, time.April, 25, 0, 0, 0, 0, time.UTC)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got := parseLocationLine(input, date, "25 April")
+		if got.Address == "" {
+			if !reflect.DeepEqual(got, SkipLocation{}) {
+				t.Errorf("parseLocationLine(%q) = %+v, want a location with an address or none", input, got)
+			}
+			return
+		}
+		if strings.TrimSpace(got.Address) == "" {
+			t.Errorf("parseLocationLine(%q).Address = %q, want non-blank", input, got.Address)
+		}
+		if got.Postcode != strings.ToUpper(got.Postcode) {
+			t.Errorf("parseLocationLine(%q).Postcode = %q, want upper case", input, got.Postcode)
+		}
+		if !postcodePattern.MatchString(got.Postcode) && !isOutcode(got.Postcode) {
+			t.Errorf("parseLocationLine(%q).Postcode = %q, want a postcode or outcode", input, got.Postcode)
+		}
+		if !got.Date.Equal(date) {
+			t.Errorf("parseLocationLine(%q).Date = %v, want %v", input, got.Date, date)
+		}
+	})
+}
+
+func BenchmarkParseSkipDate(b *testing.B) {
+	headings, _ := fixtureSeeds(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, h := range headings {
+			parseSkipDate(h, 2026)
+		}
+	}
+}
+
+func BenchmarkParseLocationLine(b *testing.B) {
+	_, items := fixtureSeeds(b)
+	date := time.Date(2026, time.April, 25, 0, 0, 0, 0, time.UTC)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, item := range items {
+			parseLocationLine(item, date, "25 April")
+		}
+	}
+}
+
+func TestCurrentBuild(t *testing.T) {
+	info := currentBuild()
+	if info.Version == "" || info.GoVersion == "" {
+		t.Errorf("Expected a version and Go version, got %+v", info)
+	}
+	if got := shortCommit("0123456789abcdef"); got != "0123456" {
+		t.Errorf("shortCommit = %q", got)
+	}
+}
+
+func TestIndexNearestPostcode(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{
+		ID: "pountney-road", Address: "POUNTNEY ROAD", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: startOfDay(time.Now()).AddDate(0, 0, 7), DateStr: "Saturday 14th", Type: EventMegaSkip,
+	}})
+
+	rec := httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/?postcode=SW11", nil))
+	page := rec.Body.String()
+
+	for _, want := range []string{
+		`<div id="nearest-info" class="visible" data-skip-id="pountney-road">`,
+		"<strong>📍 Location:</strong> Pountney Road</div>",
+		"km from the centre of SW11</div>",
+		`<div class="skip-item nearest" data-skip-id="pountney-road">`,
+		`name="postcode" placeholder="Enter your postcode" value="SW11"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected the page to contain %q", want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/?postcode=nowhere", nil))
+	page = rec.Body.String()
+	if !strings.Contains(page, html.EscapeString(describeQueryError(errInvalidPostcode))) {
+		t.Error("Expected an invalid postcode to be explained")
+	}
+	if strings.Contains(page, "skip-item nearest") {
+		t.Error("Expected no nearest skip for an invalid postcode")
+	}
+
+	rec = httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/", nil))
+	if strings.Contains(rec.Body.String(), `id="nearest-info" class="visible"`) {
+		t.Error("Expected the nearest panel to stay hidden without a postcode")
+	}
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultArchiveKeep is how many archived pages are kept by default
+const defaultArchiveKeep = 100
+
+var (
+	// archivePath is a directory the raw HTML of every full scrape is saved
+	// to, set with SCRAPE_ARCHIVE_PATH, so parsing regressions can be
+	// reproduced and turned into test fixtures. Nothing is archived when
+	// it's empty.
+	archivePath string
+
+	// archiveKeep is how many archived pages are kept, oldest removed first
+	archiveKeep = defaultArchiveKeep
+)
+
+// archiveNamePattern matches the names given to archived pages, so only
+// those can be downloaded
+var archiveNamePattern = regexp.MustCompile(`^\d{8}T\d{6}\.\d{3}Z-[a-z0-9.-]+\.html$`)
+
+// archivedPage describes an archived page in /admin/archive listings
+type archivedPage struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ArchivedAt time.Time `json:"archivedAt"`
+}
+
+// archiveName names a page fetched from pageURL at fetchedAt. Names sort
+// in the order the pages were fetched.
+func archiveName(pageURL string, fetchedAt time.Time) string {
+	host := "page"
+	if u, err := url.Parse(pageURL); err == nil && u.Hostname() != "" {
+		host = strings.ToLower(u.Hostname())
+	}
+	host = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, host)
+	return fetchedAt.UTC().Format("20060102T150405.000Z") + "-" + host + ".html"
+}
+
+// archivePage saves the raw HTML of a scrape, logging rather than failing
+// the scrape if it can't
+func archivePage(pageURL string, body []byte, fetchedAt time.Time) {
+	if archivePath == "" {
+		return
+	}
+
+	name := archiveName(pageURL, fetchedAt)
+	if err := os.MkdirAll(archivePath, 0o755); err != nil {
+		slog.Warn("Failed to create scrape archive", "path", archivePath, "err", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(archivePath, name), body, 0o644); err != nil {
+		slog.Warn("Failed to archive scraped page", "name", name, "err", err)
+		return
+	}
+	pruneArchive()
+}
+
+// listArchive returns the archived pages, newest first
+func listArchive() ([]archivedPage, error) {
+	entries, err := os.ReadDir(archivePath)
+	if os.IsNotExist(err) {
+		return []archivedPage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pages := []archivedPage{}
+	for _, entry := range entries {
+		if !archiveNamePattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		pages = append(pages, archivedPage{Name: entry.Name(), Size: info.Size(), ArchivedAt: info.ModTime().UTC()})
+	}
+	slices.SortFunc(pages, func(a, b archivedPage) int { return strings.Compare(b.Name, a.Name) })
+	return pages, nil
+}
+
+// pruneArchive removes the oldest pages beyond archiveKeep
+func pruneArchive() {
+	pages, err := listArchive()
+	if err != nil || len(pages) <= archiveKeep {
+		return
+	}
+	for _, page := range pages[archiveKeep:] {
+		if err := os.Remove(filepath.Join(archivePath, page.Name)); err != nil {
+			slog.Warn("Failed to prune scrape archive", "name", page.Name, "err", err)
+		}
+	}
+}
+
+// HandleAdminArchive handles GET /admin/archive, listing archived pages,
+// and GET /admin/archive/{name}, downloading one
+func HandleAdminArchive(w http.ResponseWriter, r *http.Request) {
+	if !adminRequest(w, r, http.MethodGet) {
+		return
+	}
+	if archivePath == "" {
+		writeProblem(w, http.StatusNotFound, "Scrape archiving is not enabled")
+		return
+	}
+
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/admin/archive"), "/")
+	if name == "" {
+		pages, err := listArchive()
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to list scrape archive", "err", err)
+			w.Header().Set("Content-Type", "application/json")
+			writeProblem(w, http.StatusInternalServerError, "Failed to list archive")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pages)
+		return
+	}
+
+	if !archiveNamePattern.MatchString(name) {
+		writeProblem(w, http.StatusBadRequest, "Invalid archive name")
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(archivePath, name))
+	if os.IsNotExist(err) {
+		writeProblem(w, http.StatusNotFound, "Archived page not found")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to read archived page", "name", name, "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to read archived page")
+		return
+	}
+
+	// Served as a plain text download so the council's markup can't run
+	// on our origin
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Write(data)
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestArchiveName(t *testing.T) {
+	fetchedAt := time.Date(2026, 2, 7, 8, 30, 0, 0, time.UTC)
+	got := archiveName("https://www.wandsworth.gov.uk/mega-skip-days", fetchedAt)
+	if want := "20260207T083000.000Z-www.wandsworth.gov.uk.html"; got != want {
+		t.Errorf("archiveName = %q, want %q", got, want)
+	}
+	if !archiveNamePattern.MatchString(got) {
+		t.Errorf("%q doesn't match archiveNamePattern", got)
+	}
+}
+
+func TestArchivePrunesAndServesPages(t *testing.T) {
+	defer func(path string, keep int) { archivePath, archiveKeep = path, keep }(archivePath, archiveKeep)
+	archivePath, archiveKeep = t.TempDir(), 2
+	t.Setenv("ADMIN_TOKEN", "s3cret")
+
+	start := time.Date(2026, 2, 7, 8, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		archivePage(councilURL, []byte("<h3>page</h3>"), start.Add(time.Duration(i)*time.Hour))
+	}
+
+	pages, err := listArchive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 || pages[0].Name != archiveName(councilURL, start.Add(2*time.Hour)) {
+		t.Fatalf("Expected the newest 2 pages, got %+v", pages)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		HandleAdminArchive(w, req)
+		return w
+	}
+
+	w := get("/admin/archive")
+	var listed []archivedPage
+	if err := json.NewDecoder(w.Body).Decode(&listed); err != nil || len(listed) != 2 {
+		t.Errorf("Listing returned %d: %+v, %v", w.Code, listed, err)
+	}
+
+	w = get("/admin/archive/" + pages[1].Name)
+	if w.Code != http.StatusOK || w.Body.String() != "<h3>page</h3>" {
+		t.Errorf("Download returned %d: %q", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	if w := get("/admin/archive/..%2Fsecrets.html"); w.Code != http.StatusBadRequest {
+		t.Errorf("Traversal attempt returned %d", w.Code)
+	}
+	if w := get("/admin/archive/" + archiveName(councilURL, start)); w.Code != http.StatusNotFound {
+		t.Errorf("Pruned page returned %d", w.Code)
+	}
+}
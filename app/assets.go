@@ -0,0 +1,155 @@
+package app
+
+import (
+	"crypto/sha256"
+	"embed"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Leaflet is vendored into static/vendor so the map doesn't depend on a
+// CDN. Run go generate to fetch it, checked against pinned hashes; until
+// then the page loads it from unpkg.
+//
+//go:generate go run vendor_leaflet.go
+
+//go:embed static
+var staticFiles embed.FS
+
+// staticAsset is a minified static file served under a content-hashed URL
+type staticAsset struct {
+	url         string
+	contentType string
+	body        []byte
+	gzipBody    []byte
+}
+
+var (
+	staticAssets = make(map[string]*staticAsset)
+
+	stylesheet = loadStaticAsset("app.css", "text/css; charset=utf-8", minifyCSS)
+	script     = loadStaticAsset("app.js", "text/javascript; charset=utf-8", minifyJS)
+
+	leafletStylesheet = loadVendorAsset("leaflet.css", "text/css; charset=utf-8", dropRelativeImages)
+	leafletScript     = loadVendorAsset("leaflet.js", "text/javascript; charset=utf-8", nil)
+)
+
+// leafletCDN is where Leaflet is loaded from when it hasn't been vendored
+const leafletCDN = "https://unpkg.com/leaflet@1.9.4/dist/"
+
+// loadVendorAsset fingerprints a third-party file from static/vendor, which
+// is already minified, after applying rewrite if it is set. It returns nil
+// if the file hasn't been vendored.
+func loadVendorAsset(name, contentType string, rewrite func(string) string) *staticAsset {
+	if _, err := staticFiles.Open("static/vendor/" + name); err != nil {
+		return nil
+	}
+	if rewrite == nil {
+		rewrite = func(src string) string { return src }
+	}
+	return loadStaticAsset("vendor/"+name, contentType, rewrite)
+}
+
+var relativeImagePattern = regexp.MustCompile(`url\(["']?images/[^)]*\)`)
+
+// dropRelativeImages removes Leaflet's images/ backgrounds, which would
+// 404 next to the fingerprinted stylesheet. They are only for the default
+// marker and the layers control; the map's markers are inline SVG.
+func dropRelativeImages(src string) string {
+	return relativeImagePattern.ReplaceAllString(src, "none")
+}
+
+// assetURL is the self-hosted URL of asset, or its CDN URL if it is nil
+func assetURL(asset *staticAsset, cdnURL string) string {
+	if asset == nil {
+		return cdnURL
+	}
+	return asset.url
+}
+
+// selfHosted reports whether every asset the page loads is served from
+// /static/, so the CSP needn't allow the CDN
+func selfHosted() bool {
+	return leafletScript != nil && leafletStylesheet != nil
+}
+
+// loadStaticAsset reads, minifies and fingerprints an embedded static file
+func loadStaticAsset(name, contentType string, minify func(string) string) *staticAsset {
+	data, err := staticFiles.ReadFile("static/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("reading static asset %s: %v", name, err))
+	}
+
+	body := []byte(minify(string(data)))
+	gzipBody, err := gzipBytes(body)
+	if err != nil {
+		panic(fmt.Sprintf("compressing static asset %s: %v", name, err))
+	}
+
+	hash := sha256.Sum256(body)
+	ext := path.Ext(name)
+	asset := &staticAsset{
+		url:         fmt.Sprintf("/static/%s.%x%s", strings.TrimSuffix(name, ext), hash[:4], ext),
+		contentType: contentType,
+		body:        body,
+		gzipBody:    gzipBody,
+	}
+	staticAssets[asset.url] = asset
+	return asset
+}
+
+var cssCommentPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// minifyCSS strips comments, indentation and blank lines. Line breaks are
+// kept so selectors split across lines keep their meaning.
+func minifyCSS(src string) string {
+	return collapseLines(cssCommentPattern.ReplaceAllString(src, ""), nil)
+}
+
+// minifyJS strips indentation, blank lines and whole-line comments. It is
+// deliberately conservative: anything that needs a real parser (trailing
+// comments, string contents) is left alone.
+func minifyJS(src string) string {
+	return collapseLines(src, func(line string) bool {
+		return strings.HasPrefix(line, "//")
+	})
+}
+
+// collapseLines trims every line and drops blank lines and lines matching skip
+func collapseLines(src string, skip func(string) bool) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || (skip != nil && skip(line)) {
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// HandleStatic handles requests to /static/ (fingerprinted CSS and JS)
+func HandleStatic(w http.ResponseWriter, r *http.Request) {
+	asset, ok := staticAssets[r.URL.Path]
+	if !ok {
+		writeProblem(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	// The URL changes whenever the content does, so it can be cached forever
+	w.Header().Set("Content-Type", asset.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if acceptsGzip(r) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(asset.gzipBody)
+		return
+	}
+	w.Write(asset.body)
+}
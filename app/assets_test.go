@@ -0,0 +1,112 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMinifyCSS(t *testing.T) {
+	input := `/* header comment */
+#header {
+    color: white; /* trailing comment */
+}
+
+.a,
+.b {
+    margin: 0;
+}
+`
+	want := "#header {\ncolor: white;\n}\n.a,\n.b {\nmargin: 0;\n}\n"
+
+	if got := minifyCSS(input); got != want {
+		t.Errorf("minifyCSS() = %q, want %q", got, want)
+	}
+}
+
+func TestMinifyJS(t *testing.T) {
+	input := `// Initialize on load
+function init() {
+    const url = 'https://example.com'; // keep
+    return url;
+}
+
+init();
+`
+	want := "function init() {\nconst url = 'https://example.com'; // keep\nreturn url;\n}\ninit();\n"
+
+	if got := minifyJS(input); got != want {
+		t.Errorf("minifyJS() = %q, want %q", got, want)
+	}
+}
+
+func TestStaticAssetsReferencedByIndex(t *testing.T) {
+	useTestSkips(t, []SkipLocation{})
+	rec := httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/", nil))
+	page := rec.Body.String()
+
+	for _, asset := range []*staticAsset{stylesheet, script} {
+		if !strings.HasPrefix(asset.url, "/static/") {
+			t.Errorf("Asset URL %q should be under /static/", asset.url)
+		}
+		if !strings.Contains(page, asset.url) {
+			t.Errorf("Index page should reference %q", asset.url)
+		}
+	}
+}
+
+func TestHandleStatic(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HandleStatic(rec, httptest.NewRequest("GET", script.url, nil))
+
+	if rec.Code != 200 {
+		t.Fatalf("Status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("Cache-Control = %q, want long-lived immutable", rec.Header().Get("Cache-Control"))
+	}
+
+	rec = httptest.NewRecorder()
+	HandleStatic(rec, httptest.NewRequest("GET", "/static/app.js", nil))
+	if rec.Code != 404 {
+		t.Errorf("Unfingerprinted path status = %d, want 404", rec.Code)
+	}
+}
+
+func TestIndexSelfHostsVendoredLeaflet(t *testing.T) {
+	useTestSkips(t, []SkipLocation{})
+	oldScript, oldStylesheet := leafletScript, leafletStylesheet
+	t.Cleanup(func() { leafletScript, leafletStylesheet = oldScript, oldStylesheet })
+	leafletScript = &staticAsset{url: "/static/vendor/leaflet.0123abcd.js"}
+	leafletStylesheet = &staticAsset{url: "/static/vendor/leaflet.0123abcd.css"}
+
+	rec := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	page := rec.Body.String()
+	for _, url := range []string{leafletScript.url, leafletStylesheet.url} {
+		if !strings.Contains(page, url) {
+			t.Errorf("Page should load %s", url)
+		}
+	}
+	if strings.Contains(page, "unpkg.com") {
+		t.Error("Page should not load anything from unpkg once Leaflet is vendored")
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); strings.Contains(csp, "unpkg.com") {
+		t.Errorf("CSP should not allow unpkg once Leaflet is vendored, got %q", csp)
+	}
+}
+
+func TestDropRelativeImages(t *testing.T) {
+	input := ".leaflet-default-icon-path {\nbackground-image: url(images/marker-icon.png);\n}\n" +
+		".leaflet-retina .leaflet-control-layers-toggle {\nbackground-image: url(\"images/layers-2x.png\");\n}\n" +
+		".leaflet-container {\nbackground: url(data:image/png;base64,AA==);\n}\n"
+	want := ".leaflet-default-icon-path {\nbackground-image: none;\n}\n" +
+		".leaflet-retina .leaflet-control-layers-toggle {\nbackground-image: none;\n}\n" +
+		".leaflet-container {\nbackground: url(data:image/png;base64,AA==);\n}\n"
+
+	if got := dropRelativeImages(input); got != want {
+		t.Errorf("dropRelativeImages() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// auditKey holds the admin audit log in the shared cache, so every
+// instance's admin calls end up in one place
+const auditKey = "admin_audit"
+
+const (
+	// auditTTL keeps the log for a year after the last admin call
+	auditTTL = 365 * 24 * time.Hour
+	// auditKeep caps the log at the most recent calls
+	auditKeep = 1000
+	// auditPayloadLimit caps how much of a request body is recorded
+	auditPayloadLimit = 4096
+)
+
+// auditMu serialises read-modify-write updates of the audit log
+var auditMu sync.Mutex
+
+// auditEntry records one admin call. ADMIN_TOKEN is shared, so the actor is
+// whoever the caller says they are in X-Admin-Actor, alongside their IP.
+type auditEntry struct {
+	Time    time.Time       `json:"time"`
+	Actor   string          `json:"actor"`
+	IP      string          `json:"ip"`
+	Method  string          `json:"method"`
+	Path    string          `json:"path"`
+	Query   string          `json:"query,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Status  int             `json:"status"`
+}
+
+// audited records every authorized call to an admin handler in the audit
+// log. Unauthorized calls are only logged, so they can't push real entries
+// out of the log.
+func audited(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !bearerAuthorized(r, "ADMIN_TOKEN") {
+			slog.WarnContext(r.Context(), "Unauthorized admin request", "method", r.Method, "path", r.URL.Path, "ip", clientIP(r))
+			next(w, r)
+			return
+		}
+
+		body, _ := io.ReadAll(io.LimitReader(r.Body, auditPayloadLimit))
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+
+		entry := auditEntry{
+			Time:    time.Now().UTC(),
+			Actor:   r.Header.Get("X-Admin-Actor"),
+			IP:      clientIP(r),
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Query:   r.URL.RawQuery,
+			Payload: auditPayload(body),
+		}
+		if entry.Actor == "" {
+			entry.Actor = "unknown"
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		entry.Status = rec.status
+
+		slog.InfoContext(r.Context(), "Admin action", "actor", entry.Actor, "method", entry.Method, "path", entry.Path, "status", entry.Status)
+		if err := appendAudit(context.WithoutCancel(r.Context()), entry); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to write audit log", "err", err)
+		}
+	}
+}
+
+// auditPayload returns a request body for the audit log, with any secrets
+// in it redacted. Bodies that aren't JSON are kept as a string.
+func auditPayload(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		data, _ := json.Marshal(string(body))
+		return data
+	}
+	for name := range fields {
+		switch strings.ToLower(name) {
+		case "secret", "token", "password":
+			fields[name] = "[redacted]"
+		}
+	}
+	data, _ := json.Marshal(fields)
+	return data
+}
+
+// appendAudit adds an entry to the audit log, dropping the oldest beyond
+// auditKeep
+func appendAudit(ctx context.Context, entry auditEntry) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	entries, err := auditLog(ctx)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > auditKeep {
+		entries = entries[len(entries)-auditKeep:]
+	}
+	return setCachedValue(ctx, auditKey, entries, auditTTL)
+}
+
+// auditLog returns the recorded admin calls, oldest first
+func auditLog(ctx context.Context) ([]auditEntry, error) {
+	var entries []auditEntry
+	_, err := getCachedValue(ctx, auditKey, &entries)
+	return entries, err
+}
+
+// auditFilter selects audit entries from /admin/audit's query
+type auditFilter struct {
+	actor string
+	path  string
+	since time.Time
+	limit int
+}
+
+// parseAuditFilter reads ?actor=, ?path= (a prefix), ?since= (RFC 3339)
+// and ?limit= from query
+func parseAuditFilter(query url.Values) (auditFilter, error) {
+	f := auditFilter{actor: query.Get("actor"), path: query.Get("path")}
+	if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return f, errors.New("since must be an RFC 3339 time")
+		}
+		f.since = t
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return f, errors.New("limit must be a positive number")
+		}
+		f.limit = n
+	}
+	return f, nil
+}
+
+// match reports whether the filter selects e
+func (f auditFilter) match(e auditEntry) bool {
+	return (f.actor == "" || e.Actor == f.actor) &&
+		strings.HasPrefix(e.Path, f.path) &&
+		!e.Time.Before(f.since)
+}
+
+// HandleAdminAudit handles GET /admin/audit, listing the recorded admin
+// calls newest first, optionally filtered by actor, path prefix and time,
+// and limited to the latest ?limit=
+func HandleAdminAudit(w http.ResponseWriter, r *http.Request) {
+	if !adminRequest(w, r, http.MethodGet) {
+		return
+	}
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := auditLog(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to read audit log", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to read audit log")
+		return
+	}
+	slices.Reverse(entries)
+	matched := []auditEntry{}
+	for _, e := range entries {
+		if filter.limit > 0 && len(matched) == filter.limit {
+			break
+		}
+		if filter.match(e) {
+			matched = append(matched, e)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(matched)
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// errUnsupportedBorough is returned for locations outside every borough we
+// have data for
+var errUnsupportedBorough = errors.New("location is outside the supported boroughs")
+
+// Borough is an area with its own skip dataset
+type Borough struct {
+	ID   string
+	Name string
+	// Outcodes are the postcode areas that fall at least partly in the borough
+	Outcodes []string
+	// Boundary is a simplified outline of the borough as lat/lng vertices
+	Boundary [][2]float64
+}
+
+// boroughs are the boroughs with a supported dataset
+var boroughs = []Borough{
+	{
+		ID:       "wandsworth",
+		Name:     "Wandsworth",
+		Outcodes: []string{"SW4", "SW8", "SW11", "SW12", "SW15", "SW16", "SW17", "SW18", "SW19"},
+		// Roughly traced along the Thames, then the Lambeth, Merton and
+		// Richmond borders; accurate to a street or two
+		Boundary: [][2]float64{
+			{51.4690, -0.2210}, {51.4668, -0.2050}, {51.4640, -0.1880},
+			{51.4700, -0.1820}, {51.4820, -0.1700}, {51.4850, -0.1500},
+			{51.4865, -0.1280}, {51.4800, -0.1250}, {51.4700, -0.1360},
+			{51.4600, -0.1390}, {51.4520, -0.1430}, {51.4400, -0.1410},
+			{51.4300, -0.1390}, {51.4200, -0.1430}, {51.4140, -0.1600},
+			{51.4200, -0.1800}, {51.4250, -0.1950}, {51.4280, -0.2100},
+			{51.4250, -0.2300}, {51.4300, -0.2480}, {51.4420, -0.2560},
+			{51.4600, -0.2500}, {51.4700, -0.2300},
+		},
+	},
+	{
+		ID:       "lambeth",
+		Name:     "Lambeth",
+		Outcodes: []string{"SE1", "SE5", "SE11", "SE19", "SE21", "SE24", "SE27", "SW2", "SW4", "SW8", "SW9", "SW16"},
+		// Along the Thames from Waterloo, down the Southwark border to West
+		// Norwood, then back up the shared border with Wandsworth
+		Boundary: [][2]float64{
+			{51.5090, -0.1180}, {51.5060, -0.1080}, {51.4990, -0.1040},
+			{51.4880, -0.1000}, {51.4780, -0.0960}, {51.4700, -0.0930},
+			{51.4600, -0.0950}, {51.4520, -0.0980}, {51.4400, -0.1000},
+			{51.4300, -0.1000}, {51.4200, -0.1050}, {51.4120, -0.1150},
+			{51.4120, -0.1300}, {51.4200, -0.1430}, {51.4300, -0.1390},
+			{51.4400, -0.1410}, {51.4520, -0.1430}, {51.4600, -0.1390},
+			{51.4700, -0.1360}, {51.4800, -0.1250}, {51.4865, -0.1280},
+			{51.4950, -0.1230},
+		},
+	},
+}
+
+// defaultBoroughID is the borough of locations cached before sources were
+// recorded, all of which came from Wandsworth
+const defaultBoroughID = "wandsworth"
+
+// borough returns the ID of the borough the location is in
+func (l SkipLocation) borough() string {
+	if l.Borough == "" {
+		return defaultBoroughID
+	}
+	return l.Borough
+}
+
+// parseBoroughs parses a comma-separated list of borough IDs or scraper
+// names, as taken by the ?borough= query parameter. An empty list means all
+// boroughs.
+func parseBoroughs(value string) ([]string, error) {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" {
+			continue
+		}
+		_, scraped := scrapers[id]
+		if !scraped && !slices.ContainsFunc(boroughs, func(b Borough) bool { return b.ID == id }) {
+			return nil, fmt.Errorf("unknown borough %q", id)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// filterByBorough returns the locations in the given boroughs, or all
+// locations when ids is empty
+func filterByBorough(locations []SkipLocation, ids []string) []SkipLocation {
+	if len(ids) == 0 {
+		return locations
+	}
+
+	var filtered []SkipLocation
+	for _, loc := range locations {
+		if slices.Contains(ids, loc.borough()) {
+			filtered = append(filtered, loc)
+		}
+	}
+	return filtered
+}
+
+// boroughAt returns the supported borough containing lat, lng
+func boroughAt(lat, lng float64) (*Borough, bool) {
+	for i := range boroughs {
+		if pointInPolygon(lat, lng, boroughs[i].Boundary) {
+			return &boroughs[i], true
+		}
+	}
+	return nil, false
+}
+
+// boroughForOutcode returns the supported borough an outcode falls in
+func boroughForOutcode(outcode string) (*Borough, bool) {
+	outcode = strings.ToUpper(strings.TrimSpace(outcode))
+	for i := range boroughs {
+		for _, o := range boroughs[i].Outcodes {
+			if o == outcode {
+				return &boroughs[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// supportedBoroughNames lists the supported boroughs for error messages
+func supportedBoroughNames() string {
+	names := make([]string, len(boroughs))
+	for i, b := range boroughs {
+		names[i] = b.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// pointInPolygon reports whether lat, lng is inside polygon, by ray casting
+func pointInPolygon(lat, lng float64, polygon [][2]float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a[0] > lat) != (b[0] > lat) &&
+			lng < (b[1]-a[1])*(lat-a[0])/(b[0]-a[0])+a[1] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// unsupportedBoroughMessage explains which boroughs are covered
+func unsupportedBoroughMessage() string {
+	return fmt.Sprintf("Sorry, that's outside the area we cover (%s).", supportedBoroughNames())
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBoroughAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     string
+	}{
+		{"Wandsworth Town", 51.4567, -0.1910, "wandsworth"},
+		{"Tooting Broadway", 51.4275, -0.1680, "wandsworth"},
+		{"Putney", 51.4610, -0.2160, "wandsworth"},
+		{"Battersea Park", 51.4791, -0.1566, "wandsworth"},
+		{"Brixton", 51.4613, -0.1156, "lambeth"},
+		{"Kennington", 51.4880, -0.1060, "lambeth"},
+		{"Peckham", 51.4740, -0.0690, ""},
+		{"Wimbledon", 51.4214, -0.2064, ""},
+		{"Fulham", 51.4743, -0.2003, ""},
+		{"Manchester", 53.4808, -2.2426, ""},
+	}
+
+	for _, tt := range tests {
+		b, ok := boroughAt(tt.lat, tt.lng)
+		got := ""
+		if ok {
+			got = b.ID
+		}
+		if got != tt.want {
+			t.Errorf("boroughAt(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLocatePostcodeRejectsUnsupportedOutcodes(t *testing.T) {
+	if _, _, _, err := locatePostcode(context.Background(), "SW18"); err != nil {
+		t.Errorf("SW18 should be supported, got %v", err)
+	}
+	if _, _, _, err := locatePostcode(context.Background(), "M1"); !errors.Is(err, errUnsupportedBorough) {
+		t.Errorf("M1 error = %v, want errUnsupportedBorough", err)
+	}
+}
+
+func TestFilterByBorough(t *testing.T) {
+	locations := []SkipLocation{
+		{Address: "Legacy"},
+		{Address: "Wandsworth", Borough: "wandsworth"},
+		{Address: "Lambeth", Borough: "lambeth"},
+	}
+
+	ids, err := parseBoroughs(" Lambeth ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filterByBorough(locations, ids); len(got) != 1 || got[0].Address != "Lambeth" {
+		t.Errorf("Expected only Lambeth, got %+v", got)
+	}
+	if got := filterByBorough(locations, []string{"wandsworth"}); len(got) != 2 {
+		t.Errorf("Expected untagged locations to count as Wandsworth, got %+v", got)
+	}
+	if got := filterByBorough(locations, nil); len(got) != 3 {
+		t.Errorf("Expected every location without a filter, got %+v", got)
+	}
+	if _, err := parseBoroughs("croydon"); err == nil {
+		t.Error("Expected an error for an unsupported borough")
+	}
+}
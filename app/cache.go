@@ -2,11 +2,39 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
-// Cacher defines the interface for caching skip locations
+// Cacher defines the interface for caching skip locations, and any other
+// state as bytes under its own key
 type Cacher interface {
 	Get(ctx context.Context, key string) ([]SkipLocation, error)
 	Set(ctx context.Context, key string, data []SkipLocation, ttl time.Duration) error
+	GetBytes(ctx context.Context, key string) ([]byte, error)
+	SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// getCachedValue decodes the JSON value stored under key into v, reporting
+// whether there was one
+func getCachedValue(ctx context.Context, key string, v any) (bool, error) {
+	data, err := activeCache.GetBytes(ctx, key)
+	if err != nil || data == nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("unmarshaling %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// setCachedValue stores v as JSON under key
+func setCachedValue(ctx context.Context, key string, v any, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", key, err)
+	}
+	return activeCache.SetBytes(ctx, key, data, ttl)
 }
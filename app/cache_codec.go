@@ -0,0 +1,79 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// cacheCompressThreshold is the encoded size above which Redis values are
+// gzipped. Small values aren't worth the CPU.
+const cacheCompressThreshold = 1024
+
+// gzipValuePrefix marks a compressed value. Upstash returns values inside a
+// JSON string, so compressed bytes are base64 encoded to survive the trip.
+const gzipValuePrefix = "gz:"
+
+// encodeCacheValue marshals locations for Redis, compressing large payloads
+func encodeCacheValue(locations []SkipLocation) ([]byte, error) {
+	data, err := json.Marshal(locations)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling data: %w", err)
+	}
+	return compressCacheValue(data)
+}
+
+// compressCacheValue gzips values over cacheCompressThreshold
+func compressCacheValue(data []byte) ([]byte, error) {
+	if len(data) < cacheCompressThreshold {
+		return data, nil
+	}
+
+	compressed, err := gzipBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("compressing data: %w", err)
+	}
+	encoded := make([]byte, len(gzipValuePrefix)+base64.StdEncoding.EncodedLen(len(compressed)))
+	copy(encoded, gzipValuePrefix)
+	base64.StdEncoding.Encode(encoded[len(gzipValuePrefix):], compressed)
+	return encoded, nil
+}
+
+// decodeCacheValue reverses encodeCacheValue
+func decodeCacheValue(data []byte) ([]SkipLocation, error) {
+	data, err := decompressCacheValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var locations []SkipLocation
+	if err := json.Unmarshal(data, &locations); err != nil {
+		return nil, fmt.Errorf("unmarshaling locations: %w", err)
+	}
+	return locations, nil
+}
+
+// decompressCacheValue reverses compressCacheValue. Uncompressed values
+// written before compression was added decode as they always did.
+func decompressCacheValue(data []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(gzipValuePrefix))
+	if !ok {
+		return data, nil
+	}
+	compressed := make([]byte, base64.StdEncoding.DecodedLen(len(rest)))
+	n, err := base64.StdEncoding.Decode(compressed, rest)
+	if err != nil {
+		return nil, fmt.Errorf("decoding compressed value: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed[:n]))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing value: %w", err)
+	}
+	if data, err = io.ReadAll(zr); err != nil {
+		return nil, fmt.Errorf("decompressing value: %w", err)
+	}
+	return data, nil
+}
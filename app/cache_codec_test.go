@@ -0,0 +1,73 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacheValueCompressesLargePayloads(t *testing.T) {
+	var locations []SkipLocation
+	for i := 0; i < 50; i++ {
+		locations = append(locations, SkipLocation{Address: fmt.Sprintf("%d Long Road", i), Postcode: "SW18 1AA"})
+	}
+
+	encoded, err := encodeCacheValue(locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(encoded, []byte(gzipValuePrefix)) {
+		t.Fatalf("Expected a compressed value, got %.40q", encoded)
+	}
+
+	decoded, err := decodeCacheValue(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 50 || decoded[49].Address != "49 Long Road" {
+		t.Errorf("Round trip lost data: %+v", decoded)
+	}
+}
+
+func TestCacheValueLeavesSmallPayloadsAsJSON(t *testing.T) {
+	encoded, err := encodeCacheValue([]SkipLocation{{Address: "Short Road"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded[0] != '[' {
+		t.Errorf("Expected plain JSON, got %q", encoded)
+	}
+
+	// Values written before compression was added still decode
+	decoded, err := decodeCacheValue([]byte(`[{"address":"Old Road"}]`))
+	if err != nil || len(decoded) != 1 || decoded[0].Address != "Old Road" {
+		t.Errorf("decodeCacheValue = %+v, %v", decoded, err)
+	}
+}
+
+func TestCachedValueRoundTrip(t *testing.T) {
+	useTestSkips(t, nil)
+	ctx := context.Background()
+
+	want := map[string]geocode{
+		"Pountney Road, SW11 5TU": {Address: "Pountney Road", Postcode: "SW11 5TU", Coordinates: Coordinates{51.47, -0.16}, Accuracy: "street"},
+	}
+	if err := setCachedValue(ctx, "values", want, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]geocode
+	if ok, err := getCachedValue(ctx, "values", &got); !ok || err != nil {
+		t.Fatalf("getCachedValue = %v, %v", ok, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Round trip got %+v, want %+v", got, want)
+	}
+
+	if ok, err := getCachedValue(ctx, "missing", &got); ok || err != nil {
+		t.Errorf("Expected a miss, got %v, %v", ok, err)
+	}
+}
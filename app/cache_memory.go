@@ -14,6 +14,7 @@ type MemoryCache struct {
 
 type memoryCacheEntry struct {
 	locations []SkipLocation
+	value     []byte
 	expiresAt time.Time
 }
 
@@ -53,3 +54,36 @@ func (c *MemoryCache) Set(ctx context.Context, key string, data []SkipLocation,
 
 	return nil
 }
+
+// GetBytes retrieves a value from the memory cache
+func (c *MemoryCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.data[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, nil // Cache miss
+	}
+	return entry.value, nil
+}
+
+// SetBytes stores a value in the memory cache with the given TTL
+func (c *MemoryCache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data[key] = memoryCacheEntry{
+		value:     data,
+		expiresAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+// Delete removes data from the memory cache
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.data, key)
+	return nil
+}
@@ -22,12 +22,48 @@ func NewRedisCache(restURL, restToken string) *RedisCache {
 	return &RedisCache{
 		restURL:   restURL,
 		restToken: restToken,
-		client:    &http.Client{Timeout: 10 * time.Second},
+		client:    httpClient,
 	}
 }
 
 // Get retrieves data from Redis
 func (c *RedisCache) Get(ctx context.Context, key string) ([]SkipLocation, error) {
+	data, err := c.get(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return decodeCacheValue(data)
+}
+
+// Set stores data in Redis with the given TTL
+func (c *RedisCache) Set(ctx context.Context, key string, data []SkipLocation, ttl time.Duration) error {
+	value, err := encodeCacheValue(data)
+	if err != nil {
+		return err
+	}
+	return c.set(ctx, key, value, ttl)
+}
+
+// GetBytes retrieves a value from Redis
+func (c *RedisCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.get(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return decompressCacheValue(data)
+}
+
+// SetBytes stores a value in Redis with the given TTL
+func (c *RedisCache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	value, err := compressCacheValue(data)
+	if err != nil {
+		return err
+	}
+	return c.set(ctx, key, value, ttl)
+}
+
+// get returns the raw value of key, or nil on a miss
+func (c *RedisCache) get(ctx context.Context, key string) ([]byte, error) {
 	url := fmt.Sprintf("%s/get/%s", c.restURL, key)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -57,31 +93,44 @@ func (c *RedisCache) Get(ctx context.Context, key string) ([]SkipLocation, error
 	if result.Result == nil {
 		return nil, nil // Cache miss
 	}
+	return []byte(*result.Result), nil
+}
 
-	var locations []SkipLocation
-	if err := json.Unmarshal([]byte(*result.Result), &locations); err != nil {
-		return nil, fmt.Errorf("unmarshaling locations: %w", err)
-	}
+// set stores the raw value of key with the given TTL
+func (c *RedisCache) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ttlSeconds := int(ttl.Seconds())
+	url := fmt.Sprintf("%s/setex/%s/%d", c.restURL, key, ttlSeconds)
 
-	return locations, nil
-}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(value))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.restToken)
+	req.Header.Set("Content-Type", "application/json")
 
-// Set stores data in Redis with the given TTL
-func (c *RedisCache) Set(ctx context.Context, key string, data []SkipLocation, ttl time.Duration) error {
-	jsonData, err := json.Marshal(data)
+	resp, err := c.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("marshaling data: %w", err)
+		return fmt.Errorf("executing request: %w", err)
 	}
+	defer resp.Body.Close()
 
-	ttlSeconds := int(ttl.Seconds())
-	url := fmt.Sprintf("%s/setex/%s/%d", c.restURL, key, ttlSeconds)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+	return nil
+}
+
+// Delete removes data from Redis
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	url := fmt.Sprintf("%s/del/%s", c.restURL, key)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+c.restToken)
-	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.client.Do(req)
 	if err != nil {
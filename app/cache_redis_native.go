@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// nativeRedisPoolSize is the number of connections kept open
+const nativeRedisPoolSize = 10
+
+// NativeRedisCache implements Cache over the Redis protocol with go-redis,
+// for standard Redis and Valkey instances rather than Upstash's REST API.
+// go-redis pools connections and retries commands on ones the server has
+// closed.
+type NativeRedisCache struct {
+	client *redis.Client
+}
+
+// NewNativeRedisCache creates a cache from a redis:// or rediss:// (TLS) URL
+// of the form redis://[[user]:password@]host[:port][/db]
+func NewNativeRedisCache(redisURL string) (*NativeRedisCache, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing Redis URL: %w", err)
+	}
+	opts.PoolSize = nativeRedisPoolSize
+	opts.DialTimeout = 5 * time.Second
+	return &NativeRedisCache{client: redis.NewClient(opts)}, nil
+}
+
+// Get retrieves data from Redis
+func (c *NativeRedisCache) Get(ctx context.Context, key string) ([]SkipLocation, error) {
+	data, err := c.get(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return decodeCacheValue(data)
+}
+
+// Set stores data in Redis with the given TTL
+func (c *NativeRedisCache) Set(ctx context.Context, key string, data []SkipLocation, ttl time.Duration) error {
+	value, err := encodeCacheValue(data)
+	if err != nil {
+		return err
+	}
+	return c.set(ctx, key, value, ttl)
+}
+
+// GetBytes retrieves a value from Redis
+func (c *NativeRedisCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.get(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return decompressCacheValue(data)
+}
+
+// SetBytes stores a value in Redis with the given TTL
+func (c *NativeRedisCache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	value, err := compressCacheValue(data)
+	if err != nil {
+		return err
+	}
+	return c.set(ctx, key, value, ttl)
+}
+
+// get returns the raw value of key, or nil on a miss
+func (c *NativeRedisCache) get(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil // Cache miss
+	}
+	return data, err
+}
+
+// set stores the raw value of key with the given TTL. A value that has
+// already expired is deleted instead, as Redis refuses a zero expiry, and
+// one with under a millisecond left is kept for one.
+func (c *NativeRedisCache) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return c.Delete(ctx, key)
+	}
+	return c.client.Set(ctx, key, value, max(ttl, time.Millisecond)).Err()
+}
+
+// Delete removes data from Redis
+func (c *NativeRedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// tieredL1TTL bounds how long a warm instance serves data from memory before
+// checking Redis again, so instances don't drift far from the shared copy
+const tieredL1TTL = 5 * time.Minute
+
+// TieredCache implements Cache with an in-memory L1 in front of a shared L2
+type TieredCache struct {
+	l1    *MemoryCache
+	l2    Cacher
+	l1TTL time.Duration
+}
+
+// NewTieredCache creates a cache that checks memory before falling back to l2
+func NewTieredCache(l2 Cacher) *TieredCache {
+	return &TieredCache{
+		l1:    NewMemoryCache(),
+		l2:    l2,
+		l1TTL: tieredL1TTL,
+	}
+}
+
+// Get retrieves data from memory, or from L2 on a miss and keeps it in memory
+func (c *TieredCache) Get(ctx context.Context, key string) ([]SkipLocation, error) {
+	if locations, _ := c.l1.Get(ctx, key); locations != nil {
+		return locations, nil
+	}
+
+	locations, err := c.l2.Get(ctx, key)
+	if err != nil || locations == nil {
+		return locations, err
+	}
+
+	c.l1.Set(ctx, key, locations, c.l1TTL)
+	return locations, nil
+}
+
+// Set stores data in both tiers. The memory copy never outlives the L2 TTL.
+func (c *TieredCache) Set(ctx context.Context, key string, data []SkipLocation, ttl time.Duration) error {
+	c.l1.Set(ctx, key, data, min(ttl, c.l1TTL))
+
+	if err := c.l2.Set(ctx, key, data, ttl); err != nil {
+		slog.WarnContext(ctx, "Tiered cache L2 set error", "err", err)
+		return err
+	}
+	return nil
+}
+
+// GetBytes retrieves a value from memory, or from L2 on a miss
+func (c *TieredCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	if data, _ := c.l1.GetBytes(ctx, key); data != nil {
+		return data, nil
+	}
+
+	data, err := c.l2.GetBytes(ctx, key)
+	if err != nil || data == nil {
+		return data, err
+	}
+
+	c.l1.SetBytes(ctx, key, data, c.l1TTL)
+	return data, nil
+}
+
+// SetBytes stores a value in both tiers
+func (c *TieredCache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	c.l1.SetBytes(ctx, key, data, min(ttl, c.l1TTL))
+
+	if err := c.l2.SetBytes(ctx, key, data, ttl); err != nil {
+		slog.WarnContext(ctx, "Tiered cache L2 set error", "err", err)
+		return err
+	}
+	return nil
+}
+
+// Delete removes data from both tiers. Other instances keep their memory
+// copy until it expires, which is at most tieredL1TTL.
+func (c *TieredCache) Delete(ctx context.Context, key string) error {
+	c.l1.Delete(ctx, key)
+	return c.l2.Delete(ctx, key)
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// countingCache records how often Get reaches it
+type countingCache struct {
+	*MemoryCache
+	gets int
+}
+
+func (c *countingCache) Get(ctx context.Context, key string) ([]SkipLocation, error) {
+	c.gets++
+	return c.MemoryCache.Get(ctx, key)
+}
+
+func TestTieredCacheFillsMemoryOnMiss(t *testing.T) {
+	ctx := context.Background()
+	l2 := &countingCache{MemoryCache: NewMemoryCache()}
+	cache := NewTieredCache(l2)
+
+	want := []SkipLocation{{Address: "Shared Road"}}
+	if err := l2.Set(ctx, "key", want, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := cache.Get(ctx, "key")
+		if err != nil || len(got) != 1 || got[0].Address != "Shared Road" {
+			t.Fatalf("Get %d: got %+v, %v", i, got, err)
+		}
+	}
+	if l2.gets != 1 {
+		t.Errorf("Expected one L2 read, got %d", l2.gets)
+	}
+}
+
+func TestTieredCacheWritesThrough(t *testing.T) {
+	ctx := context.Background()
+	l2 := &countingCache{MemoryCache: NewMemoryCache()}
+	cache := NewTieredCache(l2)
+
+	if err := cache.Set(ctx, "key", []SkipLocation{{Address: "New Road"}}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := l2.MemoryCache.Get(ctx, "key"); len(got) != 1 {
+		t.Errorf("Expected L2 to hold the data, got %+v", got)
+	}
+	if got, _ := cache.Get(ctx, "key"); len(got) != 1 || l2.gets != 0 {
+		t.Errorf("Expected an L1 hit, got %+v after %d L2 reads", got, l2.gets)
+	}
+}
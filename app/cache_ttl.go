@@ -0,0 +1,46 @@
+package app
+
+import "time"
+
+// Adaptive TTL bounds. The council rarely changes a schedule once published,
+// so data can be held for a long time while the next skip day is distant,
+// but should be checked often on the day itself in case of cancellations.
+const (
+	adaptiveTTLSkipDay = 15 * time.Minute
+	adaptiveTTLDistant = 24 * time.Hour
+	// adaptiveDistantAfter is how far away the next skip day must be before
+	// the long TTL applies
+	adaptiveDistantAfter = 7 * 24 * time.Hour
+)
+
+// adaptiveTTL is set from CACHE_TTL_MODE=adaptive
+var adaptiveTTL bool
+
+// skipLocationsTTL returns how long freshly scraped locations should be
+// cached for
+func skipLocationsTTL(locations []SkipLocation, now time.Time) time.Duration {
+	if !adaptiveTTL {
+		return cacheTTL
+	}
+	return adaptiveCacheTTL(locations, now)
+}
+
+// adaptiveCacheTTL picks a TTL from how soon the next skip day is: short on
+// a skip day, long when it is more than a week away or nothing is scheduled,
+// and cacheTTL in between
+func adaptiveCacheTTL(locations []SkipLocation, now time.Time) time.Duration {
+	day, ok := nextSkipDay(locations, now)
+	if !ok {
+		return adaptiveTTLDistant
+	}
+
+	until := day.Date.Sub(startOfDay(now))
+	switch {
+	case until < 24*time.Hour:
+		return min(adaptiveTTLSkipDay, cacheTTL)
+	case until > adaptiveDistantAfter:
+		return max(adaptiveTTLDistant, cacheTTL)
+	default:
+		return cacheTTL
+	}
+}
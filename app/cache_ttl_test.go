@@ -0,0 +1,38 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveCacheTTL(t *testing.T) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, londonTime)
+	on := func(days int) []SkipLocation {
+		return []SkipLocation{{Date: time.Date(2025, 3, 10+days, 0, 0, 0, 0, time.UTC)}}
+	}
+
+	tests := []struct {
+		name      string
+		locations []SkipLocation
+		want      time.Duration
+	}{
+		{"nothing scheduled", nil, adaptiveTTLDistant},
+		{"skip day today", on(0), adaptiveTTLSkipDay},
+		{"skip day this week", on(3), cacheTTL},
+		{"skip day next month", on(30), adaptiveTTLDistant},
+		{"only past skip days", on(-2), adaptiveTTLDistant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := adaptiveCacheTTL(tt.locations, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipLocationsTTLFixedByDefault(t *testing.T) {
+	if got := skipLocationsTTL(nil, time.Now()); got != cacheTTL {
+		t.Errorf("got %v, want %v", got, cacheTTL)
+	}
+}
@@ -1,23 +1,70 @@
 package app
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"math"
 	"net/http"
 	"net/url"
-	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // CalendarEvent represents a single calendar event
 type CalendarEvent struct {
+	// UID identifies the event, and defaults to one per date and type
+	UID         string
 	Date        time.Time
+	Type        EventType
 	Title       string
 	Description string
 	Location    string
+	// OpensAt and ClosesAt are London times as "15:04"
+	OpensAt  string
+	ClosesAt string
+	// Cancelled events stay in the feed with STATUS:CANCELLED, so
+	// subscribers see the change rather than a stale event
+	Cancelled bool
+	// Alarms are reminders as RFC 5545 durations before the event starts
+	Alarms []string
+	// AllDay events cover the whole date rather than the opening hours
+	AllDay bool
+	// Sequence is how many times the event has changed, and Modified when
+	// it last did; see eventRevisionCache
+	Sequence int
+	Modified time.Time
+	// URL links to the event's page on the site
+	URL string
+	// Latitude and Longitude are where a single-skip event takes place,
+	// or zero when the event covers several skips or isn't geocoded
+	Latitude  float64
+	Longitude float64
+}
+
+// uid identifies the event in a feed
+func (e CalendarEvent) uid() string {
+	if e.UID != "" {
+		return e.UID
+	}
+	return generateUID(e.Date, e.Type)
+}
+
+// hours returns when the event starts and ends, defaulting to the usual
+// skip opening hours
+func (e CalendarEvent) hours() (opens, closes string) {
+	if e.OpensAt == "" || e.ClosesAt == "" {
+		return defaultOpensAt, defaultClosesAt
+	}
+	return e.OpensAt, e.ClosesAt
 }
 
 // haversineDistance calculates the distance in kilometers between two points
@@ -69,6 +116,14 @@ func findNearestSkipForDate(skips []SkipLocation, date time.Time, userLat, userL
 	return nearest
 }
 
+// skipEventLocation formats a skip as an event location, or "" for no skip
+func skipEventLocation(skip *SkipLocation) string {
+	if skip == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s, %s, London, UK", skip.Address, skip.Postcode)
+}
+
 // escapeICalText escapes special characters for iCal format
 func escapeICalText(text string) string {
 	text = strings.ReplaceAll(text, "\\", "\\\\")
@@ -78,110 +133,396 @@ func escapeICalText(text string) string {
 	return text
 }
 
-// generateUID creates a unique ID for an event based on the date
-func generateUID(date time.Time) string {
-	dateStr := date.Format("2006-01-02")
-	hash := sha256.Sum256([]byte(dateStr))
+// escapeICalParam quotes a property parameter value, which can't contain
+// double quotes even when quoted
+func escapeICalParam(text string) string {
+	return `"` + strings.ReplaceAll(text, `"`, "'") + `"`
+}
+
+// generateUID creates a unique ID for an event based on the date and type.
+// Mega skips keep their original date-only UIDs so existing subscribers
+// don't see duplicated events.
+func generateUID(date time.Time, typ EventType) string {
+	key := date.Format("2006-01-02")
+	if typ != "" && typ != EventMegaSkip {
+		key += "/" + string(typ)
+	}
+	hash := sha256.Sum256([]byte(key))
 	return fmt.Sprintf("%x@wheremegaskip.com", hash[:8])
 }
 
-// generateICalFeed generates an RFC 5545 compliant iCal feed
-func generateICalFeed(events []CalendarEvent) string {
-	var sb strings.Builder
+// icalWriterPool reuses buffered writers across calendar responses
+var icalWriterPool = sync.Pool{
+	New: func() any {
+		return bufio.NewWriterSize(nil, 16*1024)
+	},
+}
 
-	// Calendar header
-	sb.WriteString("BEGIN:VCALENDAR\r\n")
-	sb.WriteString("VERSION:2.0\r\n")
-	sb.WriteString("PRODID:-//WhereMegaSkip//Calendar//EN\r\n")
-	sb.WriteString("CALSCALE:GREGORIAN\r\n")
-	sb.WriteString("METHOD:PUBLISH\r\n")
-	sb.WriteString("NAME:Where Mega Skip?\r\n")
-	sb.WriteString("X-WR-CALNAME:Where Mega Skip?\r\n")
-	sb.WriteString("X-WR-TIMEZONE:Europe/London\r\n")
+// icalInvite identifies the parties of an iTIP REQUEST sent by email
+type icalInvite struct {
+	Organizer string
+	Attendee  string
+}
 
-	// VTIMEZONE component for Europe/London
-	sb.WriteString("BEGIN:VTIMEZONE\r\n")
-	sb.WriteString("TZID:Europe/London\r\n")
-	sb.WriteString("BEGIN:DAYLIGHT\r\n")
-	sb.WriteString("TZOFFSETFROM:+0000\r\n")
-	sb.WriteString("TZOFFSETTO:+0100\r\n")
-	sb.WriteString("TZNAME:BST\r\n")
-	sb.WriteString("DTSTART:19700329T010000\r\n")
-	sb.WriteString("RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\n")
-	sb.WriteString("END:DAYLIGHT\r\n")
-	sb.WriteString("BEGIN:STANDARD\r\n")
-	sb.WriteString("TZOFFSETFROM:+0100\r\n")
-	sb.WriteString("TZOFFSETTO:+0000\r\n")
-	sb.WriteString("TZNAME:GMT\r\n")
-	sb.WriteString("DTSTART:19701025T020000\r\n")
-	sb.WriteString("RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\n")
-	sb.WriteString("END:STANDARD\r\n")
-	sb.WriteString("END:VTIMEZONE\r\n")
+// writeICalFeed streams an RFC 5545 compliant iCal feed to w
+func writeICalFeed(w io.Writer, events []CalendarEvent) error {
+	return writeICalendar(w, events, nil, cacheTTL)
+}
 
-	// Generate events
+// writeICalInvite writes a single event as an RFC 5546 METHOD:REQUEST
+// invitation, which mail clients offer to add to the calendar directly
+func writeICalInvite(w io.Writer, event CalendarEvent, invite icalInvite) error {
+	return writeICalendar(w, []CalendarEvent{event}, &invite, 0)
+}
+
+// writeICalendar writes events as a published feed, or as an invitation
+// when invite is set. Feeds ask to be refreshed every refresh.
+func writeICalendar(w io.Writer, events []CalendarEvent, invite *icalInvite, refresh time.Duration) error {
+	bw := icalWriterPool.Get().(*bufio.Writer)
+	bw.Reset(w)
+	defer func() {
+		bw.Reset(nil)
+		icalWriterPool.Put(bw)
+	}()
+
+	// Calendar header
+	bw.WriteString("BEGIN:VCALENDAR\r\n")
+	bw.WriteString("VERSION:2.0\r\n")
+	bw.WriteString("PRODID:-//WhereMegaSkip//Calendar " + currentBuild().Version + "//EN\r\n")
+	bw.WriteString("CALSCALE:GREGORIAN\r\n")
+	if invite != nil {
+		bw.WriteString("METHOD:REQUEST\r\n")
+	} else {
+		bw.WriteString("METHOD:PUBLISH\r\n")
+	}
+	calName := escapeICalText(currentTenant().SiteTitle)
+	fmt.Fprintf(bw, "NAME:%s\r\n", calName)
+	fmt.Fprintf(bw, "X-WR-CALNAME:%s\r\n", calName)
+	bw.WriteString("X-WR-TIMEZONE:Europe/London\r\n")
+
+	// Generated timestamps, as of now
 	now := time.Now().UTC()
 	dtstamp := now.Format("20060102T150405Z")
 
-	for _, event := range events {
-		sb.WriteString("BEGIN:VEVENT\r\n")
-		sb.WriteString(fmt.Sprintf("UID:%s\r\n", generateUID(event.Date)))
-		sb.WriteString(fmt.Sprintf("DTSTAMP:%s\r\n", dtstamp))
+	if invite == nil && refresh > 0 {
+		// RFC 7986's REFRESH-INTERVAL, and Outlook's and Google's
+		// X-PUBLISHED-TTL, so clients poll about as often as the data can
+		// change
+		fmt.Fprintf(bw, "REFRESH-INTERVAL;VALUE=DURATION:%s\r\n", icalDuration(refresh))
+		fmt.Fprintf(bw, "X-PUBLISHED-TTL:%s\r\n", icalDuration(refresh))
+		// VCALENDAR can't have a COMMENT, so when the feed was generated
+		// is noted in an X- property, to help debug stale subscriptions
+		fmt.Fprintf(bw, "X-WHEREMEGASKIP-GENERATED:%s\r\n", dtstamp)
+	}
 
-		// Event start: 9am London time
-		dtstart := fmt.Sprintf("%04d%02d%02dT090000",
-			event.Date.Year(), event.Date.Month(), event.Date.Day())
-		sb.WriteString(fmt.Sprintf("DTSTART;TZID=Europe/London:%s\r\n", dtstart))
+	// VTIMEZONE component for Europe/London
+	bw.WriteString("BEGIN:VTIMEZONE\r\n")
+	bw.WriteString("TZID:Europe/London\r\n")
+	bw.WriteString("BEGIN:DAYLIGHT\r\n")
+	bw.WriteString("TZOFFSETFROM:+0000\r\n")
+	bw.WriteString("TZOFFSETTO:+0100\r\n")
+	bw.WriteString("TZNAME:BST\r\n")
+	bw.WriteString("DTSTART:19700329T010000\r\n")
+	bw.WriteString("RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\n")
+	bw.WriteString("END:DAYLIGHT\r\n")
+	bw.WriteString("BEGIN:STANDARD\r\n")
+	bw.WriteString("TZOFFSETFROM:+0100\r\n")
+	bw.WriteString("TZOFFSETTO:+0000\r\n")
+	bw.WriteString("TZNAME:GMT\r\n")
+	bw.WriteString("DTSTART:19701025T020000\r\n")
+	bw.WriteString("RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\n")
+	bw.WriteString("END:STANDARD\r\n")
+	bw.WriteString("END:VTIMEZONE\r\n")
 
-		// Event end: 12pm London time
-		dtend := fmt.Sprintf("%04d%02d%02dT120000",
-			event.Date.Year(), event.Date.Month(), event.Date.Day())
-		sb.WriteString(fmt.Sprintf("DTEND;TZID=Europe/London:%s\r\n", dtend))
+	// Generate events
+	for _, event := range events {
+		bw.WriteString("BEGIN:VEVENT\r\n")
+		if invite != nil {
+			// Invites get their own UID so accepting one doesn't clash
+			// with the same day in a subscribed feed
+			fmt.Fprintf(bw, "UID:invite-%s\r\n", generateUID(event.Date, event.Type))
+		} else {
+			fmt.Fprintf(bw, "UID:%s\r\n", event.uid())
+		}
+		fmt.Fprintf(bw, "DTSTAMP:%s\r\n", dtstamp)
+
+		if event.AllDay {
+			// DTEND is exclusive, so the event ends at the start of the next day
+			fmt.Fprintf(bw, "DTSTART;VALUE=DATE:%s\r\n", event.Date.Format("20060102"))
+			fmt.Fprintf(bw, "DTEND;VALUE=DATE:%s\r\n", event.Date.AddDate(0, 0, 1).Format("20060102"))
+			// Don't show the whole day as busy
+			bw.WriteString("TRANSP:TRANSPARENT\r\n")
+		} else {
+			// Opening hours in London time
+			opens, closes := event.hours()
+			fmt.Fprintf(bw, "DTSTART;TZID=Europe/London:%s\r\n", atClock(event.Date, opens).Format("20060102T150405"))
+			fmt.Fprintf(bw, "DTEND;TZID=Europe/London:%s\r\n", atClock(event.Date, closes).Format("20060102T150405"))
+		}
 
-		sb.WriteString(fmt.Sprintf("SUMMARY:%s\r\n", escapeICalText(event.Title)))
-		sb.WriteString(fmt.Sprintf("DESCRIPTION:%s\r\n", escapeICalText(event.Description)))
+		fmt.Fprintf(bw, "SUMMARY:%s\r\n", escapeICalText(event.Title))
+		fmt.Fprintf(bw, "DESCRIPTION:%s\r\n", escapeICalText(event.Description))
 
 		if event.Location != "" {
-			sb.WriteString(fmt.Sprintf("LOCATION:%s\r\n", escapeICalText(event.Location)))
+			fmt.Fprintf(bw, "LOCATION:%s\r\n", escapeICalText(event.Location))
 		}
+		if event.URL != "" {
+			fmt.Fprintf(bw, "URL:%s\r\n", event.URL)
+		}
+		if event.Latitude != 0 || event.Longitude != 0 {
+			fmt.Fprintf(bw, "GEO:%.6f;%.6f\r\n", event.Latitude, event.Longitude)
+			// Apple Calendar offers directions to a structured location
+			// rather than searching for the LOCATION text
+			if event.Location != "" {
+				title, _, _ := strings.Cut(event.Location, ",")
+				fmt.Fprintf(bw, "X-APPLE-STRUCTURED-LOCATION;VALUE=URI;X-ADDRESS=%s;X-APPLE-RADIUS=70;X-TITLE=%s:geo:%.6f,%.6f\r\n",
+					escapeICalParam(event.Location), escapeICalParam(title), event.Latitude, event.Longitude)
+			}
+		}
+
+		if invite == nil {
+			// Bumping the sequence tells clients the event has changed.
+			// Cancelled events were always sent as SEQUENCE:1, so never
+			// go below that for them.
+			sequence := event.Sequence
+			if event.Cancelled {
+				sequence = max(sequence, 1)
+			}
+			fmt.Fprintf(bw, "SEQUENCE:%d\r\n", sequence)
+			if !event.Modified.IsZero() {
+				fmt.Fprintf(bw, "LAST-MODIFIED:%s\r\n", event.Modified.UTC().Format("20060102T150405Z"))
+			}
+		}
+		if event.Cancelled {
+			bw.WriteString("STATUS:CANCELLED\r\n")
+		}
+
+		if invite != nil {
+			bw.WriteString("SEQUENCE:0\r\n")
+			bw.WriteString("STATUS:CONFIRMED\r\n")
+			fmt.Fprintf(bw, "ORGANIZER;CN=%s:mailto:%s\r\n", escapeICalParam(currentTenant().SiteTitle), invite.Organizer)
+			fmt.Fprintf(bw, "ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=FALSE:mailto:%s\r\n", invite.Attendee)
+		}
+
+		// Cancelled events don't remind anyone
+		if !event.Cancelled {
+			for _, alarm := range event.Alarms {
+				bw.WriteString("BEGIN:VALARM\r\n")
+				bw.WriteString("ACTION:DISPLAY\r\n")
+				fmt.Fprintf(bw, "DESCRIPTION:%s\r\n", escapeICalText(event.Title))
+				fmt.Fprintf(bw, "TRIGGER:-%s\r\n", alarm)
+				bw.WriteString("END:VALARM\r\n")
+			}
+		}
+
+		bw.WriteString("END:VEVENT\r\n")
+	}
+
+	bw.WriteString("END:VCALENDAR\r\n")
+	return bw.Flush()
+}
+
+// feedRefreshIntervalCache is how long the current skip locations are
+// cached for, which may be adaptive
+var feedRefreshIntervalCache = newDerived(func(locations []SkipLocation) (time.Duration, error) {
+	return skipLocationsTTL(locations, time.Now()), nil
+})
+
+// feedRefreshInterval is how often calendar clients should poll feeds
+func feedRefreshInterval(ctx context.Context) time.Duration {
+	refresh, err := feedRefreshIntervalCache.get(ctx)
+	if err != nil {
+		return cacheTTL
+	}
+	return refresh
+}
 
-		sb.WriteString("END:VEVENT\r\n")
+// icalDuration formats d as an RFC 5545 duration in its largest whole unit,
+// such as P1D, PT3H or PT90M
+func icalDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("P%dD", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("PT%dM", d/time.Minute)
+	default:
+		return fmt.Sprintf("PT%dS", d/time.Second)
 	}
+}
 
-	sb.WriteString("END:VCALENDAR\r\n")
-	return sb.String()
+// writeCalendarResponse writes events as an iCal attachment, with any
+// ?alarm= reminders, as all-day events with ?allday=1
+func writeCalendarResponse(w http.ResponseWriter, r *http.Request, events []CalendarEvent) {
+	alarms, err := parseAlarms(r.URL.Query()["alarm"])
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	allDay := false
+	if value := r.URL.Query().Get("allday"); value != "" {
+		if allDay, err = strconv.ParseBool(value); err != nil {
+			writeProblem(w, http.StatusBadRequest, "allday must be 1 or 0")
+			return
+		}
+	}
+
+	setCalendarHeaders(w)
+	err = writeRendered(w, func(buf io.Writer) error {
+		return renderCalendar(r.Context(), buf, events, alarms, allDay)
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error writing calendar", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate calendar")
+	}
+}
+
+// setCalendarHeaders marks the response as an iCal attachment
+func setCalendarHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.ics\"", currentTenant().FileSlug))
+}
+
+// renderCalendar sorts events by date and writes them as an iCal feed, with
+// their revisions and the given alarms
+func renderCalendar(ctx context.Context, w io.Writer, events []CalendarEvent, alarms []string, allDay bool) error {
+	revisions, err := eventRevisionCache.get(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Error getting event revisions", "err", err)
+	}
+	for i := range events {
+		events[i].Alarms = alarms
+		events[i].AllDay = allDay
+		revision := revisions[events[i].uid()]
+		events[i].Sequence, events[i].Modified = revision.Sequence, revision.Modified
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+
+	return writeICalendar(w, events, nil, feedRefreshInterval(ctx))
+}
+
+// defaultCalendar is /calendar.ics without any query, which is what most
+// subscribers poll, rendered and compressed once per refresh
+var defaultCalendar = newDerived(func(locations []SkipLocation) (*skipsPayload, error) {
+	var buf bytes.Buffer
+	if err := renderCalendar(context.Background(), &buf, dayEvents(locations), nil, false); err != nil {
+		return nil, fmt.Errorf("rendering calendar: %w", err)
+	}
+	return newPayload(buf.Bytes())
+})
+
+// calendarNotModified sets the caching headers for a calendar feed and
+// answers a conditional request for it. Feeds are derived from the dataset
+// by the URL alone, so they are validated without being rendered.
+func calendarNotModified(w http.ResponseWriter, r *http.Request) bool {
+	calendarCaching.apply(w)
+	version, err := currentDataset.get(r.Context())
+	if err != nil {
+		return false
+	}
+	return notModified(w, r, version.etagFor(r, ""), version.modified)
 }
 
 // HandleCalendarDefault handles requests to /calendar.ics (default feed, no location)
 func HandleCalendarDefault(w http.ResponseWriter, r *http.Request) {
-	locations, err := getSkipLocations()
+	locations, err := getSkipLocations(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to generate calendar", http.StatusInternalServerError)
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate calendar")
 		return
 	}
+	setWebSubLinks(w, r)
+	setCanonicalLink(w, "", r.URL.Query())
+	if calendarNotModified(w, r) {
+		return
+	}
+
+	if r.URL.RawQuery == "" {
+		payload, err := defaultCalendar.get(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Error rendering calendar", "err", err)
+			writeProblem(w, http.StatusInternalServerError, "Failed to generate calendar")
+			return
+		}
+		setCalendarHeaders(w)
+		payload.writeTo(w, r)
+		return
+	}
+
+	types, err := parseEventTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	boroughIDs, err := parseBoroughs(r.URL.Query().Get("borough"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeCalendarResponse(w, r, dayEvents(filterByBorough(filterByType(locations, types), boroughIDs)))
+}
 
-	// Group by date and create one event per date
-	groups := groupSkipsByDate(locations)
+// dayEvents groups locations by date, with one event per date and type
+func dayEvents(locations []SkipLocation) []CalendarEvent {
+	siteURL := currentTenant().SiteURL
 
 	var events []CalendarEvent
-	for date := range groups {
-		events = append(events, CalendarEvent{
-			Date:        date,
-			Title:       "Wandsworth Mega Skip",
-			Description: "https://wheremegaskip.com",
-			Location:    "",
-		})
+	for date, skips := range groupSkipsByDate(locations) {
+		for _, typ := range typesOf(skips) {
+			ofType := filterByType(skips, []EventType{typ})
+			cancelled := allCancelled(ofType)
+			if !cancelled {
+				ofType = scheduledSkips(ofType)
+			}
+			opens, closes := dayHours(ofType)
+			events = append(events, CalendarEvent{
+				Date:        date,
+				Type:        typ,
+				Title:       eventTitle(typ),
+				Description: eventDescription(siteURL, ofType),
+				Location:    "",
+				URL:         siteURL,
+				OpensAt:     opens,
+				ClosesAt:    closes,
+				Cancelled:   cancelled,
+			})
+		}
 	}
+	return events
+}
 
-	// Sort events by date
-	sort.Slice(events, func(i, j int) bool {
-		return events[i].Date.Before(events[j].Date)
-	})
+// maxNearestSkips caps ?nearest= on postcode feeds
+const maxNearestSkips = 10
 
-	ical := generateICalFeed(events)
+// parseNearestCount parses ?nearest=, how many of the closest skips a
+// postcode feed lists on each date, defaulting to 1
+func parseNearestCount(value string) (int, error) {
+	if value == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > maxNearestSkips {
+		return 0, fmt.Errorf("nearest must be a number from 1 to %d", maxNearestSkips)
+	}
+	return n, nil
+}
 
-	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
-	w.Header().Set("Content-Disposition", "attachment; filename=\"wandsworth-megaskip.ics\"")
-	w.Write([]byte(ical))
+// nearestDescription is the calendar description of several skips near a
+// point, nearest first with their distances, followed by what can and
+// can't be brought
+func nearestDescription(siteURL string, skips []SkipLocation, lat, lng float64) string {
+	lines := []string{siteURL, "", "Nearest skips:"}
+	for _, skip := range skips {
+		lines = append(lines, fmt.Sprintf("%s, %s (%.1f km)", skip.Address, skip.Postcode,
+			haversineDistance(lat, lng, skip.Latitude, skip.Longitude)))
+	}
+	if items := itemsDescription(skipItems(skips)); items != "" {
+		lines = append(lines, "", items)
+	}
+	return strings.Join(lines, "\n")
 }
 
 // HandleCalendarPostcode handles requests to /calendar/{postcode}.ics (personalized feed)
@@ -189,7 +530,7 @@ func HandleCalendarPostcode(w http.ResponseWriter, r *http.Request) {
 	// Extract postcode from path
 	path := r.URL.Path
 	if !strings.HasPrefix(path, "/calendar/") || !strings.HasSuffix(path, ".ics") {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		writeProblem(w, http.StatusBadRequest, "Invalid path")
 		return
 	}
 
@@ -199,58 +540,85 @@ func HandleCalendarPostcode(w http.ResponseWriter, r *http.Request) {
 
 	postcode, err := url.QueryUnescape(postcodeEncoded)
 	if err != nil {
-		http.Error(w, "Invalid postcode encoding", http.StatusBadRequest)
+		writeProblem(w, http.StatusBadRequest, "Invalid postcode encoding")
 		return
 	}
 
-	// Validate postcode format (basic UK postcode pattern)
-	postcodePattern := regexp.MustCompile(`^[A-Za-z]{1,2}\d{1,2}[A-Za-z]?\s?\d[A-Za-z]{2}$`)
-	if !postcodePattern.MatchString(postcode) {
-		http.Error(w, "Invalid postcode format", http.StatusBadRequest)
+	types, err := parseEventTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Geocode the user's postcode
-	userLat, userLng, err := geocodePostcode(postcode)
+	count, err := parseNearestCount(r.URL.Query().Get("nearest"))
 	if err != nil {
-		http.Error(w, "Could not find postcode location", http.StatusBadRequest)
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	setCanonicalLink(w, postcode, r.URL.Query())
+
+	// Answer polling calendar apps before geocoding the postcode
+	if calendarNotModified(w, r) {
 		return
 	}
 
-	locations, err := getSkipLocations()
+	// Geocode the user's postcode, or find the centre of an outcode
+	userLat, userLng, borough, err := locatePostcode(r.Context(), postcode)
+	if errors.Is(err, errInvalidPostcode) {
+		writeProblem(w, http.StatusBadRequest, "Invalid postcode format")
+		return
+	}
+	if errors.Is(err, errUnsupportedBorough) {
+		writeProblem(w, http.StatusUnprocessableEntity, unsupportedBoroughMessage())
+		return
+	}
 	if err != nil {
-		http.Error(w, "Failed to generate calendar", http.StatusInternalServerError)
+		writeProblem(w, http.StatusBadRequest, "Could not find postcode location")
 		return
 	}
 
-	// Group by date and find nearest skip for each date
-	groups := groupSkipsByDate(locations)
+	idx, err := skipIndexFor(r.Context(), borough)
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate calendar")
+		return
+	}
 
+	// Find nearest skip of each type for each date
+	siteURL := currentTenant().SiteURL
 	var events []CalendarEvent
-	for date, skips := range groups {
-		nearest := findNearestSkipForDate(skips, date, userLat, userLng)
-
-		var location string
-		if nearest != nil {
-			location = fmt.Sprintf("%s, %s, London, UK", nearest.Address, nearest.Postcode)
+	for _, date := range idx.dates {
+		for _, typ := range idx.types[date] {
+			if !includesType(types, typ) {
+				continue
+			}
+			nearest := idx.nearest(date, typ, userLat, userLng)
+
+			event := CalendarEvent{
+				Date:        date,
+				Type:        typ,
+				Title:       eventTitle(typ),
+				Description: siteURL,
+				Location:    skipEventLocation(nearest),
+				Cancelled:   idx.isCancelled(date, typ),
+				URL:         siteURL,
+			}
+			if nearest != nil {
+				event.OpensAt, event.ClosesAt = nearest.hours()
+				event.Description = eventDescription(siteURL, []SkipLocation{*nearest})
+				event.Latitude, event.Longitude = nearest.Latitude, nearest.Longitude
+				event.URL = skipURL(nearest.ID)
+			}
+			// With ?nearest=, list the alternatives in case the nearest is full
+			if count > 1 {
+				if skips := idx.nearestN(date, typ, userLat, userLng, count); len(skips) > 0 {
+					event.OpensAt, event.ClosesAt = dayHours(skips)
+					event.Description = nearestDescription(siteURL, skips, userLat, userLng)
+				}
+			}
+			events = append(events, event)
 		}
-
-		events = append(events, CalendarEvent{
-			Date:        date,
-			Title:       "Wandsworth Mega Skip",
-			Description: "https://wheremegaskip.com",
-			Location:    location,
-		})
 	}
 
-	// Sort events by date
-	sort.Slice(events, func(i, j int) bool {
-		return events[i].Date.Before(events[j].Date)
-	})
-
-	ical := generateICalFeed(events)
-
-	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
-	w.Header().Set("Content-Disposition", "attachment; filename=\"wandsworth-megaskip.ics\"")
-	w.Write([]byte(ical))
+	writeCalendarResponse(w, r, events)
 }
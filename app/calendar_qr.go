@@ -0,0 +1,59 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// qrModuleSize is the size of a QR code module in pixels
+const qrModuleSize = 8
+
+// HandleCalendarQR handles requests to /qr/calendar/{postcode}.png, a QR
+// code of the webcal:// URL for that postcode's feed. It takes the feed's
+// ?types= filter too.
+func HandleCalendarQR(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if !strings.HasPrefix(path, "/qr/calendar/") || !strings.HasSuffix(path, ".png") {
+		writeProblem(w, http.StatusNotFound, "Not found")
+		return
+	}
+	postcode, err := url.QueryUnescape(strings.TrimSuffix(strings.TrimPrefix(path, "/qr/calendar/"), ".png"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "Invalid postcode encoding")
+		return
+	}
+	postcode = strings.ToUpper(strings.TrimSpace(postcode))
+	if !postcodePattern.MatchString(postcode) && !isOutcode(postcode) {
+		writeProblem(w, http.StatusBadRequest, "Invalid postcode format")
+		return
+	}
+
+	query := url.Values{}
+	if types := r.URL.Query().Get("types"); types != "" {
+		if _, err := parseEventTypes(types); err != nil {
+			writeProblem(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		query.Set("types", types)
+	}
+
+	qr, err := encodeQR([]byte(webcalURL(postcode, query)))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "Calendar URL is too long for a QR code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	// The code depends only on the URL, so it can be cached for a day
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	err = writeRendered(w, func(buf io.Writer) error {
+		return qr.writePNG(buf, qrModuleSize)
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering QR code", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to render QR code")
+	}
+}
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -146,8 +150,8 @@ func TestEscapeICalText(t *testing.T) {
 func TestGenerateUID(t *testing.T) {
 	date := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
 
-	uid1 := generateUID(date)
-	uid2 := generateUID(date)
+	uid1 := generateUID(date, EventMegaSkip)
+	uid2 := generateUID(date, EventMegaSkip)
 
 	// Same date should produce same UID
 	if uid1 != uid2 {
@@ -161,13 +165,13 @@ func TestGenerateUID(t *testing.T) {
 
 	// Different date should produce different UID
 	differentDate := time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)
-	uid3 := generateUID(differentDate)
+	uid3 := generateUID(differentDate, EventMegaSkip)
 	if uid1 == uid3 {
 		t.Error("Different dates should produce different UIDs")
 	}
 }
 
-func TestGenerateICalFeed(t *testing.T) {
+func TestWriteICalFeed(t *testing.T) {
 	events := []CalendarEvent{
 		{
 			Date:        time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
@@ -183,13 +187,17 @@ func TestGenerateICalFeed(t *testing.T) {
 		},
 	}
 
-	ical := generateICalFeed(events)
+	var sb strings.Builder
+	if err := writeICalFeed(&sb, events); err != nil {
+		t.Fatalf("writeICalFeed() error = %v", err)
+	}
+	ical := sb.String()
 
 	// Check required iCal components
 	requiredStrings := []string{
 		"BEGIN:VCALENDAR",
 		"VERSION:2.0",
-		"PRODID:-//WhereMegaSkip//Calendar//EN",
+		"PRODID:-//WhereMegaSkip//Calendar " + currentBuild().Version + "//EN",
 		"BEGIN:VTIMEZONE",
 		"TZID:Europe/London",
 		"END:VTIMEZONE",
@@ -214,7 +222,7 @@ func TestGenerateICalFeed(t *testing.T) {
 	}
 }
 
-func TestGenerateICalFeedNoLocation(t *testing.T) {
+func TestWriteICalFeedNoLocation(t *testing.T) {
 	events := []CalendarEvent{
 		{
 			Date:        time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
@@ -224,10 +232,214 @@ func TestGenerateICalFeedNoLocation(t *testing.T) {
 		},
 	}
 
-	ical := generateICalFeed(events)
+	var sb strings.Builder
+	if err := writeICalFeed(&sb, events); err != nil {
+		t.Fatalf("writeICalFeed() error = %v", err)
+	}
+	ical := sb.String()
 
 	// Events without location should not have LOCATION field
 	if strings.Contains(ical, "LOCATION:") {
 		t.Error("iCal feed should not contain LOCATION field for events without location")
 	}
 }
+
+func TestWriteICalFeedCancelled(t *testing.T) {
+	events := []CalendarEvent{
+		{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip"},
+		{Date: time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip", Cancelled: true},
+	}
+
+	var sb strings.Builder
+	if err := writeICalFeed(&sb, events); err != nil {
+		t.Fatalf("writeICalFeed() error = %v", err)
+	}
+	ical := sb.String()
+
+	if strings.Count(ical, "STATUS:CANCELLED\r\n") != 1 || strings.Count(ical, "SEQUENCE:1\r\n") != 1 {
+		t.Errorf("Expected only the cancelled event to have STATUS:CANCELLED and SEQUENCE:1, got:\n%s", ical)
+	}
+	if i := strings.Index(ical, "STATUS:CANCELLED"); i < strings.Index(ical, "DTSTART;TZID=Europe/London:20250322") {
+		t.Error("Expected the second event to be the cancelled one")
+	}
+}
+
+func TestWriteICalFeedAlarms(t *testing.T) {
+	alarms := []string{"P1D", "PT2H"}
+	events := []CalendarEvent{
+		{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip", Alarms: alarms},
+		{Date: time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip", Alarms: alarms, Cancelled: true},
+	}
+
+	var sb strings.Builder
+	if err := writeICalFeed(&sb, events); err != nil {
+		t.Fatalf("writeICalFeed() error = %v", err)
+	}
+	ical := sb.String()
+
+	// Cancelled events don't remind anyone
+	if strings.Count(ical, "BEGIN:VALARM\r\n") != 2 {
+		t.Errorf("Expected two alarms on the scheduled event, got:\n%s", ical)
+	}
+	for _, want := range []string{"TRIGGER:-P1D\r\n", "TRIGGER:-PT2H\r\n", "ACTION:DISPLAY\r\nDESCRIPTION:Wandsworth Mega Skip\r\n"} {
+		if !strings.Contains(ical, want) {
+			t.Errorf("Expected %q in:\n%s", want, ical)
+		}
+	}
+}
+
+func TestWriteICalFeedGeo(t *testing.T) {
+	events := []CalendarEvent{
+		{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip", Location: "Pountney Road, SW11 5TU, London, UK", Latitude: 51.4655, Longitude: -0.1612},
+		{Date: time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip"},
+	}
+
+	var sb strings.Builder
+	if err := writeICalFeed(&sb, events); err != nil {
+		t.Fatalf("writeICalFeed() error = %v", err)
+	}
+	ical := sb.String()
+
+	if strings.Count(ical, "GEO:") != 1 || !strings.Contains(ical, "GEO:51.465500;-0.161200\r\n") {
+		t.Errorf("Expected GEO only on the located event, got:\n%s", ical)
+	}
+	want := `X-APPLE-STRUCTURED-LOCATION;VALUE=URI;X-ADDRESS="Pountney Road, SW11 5TU, London, UK";X-APPLE-RADIUS=70;X-TITLE="Pountney Road":geo:51.465500,-0.161200` + "\r\n"
+	if !strings.Contains(ical, want) {
+		t.Errorf("Expected %q in:\n%s", want, ical)
+	}
+}
+
+func TestParseNearestCount(t *testing.T) {
+	if n, err := parseNearestCount(""); n != 1 || err != nil {
+		t.Errorf("parseNearestCount(\"\") = %d, %v", n, err)
+	}
+	if n, err := parseNearestCount("3"); n != 3 || err != nil {
+		t.Errorf("parseNearestCount(\"3\") = %d, %v", n, err)
+	}
+	for _, invalid := range []string{"0", "-1", "11", "three"} {
+		if _, err := parseNearestCount(invalid); err == nil {
+			t.Errorf("parseNearestCount(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestNearestDescription(t *testing.T) {
+	skips := []SkipLocation{
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612},
+		{Address: "Garratt Lane", Postcode: "SW18 4DJ", Latitude: 51.4500, Longitude: -0.1900},
+	}
+	got := nearestDescription("https://wheremegaskip.com", skips, 51.4655, -0.1612)
+	want := "https://wheremegaskip.com\n\nNearest skips:\nPountney Road, SW11 5TU (0.0 km)\nGarratt Lane, SW18 4DJ (2.6 km)"
+	if got != want {
+		t.Errorf("nearestDescription() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteICalFeedAllDay(t *testing.T) {
+	events := []CalendarEvent{
+		{Date: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip", AllDay: true},
+	}
+
+	var sb strings.Builder
+	if err := writeICalFeed(&sb, events); err != nil {
+		t.Fatalf("writeICalFeed() error = %v", err)
+	}
+	ical := sb.String()
+
+	if !strings.Contains(ical, "DTSTART;VALUE=DATE:20250331\r\nDTEND;VALUE=DATE:20250401\r\nTRANSP:TRANSPARENT\r\n") {
+		t.Errorf("Expected an all-day event, got:\n%s", ical)
+	}
+	if strings.Contains(ical, "DTSTART;TZID") {
+		t.Errorf("All-day events shouldn't have a start time, got:\n%s", ical)
+	}
+}
+
+func TestCalendarAllDayParameter(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{
+		Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), Type: EventMegaSkip,
+	}})
+
+	w := httptest.NewRecorder()
+	HandleCalendarDefault(w, httptest.NewRequest(http.MethodGet, "/calendar.ics?allday=1", nil))
+	if body := w.Body.String(); !strings.Contains(body, "DTSTART;VALUE=DATE:20260314\r\n") {
+		t.Errorf("Expected all-day events, got:\n%s", body)
+	}
+
+	w = httptest.NewRecorder()
+	HandleCalendarDefault(w, httptest.NewRequest(http.MethodGet, "/calendar.ics?allday=maybe", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid allday, got %d", w.Code)
+	}
+}
+
+func TestCalendarDefaultPrerendered(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{
+		Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), Type: EventMegaSkip,
+	}})
+
+	w := httptest.NewRecorder()
+	HandleCalendarDefault(w, httptest.NewRequest(http.MethodGet, "/calendar.ics", nil))
+	plain := w.Body.String()
+	if !strings.Contains(plain, "BEGIN:VEVENT\r\n") {
+		t.Fatalf("Expected an event, got:\n%s", plain)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/calendar; charset=utf-8" {
+		t.Errorf("Expected an iCal content type, got %q", ct)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/calendar.ics", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	HandleCalendarDefault(w, r)
+	if enc := w.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("Expected a gzip response, got Content-Encoding %q", enc)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("Response is not valid gzip: %v", err)
+	}
+	decompressed, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to decompress response: %v", err)
+	}
+	if string(decompressed) != plain {
+		t.Error("gzip response should decompress to the plain feed")
+	}
+}
+
+func TestICalDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		3 * time.Hour:    "PT3H",
+		48 * time.Hour:   "P2D",
+		90 * time.Minute: "PT90M",
+		90 * time.Second: "PT90S",
+	} {
+		if got := icalDuration(d); got != want {
+			t.Errorf("icalDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestWriteICalFeedRefreshHints(t *testing.T) {
+	var sb strings.Builder
+	if err := writeICalendar(&sb, nil, nil, 6*time.Hour); err != nil {
+		t.Fatalf("writeICalendar() error = %v", err)
+	}
+	ical := sb.String()
+
+	for _, want := range []string{"REFRESH-INTERVAL;VALUE=DURATION:PT6H\r\n", "X-PUBLISHED-TTL:PT6H\r\n", "X-WHEREMEGASKIP-GENERATED:"} {
+		if !strings.Contains(ical, want) {
+			t.Errorf("Expected %q in:\n%s", want, ical)
+		}
+	}
+
+	sb.Reset()
+	if err := writeICalInvite(&sb, CalendarEvent{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)}, icalInvite{Organizer: "a@example.com", Attendee: "b@example.com"}); err != nil {
+		t.Fatalf("writeICalInvite() error = %v", err)
+	}
+	if strings.Contains(sb.String(), "REFRESH-INTERVAL") {
+		t.Error("Invitations aren't polled, so shouldn't have refresh hints")
+	}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Statuses of a skip location. Locations are scheduled unless the council
+// page says otherwise.
+const (
+	StatusScheduled = ""
+	StatusCancelled = "cancelled"
+	StatusPostponed = "postponed"
+)
+
+// cancellationPattern matches cancellation wording, with any brackets or
+// dash setting it apart, such as " - CANCELLED" or " (postponed)"
+var cancellationPattern = regexp.MustCompile(`(?i)\s*[-–—:(\[]?\s*\b(cancel+ed|postponed)\b\s*[)\]]?`)
+
+// cutStatus removes cancellation wording from text, returning the rest of
+// the text and the status it gives, so "Saturday 7 February - CANCELLED"
+// is the date "Saturday 7 February", cancelled
+func cutStatus(text string) (string, string) {
+	m := cancellationPattern.FindStringSubmatch(text)
+	if m == nil {
+		return text, StatusScheduled
+	}
+
+	status := StatusCancelled
+	if strings.EqualFold(m[1], StatusPostponed) {
+		status = StatusPostponed
+	}
+	rest := cancellationPattern.ReplaceAllString(text, "")
+	return strings.Trim(rest, " ,;:-–—"), status
+}
+
+// withStatus sets the location's status, unless it already has one
+func withStatus(loc SkipLocation, status string) SkipLocation {
+	if loc.Status == StatusScheduled {
+		loc.Status = status
+	}
+	return loc
+}
+
+// cancelled reports whether the skip won't go ahead on its date
+func (l SkipLocation) cancelled() bool {
+	return l.Status == StatusCancelled || l.Status == StatusPostponed
+}
+
+// scheduledSkips returns the skips that are going ahead
+func scheduledSkips(skips []SkipLocation) []SkipLocation {
+	var scheduled []SkipLocation
+	for _, skip := range skips {
+		if !skip.cancelled() {
+			scheduled = append(scheduled, skip)
+		}
+	}
+	return scheduled
+}
+
+// allCancelled reports whether every one of skips is cancelled, so there
+// is no event at all
+func allCancelled(skips []SkipLocation) bool {
+	return len(skips) > 0 && len(scheduledSkips(skips)) == 0
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCutStatus(t *testing.T) {
+	tests := []struct {
+		text   string
+		rest   string
+		status string
+	}{
+		{"Saturday 7 February", "Saturday 7 February", StatusScheduled},
+		{"Saturday 7 February - CANCELLED", "Saturday 7 February", StatusCancelled},
+		{"Saturday 7 February (postponed)", "Saturday 7 February", StatusPostponed},
+		{"Cancelled: Pountney Road, SW11 5TU", "Pountney Road, SW11 5TU", StatusCancelled},
+		{"Pountney Road, SW11 5TU [canceled]", "Pountney Road, SW11 5TU", StatusCancelled},
+		{"This skip day has been cancelled.", "This skip day has been.", StatusCancelled},
+	}
+
+	for _, tc := range tests {
+		rest, status := cutStatus(tc.text)
+		if rest != tc.rest || status != tc.status {
+			t.Errorf("cutStatus(%q) = %q, %q; want %q, %q", tc.text, rest, status, tc.rest, tc.status)
+		}
+	}
+}
+
+func TestParseCouncilPageCancellations(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`
+		<h3>Saturday 7 February - CANCELLED</h3>
+		<ul><li>Pountney Road, SW11 5TU</li></ul>
+		<h3>Saturday 14 February</h3>
+		<p>This skip day has been postponed due to bad weather.</p>
+		<ul><li>Larch Close, SW12 9SY</li></ul>
+		<h3>Saturday 21 February</h3>
+		<ul><li>Totterdown Street, SW17 8TB (cancelled)</li><li>Alton Estate, SW15 4DE</li></ul>
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locations := parseCouncilPage(doc, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	want := map[string]string{
+		"Pountney Road":     StatusCancelled,
+		"Larch Close":       StatusPostponed,
+		"Totterdown Street": StatusCancelled,
+		"Alton Estate":      StatusScheduled,
+	}
+	if len(locations) != len(want) {
+		t.Fatalf("Expected %d locations, got %+v", len(want), locations)
+	}
+	for _, loc := range locations {
+		if loc.Status != want[loc.Address] {
+			t.Errorf("Expected %s to have status %q, got %q", loc.Address, want[loc.Address], loc.Status)
+		}
+	}
+	if locations[0].DateStr != "Saturday 7 February" {
+		t.Errorf("Expected the cancellation to be cut from the date, got %q", locations[0].DateStr)
+	}
+}
+
+func TestSkipIndexCancelled(t *testing.T) {
+	date := time.Date(2026, 2, 7, 0, 0, 0, 0, time.UTC)
+	idx := newSkipIndex([]SkipLocation{
+		{Address: "Pountney Road", Date: date, Type: EventMegaSkip, Latitude: 51.46, Longitude: -0.16, Status: StatusCancelled},
+		{Address: "Larch Close", Date: date, Type: EventMegaSkip, Latitude: 51.44, Longitude: -0.15},
+		{Address: "Tree Road", Date: date, Type: EventChristmasTrees, Latitude: 51.46, Longitude: -0.16, Status: StatusCancelled},
+	})
+
+	if nearest := idx.nearest(date, EventMegaSkip, 51.46, -0.16); nearest == nil || nearest.Address != "Larch Close" {
+		t.Errorf("Expected the nearest skip going ahead, got %+v", nearest)
+	}
+	if idx.isCancelled(date, EventMegaSkip) {
+		t.Error("Expected mega skips to go ahead")
+	}
+	if !idx.isCancelled(date, EventChristmasTrees) || idx.nearest(date, EventChristmasTrees, 51.46, -0.16) != nil {
+		t.Error("Expected Christmas tree collection to be cancelled")
+	}
+}
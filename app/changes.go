@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Kinds of SkipChange
+const (
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+	ChangeCancelled = "cancelled"
+)
+
+// changeBuffer is how many batches of changes a slow subscriber can fall
+// behind before batches are dropped for it
+const changeBuffer = 8
+
+// SkipChange is a location added, removed or called off between two scrapes
+type SkipChange struct {
+	Kind string       `json:"kind"`
+	Skip SkipLocation `json:"skip"`
+}
+
+// diffSkips returns what changed from previous to current, matching
+// locations by ID. Skips that have simply passed are not reported as
+// removed.
+func diffSkips(previous, current []SkipLocation, now time.Time) []SkipChange {
+	before := make(map[string]SkipLocation, len(previous))
+	for _, l := range previous {
+		before[l.ID] = l
+	}
+	after := make(map[string]bool, len(current))
+
+	var changes []SkipChange
+	for _, l := range current {
+		after[l.ID] = true
+		prev, existed := before[l.ID]
+		switch {
+		case !existed:
+			changes = append(changes, SkipChange{Kind: ChangeAdded, Skip: l})
+		case l.cancelled() && !prev.cancelled():
+			changes = append(changes, SkipChange{Kind: ChangeCancelled, Skip: l})
+		}
+	}
+
+	today := startOfDay(now)
+	for _, l := range previous {
+		if !after[l.ID] && !l.Date.Before(today) {
+			changes = append(changes, SkipChange{Kind: ChangeRemoved, Skip: l})
+		}
+	}
+	return changes
+}
+
+var (
+	skipUpdatesMu sync.Mutex
+	// skipUpdates is when this instance saw each current skip added or
+	// called off
+	skipUpdates = make(map[string]time.Time)
+)
+
+// recordSkipUpdates notes when changes were seen, forgetting skips no
+// longer in current
+func recordSkipUpdates(current []SkipLocation, changes []SkipChange, now time.Time) {
+	skipUpdatesMu.Lock()
+	defer skipUpdatesMu.Unlock()
+
+	for _, c := range changes {
+		if c.Kind != ChangeRemoved {
+			skipUpdates[c.Skip.ID] = now
+		}
+	}
+	ids := make(map[string]bool, len(current))
+	for _, l := range current {
+		ids[l.ID] = true
+	}
+	for id := range skipUpdates {
+		if !ids[id] {
+			delete(skipUpdates, id)
+		}
+	}
+}
+
+// skipUpdatedAt returns when l was last seen to change, or fallback if it
+// hasn't changed since this instance started
+func skipUpdatedAt(l SkipLocation, fallback time.Time) time.Time {
+	skipUpdatesMu.Lock()
+	defer skipUpdatesMu.Unlock()
+
+	if t, ok := skipUpdates[l.ID]; ok {
+		return t
+	}
+	return fallback
+}
+
+// changeFeed fans each batch of changes out to its subscribers. It is per
+// instance, so subscribers hear about scrapes made by the instance they
+// are connected to; streams use a changeWatcher to hear about them all.
+type changeFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan []SkipChange]struct{}
+}
+
+// skipChanges publishes the changes found by each scrape
+var skipChanges = &changeFeed{subscribers: make(map[chan []SkipChange]struct{})}
+
+// subscribe returns a channel of change batches, and a function to call
+// once done with it
+func (f *changeFeed) subscribe() (<-chan []SkipChange, func()) {
+	ch := make(chan []SkipChange, changeBuffer)
+
+	f.mu.Lock()
+	f.subscribers[ch] = struct{}{}
+	f.mu.Unlock()
+
+	return ch, func() {
+		f.mu.Lock()
+		delete(f.subscribers, ch)
+		f.mu.Unlock()
+	}
+}
+
+// publish sends changes to every subscriber without waiting on any of them
+func (f *changeFeed) publish(changes []SkipChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subscribers {
+		select {
+		case ch <- changes:
+		default:
+			slog.Warn("Dropping skip changes for a slow subscriber", "changes", len(changes))
+		}
+	}
+}
+
+// changePollInterval is how often streams check the shared cache for a
+// newer snapshot
+var changePollInterval = 15 * time.Second
+
+// changeWatcher finds the changes made by scrapes on any instance. Each
+// scrape saves its locations as the snapshot in the shared cache, so a new
+// snapshot is diffed against the last one seen.
+type changeWatcher struct {
+	takenAt   time.Time
+	locations []SkipLocation
+}
+
+// newChangeWatcher starts from the current snapshot
+func newChangeWatcher(ctx context.Context) *changeWatcher {
+	cw := &changeWatcher{}
+	cw.poll(ctx, time.Now())
+	return cw
+}
+
+// poll returns the changes made since the last poll, if a scrape has
+// saved a new snapshot
+func (cw *changeWatcher) poll(ctx context.Context, now time.Time) []SkipChange {
+	takenAt := snapshotTakenAt(ctx)
+	if takenAt.IsZero() || takenAt.Equal(cw.takenAt) {
+		return nil
+	}
+	locations, err := activeCache.Get(ctx, snapshotCacheKey)
+	if err != nil || locations == nil {
+		return nil
+	}
+
+	// There's nothing to diff the first snapshot against
+	var changes []SkipChange
+	if !cw.takenAt.IsZero() {
+		changes = diffSkips(cw.locations, locations, now)
+	}
+	cw.takenAt, cw.locations = takenAt, locations
+	return changes
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffSkips(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	skip := func(id string, day int, status string) SkipLocation {
+		return SkipLocation{ID: id, Date: time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC), Status: status}
+	}
+
+	previous := []SkipLocation{
+		skip("passed", 7, ""),
+		skip("kept", 14, ""),
+		skip("dropped", 14, ""),
+		skip("called-off", 21, ""),
+		skip("already-off", 21, StatusCancelled),
+	}
+	current := []SkipLocation{
+		skip("kept", 14, ""),
+		skip("called-off", 21, StatusPostponed),
+		skip("already-off", 21, StatusCancelled),
+		skip("new", 28, ""),
+	}
+
+	got := diffSkips(previous, current, now)
+	want := []SkipChange{
+		{Kind: ChangeCancelled, Skip: current[1]},
+		{Kind: ChangeAdded, Skip: current[3]},
+		{Kind: ChangeRemoved, Skip: previous[2]},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i].Kind != want[i].Kind || got[i].Skip.ID != want[i].Skip.ID {
+			t.Errorf("Change %d: expected %s %s, got %s %s", i, want[i].Kind, want[i].Skip.ID, got[i].Kind, got[i].Skip.ID)
+		}
+	}
+
+	if changes := diffSkips(current, current, now); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
+
+func TestChangeFeed(t *testing.T) {
+	feed := &changeFeed{subscribers: make(map[chan []SkipChange]struct{})}
+	ch, unsubscribe := feed.subscribe()
+
+	feed.publish(nil)
+	feed.publish([]SkipChange{{Kind: ChangeAdded}})
+	select {
+	case batch := <-ch:
+		if len(batch) != 1 || batch[0].Kind != ChangeAdded {
+			t.Errorf("Unexpected batch %+v", batch)
+		}
+	default:
+		t.Fatal("Expected a batch")
+	}
+	select {
+	case batch := <-ch:
+		t.Errorf("Empty batches shouldn't be published, got %+v", batch)
+	default:
+	}
+
+	// A subscriber that stops reading doesn't block publishing
+	for range changeBuffer + 1 {
+		feed.publish([]SkipChange{{Kind: ChangeRemoved}})
+	}
+
+	unsubscribe()
+	if len(feed.subscribers) != 0 {
+		t.Error("Expected the subscriber to be removed")
+	}
+}
+
+func TestRecordSkipUpdates(t *testing.T) {
+	defer recordSkipUpdates(nil, nil, time.Now())
+
+	fallback := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	seen := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	kept, gone := SkipLocation{ID: "kept"}, SkipLocation{ID: "gone"}
+
+	recordSkipUpdates([]SkipLocation{kept, gone}, []SkipChange{{Kind: ChangeAdded, Skip: kept}, {Kind: ChangeAdded, Skip: gone}}, seen)
+	if got := skipUpdatedAt(kept, fallback); !got.Equal(seen) {
+		t.Errorf("Expected an added skip to be updated when seen, got %v", got)
+	}
+
+	recordSkipUpdates([]SkipLocation{kept}, []SkipChange{{Kind: ChangeRemoved, Skip: gone}}, seen.Add(time.Hour))
+	if got := skipUpdatedAt(kept, fallback); !got.Equal(seen) {
+		t.Errorf("Expected an unchanged skip to keep its update, got %v", got)
+	}
+	if got := skipUpdatedAt(gone, fallback); !got.Equal(fallback) {
+		t.Errorf("Expected a removed skip to be forgotten, got %v", got)
+	}
+}
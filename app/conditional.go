@@ -0,0 +1,216 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// councilPage is the last council page parsed by this instance, with the
+// validators needed to ask whether it has changed since
+type councilPage struct {
+	etag         string
+	lastModified string
+	year         int
+	locations    []SkipLocation
+}
+
+// maxPageSize bounds how much of a council page is read
+const maxPageSize = 10 << 20
+
+// councilPages holds the last *councilPage fetched from each URL, so
+// refreshes can send a conditional GET and skip parsing when the council
+// reports the page hasn't changed. It is per instance, so a cold start
+// always fetches pages in full.
+var councilPages sync.Map
+
+// pageParser extracts the skip locations from a council page
+type pageParser func(doc *goquery.Document, now time.Time) []SkipLocation
+
+// parsePage parses an HTML page with parse, returning the locations found
+// and a fingerprint of the page's structure
+func parsePage(r io.Reader, parse pageParser, now time.Time) ([]SkipLocation, pageFingerprint, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, pageFingerprint{}, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	locations := parse(doc, now)
+	return locations, fingerprintPage(doc, locations), nil
+}
+
+// fetchCouncilPage fetches the council page at pageURL and parses it with
+// parse. If the page is unchanged since the last fetch, the locations parsed
+// then are returned without downloading or parsing it again.
+func fetchCouncilPage(ctx context.Context, pageURL string, parse pageParser, now time.Time) ([]SkipLocation, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", scrapeUserAgent)
+
+	// Dates are parsed relative to the current year, so a page parsed last
+	// year has to be parsed again even if it hasn't changed
+	var prev *councilPage
+	if cached, ok := councilPages.Load(pageURL); ok && cached.(*councilPage).year == now.Year() {
+		prev = cached.(*councilPage)
+	}
+	if prev != nil {
+		if prev.etag != "" {
+			req.Header.Set("If-None-Match", prev.etag)
+		}
+		if prev.lastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.lastModified)
+		}
+	}
+
+	res, err := scrapeClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFetch, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified && prev != nil {
+		slog.InfoContext(ctx, "Council page not modified, reusing parsed locations", "locations", len(prev.locations))
+		return slices.Clone(prev.locations), nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, &statusError{StatusCode: res.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading body: %w", errFetch, err)
+	}
+	archivePage(pageURL, body, now)
+
+	locations, fingerprint, err := parsePage(bytes.NewReader(body), parse, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDrift(pageURL, fingerprint); err != nil {
+		return nil, err
+	}
+
+	page := &councilPage{
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+		year:         now.Year(),
+		locations:    slices.Clone(locations),
+	}
+	if page.etag != "" || page.lastModified != "" {
+		councilPages.Store(pageURL, page)
+	} else {
+		councilPages.Delete(pageURL)
+	}
+
+	return locations, nil
+}
+
+// datasetVersion identifies the current skip locations, for answering
+// conditional requests from clients without rendering a response
+type datasetVersion struct {
+	hash     string
+	modified time.Time
+}
+
+// currentDataset is the version of the skip locations last built. A
+// refresh that finds the same locations keeps its modified time.
+var (
+	currentDataset = newDerived(newDatasetVersion)
+	lastDataset    atomic.Pointer[datasetVersion]
+)
+
+// newDatasetVersion hashes locations, keeping the previous version if the
+// locations haven't changed
+func newDatasetVersion(locations []SkipLocation) (*datasetVersion, error) {
+	hash, err := hashLocations(locations)
+	if err != nil {
+		return nil, err
+	}
+
+	if prev := lastDataset.Load(); prev != nil && prev.hash == hash {
+		return prev, nil
+	}
+	version := &datasetVersion{hash: hash, modified: time.Now().UTC().Truncate(time.Second)}
+	lastDataset.Store(version)
+	return version, nil
+}
+
+// hashLocations identifies a set of locations
+func hashLocations(locations []SkipLocation) (string, error) {
+	data, err := json.Marshal(locations)
+	if err != nil {
+		return "", fmt.Errorf("marshaling locations: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%x", sum[:8]), nil
+}
+
+// etagFor returns a weak ETag for a response derived from this version of
+// the dataset by the request's path and query. It is weak because such
+// responses, like calendars with their DTSTAMPs, aren't byte-for-byte
+// stable. variant tells apart representations negotiated for the same
+// URL, such as the formats of /api/skips.
+func (v *datasetVersion) etagFor(r *http.Request, variant string) string {
+	key := v.hash + " " + r.URL.Path + "?" + r.URL.RawQuery
+	if variant != "" {
+		key += " " + variant
+	}
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("W/\"%x\"", sum[:8])
+}
+
+// notModified sets the ETag and Last-Modified validators and, if the
+// request's If-None-Match or If-Modified-Since shows the client already
+// has this response, writes a 304 and returns true. As in RFC 9110,
+// If-Modified-Since is ignored when If-None-Match is sent.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	match := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		match = etagMatches(inm, etag)
+	} else if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.IsZero() {
+		match = !modified.Truncate(time.Second).After(ims)
+	}
+	if !match {
+		return false
+	}
+
+	for _, h := range []string{"Content-Type", "Content-Disposition", "Content-Length"} {
+		w.Header().Del(h)
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison RFC 9110 requires for If-None-Match
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
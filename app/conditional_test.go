@@ -0,0 +1,171 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const conditionalTestPage = `<html><body>
+<h3>Saturday 31 January</h3>
+<ul><li>Pountney Road, SW11 5TU</li></ul>
+</body></html>`
+
+func TestFetchCouncilPageConditional(t *testing.T) {
+	t.Cleanup(func() { councilPages.Clear() })
+
+	var fetches, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if agent := r.Header.Get("User-Agent"); agent != scrapeUserAgent {
+			t.Errorf("User-Agent = %q, want %q", agent, scrapeUserAgent)
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(conditionalTestPage))
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	for i := range 2 {
+		locations, err := fetchCouncilPage(context.Background(), server.URL, parseCouncilPage, now)
+		if err != nil {
+			t.Fatalf("Fetch %d failed: %v", i, err)
+		}
+		if len(locations) != 1 || locations[0].Postcode != "SW11 5TU" {
+			t.Fatalf("Fetch %d returned %+v", i, locations)
+		}
+	}
+	if fetches != 2 || notModified != 1 {
+		t.Errorf("Got %d fetches with %d not modified, want 2 with 1", fetches, notModified)
+	}
+
+	// A new year means dates must be parsed again
+	if _, err := fetchCouncilPage(context.Background(), server.URL, parseCouncilPage, now.AddDate(1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if notModified != 1 {
+		t.Error("Expected a full fetch after the year changed")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	for _, tc := range []struct {
+		header, etag string
+		want         bool
+	}{
+		{`"abc"`, `"abc"`, true},
+		{`W/"abc"`, `"abc"`, true},
+		{`"abc"`, `W/"abc"`, true},
+		{`"xyz", "abc"`, `"abc"`, true},
+		{`*`, `"abc"`, true},
+		{`"xyz"`, `"abc"`, false},
+		{`abc`, `"abc"`, false},
+	} {
+		if got := etagMatches(tc.header, tc.etag); got != tc.want {
+			t.Errorf("etagMatches(%q, %q) = %v, want %v", tc.header, tc.etag, got, tc.want)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	check := func(header, value string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/calendar.ics", nil)
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		w.Header().Set("Content-Type", "text/calendar")
+		if notModified(w, r, `"v1"`, modified) {
+			w.WriteHeader(http.StatusNotModified)
+		}
+		return w
+	}
+
+	w := check("", "")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"v1"` || w.Header().Get("Last-Modified") != "Sat, 10 Jan 2026 12:00:00 GMT" {
+		t.Errorf("Expected validators on a 200, got %d %v", w.Code, w.Header())
+	}
+	if w := check("If-None-Match", `"v1"`); w.Code != http.StatusNotModified || w.Header().Get("Content-Type") != "" {
+		t.Errorf("Expected a bare 304 for a matching ETag, got %d %v", w.Code, w.Header())
+	}
+	if w := check("If-None-Match", `"v0"`); w.Code != http.StatusOK {
+		t.Errorf("Expected a 200 for a stale ETag, got %d", w.Code)
+	}
+	if w := check("If-Modified-Since", modified.Format(http.TimeFormat)); w.Code != http.StatusNotModified {
+		t.Errorf("Expected a 304 when unmodified since, got %d", w.Code)
+	}
+	if w := check("If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat)); w.Code != http.StatusOK {
+		t.Errorf("Expected a 200 when modified since, got %d", w.Code)
+	}
+}
+
+func TestConditionalResponses(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	invalidateDerived()
+	defer func() {
+		activeCache = previous
+		invalidateDerived()
+	}()
+
+	locations := []SkipLocation{{
+		Address:   "Pountney Road",
+		Postcode:  "SW11 5TU",
+		Date:      time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		Latitude:  51.4655,
+		Longitude: -0.1612,
+		Type:      EventMegaSkip,
+	}}
+	activeCache.Set(context.Background(), cacheKey, locations, time.Hour)
+
+	for _, tc := range []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/api/skips", HandleSkipsAPI},
+		{"/calendar.ics", HandleCalendarDefault},
+		{"/calendar/SW11.ics", HandleCalendarPostcode},
+	} {
+		w := httptest.NewRecorder()
+		tc.handler(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+		if etag == "" || lastModified == "" {
+			t.Errorf("%s: expected validators, got %v", tc.path, w.Header())
+			continue
+		}
+
+		r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		r.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		tc.handler(w, r)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s: expected a 304 for If-None-Match, got %d", tc.path, w.Code)
+		}
+
+		r = httptest.NewRequest(http.MethodGet, tc.path, nil)
+		r.Header.Set("If-Modified-Since", lastModified)
+		w = httptest.NewRecorder()
+		tc.handler(w, r)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("%s: expected a 304 for If-Modified-Since, got %d", tc.path, w.Code)
+		}
+	}
+
+	// A changed dataset invalidates both validators
+	version, _ := currentDataset.get(context.Background())
+	locations[0].Address = "Pountney Rd"
+	activeCache.Set(context.Background(), cacheKey, locations, time.Hour)
+	invalidateDerived()
+	changed, _ := currentDataset.get(context.Background())
+	if changed.hash == version.hash {
+		t.Error("Expected a new dataset hash after the locations changed")
+	}
+}
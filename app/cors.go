@@ -0,0 +1,99 @@
+package app
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsMaxAge is how long browsers may cache a preflight response
+const corsMaxAge = 24 * time.Hour
+
+// corsAllowedHeaders are the request headers cross-origin scripts may send
+var corsAllowedHeaders = []string{"Accept", "Content-Type", "If-Modified-Since", "If-None-Match", "X-API-Key", "X-Request-ID"}
+
+// corsExposedHeaders are the response headers cross-origin scripts may read
+var corsExposedHeaders = []string{"ETag", "Last-Modified", "X-Data-Snapshot-Time", "X-Data-Stale", "X-Request-ID"}
+
+// The origins and methods allowed to call /api/* from the browser. The data
+// is public, so by default any origin may read it.
+var (
+	corsOrigins = []string{"*"}
+	corsMethods = []string{http.MethodGet, http.MethodHead}
+)
+
+// configureCORS reads the allowed origins and methods from
+// CORS_ALLOWED_ORIGINS and CORS_ALLOWED_METHODS, both comma-separated.
+// CORS_ALLOWED_ORIGINS=none turns CORS off.
+func configureCORS() {
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		corsOrigins = nil
+		for _, origin := range strings.Split(origins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" && origin != "none" {
+				corsOrigins = append(corsOrigins, origin)
+			}
+		}
+	}
+	if methods := os.Getenv("CORS_ALLOWED_METHODS"); methods != "" {
+		corsMethods = nil
+		for _, method := range strings.Split(methods, ",") {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				corsMethods = append(corsMethods, method)
+			}
+		}
+	}
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for a
+// request's Origin, or "" if it isn't allowed
+func allowedOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	if slices.Contains(corsOrigins, "*") {
+		return "*"
+	}
+	if slices.Contains(corsOrigins, origin) {
+		return origin
+	}
+	return ""
+}
+
+// cors adds CORS headers to /api/* responses and answers preflight
+// requests, so browser apps on other sites can use the API
+func cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Responses differ by Origin unless every origin is allowed, and
+		// the CDN mustn't serve one origin's response to another
+		if !slices.Contains(corsOrigins, "*") {
+			w.Header().Add("Vary", "Origin")
+		}
+
+		origin := allowedOrigin(r.Header.Get("Origin"))
+		requestedMethod := r.Header.Get("Access-Control-Request-Method")
+		if r.Method == http.MethodOptions && requestedMethod != "" {
+			if origin != "" && slices.Contains(corsMethods, requestedMethod) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(corsMethods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if origin != "" && slices.Contains(corsMethods, r.Method) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	previousOrigins, previousMethods := corsOrigins, corsMethods
+	defer func() { corsOrigins, corsMethods = previousOrigins, previousMethods }()
+
+	handler := cors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	serve := func(method, path, origin, requestMethod string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if requestMethod != "" {
+			r.Header.Set("Access-Control-Request-Method", requestMethod)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// Any origin may read the API by default
+	w := serve(http.MethodGet, "/api/skips", "https://example.org", "")
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Expose-Headers") == "" {
+		t.Errorf("Expected CORS headers, got %v", w.Header())
+	}
+	if w := serve(http.MethodGet, "/calendar.ics", "https://example.org", ""); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Expected no CORS headers outside /api/")
+	}
+
+	w = serve(http.MethodOptions, "/api/skips", "https://example.org", http.MethodGet)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") != "GET, HEAD" || w.Body.Len() != 0 {
+		t.Errorf("Expected a preflight response, got %d %v", w.Code, w.Header())
+	}
+	if w := serve(http.MethodOptions, "/api/notify/teams", "https://example.org", http.MethodPost); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Expected POST preflights to be refused")
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://example.org, https://widgets.example.com")
+	t.Setenv("CORS_ALLOWED_METHODS", "get,post")
+	configureCORS()
+
+	w = serve(http.MethodGet, "/api/skips", "https://widgets.example.com", "")
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://widgets.example.com" || w.Header().Get("Vary") != "Origin" {
+		t.Errorf("Expected the origin echoed and varied on, got %v", w.Header())
+	}
+	if w := serve(http.MethodGet, "/api/skips", "https://evil.example", ""); w.Header().Get("Access-Control-Allow-Origin") != "" || w.Body.String() != "ok" {
+		t.Errorf("Expected other origins to get no CORS headers, got %v", w.Header())
+	}
+	if w := serve(http.MethodOptions, "/api/notify/teams", "https://example.org", http.MethodPost); w.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Errorf("Expected configured methods, got %v", w.Header())
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "none")
+	configureCORS()
+	if w := serve(http.MethodGet, "/api/skips", "https://example.org", ""); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Expected CORS to be off")
+	}
+}
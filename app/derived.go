@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// derived holds a value computed from the current skip locations, such as a
+// pre-rendered response or an index. It is built once per version of the
+// cached dataset and shared by every request until the dataset changes,
+// whichever instance changed it.
+type derived[T any] struct {
+	build func([]SkipLocation) (T, error)
+
+	mu      sync.RWMutex
+	value   T
+	built   bool
+	version string
+	// generation counts invalidations, so a build that started before one
+	// isn't kept
+	generation uint64
+}
+
+// allDerived lists every derived value so they can be invalidated together
+var allDerived []interface{ invalidate() }
+
+// newDerived registers a derived value built from the skip locations by build
+func newDerived[T any](build func([]SkipLocation) (T, error)) *derived[T] {
+	d := &derived[T]{build: build}
+	allDerived = append(allDerived, d)
+	return d
+}
+
+// get returns the current value, rebuilding it from getSkipLocations when
+// the cached dataset has changed or expired. Stale data has no version, so
+// values built from it are never kept.
+func (d *derived[T]) get(ctx context.Context) (T, error) {
+	version := skipLocationsVersion(ctx).Hash
+
+	d.mu.RLock()
+	value, ok := d.value, d.built && version != "" && d.version == version
+	generation := d.generation
+	d.mu.RUnlock()
+
+	if ok {
+		return value, nil
+	}
+
+	locations, err := getSkipLocations(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	value, err = d.build(locations)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// The locations may be newer than version, but never older, so at worst
+	// the value is built again next time
+	d.mu.Lock()
+	if d.generation == generation {
+		d.value, d.built, d.version = value, true, version
+	}
+	d.mu.Unlock()
+
+	return value, nil
+}
+
+// invalidate forces the next get to rebuild the value
+func (d *derived[T]) invalidate() {
+	d.mu.Lock()
+	d.built = false
+	d.generation++
+	d.mu.Unlock()
+}
+
+// invalidateDerived forces every derived value to be rebuilt, for use when
+// the cached dataset changes
+func invalidateDerived() {
+	for _, d := range allDerived {
+		d.invalidate()
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDerivedRebuildsWhenDatasetChanges(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{Address: "Garratt Lane"}})
+	ctx := context.Background()
+
+	builds := 0
+	d := &derived[int]{build: func(locations []SkipLocation) (int, error) {
+		builds++
+		return len(locations), nil
+	}}
+
+	for i := 0; i < 3; i++ {
+		if n, err := d.get(ctx); n != 1 || err != nil {
+			t.Fatalf("get = %d, %v", n, err)
+		}
+	}
+	if builds != 1 {
+		t.Errorf("Expected one build for one dataset, got %d", builds)
+	}
+
+	// Another instance refreshes the shared cache, without invalidating
+	// anything here
+	setSkipLocations(ctx, []SkipLocation{{Address: "Garratt Lane"}, {Address: "Pountney Road"}}, time.Now().Add(time.Hour))
+	if n, _ := d.get(ctx); n != 2 || builds != 2 {
+		t.Errorf("Expected a rebuild for the new dataset, got %d after %d builds", n, builds)
+	}
+}
+
+func TestDerivedDiscardsBuildInvalidatedMidway(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{Address: "Garratt Lane"}})
+	ctx := context.Background()
+
+	builds := 0
+	var d *derived[int]
+	d = &derived[int]{build: func(locations []SkipLocation) (int, error) {
+		builds++
+		if builds == 1 {
+			d.invalidate()
+		}
+		return builds, nil
+	}}
+
+	d.get(ctx)
+	if n, _ := d.get(ctx); n != 2 {
+		t.Errorf("A build invalidated while running should not be kept, got build %d", n)
+	}
+	if n, _ := d.get(ctx); n != 2 {
+		t.Errorf("Expected the second build to be kept, got build %d", n)
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// errParserDrift means a council page was fetched fine but no longer looks
+// the way its parser expects, so the parser needs updating
+var errParserDrift = errors.New("parser drift")
+
+// driftShrinkFactor is how many times fewer locations a page may yield than
+// last time before it's treated as drift rather than fewer skips
+const driftShrinkFactor = 4
+
+// driftMinLocations is the smallest previous count the shrink check
+// applies to, as small lists legitimately vary a lot
+const driftMinLocations = 10
+
+// pageFingerprint summarises the structure of a council page, to show what
+// changed when parsing stops working
+type pageFingerprint struct {
+	Headings  int
+	Lists     int
+	Tables    int
+	Dates     int
+	Locations int
+}
+
+func (f pageFingerprint) String() string {
+	return fmt.Sprintf("%d headings, %d lists, %d tables, %d dates, %d locations",
+		f.Headings, f.Lists, f.Tables, f.Dates, f.Locations)
+}
+
+// pageFingerprints holds the fingerprint of the last good parse of each
+// page URL on this instance
+var pageFingerprints sync.Map
+
+// driftError describes a page whose structure has drifted
+type driftError struct {
+	url      string
+	current  pageFingerprint
+	previous *pageFingerprint
+}
+
+func (e *driftError) Error() string {
+	msg := fmt.Sprintf("parser drift on %s: found %s", e.url, e.current)
+	if e.previous != nil {
+		msg += fmt.Sprintf(" (previously %s)", e.previous)
+	}
+	return msg
+}
+
+func (e *driftError) Unwrap() error { return errParserDrift }
+
+// fingerprintPage summarises the structure of doc and what was parsed from it
+func fingerprintPage(doc *goquery.Document, locations []SkipLocation) pageFingerprint {
+	dates := make(map[string]bool)
+	for _, loc := range locations {
+		dates[loc.Date.Format("2006-01-02")] = true
+	}
+
+	return pageFingerprint{
+		Headings:  doc.Find("h1, h2, h3, h4, h5, h6").Length(),
+		Lists:     doc.Find("ul, ol").Length(),
+		Tables:    doc.Find("table").Length(),
+		Dates:     len(dates),
+		Locations: len(locations),
+	}
+}
+
+// checkDrift compares a fresh parse of pageURL against the last good one.
+// A page that yields nothing, or far fewer locations than before, is
+// reported as drift rather than served as an empty or truncated dataset.
+func checkDrift(pageURL string, current pageFingerprint) error {
+	var previous *pageFingerprint
+	if cached, ok := pageFingerprints.Load(pageURL); ok {
+		f := cached.(pageFingerprint)
+		previous = &f
+	}
+
+	drifted := current.Locations == 0 ||
+		(previous != nil && previous.Locations >= driftMinLocations &&
+			current.Locations*driftShrinkFactor < previous.Locations)
+	if drifted {
+		return &driftError{url: pageURL, current: current, previous: previous}
+	}
+
+	pageFingerprints.Store(pageURL, current)
+	return nil
+}
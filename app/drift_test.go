@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckDrift(t *testing.T) {
+	const url = "https://example.com/drift"
+	t.Cleanup(func() { pageFingerprints.Delete(url) })
+
+	err := checkDrift(url, pageFingerprint{Headings: 3, Lists: 3, Dates: 0, Locations: 0})
+	if !errors.Is(err, errParserDrift) {
+		t.Fatalf("Expected drift for an empty parse, got %v", err)
+	}
+
+	good := pageFingerprint{Headings: 12, Lists: 10, Dates: 10, Locations: 40}
+	if err := checkDrift(url, good); err != nil {
+		t.Fatalf("Unexpected drift: %v", err)
+	}
+
+	// Fewer skips is fine, a collapse isn't
+	if err := checkDrift(url, pageFingerprint{Headings: 8, Lists: 6, Dates: 6, Locations: 24}); err != nil {
+		t.Errorf("Unexpected drift: %v", err)
+	}
+	err = checkDrift(url, pageFingerprint{Headings: 2, Lists: 1, Dates: 1, Locations: 2})
+	if !errors.Is(err, errParserDrift) {
+		t.Fatalf("Expected drift when locations collapse, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "previously 8 headings") {
+		t.Errorf("Expected the previous fingerprint in %q", err)
+	}
+	if retryable(err) {
+		t.Error("Drift shouldn't be retried")
+	}
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// sentryTimeout bounds how long sending one event may take
+const sentryTimeout = 5 * time.Second
+
+// reporter sends errors to Sentry, or is nil when SENTRY_DSN isn't set
+var reporter *sentryReporter
+
+// sentryReporter posts events to Sentry's store API. The protocol is small
+// enough that the SDK isn't needed.
+type sentryReporter struct {
+	storeURL    string
+	auth        string
+	environment string
+	client      *http.Client
+}
+
+// sentryEvent is the subset of Sentry's event payload we send
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	Environment string            `json:"environment,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Exception   *sentryExceptions `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// newSentryReporter parses a DSN of the form https://key@host/project
+func newSentryReporter(dsn string) (*sentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parsing DSN: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("DSN has no public key")
+	}
+
+	path, project, ok := cutLast(strings.TrimSuffix(u.Path, "/"), "/")
+	if !ok || project == "" {
+		return nil, fmt.Errorf("DSN has no project ID")
+	}
+
+	return &sentryReporter{
+		storeURL: fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path, project),
+		auth: fmt.Sprintf("Sentry sentry_version=7, sentry_client=wheremegaskip/1.0, sentry_key=%s",
+			u.User.Username()),
+		client: httpClient,
+	}, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// configureErrorReporting enables Sentry when SENTRY_DSN is set
+func configureErrorReporting() {
+	dsn, err := getSecret("SENTRY_DSN")
+	if err != nil {
+		slog.Warn("Failed to load SENTRY_DSN", "err", err)
+		return
+	}
+	if dsn == "" {
+		return
+	}
+
+	r, err := newSentryReporter(dsn)
+	if err != nil {
+		slog.Warn("Invalid SENTRY_DSN", "err", err)
+		return
+	}
+	r.environment = os.Getenv("SENTRY_ENVIRONMENT")
+	reporter = r
+	slog.Info("Reporting errors to Sentry")
+}
+
+// reportError sends err to Sentry in the background, tagged with the
+// request ID from ctx. It does nothing when reporting isn't configured.
+func reportError(ctx context.Context, err error, extra map[string]string) {
+	if reporter == nil || err == nil {
+		return
+	}
+
+	event := reporter.newEvent(ctx, "error")
+	event.Exception = &sentryExceptions{Values: []sentryException{{
+		Type:  fmt.Sprintf("%T", err),
+		Value: err.Error(),
+	}}}
+	event.Extra = extra
+	go reporter.send(context.WithoutCancel(ctx), event)
+}
+
+// reportPanic sends a recovered panic to Sentry with its stack trace
+func reportPanic(ctx context.Context, recovered any, stack []byte) {
+	if reporter == nil {
+		return
+	}
+
+	event := reporter.newEvent(ctx, "fatal")
+	event.Exception = &sentryExceptions{Values: []sentryException{{
+		Type:  "panic",
+		Value: fmt.Sprint(recovered),
+	}}}
+	event.Extra = map[string]string{"stack": string(stack)}
+	go reporter.send(context.WithoutCancel(ctx), event)
+}
+
+// newEvent starts an event with the fields common to every report
+func (s *sentryReporter) newEvent(ctx context.Context, level string) sentryEvent {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   time.Now().UTC(),
+		Level:       level,
+		Platform:    "go",
+		Logger:      "wheremegaskip",
+		Environment: s.environment,
+	}
+	if id := requestID(ctx); id != "" {
+		event.Tags = map[string]string{"request_id": id}
+	}
+	return event
+}
+
+// send posts an event, logging rather than returning any failure
+func (s *sentryReporter) send(ctx context.Context, event sentryEvent) {
+	ctx, cancel := context.WithTimeout(ctx, sentryTimeout)
+	defer cancel()
+
+	if err := s.post(ctx, event); err != nil {
+		slog.WarnContext(ctx, "Failed to report error to Sentry", "err", err)
+	}
+}
+
+func (s *sentryReporter) post(ctx context.Context, event sentryEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// recoverPanics turns a panicking handler into a 500 response, so a bad
+// parse can't take down the process, and reports the panic
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http uses this panic to abort a response on purpose
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			slog.ErrorContext(r.Context(), "Handler panicked", "panic", recovered, "stack", string(stack))
+			reportPanic(r.Context(), recovered, stack)
+			writeProblem(w, http.StatusInternalServerError, "Internal server error")
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// goSafe runs fn in a new goroutine, recovering and reporting any panic as
+// recoverPanics does for requests, so a bug in background work can't take
+// the whole process down
+func goSafe(ctx context.Context, name string, fn func()) {
+	go runSafe(ctx, name, fn)
+}
+
+// runSafe calls fn, recovering and reporting any panic. Long-running
+// goroutines use it for each piece of work, so one panic doesn't stop the
+// rest.
+func runSafe(ctx context.Context, name string, fn func()) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		stack := debug.Stack()
+		slog.ErrorContext(ctx, "Background task panicked", "task", name, "panic", recovered, "stack", string(stack))
+		reportPanic(ctx, recovered, stack)
+	}()
+	fn()
+}
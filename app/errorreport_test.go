@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewSentryReporter(t *testing.T) {
+	r, err := newSentryReporter("https://abc123@o1.ingest.sentry.io/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.storeURL != "https://o1.ingest.sentry.io/api/42/store/" {
+		t.Errorf("storeURL = %q", r.storeURL)
+	}
+	if !strings.Contains(r.auth, "sentry_key=abc123") {
+		t.Errorf("auth = %q", r.auth)
+	}
+
+	for _, bad := range []string{"https://o1.ingest.sentry.io/42", "https://abc@o1.ingest.sentry.io/"} {
+		if _, err := newSentryReporter(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestSentryReporterPostsEvent(t *testing.T) {
+	events := make(chan sentryEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event sentryEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	defer server.Close()
+
+	previous := reporter
+	reporter = &sentryReporter{storeURL: server.URL, client: server.Client()}
+	defer func() { reporter = previous }()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	ctx := req.Context()
+	reportError(ctx, errors.New("scraping failed"), map[string]string{"url": councilURL})
+
+	event := <-events
+	if event.Exception == nil || event.Exception.Values[0].Value != "scraping failed" || event.Extra["url"] != councilURL {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	handler := recoverPanics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("bad parse")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected a 500 after a panic, got %d", rec.Code)
+	}
+}
+
+func TestGoSafeReportsPanics(t *testing.T) {
+	events := make(chan sentryEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event sentryEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	defer server.Close()
+
+	previous := reporter
+	reporter = &sentryReporter{storeURL: server.URL, client: server.Client()}
+	defer func() { reporter = previous }()
+
+	goSafe(context.Background(), "test", func() { panic("bad refresh") })
+
+	event := <-events
+	if event.Level != "fatal" || event.Exception == nil || event.Exception.Values[0].Value != "bad refresh" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
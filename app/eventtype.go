@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EventType is the kind of collection a SkipLocation is for
+type EventType string
+
+const (
+	EventMegaSkip         EventType = "megaskip"
+	EventSmallElectricals EventType = "small-electricals"
+	EventChristmasTrees   EventType = "christmas-trees"
+	EventPopUpRecycling   EventType = "pop-up-recycling"
+	EventCommunitySkip    EventType = "community-skip"
+)
+
+// eventTypes lists every event type in display order
+var eventTypes = []EventType{
+	EventMegaSkip,
+	EventSmallElectricals,
+	EventChristmasTrees,
+	EventPopUpRecycling,
+	EventCommunitySkip,
+}
+
+// eventTypeLabels are the human-readable names of each event type
+var eventTypeLabels = map[EventType]string{
+	EventMegaSkip:         "Mega Skip",
+	EventSmallElectricals: "Small Electricals Collection",
+	EventChristmasTrees:   "Christmas Tree Collection",
+	EventPopUpRecycling:   "Pop-up Recycling",
+	EventCommunitySkip:    "Community Skip",
+}
+
+// eventType returns the location's type. Locations cached before types
+// were introduced have none and are mega skips.
+func (l SkipLocation) eventType() EventType {
+	if l.Type == "" {
+		return EventMegaSkip
+	}
+	return l.Type
+}
+
+// label returns the human-readable name of the event type
+func (t EventType) label() string {
+	if label, ok := eventTypeLabels[t]; ok {
+		return label
+	}
+	return string(t)
+}
+
+// eventTitle is the calendar and notification title for an event type
+func eventTitle(t EventType) string {
+	if t == EventMegaSkip {
+		return currentTenant().EventTitle
+	}
+	return t.label()
+}
+
+// parseEventTypes parses a comma-separated list of event types, as taken
+// by the ?type= and ?types= query parameters. An empty list means all types.
+func parseEventTypes(value string) ([]EventType, error) {
+	var types []EventType
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		t := EventType(name)
+		if _, ok := eventTypeLabels[t]; !ok {
+			return nil, fmt.Errorf("unknown event type %q", name)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// includesType reports whether typ is in types, where empty means all types
+func includesType(types []EventType, typ EventType) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// typesOf returns the event types present in locations, in display order
+func typesOf(locations []SkipLocation) []EventType {
+	present := make(map[EventType]bool)
+	for _, loc := range locations {
+		present[loc.eventType()] = true
+	}
+
+	var types []EventType
+	for _, t := range eventTypes {
+		if present[t] {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// filterByType returns the locations of the given types, or all locations
+// when types is empty
+func filterByType(locations []SkipLocation, types []EventType) []SkipLocation {
+	if len(types) == 0 {
+		return locations
+	}
+
+	var filtered []SkipLocation
+	for _, loc := range locations {
+		if includesType(types, loc.eventType()) {
+			filtered = append(filtered, loc)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEventTypes(t *testing.T) {
+	types, err := parseEventTypes(" megaskip, Small-Electricals ,,")
+	if err != nil {
+		t.Fatalf("parseEventTypes: %v", err)
+	}
+	if len(types) != 2 || types[0] != EventMegaSkip || types[1] != EventSmallElectricals {
+		t.Errorf("Got %v", types)
+	}
+
+	if types, err := parseEventTypes(""); err != nil || len(types) != 0 {
+		t.Errorf("Empty list should mean all types, got %v, %v", types, err)
+	}
+
+	if _, err := parseEventTypes("megaskip,bonfires"); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+}
+
+func TestFilterByTypeTreatsUntypedAsMegaSkip(t *testing.T) {
+	locations := []SkipLocation{
+		{Address: "Cached before types", Type: ""},
+		{Address: "Mega skip", Type: EventMegaSkip},
+		{Address: "Electricals", Type: EventSmallElectricals},
+	}
+
+	if got := filterByType(locations, nil); len(got) != 3 {
+		t.Errorf("No filter should keep every location, got %d", len(got))
+	}
+	if got := filterByType(locations, []EventType{EventMegaSkip}); len(got) != 2 {
+		t.Errorf("Got %d mega skips, want 2 including the untyped location", len(got))
+	}
+	if got := filterByType(locations, []EventType{EventSmallElectricals}); len(got) != 1 || got[0].Address != "Electricals" {
+		t.Errorf("Unexpected small electricals: %+v", got)
+	}
+}
+
+func TestSkipIndexSeparatesTypes(t *testing.T) {
+	date := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	idx := newSkipIndex([]SkipLocation{
+		{Address: "Near electricals", Date: date, Type: EventSmallElectricals, Latitude: 51.4567, Longitude: -0.1910},
+		{Address: "Far skip", Date: date, Type: EventMegaSkip, Latitude: 51.4400, Longitude: -0.2200},
+		{Address: "Ungeocoded trees", Date: date, Type: EventChristmasTrees},
+	})
+
+	if got := idx.types[date]; len(got) != 3 || got[0] != EventMegaSkip {
+		t.Errorf("types[date] = %v, want all three in display order", got)
+	}
+	if got := idx.nearest(date, EventMegaSkip, 51.4567, -0.1910); got == nil || got.Address != "Far skip" {
+		t.Errorf("Nearest mega skip = %+v", got)
+	}
+	if got := idx.nearest(date, EventChristmasTrees, 51.4567, -0.1910); got != nil {
+		t.Errorf("Expected no geocoded christmas tree collection, got %+v", got)
+	}
+}
+
+func TestGenerateUIDKeepsMegaSkipUIDs(t *testing.T) {
+	date := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+	if generateUID(date, "") != generateUID(date, EventMegaSkip) {
+		t.Error("Untyped and mega skip events should share a UID")
+	}
+	if generateUID(date, EventMegaSkip) == generateUID(date, EventSmallElectricals) {
+		t.Error("Different event types on the same date need different UIDs")
+	}
+}
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rssFeed is an RSS 2.0 document
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Language      string     `xml:"language"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	AtomLinks     []atomLink `xml:"atom:link"`
+	Items         []rssItem  `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// upcomingSkipDays groups the skips from today on by date, earliest first
+func upcomingSkipDays(locations []SkipLocation, now time.Time) []skipDay {
+	today := startOfDay(now)
+
+	var days []skipDay
+	for date, skips := range groupSkipsByDate(locations) {
+		if !date.Before(today) {
+			days = append(days, skipDay{Date: date, Skips: skips})
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days
+}
+
+// skipDayTitle names a skip day in a feed, marking days called off entirely
+func skipDayTitle(day skipDay) string {
+	title := fmt.Sprintf("%s day: %s", currentTenant().EventTitle, formatSkipDate(day.Date))
+	if allCancelled(day.Skips) {
+		title = "Cancelled: " + title
+	}
+	return title
+}
+
+// skipDayHTML lists a day's locations and their hours as HTML, for feed
+// readers to render
+func skipDayHTML(day skipDay) string {
+	var b strings.Builder
+	b.WriteString("<ul>")
+	for _, skip := range day.Skips {
+		opens, closes := skip.hours()
+		fmt.Fprintf(&b, "<li>%s, %s (%s–%s)", html.EscapeString(skip.Address), html.EscapeString(skip.Postcode), opens, closes)
+		if skip.cancelled() {
+			fmt.Fprintf(&b, " – %s", html.EscapeString(skip.Status))
+		}
+		b.WriteString("</li>")
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
+
+// writeRSSFeed writes one item per upcoming skip day
+func writeRSSFeed(w io.Writer, locations []SkipLocation, now time.Time) error {
+	tenant := currentTenant()
+	siteURL := strings.TrimSuffix(tenant.SiteURL, "/")
+
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         tenant.SiteTitle,
+			Link:          siteURL + "/",
+			Description:   tenant.Description,
+			Language:      "en-gb",
+			LastBuildDate: now.UTC().Format(time.RFC1123Z),
+			AtomLinks:     []atomLink{{Href: siteURL + "/feed.rss", Rel: "self", Type: "application/rss+xml"}},
+		},
+	}
+	if websubHub != "" {
+		feed.Channel.AtomLinks = append(feed.Channel.AtomLinks, atomLink{Href: websubHub, Rel: "hub"})
+	}
+	for _, day := range upcomingSkipDays(locations, now) {
+		opens, _ := dayHours(scheduledSkips(day.Skips))
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       skipDayTitle(day),
+			Link:        siteURL + "/",
+			Description: skipDayHTML(day),
+			PubDate:     atClock(day.Date, opens).Format(time.RFC1123Z),
+			GUID:        rssGUID{Value: fmt.Sprintf("%s/#%s", siteURL, day.Date.Format("2006-01-02"))},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
+
+// HandleRSSFeed handles requests to /feed.rss
+func HandleRSSFeed(w http.ResponseWriter, r *http.Request) {
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate feed")
+		return
+	}
+
+	setWebSubLinks(w, r)
+	calendarCaching.apply(w)
+	setSnapshotHeaders(w)
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	err = writeRendered(w, func(buf io.Writer) error {
+		return writeRSSFeed(buf, locations, time.Now())
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error writing RSS feed", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate feed")
+	}
+}
+
+// atomFeed is an Atom 1.0 document
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// skipEntryHTML describes one skip's hours and items as HTML
+func skipEntryHTML(skip SkipLocation) string {
+	opens, closes := skip.hours()
+	var b strings.Builder
+	fmt.Fprintf(&b, "<p>%s, %s, %s from %s to %s.</p>", html.EscapeString(skip.Address), html.EscapeString(skip.Postcode),
+		formatSkipDate(skip.Date), opens, closes)
+	if skip.cancelled() {
+		fmt.Fprintf(&b, "<p>This skip has been %s.</p>", html.EscapeString(skip.Status))
+	}
+	if items := itemsDescription(skip.AcceptedItems, skip.ProhibitedItems); items != "" {
+		fmt.Fprintf(&b, "<p>%s</p>", strings.ReplaceAll(html.EscapeString(items), "\n", "<br>"))
+	}
+	return b.String()
+}
+
+// writeAtomFeed writes an entry per upcoming skip, identified by its ID.
+// Entries are updated when a scrape sees them added or called off, and
+// otherwise when the dataset last changed.
+func writeAtomFeed(w io.Writer, locations []SkipLocation, modified, now time.Time) error {
+	tenant := currentTenant()
+	siteURL := strings.TrimSuffix(tenant.SiteURL, "/")
+
+	feed := atomFeed{
+		Title:    tenant.SiteTitle,
+		Subtitle: tenant.Description,
+		ID:       siteURL + "/feed.atom",
+		Author:   atomAuthor{Name: tenant.SiteTitle},
+		Links: []atomLink{
+			{Href: siteURL + "/feed.atom", Rel: "self", Type: "application/atom+xml"},
+			{Href: siteURL + "/", Rel: "alternate", Type: "text/html"},
+		},
+	}
+	if websubHub != "" {
+		feed.Links = append(feed.Links, atomLink{Href: websubHub, Rel: "hub"})
+	}
+
+	updated := modified
+	for _, day := range upcomingSkipDays(locations, now) {
+		for _, skip := range day.Skips {
+			entryUpdated := skipUpdatedAt(skip, modified)
+			if entryUpdated.After(updated) {
+				updated = entryUpdated
+			}
+
+			title := fmt.Sprintf("%s: %s, %s on %s", eventTitle(skip.eventType()), skip.Address, skip.Postcode, formatSkipDate(skip.Date))
+			if skip.cancelled() {
+				title = "Cancelled: " + title
+			}
+			link := siteURL + "/#skip=" + skip.ID
+			feed.Entries = append(feed.Entries, atomEntry{
+				Title:   title,
+				ID:      link,
+				Updated: entryUpdated.UTC().Format(time.RFC3339),
+				Links:   []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+				Content: atomContent{Type: "html", Value: skipEntryHTML(skip)},
+			})
+		}
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
+
+// HandleAtomFeed handles requests to /feed.atom
+func HandleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate feed")
+		return
+	}
+	version, err := currentDataset.get(r.Context())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate feed")
+		return
+	}
+
+	setWebSubLinks(w, r)
+	calendarCaching.apply(w)
+	setSnapshotHeaders(w)
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	err = writeRendered(w, func(buf io.Writer) error {
+		return writeAtomFeed(buf, locations, version.modified, time.Now())
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error writing Atom feed", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate feed")
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRSSFeed(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	locations := []SkipLocation{
+		{Address: "Old Road", Postcode: "SW11 1AA", Date: time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)},
+		{Address: "Lindsay Court", Postcode: "SW11 3HZ", Date: time.Date(2026, 3, 21, 0, 0, 0, 0, time.UTC), Status: StatusCancelled},
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{Address: "Tooting & Co", Postcode: "SW17 0AA", Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), OpensAt: "08:00", ClosesAt: "12:00"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeRSSFeed(&buf, locations, now); err != nil {
+		t.Fatal(err)
+	}
+
+	var feed rssFeed
+	if err := xml.Unmarshal(buf.Bytes(), &feed); err != nil {
+		t.Fatalf("Feed isn't valid XML: %v\n%s", err, buf.String())
+	}
+	items := feed.Channel.Items
+	if len(items) != 2 {
+		t.Fatalf("Expected an item per upcoming skip day, got %d", len(items))
+	}
+
+	if items[0].Title != "Wandsworth Mega Skip day: Saturday 14 March" {
+		t.Errorf("Unexpected title %q", items[0].Title)
+	}
+	if items[0].PubDate != "Sat, 14 Mar 2026 08:00:00 +0000" {
+		t.Errorf("Expected the earliest opening as the pubDate, got %q", items[0].PubDate)
+	}
+	if !strings.Contains(items[0].Description, "Pountney Road, SW11 5TU") || !strings.Contains(items[0].Description, "Tooting &amp; Co") {
+		t.Errorf("Expected the day's locations, got %q", items[0].Description)
+	}
+	if items[0].GUID.Value != "https://wheremegaskip.com/#2026-03-14" || items[0].GUID.IsPermaLink {
+		t.Errorf("Unexpected guid %+v", items[0].GUID)
+	}
+	if !strings.HasPrefix(items[1].Title, "Cancelled: ") {
+		t.Errorf("Expected a called-off day to be marked, got %q", items[1].Title)
+	}
+}
+
+func TestWriteAtomFeed(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	locations := []SkipLocation{
+		{ID: "2026-03-07-old-road-sw11-1aa", Address: "Old Road", Postcode: "SW11 1AA", Date: time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)},
+		{ID: "2026-03-14-pountney-road-sw11-5tu", Address: "Pountney Road", Postcode: "SW11 5TU", Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{ID: "2026-03-21-lindsay-court-sw11-3hz", Address: "Lindsay Court", Postcode: "SW11 3HZ", Date: time.Date(2026, 3, 21, 0, 0, 0, 0, time.UTC), Status: StatusCancelled},
+	}
+	cancelledAt := time.Date(2026, 3, 9, 18, 30, 0, 0, time.UTC)
+	recordSkipUpdates(locations, []SkipChange{{Kind: ChangeCancelled, Skip: locations[2]}}, cancelledAt)
+	defer recordSkipUpdates(nil, nil, now)
+
+	var buf bytes.Buffer
+	if err := writeAtomFeed(&buf, locations, modified, now); err != nil {
+		t.Fatal(err)
+	}
+
+	var feed atomFeed
+	if err := xml.Unmarshal(buf.Bytes(), &feed); err != nil {
+		t.Fatalf("Feed isn't valid XML: %v\n%s", err, buf.String())
+	}
+	if feed.Updated != "2026-03-09T18:30:00Z" {
+		t.Errorf("Expected the feed to be updated by its latest entry, got %s", feed.Updated)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("Expected an entry per upcoming skip, got %d", len(feed.Entries))
+	}
+
+	entry := feed.Entries[0]
+	if entry.ID != "https://wheremegaskip.com/#skip=2026-03-14-pountney-road-sw11-5tu" {
+		t.Errorf("Unexpected id %s", entry.ID)
+	}
+	if entry.Updated != "2026-03-01T12:00:00Z" {
+		t.Errorf("Expected an unchanged skip to be updated with the dataset, got %s", entry.Updated)
+	}
+	if entry.Title != "Wandsworth Mega Skip: Pountney Road, SW11 5TU on Saturday 14 March" {
+		t.Errorf("Unexpected title %q", entry.Title)
+	}
+
+	entry = feed.Entries[1]
+	if entry.Updated != "2026-03-09T18:30:00Z" || !strings.HasPrefix(entry.Title, "Cancelled: ") {
+		t.Errorf("Expected the cancelled skip to be updated when it was called off, got %+v", entry)
+	}
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// backgroundGeocodeTimeout bounds a single background geocoding run
+	backgroundGeocodeTimeout = 2 * time.Minute
+	// backgroundGeocodeInterval is the minimum gap between background runs,
+	// so postcodes that never resolve don't hammer the geocoder
+	backgroundGeocodeInterval = time.Minute
+
+	backgroundGeocodeMu      sync.Mutex
+	backgroundGeocodeRunning bool
+	backgroundGeocodeLastRun time.Time
+	// backgroundGeocodeCancel stops the run in progress, if any
+	backgroundGeocodeCancel context.CancelFunc
+	// backgroundGeocodes tracks the run in progress, so it can be waited for
+	backgroundGeocodes sync.WaitGroup
+)
+
+// hasCoordinates reports whether the location has been geocoded
+func (l SkipLocation) hasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+// missingCoordinates returns the distinct geocodeKeys of locations that
+// have not been geocoded yet, in the order they first appear
+func missingCoordinates(locations []SkipLocation) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, loc := range locations {
+		if loc.hasCoordinates() || seen[geocodeKey(loc)] {
+			continue
+		}
+		seen[geocodeKey(loc)] = true
+		keys = append(keys, geocodeKey(loc))
+	}
+	return keys
+}
+
+// backgroundGeocoding reports whether a background geocoding run is in progress
+func backgroundGeocoding() bool {
+	backgroundGeocodeMu.Lock()
+	defer backgroundGeocodeMu.Unlock()
+	return backgroundGeocodeRunning
+}
+
+// startBackgroundGeocoding geocodes any locations still missing coordinates
+// without blocking the caller, then writes the completed coordinates back to
+// the cache. At most one run is in progress at a time.
+//
+// On Vercel a function is frozen once its response is sent, so a run only
+// makes progress while that instance is serving requests. Anything it
+// doesn't finish is picked up by the next run, and coordinates found so far
+// are kept in the geocode cache, so the dataset still fills in over a few
+// requests; /api/skips/geocodes, which the page polls, starts runs too.
+func startBackgroundGeocoding(locations []SkipLocation) {
+	if len(missingCoordinates(locations)) == 0 {
+		return
+	}
+
+	backgroundGeocodeMu.Lock()
+	if backgroundGeocodeRunning || time.Since(backgroundGeocodeLastRun) < backgroundGeocodeInterval {
+		backgroundGeocodeMu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundGeocodeTimeout)
+	backgroundGeocodeRunning = true
+	backgroundGeocodeLastRun = time.Now()
+	backgroundGeocodeCancel = cancel
+	backgroundGeocodes.Add(1)
+	backgroundGeocodeMu.Unlock()
+
+	// Work on a copy so callers can keep reading their slice
+	pending := make([]SkipLocation, len(locations))
+	copy(pending, locations)
+
+	goSafe(context.Background(), "background geocoding", func() {
+		defer backgroundGeocodes.Done()
+		defer func() {
+			backgroundGeocodeMu.Lock()
+			backgroundGeocodeRunning = false
+			backgroundGeocodeCancel = nil
+			backgroundGeocodeMu.Unlock()
+		}()
+		defer cancel()
+
+		slog.Info("Background geocoding", "locations", len(missingCoordinates(pending)))
+		geocodeLocations(ctx, pending)
+		storeGeocodedLocations(ctx, pending)
+	})
+}
+
+// stopBackgroundGeocoding cancels the run in progress, if any, and waits
+// for it to finish, so tests can swap the cache out from under it safely
+func stopBackgroundGeocoding() {
+	backgroundGeocodeMu.Lock()
+	if backgroundGeocodeCancel != nil {
+		backgroundGeocodeCancel()
+	}
+	backgroundGeocodeMu.Unlock()
+	backgroundGeocodes.Wait()
+}
+
+// storeGeocodedLocations merges newly found coordinates into the cached
+// dataset and invalidates everything derived from it. The dataset keeps
+// the TTL it has left, so it still expires when its scrape would have; the
+// coordinates themselves are already in the geocode cache for next time.
+func storeGeocodedLocations(ctx context.Context, geocoded []SkipLocation) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	current, err := activeCache.Get(ctx, cacheKey)
+	if err != nil {
+		slog.WarnContext(ctx, "Cache get error", "err", err)
+		return
+	}
+	expires := skipLocationsVersion(ctx).Expires
+	if current == nil || !time.Now().Before(expires) {
+		// The cache expired meanwhile; the next refresh starts from scratch
+		return
+	}
+
+	merged := mergeCoordinates(current, geocoded)
+	if err := setSkipLocations(ctx, merged, expires); err != nil {
+		slog.WarnContext(ctx, "Cache set error", "err", err)
+		return
+	}
+
+	invalidateDerived()
+}
+
+// mergeCoordinates returns a copy of locations with coordinates filled in
+// from geocoded wherever a location is missing them
+func mergeCoordinates(locations, geocoded []SkipLocation) []SkipLocation {
+	found := make(map[string]SkipLocation)
+	for _, loc := range geocoded {
+		if loc.hasCoordinates() {
+			found[geocodeKey(loc)] = loc
+		}
+	}
+
+	merged := make([]SkipLocation, len(locations))
+	copy(merged, locations)
+	for i := range merged {
+		if merged[i].hasCoordinates() {
+			continue
+		}
+		if loc, ok := found[geocodeKey(merged[i])]; ok {
+			merged[i].Latitude = loc.Latitude
+			merged[i].Longitude = loc.Longitude
+			merged[i].GeocodeAccuracy = loc.GeocodeAccuracy
+		}
+	}
+	return merged
+}
+
+// Coordinates is a geocoded point
+type Coordinates struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+}
+
+// GeocodeDelta is the response from /api/skips/geocodes. Coordinates are
+// keyed by "<address>, <postcode>".
+type GeocodeDelta struct {
+	Coordinates map[string]Coordinates `json:"coordinates"`
+	Pending     []string               `json:"pending"`
+}
+
+// HandleGeocodesAPI handles requests to /api/skips/geocodes, a cheap endpoint
+// clients poll to pick up coordinates resolved by background geocoding
+func HandleGeocodesAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	delta := GeocodeDelta{
+		Coordinates: make(map[string]Coordinates),
+		Pending:     []string{},
+	}
+	for _, loc := range locations {
+		if loc.hasCoordinates() {
+			delta.Coordinates[geocodeKey(loc)] = Coordinates{loc.Latitude, loc.Longitude}
+		}
+	}
+	if backgroundGeocoding() {
+		delta.Pending = append(delta.Pending, missingCoordinates(locations)...)
+	}
+
+	json.NewEncoder(w).Encode(delta)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMissingCoordinates(t *testing.T) {
+	skips := []SkipLocation{
+		{Address: "A", Postcode: "SW11 1AA", Latitude: 51.4, Longitude: -0.1},
+		{Address: "B", Postcode: "SW11 1BB"},
+		{Address: "C", Postcode: "SW11 1CC"},
+		{Address: "B", Postcode: "SW11 1BB"},
+		{Address: "Another B", Postcode: "SW11 1BB"},
+	}
+
+	got := missingCoordinates(skips)
+	want := []string{"B, SW11 1BB", "C, SW11 1CC", "Another B, SW11 1BB"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("missingCoordinates() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeCoordinates(t *testing.T) {
+	current := []SkipLocation{
+		{Address: "A", Postcode: "SW11 1AA", Latitude: 51.4, Longitude: -0.1},
+		{Address: "B", Postcode: "SW11 1BB"},
+		{Address: "C", Postcode: "SW11 1CC"},
+	}
+	geocoded := []SkipLocation{
+		{Address: "A", Postcode: "SW11 1AA", Latitude: 99, Longitude: 99},
+		{Address: "B", Postcode: "SW11 1BB", Latitude: 51.5, Longitude: -0.2},
+		{Address: "C", Postcode: "SW11 1CC"},
+	}
+
+	merged := mergeCoordinates(current, geocoded)
+
+	if merged[0].Latitude != 51.4 {
+		t.Errorf("Existing coordinates should not be overwritten, got %v", merged[0].Latitude)
+	}
+	if merged[1].Latitude != 51.5 || merged[1].Longitude != -0.2 {
+		t.Errorf("Missing coordinates should be filled in, got %v,%v", merged[1].Latitude, merged[1].Longitude)
+	}
+	if merged[2].hasCoordinates() {
+		t.Error("Locations that failed to geocode should stay without coordinates")
+	}
+	if current[1].hasCoordinates() {
+		t.Error("mergeCoordinates should not modify its input")
+	}
+}
+
+func TestGeocodeLocationsUsesGeocodeCache(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+
+	ctx := context.Background()
+	cacheCoordinates(ctx, map[string]geocode{"B, SW11 1BB": {Address: "B", Postcode: "SW11 1BB", Coordinates: Coordinates{51.46, -0.16}, Accuracy: AccuracyStreet}})
+	cacheCoordinates(ctx, map[string]geocode{"C, SW11 1CC": {Address: "C", Postcode: "SW11 1CC", Coordinates: Coordinates{51.45, -0.19}, Accuracy: AccuracyPostcode}})
+
+	// Every postcode is cached, so this must not reach the geocoder
+	skips := []SkipLocation{
+		{Address: "B", Postcode: "SW11 1BB"},
+		{Address: "C", Postcode: "SW11 1CC"},
+		{Address: "B", Postcode: "SW11 1BB", DateStr: "Later"},
+	}
+	geocodeLocations(ctx, skips)
+
+	for _, skip := range skips {
+		if !skip.hasCoordinates() {
+			t.Errorf("%s should have coordinates from the geocode cache", skip.Address)
+		}
+	}
+	if skips[1].Latitude != 51.45 || skips[1].GeocodeAccuracy != AccuracyPostcode {
+		t.Errorf("Later cache updates should keep earlier entries, got %+v", skips[1])
+	}
+}
+
+func TestStoreGeocodedLocationsKeepsTTL(t *testing.T) {
+	useTestSkips(t, nil)
+	ctx := context.Background()
+
+	current := []SkipLocation{{Address: "B", Postcode: "SW11 1BB"}}
+	if err := setSkipLocations(ctx, current, time.Now().Add(50*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	storeGeocodedLocations(ctx, []SkipLocation{{Address: "B", Postcode: "SW11 1BB", Latitude: 51.5, Longitude: -0.2}})
+
+	if got, _ := activeCache.Get(ctx, cacheKey); len(got) != 1 || !got[0].hasCoordinates() {
+		t.Fatalf("Expected the coordinates merged in, got %+v", got)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if got, _ := activeCache.Get(ctx, cacheKey); got != nil {
+		t.Errorf("Merging coordinates should not extend the TTL, got %+v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// How precisely a location was geocoded
+const (
+	AccuracyStreet   = "street"
+	AccuracyPostcode = "postcode"
+	AccuracyOutcode  = "outcode"
+)
+
+// geocodeBoundsMargin pads the default bounds, in degrees (about 1km), so
+// skips on a border aren't rejected by the simplified borough outlines
+const geocodeBoundsMargin = 0.01
+
+// geocodeBounds is the polygon, as lat/lng vertices, that geocoded skips
+// must fall in. Geocoders sometimes match a road of the same name
+// elsewhere in the country. Set with GEOCODE_BOUNDS.
+var geocodeBounds = defaultGeocodeBounds()
+
+// geocode is where a location was placed, and how precisely
+type geocode struct {
+	Address  string
+	Postcode string
+	Coordinates
+	Accuracy string
+}
+
+// defaultGeocodeBounds is a box around the supported boroughs
+func defaultGeocodeBounds() [][2]float64 {
+	minLat, minLng, maxLat, maxLng := 90.0, 180.0, -90.0, -180.0
+	for _, b := range boroughs {
+		for _, v := range b.Boundary {
+			minLat, maxLat = min(minLat, v[0]), max(maxLat, v[0])
+			minLng, maxLng = min(minLng, v[1]), max(maxLng, v[1])
+		}
+	}
+	minLat, minLng = minLat-geocodeBoundsMargin, minLng-geocodeBoundsMargin
+	maxLat, maxLng = maxLat+geocodeBoundsMargin, maxLng+geocodeBoundsMargin
+	return [][2]float64{{minLat, minLng}, {minLat, maxLng}, {maxLat, maxLng}, {maxLat, minLng}}
+}
+
+// parseGeocodeBounds parses a polygon given as semicolon-separated
+// "lat,lng" vertices, such as "51.41,-0.26;51.41,-0.12;51.49,-0.12"
+func parseGeocodeBounds(value string) ([][2]float64, error) {
+	var polygon [][2]float64
+	for _, vertex := range strings.Split(value, ";") {
+		lat, lng, ok := strings.Cut(strings.TrimSpace(vertex), ",")
+		if !ok {
+			return nil, fmt.Errorf("vertex %q is not lat,lng", vertex)
+		}
+		latF, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		if err != nil || latF < -90 || latF > 90 {
+			return nil, fmt.Errorf("vertex %q has an invalid latitude", vertex)
+		}
+		lngF, err := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+		if err != nil || lngF < -180 || lngF > 180 {
+			return nil, fmt.Errorf("vertex %q has an invalid longitude", vertex)
+		}
+		polygon = append(polygon, [2]float64{latF, lngF})
+	}
+	if len(polygon) < 3 {
+		return nil, fmt.Errorf("a polygon needs at least 3 vertices, got %d", len(polygon))
+	}
+	return polygon, nil
+}
+
+// inGeocodeBounds reports whether c is somewhere a skip could be
+func inGeocodeBounds(c Coordinates) bool {
+	return pointInPolygon(c.Latitude, c.Longitude, geocodeBounds)
+}
+
+// checkGeocode validates where postcode was geocoded to. Coordinates
+// outside the bounds are replaced by the centre of the postcode's outcode,
+// and rejected if that is out of bounds too.
+func checkGeocode(ctx context.Context, postcode string, c Coordinates) (geocode, bool) {
+	accuracy := AccuracyPostcode
+	if isOutcode(postcode) {
+		accuracy = AccuracyOutcode
+	}
+	if inGeocodeBounds(c) {
+		return geocode{Postcode: postcode, Coordinates: c, Accuracy: accuracy}, true
+	}
+
+	outcode, _, _ := strings.Cut(postcode, " ")
+	slog.WarnContext(ctx, "Geocoded outside bounds, using the outcode centre", "postcode", postcode, "lat", c.Latitude, "lng", c.Longitude)
+	lat, lng, err := outcodeCentroid(ctx, outcode)
+	if err != nil || !inGeocodeBounds(Coordinates{lat, lng}) {
+		slog.WarnContext(ctx, "Outcode centre is outside bounds too", "postcode", postcode, "err", err)
+		return geocode{}, false
+	}
+	return geocode{Postcode: postcode, Coordinates: Coordinates{lat, lng}, Accuracy: AccuracyOutcode}, true
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseGeocodeBounds(t *testing.T) {
+	polygon, err := parseGeocodeBounds("51.41,-0.26; 51.41,-0.12; 51.49,-0.12; 51.49,-0.26")
+	if err != nil || len(polygon) != 4 || polygon[1] != [2]float64{51.41, -0.12} {
+		t.Errorf("Got %v, %v", polygon, err)
+	}
+
+	for _, bad := range []string{"51.41,-0.26;51.41,-0.12", "51.41;-0.26;51.49", "91,0;0,0;1,1", "a,b;0,0;1,1"} {
+		if _, err := parseGeocodeBounds(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestCheckGeocode(t *testing.T) {
+	ctx := context.Background()
+
+	g, ok := checkGeocode(ctx, "SW11 5TU", Coordinates{51.4655, -0.1612})
+	if !ok || g.Accuracy != AccuracyPostcode || g.Latitude != 51.4655 {
+		t.Errorf("Expected the geocode to be kept, got %+v", g)
+	}
+
+	// Pountney Road matched in another city gives way to the SW11 centre
+	g, ok = checkGeocode(ctx, "SW11 5TU", Coordinates{53.4808, -2.2426})
+	if !ok || g.Accuracy != AccuracyOutcode || g.Coordinates != outcodeCentroids["SW11"] {
+		t.Errorf("Expected the outcode centre, got %+v", g)
+	}
+
+	g, ok = checkGeocode(ctx, "SW17", outcodeCentroids["SW17"])
+	if !ok || g.Accuracy != AccuracyOutcode {
+		t.Errorf("Expected an outcode geocode to have outcode accuracy, got %+v", g)
+	}
+}
+
+func TestGeocodeLocationsRejectsOutOfBounds(t *testing.T) {
+	// Don't swap the cache out from under an earlier test's background run
+	stopBackgroundGeocoding()
+	previous, previousCache := activeGeocoder, activeCache
+	activeGeocoder = &fakeGeocoder{lat: 53.4808, lng: -2.2426}
+	activeCache = NewMemoryCache()
+	defer func() { activeGeocoder, activeCache = previous, previousCache }()
+
+	skips := []SkipLocation{{Address: "Pountney Road", Postcode: "SW11 5TU"}}
+	geocodeLocations(context.Background(), skips)
+
+	if c := (Coordinates{skips[0].Latitude, skips[0].Longitude}); c != outcodeCentroids["SW11"] || skips[0].GeocodeAccuracy != AccuracyOutcode {
+		t.Errorf("Expected the SW11 centre, got %+v", skips[0])
+	}
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// geocodeCacheKey caches coordinates separately from the skip locations,
+// so a refresh only geocodes places it hasn't seen before
+const geocodeCacheKey = "geocodes"
+
+// geocodeCacheTTL is long because streets and postcodes practically never
+// move
+const geocodeCacheTTL = 30 * 24 * time.Hour
+
+// geocodeCacheMu serialises read-modify-write updates of the geocode cache
+var geocodeCacheMu sync.Mutex
+
+// cachedCoordinates returns the known coordinates of each geocodeKey. Any
+// outside the geocode bounds are left out, so they are geocoded again.
+func cachedCoordinates(ctx context.Context) map[string]geocode {
+	coords := make(map[string]geocode)
+	if activeCache == nil {
+		return coords
+	}
+
+	var cached map[string]geocode
+	if _, err := getCachedValue(ctx, geocodeCacheKey, &cached); err != nil {
+		slog.WarnContext(ctx, "Geocode cache get error", "err", err)
+		return coords
+	}
+	for key, g := range cached {
+		if inGeocodeBounds(g.Coordinates) {
+			coords[key] = g
+		}
+	}
+	return coords
+}
+
+// cacheCoordinates adds newly geocoded locations to the geocode cache
+func cacheCoordinates(ctx context.Context, found map[string]geocode) {
+	if activeCache == nil || len(found) == 0 {
+		return
+	}
+
+	geocodeCacheMu.Lock()
+	defer geocodeCacheMu.Unlock()
+
+	coords := cachedCoordinates(ctx)
+	for key, g := range found {
+		coords[key] = g
+	}
+	if err := setCachedValue(ctx, geocodeCacheKey, coords, geocodeCacheTTL); err != nil {
+		slog.WarnContext(ctx, "Geocode cache set error", "err", err)
+	}
+}
+
+// postcodeCacheKey caches the coordinates of a postcode visitors have
+// searched for, so returning visitors and repeated searches don't reach the
+// geocoder
+func postcodeCacheKey(postcode string) string {
+	return "postcode_" + strings.ReplaceAll(strings.ToUpper(postcode), " ", "")
+}
+
+// geocodeUserPostcode geocodes a postcode given by a visitor, through the
+// geocode cache
+func geocodeUserPostcode(ctx context.Context, postcode string) (float64, float64, error) {
+	if activeCache == nil {
+		return geocodePostcode(ctx, postcode)
+	}
+
+	key := postcodeCacheKey(postcode)
+	var c Coordinates
+	if ok, err := getCachedValue(ctx, key, &c); err != nil {
+		slog.WarnContext(ctx, "Geocode cache get error", "err", err)
+	} else if ok {
+		return c.Latitude, c.Longitude, nil
+	}
+
+	lat, lng, err := geocodePostcode(ctx, postcode)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := setCachedValue(ctx, key, Coordinates{lat, lng}, geocodeCacheTTL); err != nil {
+		slog.WarnContext(ctx, "Geocode cache set error", "err", err)
+	}
+	return lat, lng, nil
+}
+
+// geocodeKey identifies what a location is geocoded from. Entries cached
+// before street-level geocoding have no address, so are geocoded again.
+func geocodeKey(l SkipLocation) string {
+	return l.Address + ", " + l.Postcode
+}
+
+// applyCoordinates fills in coordinates for locations missing them
+func applyCoordinates(locations []SkipLocation, coords map[string]geocode) {
+	for i := range locations {
+		if locations[i].hasCoordinates() {
+			continue
+		}
+		if g, ok := coords[geocodeKey(locations[i])]; ok {
+			locations[i].Latitude = g.Latitude
+			locations[i].Longitude = g.Longitude
+			locations[i].GeocodeAccuracy = g.Accuracy
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Geocoder converts a postcode or place to coordinates
+type Geocoder interface {
+	Geocode(ctx context.Context, query string) (lat, lng float64, err error)
+}
+
+// activeGeocoder is used for skip locations and user postcodes alike
+var activeGeocoder Geocoder = newNominatimGeocoder()
+
+// geocoderUserAgent identifies us to public geocoding services
+const geocoderUserAgent = "WhereMegaSkip/1.0 (https://github.com/JosephSalisbury/wheremegaskip)"
+
+// newGeocoder builds the geocoder named by a comma-separated provider list,
+// as set by GEOCODERS, e.g. "postcodesio,nominatim". Providers are tried in
+// order until one succeeds.
+func newGeocoder(providers string) (Geocoder, error) {
+	var chain fallbackGeocoder
+	for _, name := range strings.Split(providers, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+			continue
+		case "nominatim":
+			chain = append(chain, newNominatimGeocoder())
+		case "postcodesio", "postcodes.io":
+			chain = append(chain, newPostcodesIOGeocoder())
+		default:
+			return nil, fmt.Errorf("unknown geocoder %q", name)
+		}
+	}
+
+	switch len(chain) {
+	case 0:
+		return nil, errors.New("no geocoders configured")
+	case 1:
+		return chain[0], nil
+	default:
+		return chain, nil
+	}
+}
+
+// fallbackGeocoder tries each geocoder in turn
+type fallbackGeocoder []Geocoder
+
+func (f fallbackGeocoder) Geocode(ctx context.Context, query string) (float64, float64, error) {
+	var errs []error
+	for _, g := range f {
+		lat, lng, err := g.Geocode(ctx, query)
+		if err == nil {
+			return lat, lng, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return 0, 0, errors.Join(errs...)
+}
+
+// geocoderThrottled reports whether every request to g is spaced out by a
+// rate limit, so concurrent workers would only queue behind each other
+func geocoderThrottled(g Geocoder) bool {
+	switch g := g.(type) {
+	case *nominatimGeocoder:
+		return g.throttle != nil
+	case fallbackGeocoder:
+		for _, member := range g {
+			if !geocoderThrottled(member) {
+				return false
+			}
+		}
+		return len(g) > 0
+	}
+	return false
+}
+
+// nominatimInterval is the gap Nominatim's usage policy asks for between
+// requests: no more than one a second
+const nominatimInterval = time.Second
+
+// nominatimThrottle is shared by every nominatimGeocoder, so refreshes,
+// background geocoding and visitors' postcodes all count against the one
+// limit however many geocoding workers are running
+var nominatimThrottle = newThrottle(nominatimInterval)
+
+// throttle spaces calls to wait at least interval apart
+type throttle struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newThrottle(interval time.Duration) *throttle {
+	return &throttle{interval: interval}
+}
+
+// wait blocks until the caller's turn, or ctx is done
+func (t *throttle) wait(ctx context.Context) error {
+	t.mu.Lock()
+	at := time.Now()
+	if t.next.After(at) {
+		at = t.next
+	}
+	t.next = at.Add(t.interval)
+	t.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// nominatimGeocoder uses the OpenStreetMap Nominatim search API
+type nominatimGeocoder struct {
+	baseURL  string
+	throttle *throttle
+}
+
+func newNominatimGeocoder() *nominatimGeocoder {
+	return &nominatimGeocoder{baseURL: "https://nominatim.openstreetmap.org", throttle: nominatimThrottle}
+}
+
+func (g *nominatimGeocoder) Geocode(ctx context.Context, query string) (float64, float64, error) {
+	// Street addresses already say London
+	if !strings.HasSuffix(query, ", London") {
+		query += " London"
+	}
+	apiURL := fmt.Sprintf("%s/search?q=%s+UK&format=json&limit=1&countrycodes=gb",
+		g.baseURL, url.QueryEscape(query))
+
+	if err := g.throttle.wait(ctx); err != nil {
+		return 0, 0, fmt.Errorf("nominatim: %w", err)
+	}
+
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := getGeocodeJSON(ctx, apiURL, &results); err != nil {
+		return 0, 0, fmt.Errorf("nominatim: %w", err)
+	}
+
+	if len(results) == 0 {
+		return 0, 0, fmt.Errorf("nominatim: no geocode results for %s", query)
+	}
+
+	var lat, lng float64
+	if _, err := fmt.Sscanf(results[0].Lat, "%f", &lat); err != nil {
+		return 0, 0, fmt.Errorf("nominatim: failed to parse latitude: %w", err)
+	}
+	if _, err := fmt.Sscanf(results[0].Lon, "%f", &lng); err != nil {
+		return 0, 0, fmt.Errorf("nominatim: failed to parse longitude: %w", err)
+	}
+
+	return lat, lng, nil
+}
+
+// postcodesIOGeocoder uses postcodes.io, which has exact ONS postcode and
+// outcode centroids and no strict rate limit
+type postcodesIOGeocoder struct {
+	baseURL string
+}
+
+func newPostcodesIOGeocoder() *postcodesIOGeocoder {
+	return &postcodesIOGeocoder{baseURL: "https://api.postcodes.io"}
+}
+
+func (g *postcodesIOGeocoder) Geocode(ctx context.Context, query string) (float64, float64, error) {
+	query = strings.TrimSpace(query)
+	resource := "postcodes"
+	if isOutcode(query) {
+		resource = "outcodes"
+	}
+	apiURL := fmt.Sprintf("%s/%s/%s", g.baseURL, resource, url.PathEscape(query))
+
+	var result struct {
+		Result *struct {
+			Latitude  *float64 `json:"latitude"`
+			Longitude *float64 `json:"longitude"`
+		} `json:"result"`
+	}
+	if err := getGeocodeJSON(ctx, apiURL, &result); err != nil {
+		return 0, 0, fmt.Errorf("postcodes.io: %w", err)
+	}
+
+	// Terminated postcodes can come back without coordinates
+	if result.Result == nil || result.Result.Latitude == nil || result.Result.Longitude == nil {
+		return 0, 0, fmt.Errorf("postcodes.io: no coordinates for %s", query)
+	}
+	return *result.Result.Latitude, *result.Result.Longitude, nil
+}
+
+// getGeocodeJSON fetches apiURL and decodes its JSON body into v
+func getGeocodeJSON(ctx context.Context, apiURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", geocoderUserAgent)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch geocode: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("geocode API returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode geocode response: %w", err)
+	}
+	return nil
+}
+
+// configureGeocoder selects the geocoder from GEOCODERS, keeping the
+// current one if it is unset or invalid
+func configureGeocoder(providers string) {
+	if providers == "" {
+		return
+	}
+	g, err := newGeocoder(providers)
+	if err != nil {
+		slog.Warn("Invalid GEOCODERS, keeping current geocoder", "err", err)
+		return
+	}
+	activeGeocoder = g
+}
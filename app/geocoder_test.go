@@ -0,0 +1,205 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeGeocoder struct {
+	lat, lng float64
+	err      error
+	calls    int
+}
+
+func (f *fakeGeocoder) Geocode(ctx context.Context, query string) (float64, float64, error) {
+	f.calls++
+	return f.lat, f.lng, f.err
+}
+
+func TestFallbackGeocoder(t *testing.T) {
+	failing := &fakeGeocoder{err: errors.New("down")}
+	working := &fakeGeocoder{lat: 51.45, lng: -0.19}
+	unused := &fakeGeocoder{lat: 1, lng: 1}
+
+	lat, lng, err := fallbackGeocoder{failing, working, unused}.Geocode(context.Background(), "SW18 4AA")
+	if err != nil || lat != 51.45 || lng != -0.19 {
+		t.Errorf("Got %v, %v, %v; want the second geocoder's answer", lat, lng, err)
+	}
+	if unused.calls != 0 {
+		t.Error("Geocoders after a success should not be called")
+	}
+
+	if _, _, err := (fallbackGeocoder{failing, failing}).Geocode(context.Background(), "SW18 4AA"); err == nil {
+		t.Error("Expected an error when every geocoder fails")
+	}
+}
+
+func TestNewGeocoder(t *testing.T) {
+	g, err := newGeocoder("postcodesio, nominatim")
+	if err != nil {
+		t.Fatalf("newGeocoder: %v", err)
+	}
+	if chain, ok := g.(fallbackGeocoder); !ok || len(chain) != 2 {
+		t.Errorf("Expected a chain of two geocoders, got %T", g)
+	}
+
+	if g, err := newGeocoder("nominatim"); err != nil {
+		t.Errorf("newGeocoder(nominatim): %v", err)
+	} else if _, ok := g.(*nominatimGeocoder); !ok {
+		t.Errorf("A single provider should not be wrapped, got %T", g)
+	}
+
+	for _, providers := range []string{"", " , ", "google"} {
+		if _, err := newGeocoder(providers); err == nil {
+			t.Errorf("newGeocoder(%q) should fail", providers)
+		}
+	}
+}
+
+func TestPostcodesIOGeocoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/postcodes/SW18 4AA":
+			w.Write([]byte(`{"status":200,"result":{"postcode":"SW18 4AA","latitude":51.4561,"longitude":-0.1935}}`))
+		case "/outcodes/SW17":
+			w.Write([]byte(`{"status":200,"result":{"outcode":"SW17","latitude":51.4290,"longitude":-0.1667}}`))
+		case "/postcodes/SW1A 0AA":
+			w.Write([]byte(`{"status":200,"result":{"postcode":"SW1A 0AA","latitude":null,"longitude":null}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"error":"Postcode not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	g := &postcodesIOGeocoder{baseURL: server.URL}
+	ctx := context.Background()
+
+	if lat, lng, err := g.Geocode(ctx, "SW18 4AA"); err != nil || lat != 51.4561 || lng != -0.1935 {
+		t.Errorf("Postcode lookup = %v, %v, %v", lat, lng, err)
+	}
+	if lat, _, err := g.Geocode(ctx, "SW17"); err != nil || lat != 51.4290 {
+		t.Errorf("Outcode lookup = %v, %v", lat, err)
+	}
+	if _, _, err := g.Geocode(ctx, "SW1A 0AA"); err == nil {
+		t.Error("Expected an error for a postcode without coordinates")
+	}
+	if _, _, err := g.Geocode(ctx, "ZZ1 1ZZ"); err == nil {
+		t.Error("Expected an error for an unknown postcode")
+	}
+}
+
+// geocoderFunc answers queries with a function
+type geocoderFunc func(query string) (float64, float64, error)
+
+func (f geocoderFunc) Geocode(ctx context.Context, query string) (float64, float64, error) {
+	return f(query)
+}
+
+func TestGeocodeLocation(t *testing.T) {
+	previous := activeGeocoder
+	defer func() { activeGeocoder = previous }()
+
+	var queries []string
+	activeGeocoder = geocoderFunc(func(query string) (float64, float64, error) {
+		queries = append(queries, query)
+		switch query {
+		case "Pountney Road, SW11 5TU, London":
+			return 51.4662, -0.1608, nil
+		case "SW11 5TU", "SW18 3SG":
+			return 51.4655, -0.1612, nil
+		}
+		return 0, 0, errors.New("no results")
+	})
+
+	g, ok := geocodeLocation(context.Background(), SkipLocation{Address: "Pountney Road", Postcode: "SW11 5TU"})
+	if !ok || g.Accuracy != AccuracyStreet || g.Latitude != 51.4662 {
+		t.Errorf("Expected the street, got %+v", g)
+	}
+
+	queries = nil
+	g, ok = geocodeLocation(context.Background(), SkipLocation{Address: "Fitzhugh Estate", Postcode: "SW18 3SG"})
+	if !ok || g.Accuracy != AccuracyPostcode || g.Address != "Fitzhugh Estate" {
+		t.Errorf("Expected to fall back to the postcode, got %+v", g)
+	}
+	if want := []string{"Fitzhugh Estate, SW18 3SG, London", "SW18 3SG"}; !slices.Equal(queries, want) {
+		t.Errorf("Expected queries %q, got %q", want, queries)
+	}
+}
+
+func TestThrottleSpacesCalls(t *testing.T) {
+	th := newThrottle(20 * time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			th.wait(ctx)
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Four calls took %v, want at least three intervals", elapsed)
+	}
+
+	slow := newThrottle(time.Hour)
+	slow.wait(ctx)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := slow.wait(cancelled); err == nil {
+		t.Error("Expected a cancelled wait to fail")
+	}
+}
+
+func TestNominatimGeocoderUsesSharedThrottle(t *testing.T) {
+	a, b := newNominatimGeocoder(), newNominatimGeocoder()
+	if a.throttle != nominatimThrottle || b.throttle != nominatimThrottle {
+		t.Error("Expected every Nominatim geocoder to share one throttle")
+	}
+}
+
+func TestGeocoderThrottled(t *testing.T) {
+	nominatim, postcodesIO := newNominatimGeocoder(), &postcodesIOGeocoder{}
+	tests := []struct {
+		name string
+		g    Geocoder
+		want bool
+	}{
+		{"nominatim", nominatim, true},
+		{"postcodes.io", postcodesIO, false},
+		{"nominatim only", fallbackGeocoder{nominatim, nominatim}, true},
+		{"with postcodes.io", fallbackGeocoder{postcodesIO, nominatim}, false},
+	}
+	for _, tt := range tests {
+		if got := geocoderThrottled(tt.g); got != tt.want {
+			t.Errorf("%s: geocoderThrottled = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGeocodeUserPostcodeCaches(t *testing.T) {
+	useTestSkips(t, nil)
+	previous := activeGeocoder
+	defer func() { activeGeocoder = previous }()
+	fake := &fakeGeocoder{lat: 51.46, lng: -0.16}
+	activeGeocoder = fake
+
+	for _, postcode := range []string{"SW11 5TU", "sw11 5tu", "SW115TU"} {
+		lat, lng, err := geocodeUserPostcode(context.Background(), postcode)
+		if err != nil || lat != 51.46 || lng != -0.16 {
+			t.Errorf("geocodeUserPostcode(%q) = %v, %v, %v", postcode, lat, lng, err)
+		}
+	}
+	if fake.calls != 1 {
+		t.Errorf("Expected one geocoder call, got %d", fake.calls)
+	}
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// geoJSONContentType is the media type of GeoJSON (RFC 7946)
+const geoJSONContentType = "application/geo+json"
+
+// GeoJSONFeatureCollection is a GeoJSON FeatureCollection of skips
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature is a skip as a GeoJSON Point feature
+type GeoJSONFeature struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id,omitempty"`
+	Geometry   GeoJSONPoint      `json:"geometry"`
+	Properties GeoJSONProperties `json:"properties"`
+}
+
+// GeoJSONPoint is a Point geometry; coordinates are [longitude, latitude]
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// GeoJSONProperties are a skip's date and address, flattened for mapping
+// tools that show properties as a table
+type GeoJSONProperties struct {
+	Address         string    `json:"address"`
+	Postcode        string    `json:"postcode"`
+	Date            string    `json:"date"`
+	DateStr         string    `json:"dateStr"`
+	Type            EventType `json:"type"`
+	Borough         string    `json:"borough,omitempty"`
+	OpensAt         string    `json:"opensAt"`
+	ClosesAt        string    `json:"closesAt"`
+	Status          string    `json:"status,omitempty"`
+	GeocodeAccuracy string    `json:"geocodeAccuracy,omitempty"`
+}
+
+// skipsGeoJSON converts locations to a FeatureCollection. Skips that
+// haven't been geocoded yet are left out, as they have no point to plot.
+func skipsGeoJSON(locations []SkipLocation) GeoJSONFeatureCollection {
+	collection := GeoJSONFeatureCollection{Type: "FeatureCollection", Features: []GeoJSONFeature{}}
+	for _, l := range locations {
+		if !l.hasCoordinates() {
+			continue
+		}
+		opens, closes := l.hours()
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type:     "Feature",
+			ID:       l.ID,
+			Geometry: GeoJSONPoint{Type: "Point", Coordinates: [2]float64{l.Longitude, l.Latitude}},
+			Properties: GeoJSONProperties{
+				Address:         l.Address,
+				Postcode:        l.Postcode,
+				Date:            l.Date.Format("2006-01-02"),
+				DateStr:         l.DateStr,
+				Type:            l.eventType(),
+				Borough:         l.Borough,
+				OpensAt:         opens,
+				ClosesAt:        closes,
+				Status:          l.Status,
+				GeocodeAccuracy: l.GeocodeAccuracy,
+			},
+		})
+	}
+	return collection
+}
+
+// writeSkipsGeoJSON writes locations as a GeoJSON FeatureCollection
+func writeSkipsGeoJSON(w io.Writer, locations []SkipLocation) error {
+	return json.NewEncoder(w).Encode(skipsGeoJSON(locations))
+}
+
+// HandleSkipsGeoJSON handles requests to /api/skips.geojson, taking the
+// same ?type= and ?borough= filters as /api/skips
+func HandleSkipsGeoJSON(w http.ResponseWriter, r *http.Request) {
+	types, err := parseEventTypes(r.URL.Query().Get("type"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	boroughIDs, err := parseBoroughs(r.URL.Query().Get("borough"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	setSnapshotHeaders(w)
+	apiCaching.apply(w)
+	w.Header().Set("Content-Type", geoJSONContentType)
+	err = writeRendered(w, func(buf io.Writer) error {
+		return writeSkipsGeoJSON(buf, filterByBorough(filterByType(locations, types), boroughIDs))
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error writing GeoJSON", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleSkipsGeoJSON(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	invalidateDerived()
+	defer func() {
+		activeCache = previous
+		invalidateDerived()
+	}()
+	activeCache.Set(context.Background(), cacheKey, []SkipLocation{
+		{ID: "2026-03-14-pountney-road-sw11-5tu", Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+			Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), DateStr: "Saturday 14 March", Type: EventMegaSkip},
+		{ID: "2026-03-14-lindsay-court-sw11-3hz", Address: "Lindsay Court", Postcode: "SW11 3HZ", Latitude: 51.4700, Longitude: -0.1700,
+			Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), Type: EventMegaSkip, Status: StatusCancelled},
+	}, time.Hour)
+
+	w := httptest.NewRecorder()
+	HandleSkipsGeoJSON(w, httptest.NewRequest(http.MethodGet, "/api/skips.geojson", nil))
+	if ct := w.Header().Get("Content-Type"); ct != geoJSONContentType {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+
+	var collection GeoJSONFeatureCollection
+	if err := json.NewDecoder(w.Body).Decode(&collection); err != nil {
+		t.Fatal(err)
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 2 {
+		t.Fatalf("Expected a feature per skip, got %+v", collection)
+	}
+
+	feature := collection.Features[0]
+	if feature.ID != "2026-03-14-pountney-road-sw11-5tu" || feature.Geometry.Type != "Point" {
+		t.Errorf("Unexpected feature %+v", feature)
+	}
+	if feature.Geometry.Coordinates != [2]float64{-0.1612, 51.4655} {
+		t.Errorf("Expected [lng, lat] coordinates, got %v", feature.Geometry.Coordinates)
+	}
+	if feature.Properties.Date != "2026-03-14" || feature.Properties.Address != "Pountney Road" || feature.Properties.OpensAt == "" {
+		t.Errorf("Unexpected properties %+v", feature.Properties)
+	}
+	if collection.Features[1].Properties.Status != StatusCancelled {
+		t.Errorf("Expected the status to be kept, got %+v", collection.Features[1].Properties)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSkipsGeoJSON(w, httptest.NewRequest(http.MethodGet, "/api/skips.geojson?type=bogus", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a bad type to be refused, got %d", w.Code)
+	}
+}
+
+func TestSkipsGeoJSONLeavesOutUngeocoded(t *testing.T) {
+	collection := skipsGeoJSON([]SkipLocation{{Address: "Pending Road", Postcode: "SW11 1AA"}})
+	if len(collection.Features) != 0 {
+		t.Errorf("Expected skips without coordinates to be left out, got %+v", collection.Features)
+	}
+
+	data, _ := json.Marshal(collection)
+	if string(data) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("Expected an empty feature list, got %s", data)
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// updateGolden rewrites the golden files from the current parsers:
+//
+//	go test ./app -run TestParserGoldenFiles -update
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// goldenParsers are the parsers for each source's directory in testdata
+var goldenParsers = map[string]pageParser{
+	"wandsworth": parseCouncilPage,
+	"lambeth":    parseLambethPage,
+}
+
+// goldenNow is when the recorded pages are parsed, fixing the year
+var goldenNow = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// TestParserGoldenFiles parses every saved page in testdata/{source} and
+// compares the locations with the .golden.json file beside it. To add a
+// page, save it there (pages archived with SCRAPE_ARCHIVE_PATH work well),
+// run with -update and check the golden file by eye.
+func TestParserGoldenFiles(t *testing.T) {
+	for source, parse := range goldenParsers {
+		pages, err := filepath.Glob(filepath.Join("testdata", source, "*.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pages) == 0 {
+			t.Errorf("No recorded pages for %s", source)
+		}
+
+		for _, page := range pages {
+			t.Run(source+"/"+filepath.Base(page), func(t *testing.T) {
+				f, err := os.Open(page)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+
+				locations, _, err := parsePage(f, parse, goldenNow)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := json.MarshalIndent(locations, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, '\n')
+
+				golden := strings.TrimSuffix(page, ".html") + ".golden.json"
+				if *updateGolden {
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("Missing golden file, run with -update: %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("Parsed locations differ from %s:\ngot:\n%s\nwant:\n%s", golden, got, want)
+				}
+			})
+		}
+	}
+}
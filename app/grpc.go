@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	skipsv1 "github.com/JosephSalisbury/wheremegaskip/proto/wheremegaskip/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// skipService implements SkipService, defined in
+// proto/wheremegaskip/v1/skips.proto, over the same data as the JSON API
+type skipService struct {
+	skipsv1.UnimplementedSkipServiceServer
+}
+
+// ListSkips returns the skips, filtered by type and borough
+func (skipService) ListSkips(ctx context.Context, req *skipsv1.ListSkipsRequest) (*skipsv1.ListSkipsResponse, error) {
+	types, err := parseEventTypes(strings.Join(req.GetTypes(), ","))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	boroughIDs, err := parseBoroughs(strings.Join(req.GetBoroughs(), ","))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	locations, err := getSkipLocations(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting skip locations", "err", err)
+		return nil, status.Error(codes.Unavailable, "Failed to fetch skip locations")
+	}
+
+	resp := &skipsv1.ListSkipsResponse{}
+	for _, l := range filterByBorough(filterByType(locations, types), boroughIDs) {
+		resp.Skips = append(resp.Skips, skipMessage(l))
+	}
+	return resp, nil
+}
+
+// GetNearest returns the nearest upcoming mega skip to a postcode
+func (skipService) GetNearest(ctx context.Context, req *skipsv1.GetNearestRequest) (*skipsv1.GetNearestResponse, error) {
+	nearest, err := queryNearestSkip(ctx, req.GetPostcode(), time.Now())
+	if err != nil {
+		code := grpcCode(queryErrorStatus(err))
+		if code == codes.Internal {
+			slog.ErrorContext(ctx, "Error finding nearest skip", "err", err)
+		}
+		return nil, status.Error(code, describeQueryError(err))
+	}
+
+	return &skipsv1.GetNearestResponse{
+		Postcode:    nearest.Postcode,
+		Date:        nearest.Date.Format(time.DateOnly),
+		Skip:        skipMessage(nearest.Skip),
+		DistanceKm:  nearest.DistanceKm,
+		Approximate: nearest.Approximate,
+	}, nil
+}
+
+// StreamChanges sends each change made by any instance's scrapes until the
+// client goes away. Headers are sent once it is watching for changes.
+func (skipService) StreamChanges(req *skipsv1.StreamChangesRequest, stream grpc.ServerStreamingServer[skipsv1.StreamChangesResponse]) error {
+	ctx := stream.Context()
+	changes := newChangeWatcher(ctx)
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	poll := time.NewTicker(changePollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+			for _, c := range changes.poll(ctx, time.Now()) {
+				msg := &skipsv1.StreamChangesResponse{Change: &skipsv1.SkipChange{
+					Kind: skipChangeKinds[c.Kind],
+					Skip: skipMessage(c.Skip),
+				}}
+				if err := stream.Send(msg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// skipChangeKinds maps each change kind to its SkipChange.Kind
+var skipChangeKinds = map[string]skipsv1.SkipChange_Kind{
+	ChangeAdded:     skipsv1.SkipChange_KIND_ADDED,
+	ChangeRemoved:   skipsv1.SkipChange_KIND_REMOVED,
+	ChangeCancelled: skipsv1.SkipChange_KIND_CANCELLED,
+}
+
+// skipMessage converts a location to a Skip message
+func skipMessage(l SkipLocation) *skipsv1.Skip {
+	return &skipsv1.Skip{
+		Id:              l.ID,
+		Address:         l.Address,
+		Postcode:        l.Postcode,
+		Date:            l.Date.Format(time.DateOnly),
+		DateStr:         l.DateStr,
+		Lat:             l.Latitude,
+		Lng:             l.Longitude,
+		Type:            string(l.eventType()),
+		Borough:         l.Borough,
+		OpensAt:         l.OpensAt,
+		ClosesAt:        l.ClosesAt,
+		AcceptedItems:   l.AcceptedItems,
+		ProhibitedItems: l.ProhibitedItems,
+		Status:          l.Status,
+		GeocodeAccuracy: l.GeocodeAccuracy,
+	}
+}
+
+// grpcCode maps an HTTP status from queryErrorStatus to a gRPC code
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
+
+// grpcRateLimit applies the anonymous per-IP limit to unary calls and to
+// opening streams
+func grpcRateLimit(ctx context.Context) error {
+	if apiLimiter == nil {
+		return nil
+	}
+	client := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		client = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client); err == nil {
+			client = host
+		}
+	}
+	if ok, wait := apiLimiter.allow(client, time.Now()); !ok {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("Too many requests, try again in %d seconds", int(math.Ceil(wait.Seconds()))))
+	}
+	return nil
+}
+
+// NewGRPCServer returns a server for SkipService, rate limited like the
+// JSON API
+func NewGRPCServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := grpcRateLimit(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := grpcRateLimit(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	skipsv1.RegisterSkipServiceServer(server, skipService{})
+	return server
+}
+
+// ListenAndServeGRPC serves SkipService to gRPC clients on addr
+func ListenAndServeGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return NewGRPCServer().Serve(lis)
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	skipsv1 "github.com/JosephSalisbury/wheremegaskip/proto/wheremegaskip/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// useTestSkipService starts SkipService in memory and returns a client
+func useTestSkipService(t *testing.T) skipsv1.SkipServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := NewGRPCServer()
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return skipsv1.NewSkipServiceClient(conn)
+}
+
+func TestGRPCListSkipsAndGetNearest(t *testing.T) {
+	date := startOfDay(time.Now()).AddDate(0, 0, 1)
+	useTestSkips(t, []SkipLocation{
+		{ID: "pountney", Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612, Date: date, Type: EventMegaSkip},
+		{ID: "trees", Address: "Garratt Lane", Postcode: "SW18 4AA", Latitude: 51.4520, Longitude: -0.1900, Date: date, Type: EventChristmasTrees},
+	})
+	client := useTestSkipService(t)
+	ctx := context.Background()
+
+	list, err := client.ListSkips(ctx, &skipsv1.ListSkipsRequest{Types: []string{"megaskip"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetSkips()) != 1 || list.GetSkips()[0].GetId() != "pountney" || list.GetSkips()[0].GetDate() != date.Format(time.DateOnly) {
+		t.Errorf("Unexpected skips %v", list.GetSkips())
+	}
+
+	if _, err := client.ListSkips(ctx, &skipsv1.ListSkipsRequest{Types: []string{"bogus"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown type, got %v", err)
+	}
+
+	nearest, err := client.GetNearest(ctx, &skipsv1.GetNearestRequest{Postcode: "SW11"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nearest.GetSkip().GetId() != "pountney" || !nearest.GetApproximate() {
+		t.Errorf("Unexpected nearest %v", nearest)
+	}
+
+	if _, err := client.GetNearest(ctx, &skipsv1.GetNearestRequest{Postcode: "not a postcode"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a bad postcode, got %v", err)
+	}
+}
+
+func TestGRPCStreamChanges(t *testing.T) {
+	previous := changePollInterval
+	changePollInterval = 10 * time.Millisecond
+	defer func() { changePollInterval = previous }()
+	useTestSkips(t, []SkipLocation{})
+	client := useTestSkipService(t)
+	pountney := SkipLocation{ID: "pountney", Address: "Pountney Road", Date: startOfDay(time.Now()).AddDate(0, 0, 1)}
+	saveSnapshot(context.Background(), []SkipLocation{pountney}, time.Now().Add(-time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.StreamChanges(ctx, &skipsv1.StreamChangesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Headers are sent once the server has read the snapshot
+	if _, err := stream.Header(); err != nil {
+		t.Fatal(err)
+	}
+	pountney.Status = StatusCancelled
+	saveSnapshot(context.Background(), []SkipLocation{pountney}, time.Now())
+
+	msg, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change := msg.GetChange(); change.GetKind() != skipsv1.SkipChange_KIND_CANCELLED || change.GetSkip().GetId() != "pountney" {
+		t.Errorf("Unexpected change %v", change)
+	}
+}
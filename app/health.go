@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Defaults for the deep health check, overridden by HEALTH_MAX_SCRAPE_AGE_HOURS
+// and HEALTH_MIN_LOCATIONS
+const (
+	defaultMaxScrapeAge = 48 * time.Hour
+	defaultMinLocations = 1
+)
+
+// HealthCheck is the result of one deep health check
+type HealthCheck struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// DeepHealth is the response served from /healthz/deep
+type DeepHealth struct {
+	Status     string                 `json:"status"`
+	LastScrape *time.Time             `json:"lastScrape,omitempty"`
+	Locations  int                    `json:"locations"`
+	Checks     map[string]HealthCheck `json:"checks"`
+}
+
+// healthThresholds reads the deep health check limits from the environment
+func healthThresholds() (maxAge time.Duration, minLocations int) {
+	maxAge, minLocations = defaultMaxScrapeAge, defaultMinLocations
+	if hours := os.Getenv("HEALTH_MAX_SCRAPE_AGE_HOURS"); hours != "" {
+		if d, err := time.ParseDuration(hours + "h"); err == nil && d > 0 {
+			maxAge = d
+		}
+	}
+	if value := os.Getenv("HEALTH_MIN_LOCATIONS"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			minLocations = n
+		}
+	}
+	return maxAge, minLocations
+}
+
+// checkDeepHealth inspects the last successful scrape. It reads the
+// snapshot rather than the cache so a check never triggers a scrape, and so
+// stale or fallback data being served can't hide a broken scraper.
+func checkDeepHealth(ctx context.Context, now time.Time, maxAge time.Duration, minLocations int) DeepHealth {
+	health := DeepHealth{Status: "ok", Checks: make(map[string]HealthCheck)}
+
+	locations, takenAt, ok := loadSnapshot(ctx)
+	health.Locations = len(locations)
+
+	switch {
+	case !ok || takenAt.IsZero():
+		health.Checks["scrapeFreshness"] = HealthCheck{false, "no successful scrape recorded"}
+	case now.Sub(takenAt) > maxAge:
+		health.Checks["scrapeFreshness"] = HealthCheck{false, "last successful scrape was " + now.Sub(takenAt).Round(time.Minute).String() + " ago, over " + maxAge.String()}
+	default:
+		health.Checks["scrapeFreshness"] = HealthCheck{true, "last successful scrape was " + now.Sub(takenAt).Round(time.Minute).String() + " ago"}
+	}
+	if !takenAt.IsZero() {
+		takenAt = takenAt.UTC()
+		health.LastScrape = &takenAt
+	}
+
+	if len(locations) < minLocations {
+		health.Checks["locationCount"] = HealthCheck{false, "parsed " + strconv.Itoa(len(locations)) + " locations, expected at least " + strconv.Itoa(minLocations)}
+	} else {
+		health.Checks["locationCount"] = HealthCheck{true, "parsed " + strconv.Itoa(len(locations)) + " locations"}
+	}
+
+	for _, check := range health.Checks {
+		if !check.OK {
+			health.Status = "fail"
+		}
+	}
+	return health
+}
+
+// HandleDeepHealth handles requests to /healthz/deep, returning 503 when the
+// scraper has stopped producing plausible data
+func HandleDeepHealth(w http.ResponseWriter, r *http.Request) {
+	maxAge, minLocations := healthThresholds()
+	health := checkDeepHealth(r.Context(), time.Now(), maxAge, minLocations)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if health.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(health)
+}
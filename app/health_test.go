@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCheckDeepHealth(t *testing.T) {
+	previous, previousPath := activeCache, snapshotPath
+	activeCache, snapshotPath = NewMemoryCache(), ""
+	defer func() { activeCache, snapshotPath = previous, previousPath }()
+
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if health := checkDeepHealth(ctx, now, time.Hour, 1); health.Status != "fail" || health.Checks["scrapeFreshness"].OK {
+		t.Errorf("No scrape yet should fail, got %+v", health)
+	}
+
+	saveSnapshot(ctx, []SkipLocation{{Address: "One Road"}, {Address: "Two Road"}}, now.Add(-30*time.Minute))
+	if health := checkDeepHealth(ctx, now, time.Hour, 1); health.Status != "ok" || health.Locations != 2 {
+		t.Errorf("Recent plausible scrape should pass, got %+v", health)
+	}
+	if health := checkDeepHealth(ctx, now, 10*time.Minute, 1); health.Checks["scrapeFreshness"].OK {
+		t.Errorf("Old scrape should fail freshness, got %+v", health)
+	}
+	if health := checkDeepHealth(ctx, now, time.Hour, 5); health.Status != "fail" || health.Checks["locationCount"].OK {
+		t.Errorf("Too few locations should fail, got %+v", health)
+	}
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Skips open at 9am and close at noon (or when full), London time, unless
+// the council page says otherwise. Times are kept as "15:04" in London time.
+const (
+	defaultOpensAt  = "09:00"
+	defaultClosesAt = "12:00"
+)
+
+// clockPattern matches times such as "9am", "9.30am", "1:00 pm", "12 noon"
+// and "midday"
+var clockPattern = regexp.MustCompile(`(?i)\b(?:(\d{1,2})(?:[:.](\d{2}))?\s*(am|pm)\b|(?:12\s*)?(noon|midday)\b)`)
+
+// parseOpeningHours finds the first two times in text, such as "9am to
+// 1pm" or "from 9am until they are full, or until 12 noon", and returns
+// them as opening and closing times
+func parseOpeningHours(text string) (opens, closes string, ok bool) {
+	var times []string
+	for _, m := range clockPattern.FindAllStringSubmatch(text, -1) {
+		if t, ok := clockTime(m); ok {
+			times = append(times, t)
+		}
+		if len(times) == 2 {
+			break
+		}
+	}
+	if len(times) < 2 || times[0] >= times[1] {
+		return "", "", false
+	}
+	return times[0], times[1], true
+}
+
+// clockTime converts a clockPattern match to "15:04"
+func clockTime(m []string) (string, bool) {
+	if m[4] != "" {
+		return "12:00", true
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if hour < 1 || hour > 12 || minute > 59 {
+		return "", false
+	}
+
+	switch {
+	case strings.EqualFold(m[3], "am") && hour == 12:
+		hour = 0
+	case strings.EqualFold(m[3], "pm") && hour != 12:
+		hour += 12
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute), true
+}
+
+// withOpeningHours sets the location's hours, unless it already has them,
+// from the first of texts that mentions them, most specific first. They are
+// left unset, so the defaults apply, if none do.
+func withOpeningHours(loc SkipLocation, texts ...string) SkipLocation {
+	if loc.OpensAt != "" {
+		return loc
+	}
+	for _, text := range texts {
+		if opens, closes, ok := parseOpeningHours(text); ok {
+			loc.OpensAt, loc.ClosesAt = opens, closes
+			break
+		}
+	}
+	return loc
+}
+
+// pageOpeningHours returns the first paragraph of a council page that
+// gives opening hours, such as "available from 9am until 12 noon", or ""
+func pageOpeningHours(doc *goquery.Document) string {
+	var hours string
+	doc.Find("p").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if _, _, ok := parseOpeningHours(s.Text()); ok {
+			hours = s.Text()
+			return false
+		}
+		return true
+	})
+	return hours
+}
+
+// hours returns when the skip opens and closes as "15:04" London times
+func (l SkipLocation) hours() (opens, closes string) {
+	opens, closes = l.OpensAt, l.ClosesAt
+	if opens == "" || closes == "" {
+		return defaultOpensAt, defaultClosesAt
+	}
+	return opens, closes
+}
+
+// dayHours spans the hours of every skip on a day, from the earliest
+// opening to the latest closing
+func dayHours(skips []SkipLocation) (opens, closes string) {
+	if len(skips) == 0 {
+		return defaultOpensAt, defaultClosesAt
+	}
+
+	opens, closes = skips[0].hours()
+	for _, skip := range skips[1:] {
+		o, c := skip.hours()
+		opens, closes = min(opens, o), max(closes, c)
+	}
+	return opens, closes
+}
+
+// atClock returns the London time hhmm on date's day
+func atClock(date time.Time, hhmm string) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		t, _ = time.Parse("15:04", defaultOpensAt)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, londonTime)
+}
+
+// formatClock formats "15:04" as "3pm" or "3.30pm"
+func formatClock(hhmm string) string {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return hhmm
+	}
+	if t.Minute() == 0 {
+		return t.Format("3pm")
+	}
+	return t.Format("3.04pm")
+}
+
+// formatHours describes opening hours, such as "9am to 12pm"
+func formatHours(opens, closes string) string {
+	return formatClock(opens) + " to " + formatClock(closes)
+}
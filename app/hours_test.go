@@ -0,0 +1,54 @@
+package app
+
+import "testing"
+
+func TestParseOpeningHours(t *testing.T) {
+	tests := []struct {
+		text   string
+		opens  string
+		closes string
+		ok     bool
+	}{
+		{"Skips are available from 9am until 12 noon", "09:00", "12:00", true},
+		{"8.30am to 1pm", "08:30", "13:00", true},
+		{"10:00 AM - 2:00 PM", "10:00", "14:00", true},
+		{"From 9am until midday, or until full", "09:00", "12:00", true},
+		{"Saturday 7 February", "", "", false},
+		{"Opens 9am", "", "", false},
+		{"1pm to 9am", "", "", false},
+		{"13pm to 14pm", "", "", false},
+	}
+
+	for _, tc := range tests {
+		opens, closes, ok := parseOpeningHours(tc.text)
+		if opens != tc.opens || closes != tc.closes || ok != tc.ok {
+			t.Errorf("parseOpeningHours(%q) = %q, %q, %v; want %q, %q, %v", tc.text, opens, closes, ok, tc.opens, tc.closes, tc.ok)
+		}
+	}
+}
+
+func TestDayHours(t *testing.T) {
+	opens, closes := dayHours(nil)
+	if opens != defaultOpensAt || closes != defaultClosesAt {
+		t.Errorf("Expected the default hours for no skips, got %s to %s", opens, closes)
+	}
+
+	skips := []SkipLocation{
+		{Address: "Default Road"},
+		{Address: "Early Road", OpensAt: "08:00", ClosesAt: "11:00"},
+		{Address: "Late Road", OpensAt: "10:00", ClosesAt: "14:30"},
+	}
+	opens, closes = dayHours(skips)
+	if opens != "08:00" || closes != "14:30" {
+		t.Errorf("Expected 08:00 to 14:30, got %s to %s", opens, closes)
+	}
+}
+
+func TestFormatHours(t *testing.T) {
+	if got := formatHours("09:00", "12:00"); got != "9am to 12pm" {
+		t.Errorf("Expected 9am to 12pm, got %q", got)
+	}
+	if got := formatHours("08:30", "13:15"); got != "8.30am to 1.15pm" {
+		t.Errorf("Expected 8.30am to 1.15pm, got %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cachePolicy is how long browsers (maxAge) and the CDN (sMaxAge) may cache
+// a response, and for how long after that the CDN may keep serving it while
+// it fetches a fresh copy in the background
+type cachePolicy struct {
+	maxAge               time.Duration
+	sMaxAge              time.Duration
+	staleWhileRevalidate time.Duration
+	noStore              bool
+}
+
+// staleSMaxAge caps how long the CDN keeps a response built from the last
+// known good snapshot, so it picks up fresh data soon after the council
+// website recovers
+const staleSMaxAge = time.Minute
+
+// Cache policies for the page, the JSON API and the calendar feeds. The data
+// only changes when the council page does, so the CDN can absorb almost all
+// traffic; browsers are kept on a shorter leash so a refresh is seen soon.
+var (
+	pageCaching     = cachePolicy{maxAge: 5 * time.Minute, sMaxAge: 5 * time.Minute, staleWhileRevalidate: 24 * time.Hour}
+	apiCaching      = cachePolicy{maxAge: time.Minute, sMaxAge: 5 * time.Minute, staleWhileRevalidate: time.Hour}
+	calendarCaching = cachePolicy{maxAge: time.Hour, sMaxAge: time.Hour, staleWhileRevalidate: 24 * time.Hour}
+)
+
+// personalCaching keeps pages rendered for one visitor, such as those showing
+// their postcode or carrying a CSP nonce, out of the CDN and browser caches
+var personalCaching = cachePolicy{noStore: true}
+
+// configureHTTPCaching reads the cache policies from CACHE_CONTROL_PAGE,
+// CACHE_CONTROL_API and CACHE_CONTROL_CALENDAR
+func configureHTTPCaching() {
+	for env, policy := range map[string]*cachePolicy{
+		"CACHE_CONTROL_PAGE":     &pageCaching,
+		"CACHE_CONTROL_API":      &apiCaching,
+		"CACHE_CONTROL_CALENDAR": &calendarCaching,
+	} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseCachePolicy(value)
+		if err != nil {
+			slog.Warn("Ignoring invalid cache policy", "env", env, "err", err)
+			continue
+		}
+		*policy = parsed
+	}
+}
+
+// parseCachePolicy parses Cache-Control style directives, such as
+// "max-age=60, s-maxage=300, stale-while-revalidate=3600", or "no-store"
+func parseCachePolicy(value string) (cachePolicy, error) {
+	var p cachePolicy
+	for _, directive := range strings.Split(value, ",") {
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(directive), "=")
+		if name == "no-store" && !hasArg {
+			p.noStore = true
+			continue
+		}
+
+		seconds, err := strconv.Atoi(arg)
+		if !hasArg || err != nil || seconds < 0 {
+			return cachePolicy{}, fmt.Errorf("invalid directive %q", directive)
+		}
+		d := time.Duration(seconds) * time.Second
+		switch name {
+		case "max-age":
+			p.maxAge = d
+		case "s-maxage":
+			p.sMaxAge = d
+		case "stale-while-revalidate":
+			p.staleWhileRevalidate = d
+		default:
+			return cachePolicy{}, fmt.Errorf("unknown directive %q", name)
+		}
+	}
+	return p, nil
+}
+
+// apply sets Cache-Control for browsers and CDN-Cache-Control for the CDN.
+// Responses served from the snapshot are only cached briefly.
+func (p cachePolicy) apply(w http.ResponseWriter) {
+	if p.noStore {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Del("CDN-Cache-Control")
+		return
+	}
+
+	if _, stale := servingSnapshot(); stale {
+		p.maxAge = min(p.maxAge, staleSMaxAge)
+		p.sMaxAge = min(p.sMaxAge, staleSMaxAge)
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, s-maxage=%d, stale-while-revalidate=%d",
+		int(p.maxAge.Seconds()), int(p.sMaxAge.Seconds()), int(p.staleWhileRevalidate.Seconds())))
+	w.Header().Set("CDN-Cache-Control", fmt.Sprintf("max-age=%d, stale-while-revalidate=%d",
+		int(p.sMaxAge.Seconds()), int(p.staleWhileRevalidate.Seconds())))
+}
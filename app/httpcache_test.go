@@ -0,0 +1,66 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseCachePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		want    cachePolicy
+		wantErr bool
+	}{
+		{"max-age=60, s-maxage=300, stale-while-revalidate=3600", cachePolicy{maxAge: time.Minute, sMaxAge: 5 * time.Minute, staleWhileRevalidate: time.Hour}, false},
+		{"s-maxage=600", cachePolicy{sMaxAge: 10 * time.Minute}, false},
+		{"no-store", cachePolicy{noStore: true}, false},
+		{"max-age=soon", cachePolicy{}, true},
+		{"max-age=-1", cachePolicy{}, true},
+		{"private", cachePolicy{}, true},
+	} {
+		got, err := parseCachePolicy(tc.value)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("parseCachePolicy(%q) = %+v, %v", tc.value, got, err)
+		}
+	}
+}
+
+func TestCachePolicyApply(t *testing.T) {
+	policy := cachePolicy{maxAge: time.Minute, sMaxAge: time.Hour, staleWhileRevalidate: 24 * time.Hour}
+
+	w := httptest.NewRecorder()
+	policy.apply(w)
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=60, s-maxage=3600, stale-while-revalidate=86400" {
+		t.Errorf("Unexpected Cache-Control %q", got)
+	}
+	if got := w.Header().Get("CDN-Cache-Control"); got != "max-age=3600, stale-while-revalidate=86400" {
+		t.Errorf("Unexpected CDN-Cache-Control %q", got)
+	}
+
+	// Snapshot data is only cached until the council site is back
+	takenAt := time.Now()
+	snapshotServed.Store(&takenAt)
+	defer snapshotServed.Store(nil)
+	w = httptest.NewRecorder()
+	policy.apply(w)
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=60, s-maxage=60, stale-while-revalidate=86400" {
+		t.Errorf("Unexpected Cache-Control for a snapshot %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	cachePolicy{noStore: true}.apply(w)
+	if w.Header().Get("Cache-Control") != "no-store" || w.Header().Get("CDN-Cache-Control") != "" {
+		t.Errorf("Expected no-store, got %v", w.Header())
+	}
+}
+
+func TestProblemsAreNotCached(t *testing.T) {
+	w := httptest.NewRecorder()
+	apiCaching.apply(w)
+	writeProblem(w, http.StatusInternalServerError, "Failed")
+	if w.Header().Get("Cache-Control") != "no-store" || w.Header().Get("CDN-Cache-Control") != "" {
+		t.Errorf("Expected errors to be uncacheable, got %v", w.Header())
+	}
+}
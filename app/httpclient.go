@@ -0,0 +1,36 @@
+package app
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// httpClient is shared by everything that talks to the outside world (the
+// council site, geocoders and Redis) so connections are pooled and reused
+// across requests on warm instances.
+var httpClient = newHTTPClient()
+
+// newHTTPClient creates an HTTP client with sensible timeouts, keep-alives
+// and proxy support via HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+func newHTTPClient() *http.Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: tracingTransport{transport},
+		Timeout:   15 * time.Second,
+	}
+}
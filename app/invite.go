@@ -0,0 +1,305 @@
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sendMail delivers a message over SMTP; replaced in tests
+var sendMail = smtp.SendMail
+
+// smtpConfig is the outgoing mail server used for calendar invites
+type smtpConfig struct {
+	addr     string
+	username string
+	password string
+	from     *mail.Address
+}
+
+// loadSMTPConfig reads the mail server from SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM. It returns false when
+// invites are not configured.
+func loadSMTPConfig() (smtpConfig, bool) {
+	host := os.Getenv("SMTP_HOST")
+	fromValue := os.Getenv("SMTP_FROM")
+	if host == "" || fromValue == "" {
+		return smtpConfig{}, false
+	}
+
+	from, err := mail.ParseAddress(fromValue)
+	if err != nil {
+		slog.Warn("Invalid SMTP_FROM address", "err", err)
+		return smtpConfig{}, false
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	password, err := getSecret("SMTP_PASSWORD")
+	if err != nil {
+		slog.Warn("Failed to load SMTP password", "err", err)
+		return smtpConfig{}, false
+	}
+
+	return smtpConfig{
+		addr:     net.JoinHostPort(host, port),
+		username: os.Getenv("SMTP_USERNAME"),
+		password: password,
+		from:     from,
+	}, true
+}
+
+// auth returns PLAIN auth when a username is configured
+func (c smtpConfig) auth() smtp.Auth {
+	if c.username == "" {
+		return nil
+	}
+	host, _, _ := net.SplitHostPort(c.addr)
+	return smtp.PlainAuth("", c.username, c.password, host)
+}
+
+// HandleCalendarInvite handles form posts to /calendar/invite, emailing an
+// invitation for one skip day to people whose calendar apps don't handle
+// subscription URLs well
+func HandleCalendarInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	cfg, ok := loadSMTPConfig()
+	if !ok {
+		writeProblem(w, http.StatusServiceUnavailable, "Email invites are not available")
+		return
+	}
+
+	to, err := mail.ParseAddress(strings.TrimSpace(r.FormValue("email")))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "Invalid email address")
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", r.FormValue("date"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "Invalid date")
+		return
+	}
+
+	typ := EventMegaSkip
+	if value := r.FormValue("type"); value != "" {
+		types, err := parseEventTypes(value)
+		if err != nil || len(types) != 1 {
+			writeProblem(w, http.StatusBadRequest, "Invalid event type")
+			return
+		}
+		typ = types[0]
+	}
+
+	sent := fmt.Sprintf("Invite for %s sent to %s\n", formatSkipDate(date), to.Address)
+
+	// Bots and over-invited addresses are turned away before looking up the
+	// skips or geocoding the postcode, so they can't make us call upstream.
+	// Bots get the same answer as people, so they can't tell they were caught.
+	if r.FormValue(inviteHoneypotField) != "" {
+		slog.InfoContext(r.Context(), "Dropping invite that filled in the honeypot")
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, sent)
+		return
+	}
+
+	if ok, err := verifyTurnstile(r); err != nil {
+		slog.ErrorContext(r.Context(), "Error verifying Turnstile response", "err", err)
+		writeProblem(w, http.StatusBadGateway, "Failed to check you're not a robot")
+		return
+	} else if !ok {
+		writeProblem(w, http.StatusForbidden, "Please complete the challenge to show you're not a robot")
+		return
+	}
+
+	if ok, err := allowInviteFrom(r.Context(), clientIP(r), time.Now()); err != nil {
+		slog.ErrorContext(r.Context(), "Error counting invites", "err", err)
+		writeProblem(w, http.StatusServiceUnavailable, "Email invites are not available")
+		return
+	} else if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(invitesWindow.Seconds())))
+		writeProblem(w, http.StatusTooManyRequests, "Too many invites have been sent from your network today")
+		return
+	}
+
+	if ok, err := allowInvite(r.Context(), to.Address, time.Now()); err != nil {
+		slog.ErrorContext(r.Context(), "Error counting invites", "err", err)
+		writeProblem(w, http.StatusServiceUnavailable, "Email invites are not available")
+		return
+	} else if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(invitesWindow.Seconds())))
+		writeProblem(w, http.StatusTooManyRequests, "That address has been sent too many invites today")
+		return
+	}
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+	onDate := groupSkipsByDate(filterByType(locations, []EventType{typ}))
+	if _, ok := onDate[date]; !ok || date.Before(startOfDay(time.Now())) {
+		writeProblem(w, http.StatusBadRequest, "There is no upcoming skip day on that date")
+		return
+	}
+	if allCancelled(onDate[date]) {
+		writeProblem(w, http.StatusBadRequest, "That skip day has been cancelled")
+		return
+	}
+	scheduled := scheduledSkips(onDate[date])
+
+	event := CalendarEvent{
+		Date:        date,
+		Type:        typ,
+		Title:       eventTitle(typ),
+		Description: eventDescription(currentTenant().SiteURL, scheduled),
+		URL:         currentTenant().SiteURL,
+	}
+	event.OpensAt, event.ClosesAt = dayHours(scheduled)
+
+	// Optionally include the nearest skip to the user's postcode
+	if postcode := strings.TrimSpace(r.FormValue("postcode")); postcode != "" {
+		lat, lng, borough, err := locatePostcode(r.Context(), postcode)
+		if errors.Is(err, errInvalidPostcode) {
+			writeProblem(w, http.StatusBadRequest, "Invalid postcode format")
+			return
+		}
+		if errors.Is(err, errUnsupportedBorough) {
+			writeProblem(w, http.StatusUnprocessableEntity, unsupportedBoroughMessage())
+			return
+		}
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "Could not find postcode location")
+			return
+		}
+		idx, err := skipIndexFor(r.Context(), borough)
+		if err != nil {
+			writeProblem(w, http.StatusInternalServerError, "Failed to find nearest skip")
+			return
+		}
+		nearest := idx.nearest(date, typ, lat, lng)
+		event.Location = skipEventLocation(nearest)
+		if nearest != nil {
+			event.OpensAt, event.ClosesAt = nearest.hours()
+			event.Description = eventDescription(currentTenant().SiteURL, []SkipLocation{*nearest})
+			event.Latitude, event.Longitude = nearest.Latitude, nearest.Longitude
+			event.URL = skipURL(nearest.ID)
+		}
+	}
+
+	msg, err := buildInviteEmail(cfg.from, to, event, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building invite email", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to build invite")
+		return
+	}
+
+	if err := sendMail(cfg.addr, cfg.auth(), cfg.from.Address, []string{to.Address}, msg); err != nil {
+		slog.ErrorContext(r.Context(), "Error sending invite email", "err", err)
+		writeProblem(w, http.StatusBadGateway, "Failed to send invite")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, sent)
+}
+
+// buildInviteEmail builds a MIME message carrying event as an inline
+// text/calendar REQUEST part, which mail clients render as an invitation,
+// and as an .ics attachment for clients that don't
+func buildInviteEmail(from, to *mail.Address, event CalendarEvent, now time.Time) ([]byte, error) {
+	var ics bytes.Buffer
+	if err := writeICalInvite(&ics, event, icalInvite{Organizer: from.Address, Attendee: to.Address}); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	text := fmt.Sprintf("%s, %s\r\n", formatSkipDate(event.Date), formatHours(event.hours()))
+	if event.Location != "" {
+		text += event.Location + "\r\n"
+	}
+	text += "\r\n" + strings.ReplaceAll(event.Description, "\n", "\r\n") + "\r\n"
+
+	parts := []struct {
+		header textproto.MIMEHeader
+		data   []byte
+	}{
+		{textproto.MIMEHeader{
+			"Content-Type":              {"text/plain; charset=utf-8"},
+			"Content-Transfer-Encoding": {"base64"},
+		}, []byte(text)},
+		{textproto.MIMEHeader{
+			"Content-Type":              {"text/calendar; charset=utf-8; method=REQUEST"},
+			"Content-Transfer-Encoding": {"base64"},
+		}, ics.Bytes()},
+		{textproto.MIMEHeader{
+			"Content-Type":              {`application/ics; name="invite.ics"`},
+			"Content-Disposition":       {`attachment; filename="invite.ics"`},
+			"Content-Transfer-Encoding": {"base64"},
+		}, ics.Bytes()},
+	}
+	for _, part := range parts {
+		pw, err := mw.CreatePart(part.header)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(pw, part.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("%s: %s", event.Title, formatSkipDate(event.Date))
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n", mw.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// writeBase64Lines writes data as base64 wrapped at 76 characters, as MIME requires
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := io.WriteString(w, encoded+"\r\n")
+	return err
+}
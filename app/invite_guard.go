@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// inviteHoneypotField is hidden from people by CSS, so a form post that
+// fills it in came from a bot filling in every field
+const inviteHoneypotField = "website"
+
+const (
+	// invitesPerRecipient caps how many invites one address is sent in
+	// invitesWindow, so the form can't be used to flood an inbox
+	invitesPerRecipient = 3
+	invitesWindow       = 24 * time.Hour
+
+	// invitesPerClient caps how many invites one client IP can send in
+	// invitesWindow, so the form can't be used to mail many addresses
+	invitesPerClient = 10
+)
+
+// inviteCountsMu serialises read-modify-write updates of the invite counts
+var inviteCountsMu sync.Mutex
+
+// inviteCount is how many invites an address has been sent since Since
+type inviteCount struct {
+	Count int       `json:"count"`
+	Since time.Time `json:"since"`
+}
+
+// inviteCountKey is the cache key counting invites to address. Addresses
+// are hashed so the cache never holds them.
+func inviteCountKey(address string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(address)))
+	return fmt.Sprintf("invites_%x", sum[:12])
+}
+
+// inviteClientKey is the cache key counting invites sent by a client IP,
+// hashed like addresses
+func inviteClientKey(ip string) string {
+	sum := sha256.Sum256([]byte(ip))
+	return fmt.Sprintf("invites_from_%x", sum[:12])
+}
+
+// allowInvite counts an invite to address, reporting false once the address
+// has had invitesPerRecipient in the current window. The counts live in the
+// shared cache, so the cap holds across instances.
+func allowInvite(ctx context.Context, address string, now time.Time) (bool, error) {
+	return allowInviteCount(ctx, inviteCountKey(address), invitesPerRecipient, now)
+}
+
+// allowInviteFrom counts an invite sent by a client IP, reporting false
+// once it has sent invitesPerClient in the current window
+func allowInviteFrom(ctx context.Context, ip string, now time.Time) (bool, error) {
+	return allowInviteCount(ctx, inviteClientKey(ip), invitesPerClient, now)
+}
+
+// allowInviteCount counts an invite under key, reporting false once limit
+// have been counted in the current window
+func allowInviteCount(ctx context.Context, key string, limit int, now time.Time) (bool, error) {
+	inviteCountsMu.Lock()
+	defer inviteCountsMu.Unlock()
+
+	var count inviteCount
+	if _, err := getCachedValue(ctx, key, &count); err != nil {
+		return false, err
+	}
+	if now.Sub(count.Since) >= invitesWindow {
+		count = inviteCount{Since: now}
+	}
+	if count.Count >= limit {
+		return false, nil
+	}
+
+	count.Count++
+	return true, setCachedValue(ctx, key, count, invitesWindow-now.Sub(count.Since))
+}
+
+var (
+	// turnstileSiteKey shows a Cloudflare Turnstile challenge on the invite
+	// form, set from TURNSTILE_SITE_KEY. Responses are checked against
+	// TURNSTILE_SECRET_KEY.
+	turnstileSiteKey string
+
+	// turnstileVerifyURL is Turnstile's siteverify endpoint; replaced in
+	// tests
+	turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// turnstileOrigin serves the Turnstile script and challenge frame
+const turnstileOrigin = "https://challenges.cloudflare.com"
+
+// configureTurnstile reads the Turnstile site key
+func configureTurnstile() {
+	turnstileSiteKey = os.Getenv("TURNSTILE_SITE_KEY")
+}
+
+// verifyTurnstile checks the Turnstile response posted with a form. It
+// passes every request when no secret key is configured.
+func verifyTurnstile(r *http.Request) (bool, error) {
+	secret, err := getSecret("TURNSTILE_SECRET_KEY")
+	if err != nil {
+		return false, err
+	}
+	if secret == "" {
+		return true, nil
+	}
+
+	token := r.FormValue("cf-turnstile-response")
+	if token == "" {
+		return false, nil
+	}
+
+	form := url.Values{"secret": {secret}, "response": {token}, "remoteip": {clientIP(r)}}
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, turnstileVerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("decoding response: %w", err)
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,255 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildInviteEmail(t *testing.T) {
+	from := &mail.Address{Name: "Where Mega Skip?", Address: "skips@example.com"}
+	to := &mail.Address{Address: "resident@example.com"}
+	event := CalendarEvent{
+		Date:        time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+		Title:       "Wandsworth Mega Skip",
+		Description: "https://wheremegaskip.com",
+		Location:    "Garratt Lane, SW18 4AA, London, UK",
+	}
+
+	raw, err := buildInviteEmail(from, to, event, time.Now())
+	if err != nil {
+		t.Fatalf("buildInviteEmail: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("Invite is not a valid message: %v", err)
+	}
+	if got := msg.Header.Get("To"); got != "<resident@example.com>" {
+		t.Errorf("To = %q", got)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, %v", mediaType, err)
+	}
+
+	var types []string
+	var calendar string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/calendar") {
+			data, _ := io.ReadAll(part)
+			decoded, err := decodeBase64Lines(string(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			calendar = decoded
+		}
+	}
+
+	if len(types) != 3 || !strings.Contains(types[1], "method=REQUEST") || !strings.HasPrefix(types[2], "application/ics") {
+		t.Errorf("Unexpected parts: %v", types)
+	}
+	for _, want := range []string{
+		"METHOD:REQUEST\r\n",
+		"ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=FALSE:mailto:resident@example.com\r\n",
+		"mailto:skips@example.com\r\n",
+		"LOCATION:Garratt Lane\\, SW18 4AA\\, London\\, UK\r\n",
+		"UID:invite-",
+	} {
+		if !strings.Contains(calendar, want) {
+			t.Errorf("Invite calendar should contain %q, got:\n%s", want, calendar)
+		}
+	}
+}
+
+func TestHandleCalendarInviteRequiresConfig(t *testing.T) {
+	t.Setenv("SMTP_HOST", "")
+	sent := false
+	sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+		sent = true
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+
+	form := url.Values{"email": {"resident@example.com"}, "date": {"2026-10-18"}}
+	req := httptest.NewRequest("POST", "/calendar/invite", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	HandleCalendarInvite(rec, req)
+
+	if rec.Code != 503 || sent {
+		t.Errorf("Status = %d, sent = %v; want 503 without sending", rec.Code, sent)
+	}
+}
+
+// postInvite posts the invite form for the test skip day set up by
+// useInviteTest
+func postInvite(t *testing.T, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	if form.Get("date") == "" {
+		form.Set("date", startOfDay(time.Now()).AddDate(0, 0, 2).Format("2006-01-02"))
+	}
+	req := httptest.NewRequest("POST", "/calendar/invite", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	HandleCalendarInvite(rec, req)
+	return rec
+}
+
+// useInviteTest configures SMTP and a skip day two days away, returning a
+// count of the emails sent
+func useInviteTest(t *testing.T) *int {
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_FROM", "skips@example.com")
+	t.Setenv("TURNSTILE_SECRET_KEY", "")
+	useTestSkips(t, []SkipLocation{{
+		Address: "Garratt Lane", Postcode: "SW18 4DJ", Type: EventMegaSkip,
+		Date: startOfDay(time.Now()).AddDate(0, 0, 2), Latitude: 51.45, Longitude: -0.19,
+	}})
+
+	sent := new(int)
+	sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+		*sent++
+		return nil
+	}
+	t.Cleanup(func() { sendMail = smtp.SendMail })
+	return sent
+}
+
+func TestHandleCalendarInviteHoneypot(t *testing.T) {
+	sent := useInviteTest(t)
+
+	rec := postInvite(t, url.Values{"email": {"resident@example.com"}, inviteHoneypotField: {"https://spam.example"}})
+	if rec.Code != 200 || *sent != 0 {
+		t.Errorf("Status = %d, sent = %d; want a pretend success without sending", rec.Code, *sent)
+	}
+
+	// Bots are dropped before the skip day or postcode are looked up
+	rec = postInvite(t, url.Values{
+		"email": {"resident@example.com"}, "postcode": {"ZZ99 9ZZ"}, inviteHoneypotField: {"https://spam.example"},
+		"date": {startOfDay(time.Now()).AddDate(0, 0, 3).Format("2006-01-02")},
+	})
+	if rec.Code != 200 || *sent != 0 {
+		t.Errorf("Status = %d, sent = %d; want a pretend success before any lookups", rec.Code, *sent)
+	}
+}
+
+func TestHandleCalendarInviteCapsRecipient(t *testing.T) {
+	sent := useInviteTest(t)
+
+	for i := 0; i < invitesPerRecipient; i++ {
+		if rec := postInvite(t, url.Values{"email": {"resident@example.com"}}); rec.Code != 200 {
+			t.Fatalf("Invite %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+	}
+	rec := postInvite(t, url.Values{"email": {"Resident@Example.com"}})
+	if rec.Code != 429 || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Status = %d, Retry-After = %q; want 429", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := postInvite(t, url.Values{"email": {"neighbour@example.com"}}); rec.Code != 200 {
+		t.Errorf("Other recipients should still be sent invites, got %d", rec.Code)
+	}
+	if *sent != invitesPerRecipient+1 {
+		t.Errorf("Sent %d invites, want %d", *sent, invitesPerRecipient+1)
+	}
+}
+
+func TestHandleCalendarInviteCapsClient(t *testing.T) {
+	sent := useInviteTest(t)
+
+	for i := 0; i < invitesPerClient; i++ {
+		if rec := postInvite(t, url.Values{"email": {fmt.Sprintf("resident%d@example.com", i)}}); rec.Code != 200 {
+			t.Fatalf("Invite %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+	}
+	rec := postInvite(t, url.Values{"email": {"another@example.com"}})
+	if rec.Code != 429 || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Status = %d, Retry-After = %q; want 429", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if *sent != invitesPerClient {
+		t.Errorf("Sent %d invites, want %d", *sent, invitesPerClient)
+	}
+
+	// Other clients can still send invites, including to that address
+	form := url.Values{"email": {"another@example.com"}, "date": {startOfDay(time.Now()).AddDate(0, 0, 2).Format("2006-01-02")}}
+	req := httptest.NewRequest("POST", "/calendar/invite", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	rec = httptest.NewRecorder()
+	HandleCalendarInvite(rec, req)
+	if rec.Code != 200 {
+		t.Errorf("Expected another client's invite to be sent, got %d", rec.Code)
+	}
+}
+
+func TestAllowInviteWindowExpires(t *testing.T) {
+	useTestSkips(t, nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < invitesPerRecipient; i++ {
+		allowInvite(ctx, "resident@example.com", now)
+	}
+	if ok, _ := allowInvite(ctx, "resident@example.com", now); ok {
+		t.Error("Expected the cap to apply within the window")
+	}
+	if ok, err := allowInvite(ctx, "resident@example.com", now.Add(invitesWindow)); !ok || err != nil {
+		t.Errorf("Expected a new window, got %v, %v", ok, err)
+	}
+}
+
+func TestHandleCalendarInviteTurnstile(t *testing.T) {
+	sent := useInviteTest(t)
+	t.Setenv("TURNSTILE_SECRET_KEY", "secret")
+
+	var posted url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		posted = r.PostForm
+		fmt.Fprintf(w, `{"success": %t}`, r.PostForm.Get("response") == "good")
+	}))
+	defer srv.Close()
+	previous := turnstileVerifyURL
+	turnstileVerifyURL = srv.URL
+	defer func() { turnstileVerifyURL = previous }()
+
+	for _, token := range []string{"", "bad"} {
+		rec := postInvite(t, url.Values{"email": {"resident@example.com"}, "cf-turnstile-response": {token}})
+		if rec.Code != 403 {
+			t.Errorf("Token %q: status = %d, want 403", token, rec.Code)
+		}
+	}
+	rec := postInvite(t, url.Values{"email": {"resident@example.com"}, "cf-turnstile-response": {"good"}})
+	if rec.Code != 200 || *sent != 1 {
+		t.Errorf("Status = %d, sent = %d; want the invite sent", rec.Code, *sent)
+	}
+	if posted.Get("secret") != "secret" {
+		t.Errorf("siteverify got secret %q", posted.Get("secret"))
+	}
+}
+
+func decodeBase64Lines(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(s, "\r\n", ""))
+	return string(data), err
+}
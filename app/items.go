@@ -0,0 +1,96 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	// prohibitedHeading matches headings such as "What you can't bring" or
+	// "Items not accepted". It's checked before acceptedHeading, which
+	// would otherwise match "Items not accepted" too.
+	prohibitedHeading = regexp.MustCompile(`(?i)\b(?:can['’]?t|cannot|can not|not)\s+(?:bring|accept|take|put|be accepted)|\bnot accepted\b|\bprohibited\b|\bexcluded\b`)
+
+	// acceptedHeading matches headings such as "What you can bring" or
+	// "Items we accept"
+	acceptedHeading = regexp.MustCompile(`(?i)\b(?:can|we)\s+(?:bring|accept|take|put)\b|\baccepted\b|\bwhat goes in\b`)
+)
+
+// pageItems returns the list items under a council page's "What you can
+// bring" and "What you can't bring" headings, which apply to every skip
+// on the page
+func pageItems(doc *goquery.Document) (accepted, prohibited []string) {
+	doc.Find("h2, h3, h4").Each(func(i int, s *goquery.Selection) {
+		heading := s.Text()
+		switch {
+		case prohibitedHeading.MatchString(heading):
+			prohibited = append(prohibited, sectionItems(s)...)
+		case acceptedHeading.MatchString(heading):
+			accepted = append(accepted, sectionItems(s)...)
+		}
+	})
+	return accepted, prohibited
+}
+
+// sectionItems returns the text of the list items between a heading and
+// the next heading
+func sectionItems(heading *goquery.Selection) []string {
+	var items []string
+	heading.NextUntil("h1, h2, h3, h4").Find("li").Each(func(i int, s *goquery.Selection) {
+		item := strings.Join(strings.Fields(s.Text()), " ")
+		for range 3 {
+			item = strings.TrimSpace(listMarker.ReplaceAllString(item, ""))
+		}
+		if item != "" {
+			items = append(items, item)
+		}
+	})
+	return items
+}
+
+// withItems sets what can and can't be brought to the location, unless
+// the location already has its own lists
+func withItems(loc SkipLocation, accepted, prohibited []string) SkipLocation {
+	if loc.AcceptedItems == nil {
+		loc.AcceptedItems = accepted
+	}
+	if loc.ProhibitedItems == nil {
+		loc.ProhibitedItems = prohibited
+	}
+	return loc
+}
+
+// skipItems returns the accepted and prohibited items of the first of
+// skips that lists any
+func skipItems(skips []SkipLocation) (accepted, prohibited []string) {
+	for _, skip := range skips {
+		if len(skip.AcceptedItems) > 0 || len(skip.ProhibitedItems) > 0 {
+			return skip.AcceptedItems, skip.ProhibitedItems
+		}
+	}
+	return nil, nil
+}
+
+// itemsDescription describes what can and can't be brought, one list per
+// line, or "" if neither is known
+func itemsDescription(accepted, prohibited []string) string {
+	var lines []string
+	if len(accepted) > 0 {
+		lines = append(lines, "What you can bring: "+strings.Join(accepted, ", "))
+	}
+	if len(prohibited) > 0 {
+		lines = append(lines, "What you can't bring: "+strings.Join(prohibited, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// eventDescription is the calendar description of skips: the site URL,
+// followed by what can and can't be brought
+func eventDescription(siteURL string, skips []SkipLocation) string {
+	if items := itemsDescription(skipItems(skips)); items != "" {
+		return siteURL + "\n\n" + items
+	}
+	return siteURL
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestPageItems(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`
+		<h2>Items we accept</h2>
+		<p>Most household waste, including:</p>
+		<ul><li>• Furniture</li><li>Carpets and
+			rugs</li></ul>
+		<h2>Items not accepted</h2>
+		<ul><li>Tyres</li></ul>
+		<h2>Dates</h2>
+		<ul><li>Pountney Road, SW11 5TU</li></ul>
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accepted, prohibited := pageItems(doc)
+	if want := []string{"Furniture", "Carpets and rugs"}; !slices.Equal(accepted, want) {
+		t.Errorf("Expected accepted items %q, got %q", want, accepted)
+	}
+	if want := []string{"Tyres"}; !slices.Equal(prohibited, want) {
+		t.Errorf("Expected prohibited items %q, got %q", want, prohibited)
+	}
+}
+
+func TestItemHeadings(t *testing.T) {
+	tests := []struct {
+		heading    string
+		accepted   bool
+		prohibited bool
+	}{
+		{"What you can bring", true, false},
+		{"What you can't bring", false, true},
+		{"What you can’t bring", false, true},
+		{"What you cannot bring", false, true},
+		{"Accepted items", true, false},
+		{"Items not accepted", false, true},
+		{"Prohibited items", false, true},
+		{"Dates and locations", false, false},
+	}
+
+	for _, tc := range tests {
+		prohibited := prohibitedHeading.MatchString(tc.heading)
+		accepted := !prohibited && acceptedHeading.MatchString(tc.heading)
+		if accepted != tc.accepted || prohibited != tc.prohibited {
+			t.Errorf("%q: accepted %v, prohibited %v; want %v, %v", tc.heading, accepted, prohibited, tc.accepted, tc.prohibited)
+		}
+	}
+}
+
+func TestEventDescription(t *testing.T) {
+	skips := []SkipLocation{
+		{Address: "Unlisted Road"},
+		{Address: "Pountney Road", AcceptedItems: []string{"Furniture", "Garden waste"}, ProhibitedItems: []string{"Trade waste"}},
+	}
+
+	want := "https://wheremegaskip.com\n\nWhat you can bring: Furniture, Garden waste\nWhat you can't bring: Trade waste"
+	if got := eventDescription("https://wheremegaskip.com", skips); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := eventDescription("https://wheremegaskip.com", skips[:1]); got != "https://wheremegaskip.com" {
+		t.Errorf("Expected just the site URL without items, got %q", got)
+	}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// listedDay is a skip day as listed in the page, so the locations can be
+// read without JavaScript. The map and list replace it once they load.
+type listedDay struct {
+	Date  string
+	Skips []listedSkip
+}
+
+// listedSkip is a location in the page's list
+type listedSkip struct {
+	SkipLocation
+	// TypeLabel names anything other than a mega skip
+	TypeLabel string
+	// StatusLabel is "Cancelled" or "Postponed" if it won't go ahead
+	StatusLabel string
+	MapsURL     string
+	Nearest     bool
+}
+
+// googleMapsURL searches Google Maps for a location's address
+func googleMapsURL(l SkipLocation) string {
+	return "https://www.google.com/maps/search/?api=1&query=" + url.QueryEscape(l.Address+", "+l.Postcode+", London")
+}
+
+// statusLabel is how a location's Status reads in a page
+func statusLabel(status string) string {
+	switch status {
+	case StatusCancelled:
+		return "Cancelled"
+	case StatusPostponed:
+		return "Postponed"
+	}
+	return ""
+}
+
+// indexListing returns the upcoming skip days for the page's list, marking
+// the nearest skip if there is one, or none if the locations can't be got
+func indexListing(ctx context.Context, nearest *nearestSkip) []listedDay {
+	locations, err := getSkipLocations(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Rendering index without a skip list", "err", err)
+		return nil
+	}
+
+	var days []listedDay
+	for _, day := range upcomingSkipDays(locations, time.Now()) {
+		listed := listedDay{Date: formatSkipDate(day.Date)}
+		for _, l := range day.Skips {
+			skip := listedSkip{
+				SkipLocation: l,
+				MapsURL:      googleMapsURL(l),
+				Nearest:      nearest != nil && l.ID != "" && l.ID == nearest.Skip.ID,
+			}
+			if typ := l.eventType(); typ != EventMegaSkip {
+				skip.TypeLabel = typ.label()
+			}
+			skip.StatusLabel = statusLabel(l.Status)
+			listed.Skips = append(listed.Skips, skip)
+		}
+		days = append(days, listed)
+	}
+	return days
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoogleMapsURL(t *testing.T) {
+	got := googleMapsURL(SkipLocation{Address: "Pountney Road", Postcode: "SW11 5TU"})
+	want := "https://www.google.com/maps/search/?api=1&query=Pountney+Road%2C+SW11+5TU%2C+London"
+	if got != want {
+		t.Errorf("googleMapsURL = %q, want %q", got, want)
+	}
+}
+
+func TestIndexListsSkipsWithoutJavaScript(t *testing.T) {
+	first := startOfDay(time.Now()).AddDate(0, 0, 7)
+	second := first.AddDate(0, 0, 14)
+	useTestSkips(t, []SkipLocation{
+		{ID: "later", Address: "GARRATT LANE", Postcode: "SW18 4DJ", Latitude: 51.45, Longitude: -0.19, Date: second, Type: EventMegaSkip, Status: StatusPostponed},
+		{ID: "trees", Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612, Date: first, Type: EventChristmasTrees},
+		{ID: "past", Address: "Old Road", Postcode: "SW11 1AA", Latitude: 51.46, Longitude: -0.16, Date: first.AddDate(0, 0, -30), Type: EventMegaSkip},
+	})
+
+	rec := httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/", nil))
+	page := rec.Body.String()
+
+	if !strings.Contains(page, `<html lang="en" class="no-js">`) {
+		t.Error("Expected the page to start as no-js")
+	}
+	firstAt := strings.Index(page, `<div class="date-group-header">`+formatSkipDate(first)+`</div>`)
+	secondAt := strings.Index(page, `<div class="date-group-header">`+formatSkipDate(second)+`</div>`)
+	if firstAt == -1 || secondAt == -1 || secondAt < firstAt {
+		t.Errorf("Expected both upcoming days in date order, at %d and %d", firstAt, secondAt)
+	}
+	for _, want := range []string{
+		"<h4>📍 Garratt Lane</h4>",
+		`<p class="skip-status">⚠️ Postponed</p>`,
+		`<p class="event-type">♻️ ` + EventChristmasTrees.label() + `</p>`,
+		`href="https://www.google.com/maps/search/?api=1&amp;query=Pountney&#43;Road%2C&#43;SW11&#43;5TU%2C&#43;London"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected the page to contain %q", want)
+		}
+	}
+	if strings.Contains(page, "Old Road") {
+		t.Error("Expected past skips to be left out")
+	}
+	_, list, _ := strings.Cut(page, `<div id="skip-items">`)
+	list, _, _ = strings.Cut(list, `<div id="items-info"`)
+	if strings.Contains(list, "Loading...") {
+		t.Error("Expected the list instead of a loading placeholder")
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// liteTemplate is the /lite page: no map, scripts or external assets, for
+// slow connections and old devices
+var liteTemplate = mustParsePage("lite.html")
+
+// litePage is the data rendered into the lite template
+type litePage struct {
+	Nonce    string
+	Tenant   Tenant
+	Postcode string
+	// Error explains why ?postcode= couldn't be located
+	Error string
+	Days  []liteDay
+}
+
+// liteDay is an upcoming skip day on the lite page
+type liteDay struct {
+	Date  string
+	Skips []liteSkip
+}
+
+// liteSkip is a location on the lite page. Distance is set when a
+// ?postcode= was given and the skip is geocoded.
+type liteSkip struct {
+	Address  string
+	Postcode string
+	Distance string
+	Status   string
+
+	distanceKm float64
+}
+
+// HandleLite handles requests to /lite, listing the upcoming skip days, and
+// with ?postcode= each location's distance from it, nearest first
+func HandleLite(w http.ResponseWriter, r *http.Request) {
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	page := litePage{
+		Nonce:    cspNonce(r.Context()),
+		Tenant:   currentTenant(),
+		Postcode: strings.TrimSpace(r.URL.Query().Get("postcode")),
+	}
+
+	located := false
+	var lat, lng float64
+	if page.Postcode != "" {
+		var borough *Borough
+		if lat, lng, borough, err = locatePostcode(r.Context(), strings.ToUpper(page.Postcode)); err != nil {
+			page.Error = describeQueryError(err)
+		} else {
+			// Only the postcode's own council runs skips for it
+			locations = filterByBorough(locations, []string{borough.ID})
+			located = true
+		}
+	}
+
+	for _, day := range upcomingSkipDays(locations, time.Now()) {
+		lite := liteDay{Date: formatSkipDate(day.Date)}
+		for _, l := range day.Skips {
+			skip := liteSkip{Address: l.Address, Postcode: l.Postcode, Status: statusLabel(l.Status), distanceKm: -1}
+			if located && (l.Latitude != 0 || l.Longitude != 0) {
+				skip.distanceKm = haversineDistance(lat, lng, l.Latitude, l.Longitude)
+				skip.Distance = fmt.Sprintf("%.1f km", skip.distanceKm)
+			}
+			lite.Skips = append(lite.Skips, skip)
+		}
+		if located {
+			// Skips that aren't geocoded have no distance, and go last
+			sort.SliceStable(lite.Skips, func(i, j int) bool {
+				a, b := lite.Skips[i].distanceKm, lite.Skips[j].distanceKm
+				return a >= 0 && (b < 0 || a < b)
+			})
+		}
+		page.Days = append(page.Days, lite)
+	}
+
+	// The inline style carries this request's CSP nonce, so the page can't
+	// be shared from a cache
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	personalCaching.apply(w)
+	err = writeRendered(w, func(buf io.Writer) error {
+		return liteTemplate.Execute(buf, page)
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering lite page", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to render page")
+	}
+}
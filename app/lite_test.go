@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"html"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleLite(t *testing.T) {
+	date := startOfDay(time.Now()).AddDate(0, 0, 7)
+	useTestSkips(t, []SkipLocation{
+		{Address: "Far Road", Postcode: "SW18 4DJ", Latitude: 51.45, Longitude: -0.19, Date: date, Type: EventMegaSkip},
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612, Date: date, Type: EventMegaSkip, Status: StatusCancelled},
+	})
+
+	rec := httptest.NewRecorder()
+	HandleLite(rec, httptest.NewRequest("GET", "/lite", nil))
+	page := rec.Body.String()
+	if !strings.Contains(page, "<h2>"+formatSkipDate(date)+"</h2>") {
+		t.Errorf("Expected the skip day, got:\n%s", page)
+	}
+	if strings.Contains(page, " km)") {
+		t.Error("Expected no distances without a postcode")
+	}
+	if strings.Contains(page, "<script") || strings.Contains(page, "https://") {
+		t.Error("Expected no scripts or external assets")
+	}
+
+	rec = httptest.NewRecorder()
+	HandleLite(rec, httptest.NewRequest("GET", "/lite?postcode=SW11", nil))
+	page = rec.Body.String()
+	near := strings.Index(page, "<li>Pountney Road, SW11 5TU (")
+	far := strings.Index(page, "<li>Far Road, SW18 4DJ (")
+	if near == -1 || far == -1 || far < near {
+		t.Errorf("Expected distances, nearest first, got:\n%s", page)
+	}
+	if !strings.Contains(page, "km) - Cancelled</li>") {
+		t.Error("Expected the cancelled skip to be marked")
+	}
+
+	rec = httptest.NewRecorder()
+	HandleLite(rec, httptest.NewRequest("GET", "/lite?postcode=nowhere", nil))
+	if !strings.Contains(rec.Body.String(), html.EscapeString(describeQueryError(errInvalidPostcode))) {
+		t.Error("Expected an invalid postcode to be explained")
+	}
+}
+
+func TestHandleLiteStaysSmall(t *testing.T) {
+	// A busy skip day, listed with distances
+	date := startOfDay(time.Now()).AddDate(0, 0, 7)
+	var locations []SkipLocation
+	for i := range 60 {
+		locations = append(locations, SkipLocation{
+			Address: fmt.Sprintf("Longish Residential Road %d", i), Postcode: "SW18 4DJ",
+			Latitude: 51.45, Longitude: -0.19, Date: date.AddDate(0, 0, 7*(i%4)), Type: EventMegaSkip,
+		})
+	}
+	useTestSkips(t, locations)
+
+	rec := httptest.NewRecorder()
+	HandleLite(rec, httptest.NewRequest("GET", "/lite?postcode=SW11", nil))
+	if size := rec.Body.Len(); size > 10*1024 {
+		t.Errorf("Expected the page to stay under 10KB, got %d bytes", size)
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if got := clientIP(req); got != "192.0.2.1" {
+		t.Errorf("clientIP = %q, want the remote address", got)
+	}
+
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	if got := clientIP(req); got != "203.0.113.7" {
+		t.Errorf("clientIP = %q, want the first forwarded address", got)
+	}
+}
+
+func TestRequestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	handler := requestLogging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	line := buf.String()
+	for _, want := range []string{"method=GET", "path=/missing", "status=404", "duration=", "ip=192.0.2.1"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in log line %q", want, line)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// scrapeStats describes the last scrape run by this instance
+type scrapeStats struct {
+	At       time.Time
+	Duration time.Duration
+}
+
+// lastScrape is set after each successful scrape on this instance
+var lastScrape atomic.Pointer[scrapeStats]
+
+// Meta is the response served from /api/meta
+type Meta struct {
+	Source    string `json:"source"`
+	Locations int    `json:"locations"`
+	// LastScrape is when the data was last scraped, by any instance
+	LastScrape *time.Time `json:"lastScrape,omitempty"`
+	// CacheAgeSeconds is how long ago LastScrape was
+	CacheAgeSeconds *int64 `json:"cacheAgeSeconds,omitempty"`
+	// LastScrapeDurationMs is only known to the instance that scraped
+	LastScrapeDurationMs *int64 `json:"lastScrapeDurationMs,omitempty"`
+	// Stale is set when the data is past its TTL or is a fallback snapshot
+	Stale bool `json:"stale"`
+}
+
+// recordScrape notes a successful scrape that started at start
+func recordScrape(start time.Time) {
+	lastScrape.Store(&scrapeStats{At: time.Now(), Duration: time.Since(start)})
+}
+
+// buildMeta describes the data currently being served
+func buildMeta(ctx context.Context, locations []SkipLocation, now time.Time) Meta {
+	meta := Meta{
+		Source:    councilURL,
+		Locations: len(locations),
+	}
+
+	// The snapshot time is shared through the cache, so it is known even
+	// on instances that haven't scraped themselves
+	scrapedAt := snapshotTakenAt(ctx)
+	stats := lastScrape.Load()
+	if stats != nil {
+		if stats.At.After(scrapedAt) {
+			scrapedAt = stats.At
+		}
+		ms := stats.Duration.Milliseconds()
+		meta.LastScrapeDurationMs = &ms
+	}
+	if !scrapedAt.IsZero() {
+		scrapedAt = scrapedAt.UTC()
+		age := int64(now.Sub(scrapedAt).Seconds())
+		meta.LastScrape, meta.CacheAgeSeconds = &scrapedAt, &age
+	}
+
+	cacheMu.RLock()
+	fresh, err := activeCache.Get(ctx, cacheKey)
+	cacheMu.RUnlock()
+	_, snapshot := servingSnapshot()
+	meta.Stale = snapshot || (err == nil && fresh == nil)
+
+	return meta
+}
+
+// HandleMetaAPI handles requests to /api/meta, describing how fresh the
+// served data is for monitoring and "data as of" displays
+func HandleMetaAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(buildMeta(r.Context(), locations, time.Now()))
+}
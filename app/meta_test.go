@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBuildMeta(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() {
+		activeCache = previous
+		lastScrape.Store(nil)
+	}()
+
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	locations := []SkipLocation{{Address: "One Road"}, {Address: "Two Road"}}
+
+	meta := buildMeta(ctx, locations, now)
+	if meta.Locations != 2 || meta.Source != councilURL {
+		t.Errorf("Unexpected meta %+v", meta)
+	}
+	if meta.LastScrape != nil || !meta.Stale {
+		t.Errorf("Nothing cached yet, so no scrape time and stale: %+v", meta)
+	}
+
+	activeCache.Set(ctx, cacheKey, locations, time.Hour)
+	saveSnapshot(ctx, locations, now.Add(-10*time.Minute))
+	lastScrape.Store(&scrapeStats{At: now.Add(-10 * time.Minute), Duration: 1500 * time.Millisecond})
+
+	meta = buildMeta(ctx, locations, now)
+	if meta.Stale {
+		t.Error("Fresh data should not be stale")
+	}
+	if meta.CacheAgeSeconds == nil || *meta.CacheAgeSeconds != 600 {
+		t.Errorf("Expected a cache age of 600s, got %v", meta.CacheAgeSeconds)
+	}
+	if meta.LastScrapeDurationMs == nil || *meta.LastScrapeDurationMs != 1500 {
+		t.Errorf("Expected a scrape duration of 1500ms, got %v", meta.LastScrapeDurationMs)
+	}
+}
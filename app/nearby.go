@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// nearbyQuery is a point to measure skips from, given by ?lat=&lng= or
+// ?near=postcode, and an optional ?radius_km= to limit them to
+type nearbyQuery struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+	// Borough is the borough a ?near= postcode is in
+	Borough *Borough
+}
+
+// NearbySkip is a skip annotated with its distance from a nearbyQuery
+type NearbySkip struct {
+	SkipLocation
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+// errBadNearbyQuery is returned for malformed ?lat=, ?lng= and ?radius_km=
+var errBadNearbyQuery = errors.New("invalid nearby query")
+
+// parseNearbyQuery reads a nearbyQuery from query parameters, or returns
+// nil if none is asked for. Postcodes are located with locatePostcode, so
+// fail with its errors.
+func parseNearbyQuery(ctx context.Context, query url.Values) (*nearbyQuery, error) {
+	lat, lng, near, radius := query.Get("lat"), query.Get("lng"), query.Get("near"), query.Get("radius_km")
+
+	var q nearbyQuery
+	switch {
+	case lat != "" || lng != "":
+		var errLat, errLng error
+		q.Latitude, errLat = strconv.ParseFloat(lat, 64)
+		q.Longitude, errLng = strconv.ParseFloat(lng, 64)
+		if errLat != nil || errLng != nil || !isFinite(q.Latitude) || !isFinite(q.Longitude) || q.Latitude < -90 || q.Latitude > 90 || q.Longitude < -180 || q.Longitude > 180 {
+			return nil, fmt.Errorf("%w: lat and lng must both be valid coordinates", errBadNearbyQuery)
+		}
+	case near != "":
+		var err error
+		if q.Latitude, q.Longitude, q.Borough, err = locatePostcode(ctx, near); err != nil {
+			return nil, err
+		}
+	case radius != "":
+		return nil, fmt.Errorf("%w: radius_km needs lat and lng, or near", errBadNearbyQuery)
+	default:
+		return nil, nil
+	}
+
+	if radius != "" {
+		r, err := strconv.ParseFloat(radius, 64)
+		if err != nil || !isFinite(r) || r <= 0 {
+			return nil, fmt.Errorf("%w: radius_km must be a positive number", errBadNearbyQuery)
+		}
+		q.RadiusKm = r
+	}
+	return &q, nil
+}
+
+// isFinite reports whether f is neither NaN nor infinite, which
+// strconv.ParseFloat accepts and range checks let through
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// nearbySkips returns the geocoded locations within the query's radius,
+// nearest first
+func nearbySkips(locations []SkipLocation, q nearbyQuery) []NearbySkip {
+	nearby := []NearbySkip{}
+	for _, loc := range locations {
+		if !loc.hasCoordinates() {
+			continue
+		}
+		d := haversineDistance(q.Latitude, q.Longitude, loc.Latitude, loc.Longitude)
+		if q.RadiusKm > 0 && d > q.RadiusKm {
+			continue
+		}
+		nearby = append(nearby, NearbySkip{SkipLocation: loc, DistanceKm: d})
+	}
+
+	sort.SliceStable(nearby, func(i, j int) bool {
+		return nearby[i].DistanceKm < nearby[j].DistanceKm
+	})
+	return nearby
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseNearbyQuery(t *testing.T) {
+	ctx := context.Background()
+
+	q, err := parseNearbyQuery(ctx, url.Values{"lat": {"51.46"}, "lng": {"-0.16"}, "radius_km": {"2"}})
+	if err != nil || q == nil || q.Latitude != 51.46 || q.Longitude != -0.16 || q.RadiusKm != 2 {
+		t.Errorf("Got %+v, %v", q, err)
+	}
+
+	q, err = parseNearbyQuery(ctx, url.Values{"near": {"SW17"}})
+	if err != nil || q == nil || q.Latitude != outcodeCentroids["SW17"].Latitude || q.RadiusKm != 0 {
+		t.Errorf("Expected the SW17 centre with no radius, got %+v, %v", q, err)
+	}
+
+	if q, err := parseNearbyQuery(ctx, url.Values{"type": {"megaskip"}}); q != nil || err != nil {
+		t.Errorf("Expected no nearby query, got %+v, %v", q, err)
+	}
+
+	for _, bad := range []url.Values{
+		{"lat": {"51.46"}},
+		{"lat": {"north"}, "lng": {"-0.16"}},
+		{"lat": {"91"}, "lng": {"-0.16"}},
+		{"radius_km": {"2"}},
+		{"lat": {"51.46"}, "lng": {"-0.16"}, "radius_km": {"-1"}},
+		{"lat": {"NaN"}, "lng": {"-0.16"}},
+		{"lat": {"51.46"}, "lng": {"nan"}},
+		{"lat": {"+Inf"}, "lng": {"-0.16"}},
+		{"lat": {"51.46"}, "lng": {"-0.16"}, "radius_km": {"NaN"}},
+		{"lat": {"51.46"}, "lng": {"-0.16"}, "radius_km": {"Inf"}},
+	} {
+		if _, err := parseNearbyQuery(ctx, bad); !errors.Is(err, errBadNearbyQuery) {
+			t.Errorf("Expected errBadNearbyQuery for %v, got %v", bad, err)
+		}
+	}
+
+	if _, err := parseNearbyQuery(ctx, url.Values{"near": {"not a postcode"}}); !errors.Is(err, errInvalidPostcode) {
+		t.Errorf("Expected errInvalidPostcode, got %v", err)
+	}
+}
+
+func TestNearbySkips(t *testing.T) {
+	locations := []SkipLocation{
+		{Address: "Far Road", Latitude: 51.42, Longitude: -0.21},
+		{Address: "Near Road", Latitude: 51.4655, Longitude: -0.1612},
+		{Address: "Ungeocoded Road"},
+		{Address: "Middle Road", Latitude: 51.455, Longitude: -0.165},
+	}
+
+	got := nearbySkips(locations, nearbyQuery{Latitude: 51.4655, Longitude: -0.1612, RadiusKm: 2})
+	if len(got) != 2 || got[0].Address != "Near Road" || got[1].Address != "Middle Road" {
+		t.Fatalf("Expected Near Road then Middle Road, got %+v", got)
+	}
+	if got[0].DistanceKm != 0 || got[1].DistanceKm <= 0 {
+		t.Errorf("Expected distances to be annotated, got %v and %v", got[0].DistanceKm, got[1].DistanceKm)
+	}
+
+	if got := nearbySkips(locations, nearbyQuery{Latitude: 51.4655, Longitude: -0.1612}); len(got) != 3 || got[2].Address != "Far Road" {
+		t.Errorf("Expected every geocoded skip without a radius, got %+v", got)
+	}
+}
+
+func TestHandleSkipsAPINearby(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+	invalidateDerived()
+	defer invalidateDerived()
+
+	date := time.Date(2026, 2, 7, 0, 0, 0, 0, time.UTC)
+	activeCache.Set(context.Background(), cacheKey, []SkipLocation{
+		{Address: "Far Road", Date: date, Latitude: 51.42, Longitude: -0.21},
+		{Address: "Near Road", Date: date, Latitude: 51.4655, Longitude: -0.1612},
+	}, time.Hour)
+
+	w := httptest.NewRecorder()
+	HandleSkipsAPI(w, httptest.NewRequest("GET", "/api/skips?lat=51.466&lng=-0.161&radius_km=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Got %d: %s", w.Code, w.Body)
+	}
+	var got []NearbySkip
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Address != "Near Road" || got[0].DistanceKm > 1 {
+		t.Errorf("Expected just Near Road within 1km, got %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSkipsAPI(w, httptest.NewRequest("GET", "/api/skips?radius_km=1", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a radius without a point, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSkipsAPI(w, httptest.NewRequest("GET", "/api/skips?lat=NaN&lng=-0.161&radius_km=NaN", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for NaN coordinates, got %d", w.Code)
+	}
+}
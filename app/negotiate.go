@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// skipsFormat is a representation of /api/skips
+type skipsFormat struct {
+	name      string
+	mediaType string
+}
+
+var (
+	skipsFormatJSON    = skipsFormat{"json", "application/json"}
+	skipsFormatCSV     = skipsFormat{"csv", "text/csv"}
+	skipsFormatGeoJSON = skipsFormat{"geojson", geoJSONContentType}
+	skipsFormatICal    = skipsFormat{"ics", "text/calendar"}
+
+	// skipsFormats in order of preference when a client accepts several
+	// equally
+	skipsFormats = []skipsFormat{skipsFormatJSON, skipsFormatGeoJSON, skipsFormatCSV, skipsFormatICal}
+)
+
+// negotiateSkipsFormat picks the /api/skips format from ?format=, or else
+// the Accept header. JSON is served when neither asks for anything else,
+// including to clients whose Accept header matches no format, so existing
+// clients keep working.
+func negotiateSkipsFormat(r *http.Request) (skipsFormat, error) {
+	if name := strings.ToLower(r.URL.Query().Get("format")); name != "" {
+		if name == "ical" {
+			name = skipsFormatICal.name
+		}
+		for _, f := range skipsFormats {
+			if f.name == name {
+				return f, nil
+			}
+		}
+		return skipsFormat{}, fmt.Errorf("unknown format %q: use json, csv, geojson or ics", name)
+	}
+
+	best, bestQ := skipsFormatJSON, 0.0
+	for _, f := range skipsFormats {
+		if q := acceptQuality(r.Header.Get("Accept"), f.mediaType); q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	return best, nil
+}
+
+// acceptQuality returns the q-value an Accept header gives mediaType, from
+// its most specific matching range, or 0 if it isn't acceptable
+func acceptQuality(accept, mediaType string) float64 {
+	typ, _, _ := strings.Cut(mediaType, "/")
+
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		name, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		var s int
+		switch name {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity, q = s, 1
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+	}
+	return q
+}
+
+// writeSkipsFormat writes locations as CSV, GeoJSON or iCal for /api/skips
+func writeSkipsFormat(w http.ResponseWriter, r *http.Request, format skipsFormat, locations []SkipLocation) {
+	if format == skipsFormatICal {
+		writeCalendarResponse(w, r, dayEvents(locations))
+		return
+	}
+
+	w.Header().Set("Content-Type", format.mediaType)
+	if format == skipsFormatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	}
+	err := writeRendered(w, func(buf io.Writer) error {
+		if format == skipsFormatCSV {
+			return writeSkipsCSV(buf, locations)
+		}
+		return writeSkipsGeoJSON(buf, locations)
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error writing skips", "format", format.name, "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+// handleSkipsFormat answers /api/skips in a format other than JSON. Only
+// the ?type= and ?borough= filters apply; distances and paging are JSON
+// only.
+func handleSkipsFormat(w http.ResponseWriter, r *http.Request, format skipsFormat, types []EventType, boroughIDs []string) {
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	setSnapshotHeaders(w)
+	if format == skipsFormatICal {
+		calendarCaching.apply(w)
+	} else {
+		apiCaching.apply(w)
+	}
+	if version, err := currentDataset.get(r.Context()); err == nil {
+		if notModified(w, r, version.etagFor(r, format.name), version.modified) {
+			return
+		}
+	}
+	writeSkipsFormat(w, r, format, filterByBorough(filterByType(locations, types), boroughIDs))
+}
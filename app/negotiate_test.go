@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiateSkipsFormat(t *testing.T) {
+	tests := []struct {
+		target, accept string
+		want           skipsFormat
+	}{
+		{"/api/skips", "", skipsFormatJSON},
+		{"/api/skips", "*/*", skipsFormatJSON},
+		{"/api/skips", "text/html,application/xhtml+xml,*/*;q=0.8", skipsFormatJSON},
+		{"/api/skips", "text/plain", skipsFormatJSON},
+		{"/api/skips", "text/csv", skipsFormatCSV},
+		{"/api/skips", "application/json;q=0.5, application/geo+json", skipsFormatGeoJSON},
+		{"/api/skips", "text/calendar, */*;q=0.1", skipsFormatICal},
+		{"/api/skips", "text/*;q=0.9, text/calendar;q=0", skipsFormatCSV},
+		{"/api/skips?format=csv", "application/json", skipsFormatCSV},
+		{"/api/skips?format=ical", "", skipsFormatICal},
+		{"/api/skips?format=GeoJSON", "", skipsFormatGeoJSON},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		r.Header.Set("Accept", tt.accept)
+		got, err := negotiateSkipsFormat(r)
+		if err != nil || got != tt.want {
+			t.Errorf("%s with Accept %q = %v, %v; want %v", tt.target, tt.accept, got, err, tt.want)
+		}
+	}
+
+	if _, err := negotiateSkipsFormat(httptest.NewRequest(http.MethodGet, "/api/skips?format=xml", nil)); err == nil {
+		t.Error("Expected an unknown format to be refused")
+	}
+}
+
+func TestHandleSkipsAPIFormats(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	invalidateDerived()
+	defer func() {
+		activeCache = previous
+		invalidateDerived()
+	}()
+	activeCache.Set(context.Background(), cacheKey, []SkipLocation{{
+		ID: "2026-03-14-pountney-road-sw11-5tu", Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), DateStr: "Saturday 14 March", Type: EventMegaSkip,
+	}}, time.Hour)
+
+	serve := func(target, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		HandleSkipsAPI(w, r)
+		return w
+	}
+
+	tests := []struct {
+		target, accept, contentType, body string
+	}{
+		{"/api/skips", "", "application/json", `"address":"Pountney Road"`},
+		{"/api/skips", "text/csv", "text/csv; charset=utf-8", "Pountney Road,SW11 5TU"},
+		{"/api/skips", "application/geo+json", geoJSONContentType, `"type":"FeatureCollection"`},
+		{"/api/skips?format=ics", "application/json", "text/calendar; charset=utf-8", "BEGIN:VCALENDAR"},
+	}
+	etags := map[string]bool{}
+	for _, tt := range tests {
+		w := serve(tt.target, tt.accept)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s (%s): status %d", tt.target, tt.accept, w.Code)
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s (%s): content type %q, want %q", tt.target, tt.accept, ct, tt.contentType)
+		}
+		if !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s (%s): expected %q in %s", tt.target, tt.accept, tt.body, w.Body.String())
+		}
+		if !strings.Contains(strings.Join(w.Header().Values("Vary"), ","), "Accept") {
+			t.Errorf("%s (%s): expected Vary: Accept", tt.target, tt.accept)
+		}
+		etags[w.Header().Get("ETag")] = true
+	}
+	if len(etags) != len(tests) {
+		t.Errorf("Expected each format to have its own ETag, got %v", etags)
+	}
+
+	// A format negotiated by Accept is validated on its own ETag
+	w := serve("/api/skips", "text/csv")
+	r := httptest.NewRequest(http.MethodGet, "/api/skips", nil)
+	r.Header.Set("Accept", "text/csv")
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	HandleSkipsAPI(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected a 304 for a matching CSV ETag, got %d", w.Code)
+	}
+
+	if w := serve("/api/skips?format=xml", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown format to be a 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minorWords stay lower case in a title-cased address unless they start it
+var minorWords = map[string]bool{"and": true, "at": true, "in": true, "of": true, "on": true, "the": true}
+
+// titleCase capitalises each word of an address, so "PUTNEY HEATH" and
+// "putney heath" are both "Putney Heath". Words with digits, such as flat
+// numbers like "12A", are upper-cased.
+func titleCase(address string) string {
+	words := strings.Fields(address)
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		switch {
+		case strings.ContainsFunc(word, unicode.IsDigit):
+			words[i] = strings.ToUpper(word)
+		case i > 0 && minorWords[lower]:
+			words[i] = lower
+		default:
+			words[i] = capitalise(lower)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// capitalise upper-cases the first letter of word and of each part after a
+// hyphen or bracket, so "(rear)" is "(Rear)" and "ship-lane" is "Ship-Lane"
+func capitalise(word string) string {
+	runes := []rune(word)
+	start := true
+	for i, r := range runes {
+		if start && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+		}
+		start = r == '-' || r == '(' || r == '['
+	}
+	return string(runes)
+}
+
+// canonicalPostcode upper-cases a postcode with a single space before the
+// inward code, so "sw115tu" is "SW11 5TU". Outcodes are upper-cased.
+func canonicalPostcode(postcode string) string {
+	compact := normalisePostcode(postcode)
+	if len(compact) > 3 && postcodePattern.MatchString(compact) {
+		return compact[:len(compact)-3] + " " + compact[len(compact)-3:]
+	}
+	return strings.Join(strings.Fields(strings.ToUpper(postcode)), " ")
+}
+
+// normaliseLocations title-cases addresses, collapses whitespace and
+// canonicalises postcodes, then drops locations listed twice for the same
+// date and type, which would otherwise give duplicate pins and events
+func normaliseLocations(locations []SkipLocation) []SkipLocation {
+	type key struct {
+		address, postcode string
+		date              int64
+		typ               EventType
+	}
+
+	seen := make(map[key]bool)
+	normalised := make([]SkipLocation, 0, len(locations))
+	for _, loc := range locations {
+		loc.Address = titleCase(loc.Address)
+		loc.Postcode = canonicalPostcode(loc.Postcode)
+		loc.DateStr = strings.Join(strings.Fields(loc.DateStr), " ")
+
+		k := key{strings.ToLower(loc.Address), loc.Postcode, loc.Date.Unix(), loc.eventType()}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		normalised = append(normalised, loc)
+	}
+	return normalised
+}
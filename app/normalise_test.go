@@ -0,0 +1,58 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTitleCase(t *testing.T) {
+	tests := map[string]string{
+		"PUTNEY HEATH":              "Putney Heath",
+		"pountney  road":            "Pountney Road",
+		"Garden OF Remembrance":     "Garden of Remembrance",
+		"the alton estate":          "The Alton Estate",
+		"12a st john's hill":        "12A St John's Hill",
+		"car park (rear) ship-lane": "Car Park (Rear) Ship-Lane",
+		"Fitzhugh Estate car park":  "Fitzhugh Estate Car Park",
+	}
+
+	for input, want := range tests {
+		if got := titleCase(input); got != want {
+			t.Errorf("titleCase(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCanonicalPostcode(t *testing.T) {
+	tests := map[string]string{
+		"sw115tu":   "SW11 5TU",
+		"SW11  5TU": "SW11 5TU",
+		"sw18 1aa":  "SW18 1AA",
+		"sw17":      "SW17",
+		" SW1A2AA ": "SW1A 2AA",
+	}
+
+	for input, want := range tests {
+		if got := canonicalPostcode(input); got != want {
+			t.Errorf("canonicalPostcode(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestNormaliseLocations(t *testing.T) {
+	date := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	locations := []SkipLocation{
+		{Address: "POUNTNEY ROAD", Postcode: "sw115tu", Date: date, DateStr: "Saturday  31 January", Type: EventMegaSkip},
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: date, Type: EventMegaSkip},
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: date.AddDate(0, 0, 7), Type: EventMegaSkip},
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: date, Type: EventChristmasTrees},
+	}
+
+	got := normaliseLocations(locations)
+	if len(got) != 3 {
+		t.Fatalf("Expected the duplicate to be dropped, got %+v", got)
+	}
+	if got[0].Address != "Pountney Road" || got[0].Postcode != "SW11 5TU" || got[0].DateStr != "Saturday 31 January" {
+		t.Errorf("Expected a normalised location, got %+v", got[0])
+	}
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The Open Graph image size recommended for large link previews
+const (
+	ogImageWidth  = 1200
+	ogImageHeight = 630
+	ogImageMargin = 80
+)
+
+// Colour indexes in the Open Graph image palette
+const (
+	ogBackground uint8 = iota
+	ogText
+	ogAccent
+)
+
+// parseHexColor parses a "#rrggbb" colour, falling back to def
+func parseHexColor(s string, def color.RGBA) color.RGBA {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || len(hex) != 6 {
+		return def
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return def
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
+
+// ogTextScale is the largest scale up to max that fits text across the
+// image between the margins
+func ogTextScale(text string, max int) int {
+	scale := max
+	for scale > 1 && bitmapTextWidth(text, scale) > ogImageWidth-2*ogImageMargin {
+		scale--
+	}
+	return scale
+}
+
+// ogImageLines are the headline, the date and the detail line. Without an
+// upcoming skip day the image says so instead.
+func ogImageLines(day *skipDay) [3]string {
+	tenant := currentTenant()
+	if day == nil {
+		return [3]string{tenant.SiteTitle, "No dates announced yet", "Check back soon"}
+	}
+
+	places := "locations"
+	if len(day.Skips) == 1 {
+		places = "location"
+	}
+	return [3]string{
+		"Next " + tenant.EventTitle + ":",
+		formatSkipDate(day.Date),
+		fmt.Sprintf("%d %s", len(day.Skips), places),
+	}
+}
+
+// renderOGImage draws the link preview image for the next skip day, or
+// for none if day is nil
+func renderOGImage(w io.Writer, day *skipDay) error {
+	tenant := currentTenant()
+	palette := color.Palette{
+		parseHexColor(tenant.Colors.Primary, color.RGBA{0x00, 0x74, 0xa2, 0xff}),
+		color.White,
+		parseHexColor(tenant.Colors.Accent, color.RGBA{0xff, 0x70, 0x43, 0xff}),
+	}
+	img := image.NewPaletted(image.Rect(0, 0, ogImageWidth, ogImageHeight), palette)
+	fillRect(img, image.Rect(0, 0, ogImageWidth, 24), ogAccent)
+
+	lines := ogImageLines(day)
+	scales := [3]int{ogTextScale(lines[0], 7), ogTextScale(lines[1], 13), ogTextScale(lines[2], 7)}
+	gap := 40
+	height := 2 * gap
+	for _, scale := range scales {
+		height += glyphHeight * scale
+	}
+
+	y := (ogImageHeight - height) / 2
+	for i, line := range lines {
+		c := ogText
+		if i == 1 {
+			c = ogAccent
+		}
+		drawBitmapText(img, ogImageMargin, y, scales[i], line, c)
+		y += glyphHeight*scales[i] + gap
+	}
+
+	if u, err := url.Parse(tenant.SiteURL); err == nil && u.Host != "" {
+		drawBitmapText(img, ogImageMargin, ogImageHeight-ogImageMargin/2-glyphHeight*3, 3, u.Host, ogText)
+	}
+
+	return png.Encode(w, img)
+}
+
+// HandleOGImage handles requests to /og.png, the image shown when the
+// site is shared
+func HandleOGImage(w http.ResponseWriter, r *http.Request) {
+	var next *skipDay
+	day, err := queryNextSkipDay(r.Context(), time.Now())
+	switch {
+	case err == nil:
+		next = &day
+	case !errors.Is(err, errNoUpcomingSkips):
+		slog.ErrorContext(r.Context(), "Error getting next skip day", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	pageCaching.apply(w)
+	err = writeRendered(w, func(buf io.Writer) error {
+		return renderOGImage(buf, next)
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering Open Graph image", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to render image")
+	}
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOGImageLines(t *testing.T) {
+	day := &skipDay{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Skips: make([]SkipLocation, 8)}
+	lines := ogImageLines(day)
+	if lines != [3]string{"Next Wandsworth Mega Skip:", "Saturday 15 March", "8 locations"} {
+		t.Errorf("Unexpected lines %q", lines)
+	}
+	if lines := ogImageLines(nil); lines[1] != "No dates announced yet" {
+		t.Errorf("Unexpected lines without a skip day %q", lines)
+	}
+}
+
+func TestOGTextScaleFits(t *testing.T) {
+	for _, text := range []string{"Saturday 15 March", "Next Royal Borough of Kensington and Chelsea Bulky Waste Day:"} {
+		if width := bitmapTextWidth(text, ogTextScale(text, 13)); width > ogImageWidth-2*ogImageMargin {
+			t.Errorf("%q is %dpx wide, wider than the image", text, width)
+		}
+	}
+}
+
+func TestBitmapText(t *testing.T) {
+	if got := string(bitmapText("Sat — 15 Mar ✓")); got != "SAT - 15 MAR ?" {
+		t.Errorf("bitmapText() = %q", got)
+	}
+	if got := bitmapTextWidth("AB", 2); got != 22 {
+		t.Errorf("bitmapTextWidth() = %d, want 22", got)
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	def := color.RGBA{1, 2, 3, 255}
+	if got := parseHexColor("#0074A2", def); got != (color.RGBA{0x00, 0x74, 0xa2, 0xff}) {
+		t.Errorf("Unexpected colour %v", got)
+	}
+	for _, bad := range []string{"", "0074A2", "#fff", "#zzzzzz"} {
+		if got := parseHexColor(bad, def); got != def {
+			t.Errorf("parseHexColor(%q) = %v, want the default", bad, got)
+		}
+	}
+}
+
+func TestHandleOGImage(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{
+		Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: startOfDay(time.Now()).AddDate(0, 0, 7), Type: EventMegaSkip,
+	}})
+
+	rec := httptest.NewRecorder()
+	HandleOGImage(rec, httptest.NewRequest("GET", "/og.png", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Fatalf("Unexpected content type %q: %s", ct, rec.Body.String())
+	}
+	img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != ogImageWidth || b.Dy() != ogImageHeight {
+		t.Errorf("Unexpected size %v", b)
+	}
+
+	rec = httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(rec.Body.String(), `<meta property="og:image" content="https://wheremegaskip.com/og.png">`) {
+		t.Error("Expected the index page to reference the image")
+	}
+}
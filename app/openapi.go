@@ -0,0 +1,35 @@
+package app
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the OpenAPI 3 description of the JSON and calendar
+// endpoints. It is maintained by hand alongside the handlers; the tests
+// check every /api route is documented.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// apiDocsTemplate renders Swagger UI from unpkg against /api/openapi.json
+var apiDocsTemplate = mustParsePage("docs.html")
+
+// HandleOpenAPI handles requests to /api/openapi.json (OpenAPI document)
+func HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(openAPISpec)
+}
+
+// HandleAPIDocs handles requests to /api/docs (Swagger UI). Swagger UI isn't
+// vendored, so this page alone allows unpkg.
+func HandleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	nonce := cspNonce(r.Context())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", contentSecurityPolicy(nonce, true))
+	personalCaching.apply(w)
+	if err := apiDocsTemplate.Execute(w, nonce); err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPISpecDocumentsAPI(t *testing.T) {
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("Expected an OpenAPI 3 document, got %q", spec.OpenAPI)
+	}
+
+	for _, path := range []string{
+		"/api/skips", "/api/skips.geojson", "/api/skips/{id}", "/api/skips/{id}.ics", "/api/skips/nearest",
+		"/api/skips/geocodes", "/api/skips/stream", "/api/today", "/api/meta",
+		"/calendar.ics", "/calendar/{postcode}.ics", "/calendar/invite",
+	} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("Expected %s to be documented", path)
+		}
+	}
+}
+
+func TestOpenAPISpecRefsResolve(t *testing.T) {
+	var spec map[string]any
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := regexp.MustCompile(`"\$ref": "#/([^"]+)"`).FindAllStringSubmatch(string(openAPISpec), -1)
+	if len(refs) == 0 {
+		t.Fatal("Expected the spec to use component refs")
+	}
+	for _, ref := range refs {
+		var node any = spec
+		for _, part := range strings.Split(ref[1], "/") {
+			m, ok := node.(map[string]any)
+			if !ok {
+				node = nil
+				break
+			}
+			node = m[part]
+		}
+		if node == nil {
+			t.Errorf("Unresolved ref #/%s", ref[1])
+		}
+	}
+}
+
+func TestHandleOpenAPI(t *testing.T) {
+	w := httptest.NewRecorder()
+	HandleOpenAPI(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Unexpected response %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !json.Valid(w.Body.Bytes()) {
+		t.Error("Expected a JSON document")
+	}
+}
+
+func TestHandleAPIDocs(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/docs", nil)
+	r = r.WithContext(context.WithValue(r.Context(), nonceKey{}, "abc123"))
+	w := httptest.NewRecorder()
+	HandleAPIDocs(w, r)
+
+	body := w.Body.String()
+	if !strings.Contains(body, `nonce="abc123"`) {
+		t.Error("Expected the scripts to carry the CSP nonce")
+	}
+	if !strings.Contains(body, "/api/openapi.json") {
+		t.Error("Expected Swagger UI to load the spec")
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'self' 'nonce-abc123' https://unpkg.com") {
+		t.Errorf("Expected the CSP to allow Swagger UI from unpkg, got %q", csp)
+	}
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// openDataSchemaVersion is bumped whenever a field in the published schema
+// changes meaning or is removed. Adding fields does not require a bump.
+const openDataSchemaVersion = 1
+
+// OpenDataField documents a single field of the published dataset
+type OpenDataField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// OpenDataDownload describes a bulk download of the dataset
+type OpenDataDownload struct {
+	Format    string `json:"format"`
+	MediaType string `json:"mediaType"`
+	URL       string `json:"url"`
+}
+
+// OpenDataset is the response served from /opendata
+type OpenDataset struct {
+	Title         string             `json:"title"`
+	Description   string             `json:"description"`
+	Source        string             `json:"source"`
+	License       string             `json:"license"`
+	LicenseURL    string             `json:"licenseUrl"`
+	Attribution   string             `json:"attribution"`
+	SchemaVersion int                `json:"schemaVersion"`
+	Schema        []OpenDataField    `json:"schema"`
+	Downloads     []OpenDataDownload `json:"downloads"`
+	GeneratedAt   time.Time          `json:"generatedAt"`
+	// Stale is set when the council website could not be reached and the
+	// data is the last successful scrape, taken at SnapshotTakenAt
+	Stale           bool           `json:"stale,omitempty"`
+	SnapshotTakenAt *time.Time     `json:"snapshotTakenAt,omitempty"`
+	RecordCount     int            `json:"recordCount"`
+	Data            []SkipLocation `json:"data"`
+}
+
+// openDataSchema documents the fields of SkipLocation as published
+var openDataSchema = []OpenDataField{
+	{Name: "address", Type: "string", Description: "Location of the skip as given by the council"},
+	{Name: "postcode", Type: "string", Description: "UK postcode of the location, upper case"},
+	{Name: "date", Type: "string", Description: "Day the skip is available; RFC 3339 at midnight UTC in JSON, YYYY-MM-DD in CSV"},
+	{Name: "dateStr", Type: "string", Description: "Day the skip is available, as written by the council"},
+	{Name: "lat", Type: "number", Description: "Latitude (WGS84) of the postcode, 0 if it could not be geocoded"},
+	{Name: "lng", Type: "number", Description: "Longitude (WGS84) of the postcode, 0 if it could not be geocoded"},
+	{Name: "type", Type: "string", Description: "Kind of collection: megaskip, small-electricals, christmas-trees or pop-up-recycling"},
+}
+
+// openDataCSVHeader is the header row of the CSV download, in schema order
+var openDataCSVHeader = []string{"address", "postcode", "date", "dateStr", "lat", "lng", "type"}
+
+// HandleOpenData handles requests to /opendata (dataset with metadata)
+func HandleOpenData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	dataset := OpenDataset{
+		Title:         "Wandsworth Mega Skip Days",
+		Description:   "Upcoming Wandsworth Council mega skip locations and dates, with geocoded coordinates.",
+		Source:        councilURL,
+		License:       "Open Government Licence v3.0",
+		LicenseURL:    "https://www.nationalarchives.gov.uk/doc/open-government-licence/version/3/",
+		Attribution:   "Contains public sector information from Wandsworth Council licensed under the Open Government Licence v3.0. Geocoding © OpenStreetMap contributors.",
+		SchemaVersion: openDataSchemaVersion,
+		Schema:        openDataSchema,
+		Downloads: []OpenDataDownload{
+			{Format: "json", MediaType: "application/json", URL: "/api/skips"},
+			{Format: "csv", MediaType: "text/csv", URL: "/opendata/skips.csv"},
+			{Format: "ical", MediaType: "text/calendar", URL: "/calendar.ics"},
+		},
+		GeneratedAt: time.Now().UTC(),
+		RecordCount: len(locations),
+		Data:        locations,
+	}
+	if takenAt, ok := servingSnapshot(); ok {
+		dataset.Stale = true
+		if !takenAt.IsZero() {
+			dataset.SnapshotTakenAt = &takenAt
+		}
+	}
+
+	setSnapshotHeaders(w)
+
+	err = writeRendered(w, func(buf io.Writer) error {
+		return json.NewEncoder(buf).Encode(dataset)
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding JSON", "err", err)
+		w.Header().Set("Content-Type", "application/json")
+		writeProblem(w, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+// HandleOpenDataCSV handles requests to /opendata/skips.csv (bulk CSV download)
+func HandleOpenDataCSV(w http.ResponseWriter, r *http.Request) {
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	setSnapshotHeaders(w)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", currentTenant().FileSlug))
+	err = writeRendered(w, func(buf io.Writer) error {
+		return writeSkipsCSV(buf, locations)
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error writing CSV", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to generate CSV")
+	}
+}
+
+// writeSkipsCSV writes locations as CSV with a header row
+func writeSkipsCSV(w io.Writer, locations []SkipLocation) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(openDataCSVHeader); err != nil {
+		return err
+	}
+
+	for _, loc := range locations {
+		record := []string{
+			loc.Address,
+			loc.Postcode,
+			loc.Date.Format("2006-01-02"),
+			loc.DateStr,
+			fmt.Sprintf("%.6f", loc.Latitude),
+			fmt.Sprintf("%.6f", loc.Longitude),
+			string(loc.eventType()),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+)
+
+func TestWriteSkipsCSV(t *testing.T) {
+	skips := []SkipLocation{
+		{
+			Address:   "Pountney Road",
+			Postcode:  "SW11 5TU",
+			Date:      time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+			DateStr:   "Saturday 15 March",
+			Latitude:  51.4645,
+			Longitude: -0.1587,
+		},
+		{
+			Address:  "Lindsay Court, Battersea High Street",
+			Postcode: "SW11 3HZ",
+			Date:     time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC),
+			DateStr:  "Saturday 22 March",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSkipsCSV(&buf, skips); err != nil {
+		t.Fatalf("writeSkipsCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV back: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records (header + 2 rows), got %d", len(records))
+	}
+
+	if len(records[0]) != len(openDataSchema) {
+		t.Errorf("Header has %d columns, schema documents %d fields", len(records[0]), len(openDataSchema))
+	}
+	for i, field := range openDataSchema {
+		if records[0][i] != field.Name {
+			t.Errorf("Header column %d = %q, want %q", i, records[0][i], field.Name)
+		}
+	}
+
+	want := []string{"Pountney Road", "SW11 5TU", "2025-03-15", "Saturday 15 March", "51.464500", "-0.158700"}
+	for i, v := range want {
+		if records[1][i] != v {
+			t.Errorf("Row 1 column %d = %q, want %q", i, records[1][i], v)
+		}
+	}
+
+	// Commas in addresses must survive the round trip
+	if records[2][0] != "Lindsay Court, Battersea High Street" {
+		t.Errorf("Row 2 address = %q, want comma preserved", records[2][0])
+	}
+}
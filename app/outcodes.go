@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// outcodePattern matches the outward half of a UK postcode, e.g. "SW17"
+var outcodePattern = regexp.MustCompile(`^[A-Za-z]{1,2}\d[A-Za-z\d]?$`)
+
+// outcodeCentroids are approximate centres of the outcodes in and around
+// Wandsworth, so outcode-only lookups don't need a geocoder round trip
+var outcodeCentroids = map[string]Coordinates{
+	"SW2":  {51.4496, -0.1194},
+	"SW4":  {51.4618, -0.1384},
+	"SW6":  {51.4743, -0.2003},
+	"SW8":  {51.4773, -0.1300},
+	"SW9":  {51.4693, -0.1115},
+	"SW10": {51.4833, -0.1817},
+	"SW11": {51.4650, -0.1650},
+	"SW12": {51.4452, -0.1479},
+	"SW13": {51.4737, -0.2445},
+	"SW14": {51.4641, -0.2651},
+	"SW15": {51.4583, -0.2261},
+	"SW16": {51.4218, -0.1288},
+	"SW17": {51.4290, -0.1667},
+	"SW18": {51.4526, -0.1923},
+	"SW19": {51.4214, -0.2064},
+	"SW20": {51.4089, -0.2274},
+}
+
+// isOutcode reports whether query is an outcode on its own
+func isOutcode(query string) bool {
+	return outcodePattern.MatchString(strings.TrimSpace(query))
+}
+
+// outcodeCentroid returns the centre of an outcode, from the bundled table
+// where possible and otherwise from the geocoder
+func outcodeCentroid(ctx context.Context, outcode string) (float64, float64, error) {
+	outcode = strings.ToUpper(strings.TrimSpace(outcode))
+	if c, ok := outcodeCentroids[outcode]; ok {
+		return c.Latitude, c.Longitude, nil
+	}
+	return geocodeUserPostcode(ctx, outcode)
+}
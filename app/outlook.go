@@ -0,0 +1,61 @@
+package app
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Outlook compose deeplink hosts, for personal and work or school accounts
+const (
+	outlookLiveHost   = "outlook.live.com"
+	outlookOfficeHost = "outlook.office.com"
+)
+
+// outlookDeeplink is an Outlook on the web link that opens a new event for
+// the nearest skip, ready to save
+func outlookDeeplink(host string, n nearestSkip) string {
+	opens, closes := n.Skip.hours()
+	query := url.Values{
+		"path":     {"/calendar/action/compose"},
+		"rru":      {"addevent"},
+		"subject":  {currentTenant().EventTitle},
+		"startdt":  {atClock(n.Date, opens).Format(time.RFC3339)},
+		"enddt":    {atClock(n.Date, closes).Format(time.RFC3339)},
+		"location": {skipEventLocation(&n.Skip)},
+		"body":     {eventDescription(currentTenant().SiteURL, []SkipLocation{n.Skip})},
+	}
+	u := url.URL{Scheme: "https", Host: host, Path: "/calendar/0/deeplink/compose", RawQuery: query.Encode()}
+	return u.String()
+}
+
+// HandleOutlookEvent handles requests to /outlook/{postcode}, redirecting
+// to Outlook to add the nearest skip on the next skip day. ?account=work
+// opens Office 365 rather than Outlook.com.
+func HandleOutlookEvent(w http.ResponseWriter, r *http.Request) {
+	postcode, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/outlook/"))
+	if err != nil {
+		writeTextProblem(w, r, http.StatusBadRequest, "Invalid postcode encoding")
+		return
+	}
+
+	host := outlookLiveHost
+	switch r.URL.Query().Get("account") {
+	case "", "personal":
+	case "work":
+		host = outlookOfficeHost
+	default:
+		writeTextProblem(w, r, http.StatusBadRequest, "account must be personal or work")
+		return
+	}
+
+	nearest, err := queryNearestSkip(r.Context(), postcode, time.Now())
+	if err != nil {
+		writeTextError(w, r, err)
+		return
+	}
+
+	pageCaching.apply(w)
+	http.Redirect(w, r, outlookDeeplink(host, nearest), http.StatusFound)
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestOutlookDeeplink(t *testing.T) {
+	link := outlookDeeplink(outlookLiveHost, nearestSkip{
+		Postcode: "SW18 1AA",
+		Date:     time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC),
+		Skip:     SkipLocation{Address: "Garratt Lane", Postcode: "SW18 4DJ", OpensAt: "09:00", ClosesAt: "13:00"},
+	})
+
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "outlook.live.com" || u.Path != "/calendar/0/deeplink/compose" {
+		t.Errorf("Unexpected deeplink %s", link)
+	}
+	query := u.Query()
+	for key, want := range map[string]string{
+		"rru":      "addevent",
+		"subject":  "Wandsworth Mega Skip",
+		"startdt":  "2025-06-14T09:00:00+01:00",
+		"enddt":    "2025-06-14T13:00:00+01:00",
+		"location": "Garratt Lane, SW18 4DJ, London, UK",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestHandleOutlookEventRejectsUnknownAccount(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HandleOutlookEvent(rec, httptest.NewRequest(http.MethodGet, "/outlook/SW18%201AA?account=hotmail", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown account, got %d", rec.Code)
+	}
+}
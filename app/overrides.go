@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// overridesPath is a JSON file of hand-curated corrections to the scraped
+// locations, set with OVERRIDES_PATH. It is re-read on every refresh, so
+// edits take effect on the next scrape or /admin/refresh.
+var overridesPath string
+
+// Override adds a location the council hasn't published, corrects one it
+// got wrong, or with Suppress set removes one that was cancelled. Any
+// scraped location of the same type on the same date at the same postcode
+// is replaced. A suppression with an address only removes locations with
+// that address, for postcodes with more than one skip.
+type Override struct {
+	Address   string    `json:"address"`
+	Postcode  string    `json:"postcode"`
+	Date      string    `json:"date"` // YYYY-MM-DD
+	Type      EventType `json:"type,omitempty"`
+	Borough   string    `json:"borough,omitempty"`
+	Latitude  float64   `json:"lat,omitempty"`
+	Longitude float64   `json:"lng,omitempty"`
+	Suppress  bool      `json:"suppress,omitempty"`
+
+	date time.Time
+}
+
+// loadOverrides reads and validates the overrides file
+func loadOverrides(path string) ([]Override, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides []Override
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, err
+	}
+
+	for i := range overrides {
+		o := &overrides[i]
+		if o.date, err = time.Parse("2006-01-02", o.Date); err != nil {
+			return nil, fmt.Errorf("override %d has an invalid date %q", i, o.Date)
+		}
+		o.Postcode = strings.ToUpper(strings.TrimSpace(o.Postcode))
+		if o.Postcode == "" {
+			return nil, fmt.Errorf("override %d has no postcode", i)
+		}
+		if !o.Suppress && o.Address == "" {
+			return nil, fmt.Errorf("override %d has no address", i)
+		}
+		if o.Type != "" {
+			if _, ok := eventTypeLabels[o.Type]; !ok {
+				return nil, fmt.Errorf("override %d has unknown event type %q", i, o.Type)
+			}
+		}
+	}
+	return overrides, nil
+}
+
+// eventType returns the override's type, defaulting to mega skips
+func (o Override) eventType() EventType {
+	if o.Type == "" {
+		return EventMegaSkip
+	}
+	return o.Type
+}
+
+// matches reports whether a scraped location is the one overridden
+func (o Override) matches(loc SkipLocation) bool {
+	if !startOfDay(loc.Date).Equal(o.date) || loc.eventType() != o.eventType() {
+		return false
+	}
+	if normalisePostcode(loc.Postcode) != normalisePostcode(o.Postcode) {
+		return false
+	}
+	return !o.Suppress || o.Address == "" || strings.EqualFold(strings.TrimSpace(loc.Address), strings.TrimSpace(o.Address))
+}
+
+// location returns the location an override adds
+func (o Override) location() SkipLocation {
+	return SkipLocation{
+		Address:   o.Address,
+		Postcode:  o.Postcode,
+		Date:      o.date,
+		DateStr:   o.date.Format("Monday 2 January"),
+		Latitude:  o.Latitude,
+		Longitude: o.Longitude,
+		Type:      o.eventType(),
+		Borough:   o.Borough,
+	}
+}
+
+// applyOverrides removes the scraped locations matched by any override and
+// adds every override that isn't a suppression
+func applyOverrides(locations []SkipLocation, overrides []Override) []SkipLocation {
+	if len(overrides) == 0 {
+		return locations
+	}
+
+	merged := make([]SkipLocation, 0, len(locations)+len(overrides))
+	for _, loc := range locations {
+		overridden := false
+		for _, o := range overrides {
+			if o.matches(loc) {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, loc)
+		}
+	}
+
+	for _, o := range overrides {
+		if !o.Suppress {
+			merged = append(merged, o.location())
+		}
+	}
+	return merged
+}
+
+// overrideLocations applies the overrides file to scraped locations. A
+// missing or invalid file is logged and the locations are left as scraped.
+func overrideLocations(ctx context.Context, locations []SkipLocation) []SkipLocation {
+	if overridesPath == "" {
+		return locations
+	}
+
+	overrides, err := loadOverrides(overridesPath)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load overrides, ignoring them", "path", overridesPath, "err", err)
+		return locations
+	}
+	slog.InfoContext(ctx, "Applying overrides", "overrides", len(overrides))
+	return applyOverrides(locations, overrides)
+}
+
+// normalisePostcode lets "sw11 5tu" and "SW115TU" match "SW11 5TU"
+func normalisePostcode(postcode string) string {
+	return strings.ReplaceAll(strings.ToUpper(postcode), " ", "")
+}
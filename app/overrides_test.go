@@ -0,0 +1,63 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	feb7 := time.Date(2026, 2, 7, 0, 0, 0, 0, time.UTC)
+	scraped := []SkipLocation{
+		{Address: "Pountney Raod", Postcode: "SW11 5TU", Date: feb7, Type: EventMegaSkip},
+		{Address: "Cancelled Street", Postcode: "SW18 1AA", Date: feb7, Type: EventMegaSkip},
+		{Address: "Cancelled Street", Postcode: "SW18 1AA", Date: feb7.AddDate(0, 0, 7), Type: EventMegaSkip},
+	}
+
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	err := os.WriteFile(path, []byte(`[
+		{"address": "Pountney Road", "postcode": "sw11 5tu", "date": "2026-02-07"},
+		{"postcode": "SW18 1AA", "date": "2026-02-07", "suppress": true},
+		{"address": "Announced On Twitter", "postcode": "SW17 0AA", "date": "2026-02-14", "type": "christmas-trees"}
+	]`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := loadOverrides(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := applyOverrides(scraped, overrides)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 locations, got %+v", got)
+	}
+	if got[0].Address != "Cancelled Street" || !got[0].Date.Equal(feb7.AddDate(0, 0, 7)) {
+		t.Errorf("Expected the unsuppressed date to remain, got %+v", got[0])
+	}
+	if got[1].Address != "Pountney Road" || got[1].Postcode != "SW11 5TU" || got[1].DateStr != "Saturday 7 February" {
+		t.Errorf("Expected the corrected location, got %+v", got[1])
+	}
+	if got[2].Address != "Announced On Twitter" || got[2].Type != EventChristmasTrees {
+		t.Errorf("Expected the added location, got %+v", got[2])
+	}
+}
+
+func TestLoadOverridesRejectsBadEntries(t *testing.T) {
+	tests := []string{
+		`[{"address": "A", "postcode": "SW11 5TU", "date": "7 February"}]`,
+		`[{"address": "A", "date": "2026-02-07"}]`,
+		`[{"postcode": "SW11 5TU", "date": "2026-02-07"}]`,
+		`[{"address": "A", "postcode": "SW11 5TU", "date": "2026-02-07", "type": "fridges"}]`,
+	}
+	for _, config := range tests {
+		path := filepath.Join(t.TempDir(), "overrides.json")
+		if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadOverrides(path); err == nil {
+			t.Errorf("Expected an error for %s", config)
+		}
+	}
+}
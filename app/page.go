@@ -0,0 +1,131 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Paging defaults for /api/skips
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// Orders /api/skips can be sorted in
+const (
+	sortDate     = "date"
+	sortDistance = "distance"
+	sortAddress  = "address"
+)
+
+// errBadPageQuery is returned for malformed ?limit=, ?offset= and ?sort=
+var errBadPageQuery = errors.New("invalid page query")
+
+// skipsPage is a page of /api/skips asked for with ?limit=, ?offset= or
+// ?sort=
+type skipsPage struct {
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// SkipsEnvelope is a page of /api/skips with the total number of matches
+type SkipsEnvelope struct {
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	// NextOffset is the offset of the next page, if there is one
+	NextOffset *int `json:"nextOffset,omitempty"`
+	Data       any  `json:"data"`
+}
+
+// parseSkipsPage reads a skipsPage from query parameters, or returns nil
+// if none is asked for, so plain requests keep getting a bare array.
+// Results are sorted by distance when near is set and by date otherwise.
+func parseSkipsPage(query url.Values, near *nearbyQuery) (*skipsPage, error) {
+	if !query.Has("limit") && !query.Has("offset") && !query.Has("sort") {
+		return nil, nil
+	}
+
+	page := skipsPage{Sort: sortDate, Limit: defaultPageLimit}
+	if near != nil {
+		page.Sort = sortDistance
+	}
+
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return nil, fmt.Errorf("%w: limit must be between 1 and %d", errBadPageQuery, maxPageLimit)
+		}
+		page.Limit = n
+	}
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: offset must be 0 or more", errBadPageQuery)
+		}
+		page.Offset = n
+	}
+
+	switch v := strings.ToLower(query.Get("sort")); v {
+	case "":
+	case sortDate, sortAddress:
+		page.Sort = v
+	case sortDistance:
+		if near == nil {
+			return nil, fmt.Errorf("%w: sort=distance needs lat and lng, or near", errBadPageQuery)
+		}
+		page.Sort = v
+	default:
+		return nil, fmt.Errorf("%w: sort must be date, distance or address", errBadPageQuery)
+	}
+	return &page, nil
+}
+
+// sortSkips orders skips for a page. Ties are broken by date, then
+// address, so pages are stable across requests.
+func sortSkips(skips []NearbySkip, by string) {
+	sort.SliceStable(skips, func(i, j int) bool {
+		a, b := skips[i], skips[j]
+		switch {
+		case by == sortDistance && a.DistanceKm != b.DistanceKm:
+			return a.DistanceKm < b.DistanceKm
+		case by == sortAddress && a.Address != b.Address:
+			return a.Address < b.Address
+		case !a.Date.Equal(b.Date):
+			return a.Date.Before(b.Date)
+		default:
+			return a.Address < b.Address
+		}
+	})
+}
+
+// envelope sorts skips and returns the page of them. Distances are only
+// included when they were measured.
+func (p skipsPage) envelope(skips []NearbySkip, withDistance bool) SkipsEnvelope {
+	sortSkips(skips, p.Sort)
+
+	env := SkipsEnvelope{Total: len(skips), Offset: p.Offset, Limit: p.Limit}
+	start := min(p.Offset, len(skips))
+	end := min(start+p.Limit, len(skips))
+	if end < len(skips) {
+		next := end
+		env.NextOffset = &next
+	}
+
+	page := skips[start:end]
+	if withDistance {
+		env.Data = page
+		return env
+	}
+	locations := make([]SkipLocation, len(page))
+	for i, s := range page {
+		locations[i] = s.SkipLocation
+	}
+	env.Data = locations
+	return env
+}
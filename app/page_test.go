@@ -0,0 +1,65 @@
+package app
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseSkipsPage(t *testing.T) {
+	if page, err := parseSkipsPage(url.Values{"type": {"megaskip"}}, nil); page != nil || err != nil {
+		t.Errorf("Expected no page for a plain request, got %+v, %v", page, err)
+	}
+
+	page, err := parseSkipsPage(url.Values{"limit": {"10"}, "offset": {"20"}}, nil)
+	if err != nil || *page != (skipsPage{Sort: sortDate, Limit: 10, Offset: 20}) {
+		t.Errorf("Got %+v, %v", page, err)
+	}
+
+	page, err = parseSkipsPage(url.Values{"offset": {"0"}}, &nearbyQuery{})
+	if err != nil || *page != (skipsPage{Sort: sortDistance, Limit: defaultPageLimit}) {
+		t.Errorf("Expected distance order by default near a point, got %+v, %v", page, err)
+	}
+
+	for _, bad := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"501"}},
+		{"offset": {"-1"}},
+		{"sort": {"postcode"}},
+		{"sort": {"distance"}},
+	} {
+		if _, err := parseSkipsPage(bad, nil); !errors.Is(err, errBadPageQuery) {
+			t.Errorf("Expected errBadPageQuery for %v, got %v", bad, err)
+		}
+	}
+}
+
+func TestSkipsPageEnvelope(t *testing.T) {
+	feb7 := time.Date(2026, 2, 7, 0, 0, 0, 0, time.UTC)
+	skips := []NearbySkip{
+		{SkipLocation: SkipLocation{Address: "Larch Close", Date: feb7.AddDate(0, 0, 7)}, DistanceKm: 1},
+		{SkipLocation: SkipLocation{Address: "Pountney Road", Date: feb7}, DistanceKm: 3},
+		{SkipLocation: SkipLocation{Address: "Alton Estate", Date: feb7}, DistanceKm: 2},
+	}
+
+	env := skipsPage{Sort: sortDate, Limit: 2}.envelope(skips, false)
+	data := env.Data.([]SkipLocation)
+	if env.Total != 3 || len(data) != 2 || data[0].Address != "Alton Estate" || data[1].Address != "Pountney Road" {
+		t.Errorf("Expected the first two by date, got %+v", env)
+	}
+	if env.NextOffset == nil || *env.NextOffset != 2 {
+		t.Errorf("Expected a next offset of 2, got %v", env.NextOffset)
+	}
+
+	env = skipsPage{Sort: sortDistance, Limit: 2, Offset: 2}.envelope(skips, true)
+	nearby := env.Data.([]NearbySkip)
+	if len(nearby) != 1 || nearby[0].Address != "Pountney Road" || env.NextOffset != nil {
+		t.Errorf("Expected the last page by distance, got %+v", env)
+	}
+
+	env = skipsPage{Sort: sortAddress, Limit: 10, Offset: 10}.envelope(skips, false)
+	if len(env.Data.([]SkipLocation)) != 0 || env.Total != 3 {
+		t.Errorf("Expected an empty page past the end, got %+v", env)
+	}
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// skipsPayload is a pre-rendered response, such as /api/skips or the
+// default calendar feed
+type skipsPayload struct {
+	body     []byte
+	gzipBody []byte
+	etag     string
+}
+
+// apiPayload is the /api/skips response, marshalled once per refresh
+var apiPayload = newDerived(newSkipsPayload)
+
+// newSkipsPayload marshals locations and precomputes the gzip variant and ETag
+func newSkipsPayload(locations []SkipLocation) (*skipsPayload, error) {
+	return renderSkipsPayload(locations)
+}
+
+// renderSkipsPayload marshals v, a list of locations, as a skipsPayload
+func renderSkipsPayload(v any) (*skipsPayload, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling locations: %w", err)
+	}
+	return newPayload(append(body, '\n'))
+}
+
+// newPayload precomputes the gzip variant and ETag of a rendered body
+func newPayload(body []byte) (*skipsPayload, error) {
+	gzipBody, err := gzipBytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("compressing payload: %w", err)
+	}
+
+	hash := sha256.Sum256(body)
+
+	return &skipsPayload{
+		body:     body,
+		gzipBody: gzipBody,
+		etag:     fmt.Sprintf("\"%x\"", hash[:8]),
+	}, nil
+}
+
+// skipsPayloadFor returns the cached payload, or renders one for a request
+// filtered by ?type= or ?borough=, sorted by distance from near, or paged
+func skipsPayloadFor(ctx context.Context, types []EventType, boroughIDs []string, near *nearbyQuery, page *skipsPage) (*skipsPayload, error) {
+	if len(types) == 0 && len(boroughIDs) == 0 && near == nil && page == nil {
+		return apiPayload.get(ctx)
+	}
+
+	locations, err := getSkipLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := filterByBorough(filterByType(locations, types), boroughIDs)
+	switch {
+	case page != nil && near != nil:
+		return renderSkipsPayload(page.envelope(nearbySkips(filtered, *near), true))
+	case page != nil:
+		skips := make([]NearbySkip, len(filtered))
+		for i, loc := range filtered {
+			skips[i] = NearbySkip{SkipLocation: loc}
+		}
+		return renderSkipsPayload(page.envelope(skips, false))
+	case near != nil:
+		return renderSkipsPayload(nearbySkips(filtered, *near))
+	}
+	if filtered == nil {
+		filtered = []SkipLocation{}
+	}
+	return newSkipsPayload(filtered)
+}
+
+// gzipBytes compresses data at the best compression level
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// acceptsGzip reports whether the client advertised gzip support
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		enc = strings.TrimSpace(enc)
+		name, params, _ := strings.Cut(enc, ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
+
+// writeTo writes the payload, compressed if the client supports it. The
+// caller sets the ETag, which for /api/skips is the payload's etag.
+func (p *skipsPayload) writeTo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if acceptsGzip(r) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(p.gzipBody)
+		return
+	}
+	w.Write(p.body)
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewSkipsPayload(t *testing.T) {
+	skips := []SkipLocation{
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	p, err := newSkipsPayload(skips)
+	if err != nil {
+		t.Fatalf("newSkipsPayload() error = %v", err)
+	}
+
+	var decoded []SkipLocation
+	if err := json.Unmarshal(p.body, &decoded); err != nil {
+		t.Fatalf("Body is not valid JSON: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].Address != "Pountney Road" {
+		t.Errorf("Unexpected decoded body: %+v", decoded)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(p.gzipBody))
+	if err != nil {
+		t.Fatalf("gzip body is not valid gzip: %v", err)
+	}
+	unzipped, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to decompress gzip body: %v", err)
+	}
+	if !bytes.Equal(unzipped, p.body) {
+		t.Error("gzip body should decompress to the plain body")
+	}
+
+	// Same data should produce the same ETag, different data a different one
+	same, _ := newSkipsPayload(skips)
+	if same.etag != p.etag {
+		t.Errorf("Same data should produce same ETag, got %s and %s", p.etag, same.etag)
+	}
+	skips[0].Postcode = "SW11 5TX"
+	different, _ := newSkipsPayload(skips)
+	if different.etag == p.etag {
+		t.Error("Different data should produce different ETags")
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip", true},
+		{"gzip;q=0.5, br", true},
+		{"gzip;q=0", false},
+		{"br", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/skips", nil)
+		r.Header.Set("Accept-Encoding", tt.header)
+		if got := acceptsGzip(r); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Object identifiers used in a PKCS #7 signature (RFC 5652)
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSHA256        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAEncryption = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAWithSHA  = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+// DER tags, with the constructed bit where it applies
+const (
+	derOctetString = 0x04
+	derNull        = 0x05
+	derSequence    = 0x30
+	derSet         = 0x31
+	// derContext0 is [0] constructed, used both explicitly and implicitly
+	derContext0 = 0xa0
+)
+
+// derTLV encodes a DER tag, length and the concatenated contents
+func derTLV(tag byte, contents ...[]byte) []byte {
+	body := bytes.Join(contents, nil)
+	out := []byte{tag}
+	switch n := len(body); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n < 0x100:
+		out = append(out, 0x81, byte(n))
+	case n < 0x10000:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, body...)
+}
+
+// derSetOf encodes a DER SET OF, which sorts its elements by encoding
+func derSetOf(elements ...[]byte) []byte {
+	sorted := append([][]byte(nil), elements...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	return derTLV(derSet, sorted...)
+}
+
+// derMarshal encodes v with encoding/asn1, for the primitive types it
+// gets right
+func derMarshal(v any) []byte {
+	data, err := asn1.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("marshaling %T: %v", v, err))
+	}
+	return data
+}
+
+// derAlgorithm encodes an AlgorithmIdentifier, with NULL parameters where
+// the algorithm takes them
+func derAlgorithm(oid asn1.ObjectIdentifier) []byte {
+	if oid.Equal(oidECDSAWithSHA) {
+		return derTLV(derSequence, derMarshal(oid))
+	}
+	return derTLV(derSequence, derMarshal(oid), []byte{derNull, 0})
+}
+
+// signDetached returns a DER PKCS #7 signature of content, without the
+// content itself, made by cert's key and carrying cert and any
+// intermediates so the signature can be checked back to a root
+func signDetached(content []byte, cert *x509.Certificate, key crypto.Signer, intermediates []*x509.Certificate, now time.Time) ([]byte, error) {
+	var sigAlgorithm asn1.ObjectIdentifier
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		sigAlgorithm = oidRSAEncryption
+	case *ecdsa.PublicKey:
+		sigAlgorithm = oidECDSAWithSHA
+	default:
+		return nil, fmt.Errorf("unsupported signing key %T", key.Public())
+	}
+
+	digest := sha256.Sum256(content)
+	attributes := [][]byte{
+		derTLV(derSequence, derMarshal(oidContentType), derSetOf(derMarshal(oidData))),
+		derTLV(derSequence, derMarshal(oidSigningTime), derSetOf(derMarshal(now.UTC()))),
+		derTLV(derSequence, derMarshal(oidMessageDigest), derSetOf(derTLV(derOctetString, digest[:]))),
+	}
+
+	// The signature covers the attributes encoded as a SET, though they
+	// are sent with an implicit [0] tag
+	signedAttributes := derSetOf(attributes...)
+	attributesDigest := sha256.Sum256(signedAttributes)
+	signature, err := key.Sign(rand.Reader, attributesDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+
+	signerInfo := derTLV(derSequence,
+		derMarshal(1),
+		derTLV(derSequence, cert.RawIssuer, derMarshal(cert.SerialNumber)),
+		derAlgorithm(oidSHA256),
+		append([]byte{derContext0}, signedAttributes[1:]...),
+		derAlgorithm(sigAlgorithm),
+		derTLV(derOctetString, signature),
+	)
+
+	certs := [][]byte{cert.Raw}
+	for _, c := range intermediates {
+		certs = append(certs, c.Raw)
+	}
+
+	signedData := derTLV(derSequence,
+		derMarshal(1),
+		derSetOf(derAlgorithm(oidSHA256)),
+		derTLV(derSequence, derMarshal(oidData)),
+		derTLV(derContext0, certs...),
+		derSetOf(signerInfo),
+	)
+	return derTLV(derSequence, derMarshal(oidSignedData), derTLV(derContext0, signedData)), nil
+}
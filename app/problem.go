@@ -0,0 +1,43 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// problemContentType is the media type of RFC 7807 error responses
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Type is always about:blank,
+// so Title is the HTTP status text and Detail says what went wrong. The
+// request ID is an extension member, so it can be quoted back to us.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// writeProblem writes an application/problem+json error response,
+// replacing any Content-Type or validators the handler has already set
+func writeProblem(w http.ResponseWriter, status int, detail string) {
+	h := w.Header()
+	for _, name := range []string{"Content-Length", "Content-Encoding", "ETag", "Last-Modified"} {
+		h.Del(name)
+	}
+	h.Set("Content-Type", problemContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	// Errors must not be cached in place of the response at the CDN
+	h.Set("Cache-Control", "no-store")
+	h.Del("CDN-Cache-Control")
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		RequestID: h.Get(requestIDHeader),
+	})
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set(requestIDHeader, "req-1")
+	writeProblem(w, http.StatusUnprocessableEntity, "Postcode is outside the boroughs we cover")
+
+	if got := w.Header().Get("Content-Type"); got != problemContentType {
+		t.Errorf("Expected %s, got %q", problemContentType, got)
+	}
+
+	var p Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type:      "about:blank",
+		Title:     "Unprocessable Entity",
+		Status:    http.StatusUnprocessableEntity,
+		Detail:    "Postcode is outside the boroughs we cover",
+		RequestID: "req-1",
+	}
+	if w.Code != http.StatusUnprocessableEntity || p != want {
+		t.Errorf("Unexpected problem %d %+v", w.Code, p)
+	}
+}
+
+func TestHandlersWriteProblems(t *testing.T) {
+	for _, tc := range []struct {
+		handler http.HandlerFunc
+		method  string
+		path    string
+		status  int
+	}{
+		{HandleCalendarPostcode, http.MethodGet, "/calendar/SW11.ics?types=bogus", http.StatusBadRequest},
+		{HandleTodayAPI, http.MethodGet, "/api/today?type=bogus", http.StatusBadRequest},
+		{HandleSkipsAPI, http.MethodGet, "/api/skips?lat=nope&lng=0", http.StatusBadRequest},
+		{HandleCalendarInvite, http.MethodGet, "/calendar/invite", http.StatusMethodNotAllowed},
+		{HandleStatic, http.MethodGet, "/static/missing.js", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		tc.handler(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+		var p Problem
+		json.NewDecoder(w.Body).Decode(&p)
+		if w.Code != tc.status || w.Header().Get("Content-Type") != problemContentType || p.Status != tc.status || p.Detail == "" {
+			t.Errorf("%s: expected a %d problem, got %d %q %+v", tc.path, tc.status, w.Code, w.Header().Get("Content-Type"), p)
+		}
+	}
+}
@@ -0,0 +1,481 @@
+package app
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// QR codes are encoded in byte mode at error correction level M, which
+// survives some smudging while keeping the code small. Versions up to 10
+// hold 213 bytes, plenty for a calendar URL.
+
+// qrVersion is the Reed-Solomon block structure and alignment pattern
+// positions of a QR code version at level M
+type qrVersion struct {
+	ecPerBlock int
+	// groups are {blocks, data codewords per block}
+	groups [][2]int
+	align  []int
+}
+
+// qrVersions are versions 1 to 10, from ISO/IEC 18004 tables 9 and E.1
+var qrVersions = []qrVersion{
+	{10, [][2]int{{1, 16}}, nil},
+	{16, [][2]int{{1, 28}}, []int{6, 18}},
+	{26, [][2]int{{1, 44}}, []int{6, 22}},
+	{18, [][2]int{{2, 32}}, []int{6, 26}},
+	{24, [][2]int{{2, 43}}, []int{6, 30}},
+	{16, [][2]int{{4, 27}}, []int{6, 34}},
+	{18, [][2]int{{4, 31}}, []int{6, 22, 38}},
+	{22, [][2]int{{2, 38}, {2, 39}}, []int{6, 24, 42}},
+	{22, [][2]int{{3, 36}, {2, 37}}, []int{6, 26, 46}},
+	{26, [][2]int{{4, 43}, {1, 44}}, []int{6, 28, 50}},
+}
+
+// qrQuietZone is the light border required around a code, in modules
+const qrQuietZone = 4
+
+var errQRTooLong = errors.New("too much data for a QR code")
+
+// dataCodewords is how many data codewords the version holds
+func (v qrVersion) dataCodewords() int {
+	n := 0
+	for _, g := range v.groups {
+		n += g[0] * g[1]
+	}
+	return n
+}
+
+// qrCode is a square of modules, true for dark
+type qrCode struct {
+	size     int
+	modules  [][]bool
+	reserved [][]bool
+}
+
+// encodeQR encodes data in the smallest version that holds it
+func encodeQR(data []byte) (*qrCode, error) {
+	for i, v := range qrVersions {
+		version := i + 1
+		countBits := 8
+		if version >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) > 8*v.dataCodewords() {
+			continue
+		}
+
+		codewords := qrCodewords(v, qrDataCodewords(data, countBits, v.dataCodewords()))
+		qr := newQRCode(version)
+		qr.placeData(codewords)
+		qr.applyBestMask()
+		return qr, nil
+	}
+	return nil, errQRTooLong
+}
+
+// qrBits accumulates a bit stream, most significant bit first
+type qrBits []bool
+
+func (b *qrBits) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+// qrDataCodewords is the byte mode segment for data, terminated and padded
+// to capacity codewords
+func qrDataCodewords(data []byte, countBits, capacity int) []byte {
+	var bits qrBits
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits)
+	for _, c := range data {
+		bits.append(int(c), 8)
+	}
+	bits.append(0, min(4, capacity*8-len(bits)))
+	for len(bits)%8 != 0 {
+		bits = append(bits, false)
+	}
+
+	codewords := make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var c byte
+		for _, bit := range bits[i : i+8] {
+			c <<= 1
+			if bit {
+				c |= 1
+			}
+		}
+		codewords = append(codewords, c)
+	}
+	for pad := byte(0xec); len(codewords) < capacity; pad ^= 0xec ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+	return codewords
+}
+
+// qrCodewords splits data into blocks, adds error correction to each and
+// interleaves them in the order they are placed
+func qrCodewords(v qrVersion, data []byte) []byte {
+	var blocks, ecBlocks [][]byte
+	for _, g := range v.groups {
+		for range g[0] {
+			block := data[:g[1]]
+			data = data[g[1]:]
+			blocks = append(blocks, block)
+			ecBlocks = append(ecBlocks, reedSolomon(block, v.ecPerBlock))
+		}
+	}
+
+	var out []byte
+	longest := v.groups[len(v.groups)-1][1]
+	for i := range longest {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := range v.ecPerBlock {
+		for _, ec := range ecBlocks {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// gfExp and gfLog are exponent and logarithm tables for GF(256) with the
+// QR code polynomial x^8 + x^4 + x^3 + x^2 + 1
+var gfExp, gfLog = gfTables()
+
+func gfTables() (exp [512]byte, log [256]byte) {
+	x := 1
+	for i := range 255 {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// reedSolomon returns n error correction codewords for data
+func reedSolomon(data []byte, n int) []byte {
+	// The generator polynomial is the product of (x - a^i) for i < n,
+	// highest degree first
+	gen := []byte{1}
+	for i := range n {
+		next := make([]byte, len(gen)+1)
+		for j, c := range gen {
+			next[j] ^= c
+			next[j+1] ^= gfMul(c, gfExp[i])
+		}
+		gen = next
+	}
+
+	rem := make([]byte, n)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for k := range n {
+			rem[k] ^= gfMul(gen[k+1], factor)
+		}
+	}
+	return rem
+}
+
+// newQRCode draws the function patterns of a version, reserving the
+// modules they and the format and version information occupy
+func newQRCode(version int) *qrCode {
+	size := 17 + 4*version
+	qr := &qrCode{size: size, modules: make([][]bool, size), reserved: make([][]bool, size)}
+	for y := range size {
+		qr.modules[y] = make([]bool, size)
+		qr.reserved[y] = make([]bool, size)
+	}
+
+	for i := range size {
+		qr.setFunction(6, i, i%2 == 0)
+		qr.setFunction(i, 6, i%2 == 0)
+	}
+	for _, corner := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		qr.drawFinder(corner[0], corner[1])
+	}
+
+	align := qrVersions[version-1].align
+	for _, y := range align {
+		for _, x := range align {
+			// Skip the three that would overlap finder patterns
+			first, last := align[0], align[len(align)-1]
+			if (x == first && y == first) || (x == first && y == last) || (x == last && y == first) {
+				continue
+			}
+			qr.drawAlignment(x, y)
+		}
+	}
+
+	// Reserve the format information, drawn once a mask is chosen
+	qr.drawFormat(0)
+	if version >= 7 {
+		qr.drawVersion(version)
+	}
+	return qr
+}
+
+// setFunction sets a function pattern module, reserving it from data
+func (qr *qrCode) setFunction(x, y int, dark bool) {
+	qr.modules[y][x] = dark
+	qr.reserved[y][x] = true
+}
+
+// drawFinder draws a finder pattern and its separator centred on (cx, cy)
+func (qr *qrCode) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= qr.size || y >= qr.size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			qr.setFunction(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centred on (cx, cy)
+func (qr *qrCode) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			qr.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// qrFormatBits is the 15-bit format information for level M and mask,
+// BCH coded and masked
+func qrFormatBits(mask int) int {
+	// Level M is 00
+	data := mask
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormat draws both copies of the format information for mask, and
+// the dark module beside them
+func (qr *qrCode) drawFormat(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := range 6 {
+		qr.setFunction(8, i, bit(i))
+	}
+	qr.setFunction(8, 7, bit(6))
+	qr.setFunction(8, 8, bit(7))
+	qr.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		qr.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		qr.setFunction(qr.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		qr.setFunction(8, qr.size-15+i, bit(i))
+	}
+	qr.setFunction(8, qr.size-8, true)
+}
+
+// qrVersionBits is the 18-bit BCH coded version information
+func qrVersionBits(version int) int {
+	rem := version
+	for range 12 {
+		rem = rem<<1 ^ (rem>>11)*0x1f25
+	}
+	return version<<12 | rem
+}
+
+// drawVersion draws both copies of the version information, needed from
+// version 7
+func (qr *qrCode) drawVersion(version int) {
+	bits := qrVersionBits(version)
+	for i := range 18 {
+		dark := bits>>i&1 == 1
+		a, b := qr.size-11+i%3, i/3
+		qr.setFunction(a, b, dark)
+		qr.setFunction(b, a, dark)
+	}
+}
+
+// placeData fills the unreserved modules with codewords, in two-module
+// wide columns zigzagging up and down from the bottom right
+func (qr *qrCode) placeData(codewords []byte) {
+	i := 0
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		// The vertical timing pattern is skipped over
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := range qr.size {
+			y := vert
+			if upward {
+				y = qr.size - 1 - vert
+			}
+			for j := range 2 {
+				x := right - j
+				if qr.reserved[y][x] {
+					continue
+				}
+				// Modules left over after the last codeword stay light
+				if i < len(codewords)*8 {
+					qr.modules[y][x] = codewords[i/8]>>(7-i%8)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// qrMasks are the eight data mask patterns, by row y and column x
+var qrMasks = [8]func(x, y int) bool{
+	func(x, y int) bool { return (y+x)%2 == 0 },
+	func(x, y int) bool { return y%2 == 0 },
+	func(x, y int) bool { return x%3 == 0 },
+	func(x, y int) bool { return (y+x)%3 == 0 },
+	func(x, y int) bool { return (y/2+x/3)%2 == 0 },
+	func(x, y int) bool { return (y*x)%2+(y*x)%3 == 0 },
+	func(x, y int) bool { return ((y*x)%2+(y*x)%3)%2 == 0 },
+	func(x, y int) bool { return ((y+x)%2+(y*x)%3)%2 == 0 },
+}
+
+// applyMask flips the data modules selected by mask; applying it twice
+// undoes it
+func (qr *qrCode) applyMask(mask int) {
+	for y := range qr.size {
+		for x := range qr.size {
+			if !qr.reserved[y][x] && qrMasks[mask](x, y) {
+				qr.modules[y][x] = !qr.modules[y][x]
+			}
+		}
+	}
+}
+
+// applyBestMask applies the mask that scores the lowest penalty, making the
+// code easiest to scan
+func (qr *qrCode) applyBestMask() {
+	best, bestPenalty := 0, -1
+	for mask := range qrMasks {
+		qr.applyMask(mask)
+		qr.drawFormat(mask)
+		if p := qr.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		qr.applyMask(mask)
+	}
+	qr.applyMask(best)
+	qr.drawFormat(best)
+}
+
+// penalty scores the code by the four rules of ISO/IEC 18004 section
+// 7.8.3: runs of one colour, 2x2 blocks, finder-like patterns and an
+// imbalance of dark and light
+func (qr *qrCode) penalty() int {
+	p := 0
+	at := func(x, y int, transpose bool) bool {
+		if transpose {
+			return qr.modules[x][y]
+		}
+		return qr.modules[y][x]
+	}
+
+	for _, transpose := range []bool{false, true} {
+		for y := range qr.size {
+			run := 1
+			for x := 1; x < qr.size; x++ {
+				if at(x, y, transpose) == at(x-1, y, transpose) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					p += 3 + run - 5
+				}
+				run = 1
+			}
+			if run >= 5 {
+				p += 3 + run - 5
+			}
+
+			for x := 0; x+11 <= qr.size; x++ {
+				var pattern int
+				for k := range 11 {
+					pattern <<= 1
+					if at(x+k, y, transpose) {
+						pattern |= 1
+					}
+				}
+				if pattern == 0b10111010000 || pattern == 0b00001011101 {
+					p += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := range qr.size {
+		for x := range qr.size {
+			if qr.modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 {
+				c := qr.modules[y][x]
+				if c == qr.modules[y-1][x] && c == qr.modules[y][x-1] && c == qr.modules[y-1][x-1] {
+					p += 3
+				}
+			}
+		}
+	}
+	percent := dark * 100 / (qr.size * qr.size)
+	p += min(abs(percent/5*5-50), abs(percent/5*5+5-50)) / 5 * 10
+	return p
+}
+
+// writePNG draws the code with scale pixels per module, inside a quiet zone
+func (qr *qrCode) writePNG(w io.Writer, scale int) error {
+	side := (qr.size + 2*qrQuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := range qr.size {
+		for x := range qr.size {
+			if qr.modules[y][x] {
+				x0, y0 := (x+qrQuietZone)*scale, (y+qrQuietZone)*scale
+				fillRect(img, image.Rect(x0, y0, x0+scale, y0+scale), 1)
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at 1-M, from the worked example at thonky.com
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := reedSolomon(data, 10); !bytes.Equal(got, want) {
+		t.Errorf("reedSolomon() = %v, want %v", got, want)
+	}
+}
+
+func TestQRFormatAndVersionBits(t *testing.T) {
+	formats := map[int]int{0: 0b101010000010010, 1: 0b101000100100101, 5: 0b100000011001110, 7: 0b100101010100000}
+	for mask, want := range formats {
+		if got := qrFormatBits(mask); got != want {
+			t.Errorf("qrFormatBits(%d) = %015b, want %015b", mask, got, want)
+		}
+	}
+	versions := map[int]int{7: 0b000111110010010100, 10: 0b001010010011010011}
+	for version, want := range versions {
+		if got := qrVersionBits(version); got != want {
+			t.Errorf("qrVersionBits(%d) = %018b, want %018b", version, got, want)
+		}
+	}
+}
+
+// decodeQR reads back a code made by encodeQR, checking its error
+// correction along the way
+func decodeQR(t *testing.T, qr *qrCode) string {
+	t.Helper()
+
+	bit := func(x, y int) int {
+		if qr.modules[y][x] {
+			return 1
+		}
+		return 0
+	}
+	format := bit(8, 7)<<6 | bit(8, 8)<<7 | bit(7, 8)<<8
+	for i := range 6 {
+		format |= bit(8, i) << i
+	}
+	for i := 9; i < 15; i++ {
+		format |= bit(14-i, 8) << i
+	}
+	mask := -1
+	for m := range qrMasks {
+		if qrFormatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("Unreadable format information %015b", format)
+	}
+	qr.applyMask(mask)
+	defer qr.applyMask(mask)
+
+	var raw []byte
+	var current byte
+	n := 0
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range qr.size {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = qr.size - 1 - vert
+			}
+			for j := range 2 {
+				if qr.reserved[y][right-j] {
+					continue
+				}
+				current = current<<1 | byte(bit(right-j, y))
+				if n++; n%8 == 0 {
+					raw = append(raw, current)
+				}
+			}
+		}
+	}
+
+	v := qrVersions[(qr.size-17)/4-1]
+	var blocks [][]byte
+	for _, g := range v.groups {
+		for range g[0] {
+			blocks = append(blocks, make([]byte, 0, g[1]))
+		}
+	}
+	pos := 0
+	for i := range v.groups[len(v.groups)-1][1] {
+		for b := range blocks {
+			if i < cap(blocks[b]) {
+				blocks[b] = append(blocks[b], raw[pos])
+				pos++
+			}
+		}
+	}
+	var data []byte
+	for b, block := range blocks {
+		ec := make([]byte, v.ecPerBlock)
+		for i := range ec {
+			ec[i] = raw[pos+i*len(blocks)+b]
+		}
+		if want := reedSolomon(block, v.ecPerBlock); !bytes.Equal(ec, want) {
+			t.Errorf("Block %d has error correction %v, want %v", b, ec, want)
+		}
+		data = append(data, block...)
+	}
+
+	if data[0]>>4 != 0b0100 {
+		t.Fatalf("Expected byte mode, got %04b", data[0]>>4)
+	}
+	// The length is 8 bits, or 16 from version 10
+	length, start := int(data[0]&0x0f)<<4|int(data[1]>>4), 1
+	if qr.size >= 17+4*10 {
+		length, start = int(data[0]&0x0f)<<12|int(data[1])<<4|int(data[2]>>4), 2
+	}
+	out := make([]byte, length)
+	for i := range out {
+		out[i] = data[start+i]<<4 | data[start+1+i]>>4
+	}
+	return string(out)
+}
+
+func TestEncodeQR(t *testing.T) {
+	for _, text := range []string{
+		"webcal://wheremegaskip.com/calendar/SW11%205TU.ics",
+		"webcal://wheremegaskip.com/calendar/SW11%205TU.ics?types=megaskip%2Creuse",
+		strings.Repeat("x", 150),
+		strings.Repeat("y", 200),
+	} {
+		qr, err := encodeQR([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := decodeQR(t, qr); got != text {
+			t.Errorf("Decoded %q, want %q", got, text)
+		}
+	}
+
+	qr, _ := encodeQR([]byte("webcal://wheremegaskip.com/calendar/SW11%205TU.ics"))
+	if qr.size != 33 {
+		t.Errorf("Expected a 50 byte URL to need version 4, got size %d", qr.size)
+	}
+	if _, err := encodeQR(make([]byte, 300)); err == nil {
+		t.Error("Expected too much data to be refused")
+	}
+}
+
+func TestHandleCalendarQR(t *testing.T) {
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		HandleCalendarQR(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := serve("/qr/calendar/SW11+5TU.png?types=megaskip")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("Unexpected response %d: %s", w.Code, w.Body.String())
+	}
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Version 5 is 37 modules, plus the quiet zone
+	if side := img.Bounds().Dx(); side != (37+2*qrQuietZone)*qrModuleSize {
+		t.Errorf("Unexpected image size %d", side)
+	}
+
+	if got := webcalURL("SW11 5TU", nil); got != "webcal://wheremegaskip.com/calendar/SW11%205TU.ics" {
+		t.Errorf("webcalURL() = %q", got)
+	}
+
+	for _, target := range []string{"/qr/calendar/nope.png", "/qr/calendar/SW11.png?types=bogus"} {
+		if w := serve(target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected a 400, got %d", target, w.Code)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// postcodePattern is a basic UK postcode pattern
+var postcodePattern = regexp.MustCompile(`^[A-Za-z]{1,2}\d{1,2}[A-Za-z]?\s?\d[A-Za-z]{2}$`)
+
+var (
+	errInvalidPostcode  = errors.New("invalid postcode format")
+	errPostcodeNotFound = errors.New("could not find postcode location")
+	errNoUpcomingSkips  = errors.New("no upcoming skips")
+)
+
+// skipDay is every skip location on a single date
+type skipDay struct {
+	Date  time.Time
+	Skips []SkipLocation
+}
+
+// nearestSkip is the closest skip to a postcode on the next date that has one
+type nearestSkip struct {
+	Postcode   string       `json:"postcode"`
+	Date       time.Time    `json:"date"`
+	Skip       SkipLocation `json:"skip"`
+	DistanceKm float64      `json:"distanceKm"`
+	// Approximate is set when only an outcode was given, so the distance
+	// is from the centre of the outcode
+	Approximate bool `json:"approximate"`
+}
+
+// startOfDay returns midnight UTC on t's London date, matching how skip
+// dates are stored
+func startOfDay(t time.Time) time.Time {
+	t = t.In(londonTime)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// nextSkipDay returns the earliest date on or after now with a skip going
+// ahead
+func nextSkipDay(locations []SkipLocation, now time.Time) (skipDay, bool) {
+	today := startOfDay(now)
+
+	var next skipDay
+	found := false
+	for date, skips := range groupSkipsByDate(scheduledSkips(locations)) {
+		if date.Before(today) {
+			continue
+		}
+		if !found || date.Before(next.Date) {
+			next = skipDay{Date: date, Skips: skips}
+			found = true
+		}
+	}
+	return next, found
+}
+
+// queryNextSkipDay answers "when is the next skip day?"
+func queryNextSkipDay(ctx context.Context, now time.Time) (skipDay, error) {
+	locations, err := getSkipLocations(ctx)
+	if err != nil {
+		return skipDay{}, err
+	}
+
+	day, ok := nextSkipDay(filterByType(locations, []EventType{EventMegaSkip}), now)
+	if !ok {
+		return skipDay{}, errNoUpcomingSkips
+	}
+	return day, nil
+}
+
+// locatePostcode returns the coordinates of a full postcode, or the centre
+// of an outcode for users who'd rather not give their full postcode. It
+// fails with errUnsupportedBorough when the postcode isn't in a borough we
+// have data for, and otherwise returns the borough whose skips serve it.
+func locatePostcode(ctx context.Context, postcode string) (float64, float64, *Borough, error) {
+	postcode = strings.TrimSpace(postcode)
+
+	switch {
+	case postcodePattern.MatchString(postcode):
+		lat, lng, err := geocodeUserPostcode(ctx, postcode)
+		if err != nil {
+			return 0, 0, nil, errPostcodeNotFound
+		}
+		borough, ok := boroughAt(lat, lng)
+		if !ok {
+			return 0, 0, nil, errUnsupportedBorough
+		}
+		return lat, lng, borough, nil
+
+	case isOutcode(postcode):
+		// Outcodes straddle borough borders, so match them by name, then
+		// prefer the borough their centre falls in
+		borough, ok := boroughForOutcode(postcode)
+		if !ok {
+			return 0, 0, nil, errUnsupportedBorough
+		}
+		lat, lng, err := outcodeCentroid(ctx, postcode)
+		if err != nil {
+			return 0, 0, nil, errPostcodeNotFound
+		}
+		if inside, ok := boroughAt(lat, lng); ok {
+			borough = inside
+		}
+		return lat, lng, borough, nil
+
+	default:
+		return 0, 0, nil, errInvalidPostcode
+	}
+}
+
+// queryNearestSkip answers "where is the nearest skip to my postcode?",
+// looking at the first upcoming date with a geocoded skip
+func queryNearestSkip(ctx context.Context, postcode string, now time.Time) (nearestSkip, error) {
+	postcode = strings.ToUpper(strings.TrimSpace(postcode))
+	lat, lng, borough, err := locatePostcode(ctx, postcode)
+	if err != nil {
+		return nearestSkip{}, err
+	}
+
+	idx, err := skipIndexFor(ctx, borough)
+	if err != nil {
+		return nearestSkip{}, err
+	}
+
+	today := startOfDay(now)
+	for _, date := range idx.dates {
+		if date.Before(today) {
+			continue
+		}
+		if skip := idx.nearest(date, EventMegaSkip, lat, lng); skip != nil {
+			return nearestSkip{
+				Postcode:    postcode,
+				Date:        date,
+				Skip:        *skip,
+				DistanceKm:  haversineDistance(lat, lng, skip.Latitude, skip.Longitude),
+				Approximate: isOutcode(postcode),
+			}, nil
+		}
+	}
+	return nearestSkip{}, errNoUpcomingSkips
+}
+
+// formatSkipDate formats a skip date for reading aloud, e.g. "Saturday 18 October"
+func formatSkipDate(date time.Time) string {
+	return date.Format("Monday 2 January")
+}
+
+// describeNextSkipDay is a one-sentence answer for a next-skip query
+func describeNextSkipDay(day skipDay) string {
+	places := "locations"
+	if len(day.Skips) == 1 {
+		places = "location"
+	}
+	return fmt.Sprintf("The next %s day is %s, at %d %s.",
+		currentTenant().EventTitle, formatSkipDate(day.Date), len(day.Skips), places)
+}
+
+// describeNearestSkip is a one-sentence answer for a nearest-skip query
+func describeNearestSkip(n nearestSkip) string {
+	distance := fmt.Sprintf("%.1f km away", n.DistanceKm)
+	if n.Approximate {
+		distance = fmt.Sprintf("about %.1f km from the centre of %s", n.DistanceKm, n.Postcode)
+	}
+	return fmt.Sprintf("Your nearest %s on %s is at %s, %s, %s.",
+		currentTenant().EventTitle, formatSkipDate(n.Date), n.Skip.Address, n.Skip.Postcode, distance)
+}
+
+// describeQueryError is a one-sentence answer when a query can't be answered
+func describeQueryError(err error) string {
+	switch {
+	case errors.Is(err, errInvalidPostcode):
+		return "Sorry, that doesn't look like a UK postcode or postcode area."
+	case errors.Is(err, errPostcodeNotFound):
+		return "Sorry, I couldn't find that postcode."
+	case errors.Is(err, errUnsupportedBorough):
+		return unsupportedBoroughMessage()
+	case errors.Is(err, errNoUpcomingSkips):
+		return fmt.Sprintf("There are no upcoming %s days listed yet.", currentTenant().EventTitle)
+	default:
+		return "Sorry, I couldn't look up the skip days right now. Please try again later."
+	}
+}
+
+// queryErrorStatus is the HTTP status for a failed query
+func queryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errInvalidPostcode):
+		return http.StatusBadRequest
+	case errors.Is(err, errPostcodeNotFound), errors.Is(err, errNoUpcomingSkips):
+		return http.StatusNotFound
+	case errors.Is(err, errUnsupportedBorough):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// HandleNearestAPI handles requests to /api/skips/nearest?postcode=, which
+// accepts a full postcode or just an outcode such as SW17
+func HandleNearestAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	nearest, err := queryNearestSkip(r.Context(), r.URL.Query().Get("postcode"), time.Now())
+	if err != nil {
+		status := queryErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			slog.ErrorContext(r.Context(), "Error finding nearest skip", "err", err)
+		}
+		writeProblem(w, status, describeQueryError(err))
+		return
+	}
+
+	setSnapshotHeaders(w)
+	apiCaching.apply(w)
+	json.NewEncoder(w).Encode(nearest)
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextSkipDay(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 0, 0, 0, 0, time.UTC) }
+	locations := []SkipLocation{
+		{Address: "Past Road", Postcode: "SW18 1AA", Date: day(4)},
+		{Address: "Later Road", Postcode: "SW18 1AB", Date: day(25)},
+		{Address: "Next Road", Postcode: "SW18 1AC", Date: day(18)},
+		{Address: "Next Street", Postcode: "SW18 1AD", Date: day(18)},
+	}
+
+	next, ok := nextSkipDay(locations, time.Date(2026, time.October, 18, 15, 0, 0, 0, time.UTC))
+	if !ok {
+		t.Fatal("Expected an upcoming skip day")
+	}
+	if !next.Date.Equal(day(18)) || len(next.Skips) != 2 {
+		t.Errorf("Got %v with %d skips, want 18 October with 2", next.Date, len(next.Skips))
+	}
+
+	if _, ok := nextSkipDay(locations, day(26)); ok {
+		t.Error("Expected no upcoming skip day after the last date")
+	}
+}
+
+func TestDescribeAnswers(t *testing.T) {
+	date := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+	got := describeNextSkipDay(skipDay{Date: date, Skips: make([]SkipLocation, 3)})
+	if !strings.Contains(got, "Sunday 18 October") || !strings.Contains(got, "3 locations") {
+		t.Errorf("describeNextSkipDay = %q", got)
+	}
+
+	got = describeNearestSkip(nearestSkip{
+		Date:       date,
+		Skip:       SkipLocation{Address: "Garratt Lane", Postcode: "SW18 4AA"},
+		DistanceKm: 0.42,
+	})
+	if !strings.Contains(got, "Garratt Lane, SW18 4AA") || !strings.Contains(got, "0.4 km") {
+		t.Errorf("describeNearestSkip = %q", got)
+	}
+
+	if got := describeQueryError(errors.New("boom")); strings.Contains(got, "boom") {
+		t.Errorf("Internal errors should not be read out, got %q", got)
+	}
+}
+
+func TestDialogflowPostcodeParameter(t *testing.T) {
+	var req dialogflowRequest
+	if got := req.postcode(); got != "" {
+		t.Errorf("postcode() = %q with no parameters", got)
+	}
+
+	req.QueryResult.Parameters = map[string]any{"zip-code": "SW18 4AA"}
+	if got := req.postcode(); got != "SW18 4AA" {
+		t.Errorf("postcode() = %q, want the @sys.zip-code value", got)
+	}
+
+	req.QueryResult.Parameters["postcode"] = "SW17 0AA"
+	if got := req.postcode(); got != "SW17 0AA" {
+		t.Errorf("postcode() = %q, want the custom entity to win", got)
+	}
+}
+
+func TestLocatePostcodeOutcodes(t *testing.T) {
+	lat, lng, borough, err := locatePostcode(context.Background(), " sw17 ")
+	if err != nil {
+		t.Fatalf("locatePostcode(SW17): %v", err)
+	}
+	if want := outcodeCentroids["SW17"]; lat != want.Latitude || lng != want.Longitude {
+		t.Errorf("Got %v,%v, want the SW17 centroid", lat, lng)
+	}
+	if borough == nil || borough.ID != "wandsworth" {
+		t.Errorf("Got borough %v, want Wandsworth", borough)
+	}
+
+	for _, query := range []string{"", "SW", "SW17 0", "not a postcode"} {
+		if _, _, _, err := locatePostcode(context.Background(), query); !errors.Is(err, errInvalidPostcode) {
+			t.Errorf("locatePostcode(%q) error = %v, want errInvalidPostcode", query, err)
+		}
+	}
+}
+
+func TestDescribeApproximateNearestSkip(t *testing.T) {
+	got := describeNearestSkip(nearestSkip{
+		Postcode:    "SW17",
+		Date:        time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+		Skip:        SkipLocation{Address: "Garratt Lane", Postcode: "SW17 0AA"},
+		DistanceKm:  1.24,
+		Approximate: true,
+	})
+	if !strings.Contains(got, "about 1.2 km from the centre of SW17") {
+		t.Errorf("describeNearestSkip = %q", got)
+	}
+}
+
+func TestQueryNearestSkipStaysInBorough(t *testing.T) {
+	date := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	useTestSkips(t, []SkipLocation{
+		{Address: "Over the border", Borough: "wandsworth", Latitude: 51.4690, Longitude: -0.1120, Date: date},
+		{Address: "Further in", Borough: "lambeth", Latitude: 51.4500, Longitude: -0.1100, Date: date},
+	})
+
+	got, err := queryNearestSkip(context.Background(), "SW9", date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Skip.Address != "Further in" {
+		t.Errorf("Expected the nearest Lambeth skip for a Lambeth outcode, got %q", got.Skip.Address)
+	}
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepInterval is how often buckets that have refilled are
+// dropped, so one-off clients don't hold memory forever
+const rateLimitSweepInterval = time.Minute
+
+// tokenBucket holds the tokens left for one client as of last
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket per client IP: each request takes a token,
+// and tokens refill at rate per second up to burst. Limits are per
+// instance, so on Vercel each warm instance counts separately.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// apiLimiter limits requests to /api/*, the calendar feeds and postcode
+// lookups, which can cause scrapes and geocoding. A nil limiter allows everything.
+var apiLimiter = newRateLimiter(60, 30)
+
+// newRateLimiter returns a limiter allowing perMinute requests a minute per
+// client, in bursts of up to burst, or nil if perMinute is 0
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// configureRateLimit reads RATE_LIMIT_PER_MINUTE and RATE_LIMIT_BURST
+func configureRateLimit() {
+	perMinute, burst := 60, 30
+	if n, err := strconv.Atoi(os.Getenv("RATE_LIMIT_PER_MINUTE")); err == nil && n >= 0 {
+		perMinute = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("RATE_LIMIT_BURST")); err == nil && n > 0 {
+		burst = n
+	}
+	apiLimiter = newRateLimiter(perMinute, burst)
+}
+
+// allow takes a token for key, or reports how long until one is available
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops the buckets that would have refilled by now. The caller
+// holds l.mu.
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// rateLimited reports whether a request is rate limited: the API and
+// calendars, and pages that look up a postcode
+func rateLimited(r *http.Request) bool {
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/api/"), strings.HasPrefix(path, "/calendar/"), path == "/calendar.ics",
+		strings.HasPrefix(path, "/nearest/"), strings.HasPrefix(path, "/wallet/"), strings.HasPrefix(path, "/outlook/"),
+		strings.HasPrefix(path, "/voice/"):
+		return true
+	case path == "/lite":
+		return r.URL.Query().Get("postcode") != ""
+	case path == "/":
+		// Returning visitors are looked up from their remembered postcode
+		_, err := r.Cookie(postcodeCookie)
+		return r.URL.Query().Get("postcode") != "" || err == nil
+	}
+	return false
+}
+
+// rateLimit answers clients polling the API or calendars too often with a
+// 429 and a Retry-After. Requests with an API key are limited by the key;
+// others are told apart by clientIP, which trusts X-Forwarded-For as set
+// by Vercel's proxy.
+func rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !rateLimited(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limiter, client := apiLimiter, clientIP(r)
+		var key *apiKey
+		if sent := requestAPIKey(r); sent != "" {
+			var ok bool
+			if key, ok = apiKeys[sent]; !ok {
+				writeProblem(w, http.StatusUnauthorized, "Unknown API key")
+				return
+			}
+			if key.Disabled {
+				writeProblem(w, http.StatusForbidden, "This API key has been disabled")
+				return
+			}
+			limiter, client = key.limiter, key.Name
+		}
+
+		now := time.Now()
+		ok, wait := true, time.Duration(0)
+		if limiter != nil {
+			ok, wait = limiter.allow(client, now)
+		}
+		if key != nil {
+			recordAPIKeyUse(key, !ok, now)
+		}
+		if !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			writeProblem(w, http.StatusTooManyRequests, fmt.Sprintf("Too many requests, try again in %d seconds", seconds))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
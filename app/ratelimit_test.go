@@ -0,0 +1,94 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter := newRateLimiter(60, 2)
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	for i := range 2 {
+		if ok, _ := limiter.allow("203.0.113.7", now); !ok {
+			t.Fatalf("Request %d should be within the burst", i)
+		}
+	}
+	ok, wait := limiter.allow("203.0.113.7", now)
+	if ok || wait != time.Second {
+		t.Errorf("Expected to wait a second, got %v %v", ok, wait)
+	}
+	if ok, _ := limiter.allow("198.51.100.1", now); !ok {
+		t.Error("Other clients have their own bucket")
+	}
+	if ok, _ := limiter.allow("203.0.113.7", now.Add(time.Second)); !ok {
+		t.Error("Expected a token after a second")
+	}
+
+	// Refilled buckets are swept
+	limiter.allow("192.0.2.1", now.Add(time.Hour))
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected idle buckets to be dropped, have %d", len(limiter.buckets))
+	}
+
+	if newRateLimiter(0, 10) != nil {
+		t.Error("A rate of 0 should disable limiting")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	previous := apiLimiter
+	apiLimiter = newRateLimiter(1, 1)
+	defer func() { apiLimiter = previous }()
+
+	handler := rateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(path, forwardedFor string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("/api/skips", "203.0.113.7"); w.Code != http.StatusOK {
+		t.Fatalf("First request should pass, got %d", w.Code)
+	}
+	w := serve("/calendar/SW11.ics", "203.0.113.7, 10.0.0.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" || w.Header().Get("Content-Type") != problemContentType {
+		t.Errorf("Expected a 429 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+	if w := serve("/api/skips", "198.51.100.1"); w.Code != http.StatusOK {
+		t.Errorf("Other clients should pass, got %d", w.Code)
+	}
+	if w := serve("/", "203.0.113.7"); w.Code != http.StatusOK {
+		t.Errorf("The page isn't limited, got %d", w.Code)
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	for target, want := range map[string]bool{
+		"/api/skips":             true,
+		"/calendar/megaskip.ics": true,
+		"/nearest/SW11":          true,
+		"/wallet/SW11.pkpass":    true,
+		"/outlook/SW11":          true,
+		"/voice/dialogflow":      true,
+		"/?postcode=SW11":        true,
+		"/lite?postcode=SW11":    true,
+		"/":                      false,
+		"/lite":                  false,
+		"/static/app.js":         false,
+	} {
+		if got := rateLimited(httptest.NewRequest(http.MethodGet, target, nil)); got != want {
+			t.Errorf("rateLimited(%s) = %v, want %v", target, got, want)
+		}
+	}
+
+	remembered := httptest.NewRequest(http.MethodGet, "/", nil)
+	remembered.AddCookie(&http.Cookie{Name: postcodeCookie, Value: signCookie(postcodeCookie, "SW11")})
+	if !rateLimited(remembered) {
+		t.Error("Pages looking up a remembered postcode should be rate limited")
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// staleCacheKey holds a copy of the skip locations that outlives cacheKey by
+// maxStaleness, so expired data can be served while a refresh runs
+const staleCacheKey = "skip_locations_stale"
+
+// cacheVersionKey holds the hash of the locations under cacheKey and when
+// they expire, so they can be updated without extending their TTL, and
+// derived values on every instance can tell when they change
+const cacheVersionKey = "skip_locations_version"
+
+// cachedVersion is the value under cacheVersionKey
+type cachedVersion struct {
+	Hash    string    `json:"hash"`
+	Expires time.Time `json:"expires"`
+}
+
+var (
+	// maxStaleness is how long past its TTL data may still be served while
+	// it is refreshed in the background. Once exceeded, requests block on a
+	// scrape. Zero disables stale serving.
+	maxStaleness = 24 * time.Hour
+
+	backgroundRefreshing atomic.Bool
+
+	// refreshFlight makes concurrent cache misses share one scrape
+	refreshFlight flightGroup[[]SkipLocation]
+)
+
+// storeSkipLocations writes locations to the cache, along with the stale copy
+func storeSkipLocations(ctx context.Context, locations []SkipLocation) error {
+	ttl := skipLocationsTTL(locations, time.Now())
+	if err := setSkipLocations(ctx, locations, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+	if maxStaleness > 0 {
+		if err := activeCache.Set(ctx, staleCacheKey, locations, ttl+maxStaleness); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setSkipLocations caches locations until expires, along with their version
+func setSkipLocations(ctx context.Context, locations []SkipLocation, expires time.Time) error {
+	hash, err := hashLocations(locations)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(expires)
+	if err := activeCache.Set(ctx, cacheKey, locations, ttl); err != nil {
+		return err
+	}
+	return setCachedValue(ctx, cacheVersionKey, cachedVersion{Hash: hash, Expires: expires}, ttl)
+}
+
+// skipLocationsVersion returns the version of the cached locations, or the
+// zero version if there are none
+func skipLocationsVersion(ctx context.Context) cachedVersion {
+	var version cachedVersion
+	if _, err := getCachedValue(ctx, cacheVersionKey, &version); err != nil {
+		slog.WarnContext(ctx, "Cache get error", "err", err)
+	}
+	return version
+}
+
+// loadStaleSkipLocations returns expired locations that are still within
+// maxStaleness, or nil if there are none
+func loadStaleSkipLocations(ctx context.Context) []SkipLocation {
+	if maxStaleness <= 0 {
+		return nil
+	}
+
+	cacheMu.RLock()
+	locations, err := activeCache.Get(ctx, staleCacheKey)
+	cacheMu.RUnlock()
+
+	if err != nil {
+		slog.WarnContext(ctx, "Cache get error", "err", err)
+		return nil
+	}
+	return locations
+}
+
+// refreshSkipLocations scrapes the council website and caches the result.
+// Concurrent callers share a single scrape rather than each running one.
+func refreshSkipLocations(ctx context.Context) ([]SkipLocation, error) {
+	locations, err, shared := refreshFlight.Do(cacheKey, func() ([]SkipLocation, error) {
+		// Another caller may have refreshed just before this flight began
+		cacheMu.RLock()
+		locations, err := activeCache.Get(ctx, cacheKey)
+		cacheMu.RUnlock()
+		if err == nil && locations != nil {
+			return locations, nil
+		}
+
+		slog.InfoContext(ctx, "Fetching fresh data from council website")
+		start := time.Now()
+		refreshCtx, cancel := context.WithTimeout(ctx, refreshTimeout)
+		defer cancel()
+
+		locations, err = scrapeSources(refreshCtx)
+		if err != nil {
+			err = fmt.Errorf("scraping failed: %w", err)
+			scrapeAlerter.failed(ctx, err)
+			return nil, err
+		}
+		scrapeAlerter.succeeded(ctx, len(locations))
+		recordScrape(start)
+
+		cacheMu.Lock()
+		err = storeSkipLocations(ctx, locations)
+		cacheMu.Unlock()
+		if err != nil {
+			slog.WarnContext(ctx, "Cache set error", "err", err)
+		}
+
+		// The snapshot is the previous scrape, whichever instance made it
+		previous, _, hadPrevious := loadSnapshot(ctx)
+		saveSnapshot(ctx, locations, time.Now())
+		snapshotServed.Store(nil)
+		invalidateDerived()
+		if hadPrevious {
+			changes := diffSkips(previous, locations, time.Now())
+			recordSkipUpdates(locations, changes, time.Now())
+			skipChanges.publish(changes)
+			if err := queueWebhooks(ctx, changes, time.Now()); err != nil {
+				slog.WarnContext(ctx, "Failed to queue webhooks", "err", err)
+			}
+		}
+		// Deliver within the refresh rather than in the background, which
+		// Vercel freezes once the response is sent. Failures are retried by
+		// later refreshes and the webhooks cron.
+		if _, _, err := deliverQueuedWebhooks(ctx, time.Now()); err != nil {
+			slog.WarnContext(ctx, "Failed to deliver webhooks", "err", err)
+		}
+		return locations, nil
+	})
+	if shared {
+		slog.InfoContext(ctx, "Shared an in-flight refresh")
+	}
+	return locations, err
+}
+
+// startBackgroundRefresh refreshes the skip locations without blocking the
+// caller, who is being served stale data. At most one refresh runs at a time.
+func startBackgroundRefresh() {
+	if !backgroundRefreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	goSafe(context.Background(), "background refresh", func() {
+		defer backgroundRefreshing.Store(false)
+
+		if _, err := refreshSkipLocations(context.Background()); err != nil {
+			slog.Warn("Background refresh failed", "err", err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLoadSkipLocationsServesStaleData(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+
+	// Pretend a refresh is already running so the test doesn't scrape
+	backgroundRefreshing.Store(true)
+	defer backgroundRefreshing.Store(false)
+
+	ctx := context.Background()
+	stale := []SkipLocation{{Address: "Stale Road", Postcode: "SW18 1AA"}}
+	if err := activeCache.Set(ctx, staleCacheKey, stale, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadSkipLocations(context.Background())
+	if err != nil {
+		t.Fatalf("loadSkipLocations: %v", err)
+	}
+	if len(got) != 1 || got[0].Address != "Stale Road" {
+		t.Errorf("Expected the stale copy, got %+v", got)
+	}
+}
+
+func TestStoreSkipLocationsKeepsStaleCopy(t *testing.T) {
+	previous, previousStaleness := activeCache, maxStaleness
+	activeCache = NewMemoryCache()
+	defer func() { activeCache, maxStaleness = previous, previousStaleness }()
+
+	ctx := context.Background()
+	locations := []SkipLocation{{Address: "Fresh Road", Postcode: "SW18 1AA"}}
+
+	maxStaleness = time.Hour
+	if err := storeSkipLocations(ctx, locations); err != nil {
+		t.Fatal(err)
+	}
+	if stale := loadStaleSkipLocations(ctx); len(stale) != 1 {
+		t.Errorf("Expected a stale copy, got %+v", stale)
+	}
+
+	maxStaleness = 0
+	if stale := loadStaleSkipLocations(ctx); stale != nil {
+		t.Errorf("Stale data should not be served when disabled, got %+v", stale)
+	}
+}
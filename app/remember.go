@@ -0,0 +1,144 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// postcodeCookie remembers the postcode a visitor last searched for, so
+// the page can show their nearest skip straight away on their next visit
+const postcodeCookie = "postcode"
+
+// postcodeCookieMaxAge is how long a postcode is remembered for
+const postcodeCookieMaxAge = 365 * 24 * time.Hour
+
+// cookieKey signs cookies. Without COOKIE_SECRET it is random, so cookies
+// only verify on the instance that set them, until it restarts.
+var cookieKey = randomCookieKey()
+
+// randomCookieKey returns a fresh 256-bit key
+func randomCookieKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("generating cookie key: " + err.Error())
+	}
+	return key
+}
+
+// configureCookies reads the cookie signing key from COOKIE_SECRET
+func configureCookies() {
+	secret, err := getSecret("COOKIE_SECRET")
+	if err != nil {
+		slog.Warn("Failed to load COOKIE_SECRET", "err", err)
+		return
+	}
+	if secret == "" {
+		slog.Info("COOKIE_SECRET not set; remembered postcodes only last as long as this instance")
+		return
+	}
+	cookieKey = []byte(secret)
+}
+
+// signCookie appends an HMAC of name and value to value
+func signCookie(name, value string) string {
+	mac := hmac.New(sha256.New, cookieKey)
+	mac.Write([]byte(name + "=" + value))
+	return value + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyCookie returns the value of a signed cookie, if its HMAC matches
+func verifyCookie(name, signed string) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i == -1 {
+		return "", false
+	}
+	value := signed[:i]
+	if !hmac.Equal([]byte(signCookie(name, value)), []byte(signed)) {
+		return "", false
+	}
+	return value, true
+}
+
+// secureRequest reports whether the request came over HTTPS, directly or
+// through the CDN
+func secureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// rememberPostcode sets the signed postcode cookie. Postcodes are stored
+// without spaces, which cookie values can't hold.
+func rememberPostcode(w http.ResponseWriter, r *http.Request, postcode string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     postcodeCookie,
+		Value:    signCookie(postcodeCookie, normalisePostcode(postcode)),
+		Path:     "/",
+		MaxAge:   int(postcodeCookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   secureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// rememberedPostcode returns the postcode from a valid postcode cookie
+func rememberedPostcode(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(postcodeCookie)
+	if err != nil {
+		return "", false
+	}
+	postcode, ok := verifyCookie(postcodeCookie, cookie.Value)
+	if !ok || (!postcodePattern.MatchString(postcode) && !isOutcode(postcode)) {
+		return "", false
+	}
+	return canonicalPostcode(postcode), true
+}
+
+// forgetPostcode clears the postcode cookie
+func forgetPostcode(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     postcodeCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// HandleRememberPostcode handles POST /postcode, remembering the postcode
+// the page's search found a skip for
+func HandleRememberPostcode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	postcode := strings.TrimSpace(r.FormValue("postcode"))
+	if !postcodePattern.MatchString(postcode) && !isOutcode(postcode) {
+		writeProblem(w, http.StatusBadRequest, "Invalid postcode format")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	rememberPostcode(w, r, postcode)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleForgetPostcode handles POST /postcode/forget, the page's "forget my
+// postcode" button, and goes back to the page without it
+func HandleForgetPostcode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	forgetPostcode(w, r)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
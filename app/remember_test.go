@@ -0,0 +1,112 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignCookie(t *testing.T) {
+	signed := signCookie(postcodeCookie, "SW115TU")
+	if value, ok := verifyCookie(postcodeCookie, signed); !ok || value != "SW115TU" {
+		t.Errorf("verifyCookie(%q) = %q, %v", signed, value, ok)
+	}
+	for _, forged := range []string{
+		"SW184DJ" + strings.TrimPrefix(signed, "SW115TU"),
+		"SW115TU",
+		"SW115TU.bad",
+	} {
+		if _, ok := verifyCookie(postcodeCookie, forged); ok {
+			t.Errorf("Expected %q to be rejected", forged)
+		}
+	}
+	if _, ok := verifyCookie("other", signed); ok {
+		t.Error("Expected a value signed for another cookie to be rejected")
+	}
+}
+
+func TestIndexRemembersPostcode(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{
+		ID: "pountney-road", Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: startOfDay(time.Now()).AddDate(0, 0, 7), Type: EventMegaSkip,
+	}})
+
+	rec := httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/?postcode=SW11", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != postcodeCookie || !cookies[0].HttpOnly {
+		t.Fatalf("Expected an HttpOnly postcode cookie, got %v", cookies)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Expected a personal page not to be cached, got %q", cc)
+	}
+
+	// The next visit shows the nearest skip without asking again
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	HandleIndex(rec, r)
+	page := rec.Body.String()
+	if !strings.Contains(page, `data-skip-id="pountney-road"`) || !strings.Contains(page, `value="SW11"`) {
+		t.Error("Expected the remembered postcode's nearest skip")
+	}
+	if !strings.Contains(page, `<form id="forget-postcode" class="forget-postcode" method="post" action="/postcode/forget">`) {
+		t.Error("Expected a visible forget my postcode control")
+	}
+	if len(rec.Result().Cookies()) != 0 {
+		t.Error("Expected a remembered postcode not to be set again")
+	}
+
+	// A tampered cookie is ignored
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: postcodeCookie, Value: "SW18" + strings.TrimPrefix(cookies[0].Value, "SW11")})
+	rec = httptest.NewRecorder()
+	HandleIndex(rec, r)
+	if strings.Contains(rec.Body.String(), "skip-item nearest") {
+		t.Error("Expected a tampered cookie to be ignored")
+	}
+}
+
+func TestHandleRememberPostcode(t *testing.T) {
+	r := httptest.NewRequest("POST", "/postcode", strings.NewReader(url.Values{"postcode": {"sw11 5tu"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	HandleRememberPostcode(rec, r)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(rec.Result().Cookies()[0])
+	if postcode, ok := rememberedPostcode(r); !ok || postcode != "SW11 5TU" {
+		t.Errorf("rememberedPostcode = %q, %v", postcode, ok)
+	}
+
+	r = httptest.NewRequest("POST", "/postcode", strings.NewReader("postcode=nowhere"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	HandleRememberPostcode(rec, r)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid postcode, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	HandleRememberPostcode(rec, httptest.NewRequest("GET", "/postcode", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", rec.Code)
+	}
+}
+
+func TestHandleForgetPostcode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HandleForgetPostcode(rec, httptest.NewRequest("POST", "/postcode/forget", nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/" {
+		t.Errorf("Expected a redirect to the page, got %d to %q", rec.Code, rec.Header().Get("Location"))
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != postcodeCookie || cookies[0].MaxAge >= 0 {
+		t.Errorf("Expected the postcode cookie to be cleared, got %v", cookies)
+	}
+}
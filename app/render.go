@@ -0,0 +1,59 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// maxPooledBufferSize stops unusually large responses from pinning memory
+// in the pool
+const maxPooledBufferSize = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to the pool
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// writeRendered renders into a pooled buffer and writes it to w only once
+// rendering has succeeded. On failure nothing is written and representation
+// headers already set by the caller are dropped, so the caller can send a
+// clean error response. Only render errors are returned: once writing has
+// started the response can't be replaced, so write errors, usually a client
+// that went away, are just logged.
+func writeRendered(w http.ResponseWriter, render func(io.Writer) error) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := render(buf); err != nil {
+		for _, h := range []string{"Content-Type", "Content-Disposition", "Content-Encoding", "ETag", "Last-Modified"} {
+			w.Header().Del(h)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.Debug("Failed to write response", "err", err)
+	}
+	return nil
+}
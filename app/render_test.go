@@ -0,0 +1,68 @@
+package app
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteRendered(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "text/plain")
+
+	err := writeRendered(rec, func(w io.Writer) error {
+		_, err := io.WriteString(w, "hello")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("writeRendered() error = %v", err)
+	}
+
+	if rec.Body.String() != "hello" {
+		t.Errorf("Body = %q, want %q", rec.Body.String(), "hello")
+	}
+	if rec.Header().Get("Content-Length") != "5" {
+		t.Errorf("Content-Length = %q, want %q", rec.Header().Get("Content-Length"), "5")
+	}
+}
+
+func TestWriteRenderedFailure(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "text/calendar")
+	rec.Header().Set("Content-Disposition", "attachment")
+
+	err := writeRendered(rec, func(w io.Writer) error {
+		io.WriteString(w, "partial output")
+		return errors.New("render failed")
+	})
+	if err == nil {
+		t.Fatal("Expected render error to be returned")
+	}
+
+	if rec.Body.Len() != 0 {
+		t.Errorf("Nothing should be written on failure, got %q", rec.Body.String())
+	}
+	if rec.Header().Get("Content-Disposition") != "" {
+		t.Error("Content-Disposition should be dropped on failure")
+	}
+}
+
+// failingWriter is a response whose client has gone away
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestWriteRenderedWriteFailure(t *testing.T) {
+	w := failingWriter{httptest.NewRecorder()}
+
+	err := writeRendered(w, func(w io.Writer) error {
+		_, err := io.WriteString(w, "hello")
+		return err
+	})
+	if err != nil {
+		t.Errorf("Write errors shouldn't be reported as render errors, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDs(t *testing.T) {
+	var seen string
+	handler := requestIDs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestID(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if seen == "" || rec.Header().Get(requestIDHeader) != seen {
+		t.Errorf("Expected a generated ID in the context and response, got %q and %q", seen, rec.Header().Get(requestIDHeader))
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(requestIDHeader, "abc-123")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if seen != "abc-123" || rec.Header().Get(requestIDHeader) != "abc-123" {
+		t.Errorf("Expected the client's ID to be honored, got %q", seen)
+	}
+
+	req.Header.Set(requestIDHeader, "<script>")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if seen == "<script>" {
+		t.Error("Unsafe client IDs should be replaced")
+	}
+}
+
+func TestErrorResponsesIncludeRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set(requestIDHeader, "abc-123")
+	writeProblem(rec, http.StatusInternalServerError, "Failed")
+
+	var body Problem
+	json.NewDecoder(rec.Body).Decode(&body)
+	if rec.Code != http.StatusInternalServerError || body.Detail != "Failed" || body.RequestID != "abc-123" {
+		t.Errorf("Unexpected error response %d %+v", rec.Code, body)
+	}
+}
+
+func TestLogRecordsCarryRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(requestIDHandler{slog.NewTextHandler(&buf, nil)})
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc-123")
+	logger.InfoContext(ctx, "Scraping")
+	if !strings.Contains(buf.String(), "request_id=abc-123") {
+		t.Errorf("Expected the request ID in %q", buf.String())
+	}
+}
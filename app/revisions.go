@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// eventRevisionsKey caches the revision history of each calendar event, so
+// a changed or cancelled event gets a higher SEQUENCE and a new
+// LAST-MODIFIED, which tells subscribed calendars to update it. It needs a
+// shared cache (Redis) to stay consistent across instances and cold starts.
+const eventRevisionsKey = "event_revisions"
+
+// eventRevisionsTTL keeps the history of events for longer than the council
+// publishes ahead
+const eventRevisionsTTL = 180 * 24 * time.Hour
+
+// eventRevision is how many times an event has changed, and when it last did
+type eventRevision struct {
+	Sequence int
+	Modified time.Time
+}
+
+// seenFingerprint is one version of an event, and when it was first seen
+type seenFingerprint struct {
+	Fingerprint string    `json:"fingerprint"`
+	Seen        time.Time `json:"seen"`
+}
+
+// eventRevisionsMu serialises read-modify-write updates of the revisions
+var eventRevisionsMu sync.Mutex
+
+// eventRevisionCache holds the revision of each event UID, updated from the
+// locations whenever the dataset changes
+var eventRevisionCache = newDerived(func(locations []SkipLocation) (map[string]eventRevision, error) {
+	return updateEventRevisions(context.Background(), locations, time.Now()), nil
+})
+
+// skipFingerprint covers everything about a location that shows in its
+// calendar event
+func skipFingerprint(l SkipLocation) string {
+	opens, closes := l.hours()
+	return fmt.Sprintf("%s|%s|%s|%s-%s|%s|%s|%s", l.ID, l.Address, l.Postcode, opens, closes, l.Status,
+		strings.Join(l.AcceptedItems, ","), strings.Join(l.ProhibitedItems, ","))
+}
+
+// eventFingerprints hashes what each calendar event is made from, by UID:
+// every skip of a type on a date for the day events, and each skip for the
+// single-skip events of /api/skips/{id}.ics
+func eventFingerprints(locations []SkipLocation) map[string]string {
+	hash := func(parts []string) string {
+		sort.Strings(parts)
+		sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+		return fmt.Sprintf("%x", sum[:8])
+	}
+
+	fingerprints := make(map[string]string)
+	for date, skips := range groupSkipsByDate(locations) {
+		for _, typ := range typesOf(skips) {
+			var parts []string
+			for _, l := range filterByType(skips, []EventType{typ}) {
+				parts = append(parts, skipFingerprint(l))
+				if l.ID != "" {
+					fingerprints[skipEventUID(l)] = hash([]string{skipFingerprint(l)})
+				}
+			}
+			fingerprints[generateUID(date, typ)] = hash(parts)
+		}
+	}
+	return fingerprints
+}
+
+// updateEventRevisions records a new revision of every event whose
+// fingerprint has changed, and returns the current revision of each.
+// Events whose last change is older than eventRevisionsTTL are forgotten.
+func updateEventRevisions(ctx context.Context, locations []SkipLocation, now time.Time) map[string]eventRevision {
+	revisions := make(map[string]eventRevision)
+	if activeCache == nil {
+		return revisions
+	}
+
+	eventRevisionsMu.Lock()
+	defer eventRevisionsMu.Unlock()
+
+	// Each event's history is every fingerprint it has had, oldest first
+	history := make(map[string][]seenFingerprint)
+	if _, err := getCachedValue(ctx, eventRevisionsKey, &history); err != nil {
+		slog.WarnContext(ctx, "Event revisions cache get error", "err", err)
+	}
+
+	changed := false
+	for uid, fingerprint := range eventFingerprints(locations) {
+		h := history[uid]
+		if len(h) == 0 || h[len(h)-1].Fingerprint != fingerprint {
+			history[uid] = append(h, seenFingerprint{Fingerprint: fingerprint, Seen: now})
+			changed = true
+		}
+	}
+
+	for uid, h := range history {
+		latest := h[len(h)-1]
+		if now.Sub(latest.Seen) > eventRevisionsTTL {
+			delete(history, uid)
+			changed = true
+			continue
+		}
+		revisions[uid] = eventRevision{Sequence: len(h) - 1, Modified: latest.Seen}
+	}
+
+	if changed {
+		if err := setCachedValue(ctx, eventRevisionsKey, history, eventRevisionsTTL); err != nil {
+			slog.WarnContext(ctx, "Event revisions cache set error", "err", err)
+		}
+	}
+	return revisions
+}
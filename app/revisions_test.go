@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateEventRevisions(t *testing.T) {
+	useTestSkips(t, nil)
+	ctx := context.Background()
+
+	locations := []SkipLocation{
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Type: EventMegaSkip},
+		{Address: "Garratt Lane", Postcode: "SW18 4DJ", Date: time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC), Type: EventMegaSkip},
+	}
+	assignIDs(locations)
+	first, second := generateUID(locations[0].Date, EventMegaSkip), generateUID(locations[1].Date, EventMegaSkip)
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	revisions := updateEventRevisions(ctx, locations, t0)
+	if revisions[first] != (eventRevision{Sequence: 0, Modified: t0}) {
+		t.Errorf("Expected a first revision, got %+v", revisions[first])
+	}
+	if revisions[skipEventUID(locations[0])].Modified != t0 {
+		t.Errorf("Expected single-skip events to be tracked too, got %+v", revisions)
+	}
+
+	// Nothing changed, so nothing moves on
+	if revisions := updateEventRevisions(ctx, locations, t0.Add(time.Hour)); revisions[first] != (eventRevision{Sequence: 0, Modified: t0}) {
+		t.Errorf("Expected an unchanged revision, got %+v", revisions[first])
+	}
+
+	t1 := t0.Add(24 * time.Hour)
+	locations[0].Status = StatusCancelled
+	revisions = updateEventRevisions(ctx, locations, t1)
+	if revisions[first] != (eventRevision{Sequence: 1, Modified: t1}) || revisions[skipEventUID(locations[0])].Sequence != 1 {
+		t.Errorf("Expected the cancelled day to move on, got %+v", revisions)
+	}
+	if revisions[second] != (eventRevision{Sequence: 0, Modified: t0}) {
+		t.Errorf("Expected the other day to be unchanged, got %+v", revisions[second])
+	}
+
+	// Long-gone events are forgotten
+	revisions = updateEventRevisions(ctx, locations[1:], t1.Add(eventRevisionsTTL+time.Hour))
+	if _, ok := revisions[first]; ok {
+		t.Error("Expected the old event to be forgotten")
+	}
+}
+
+func TestWriteICalFeedRevisions(t *testing.T) {
+	modified := time.Date(2025, 3, 2, 9, 30, 0, 0, time.UTC)
+	events := []CalendarEvent{
+		{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip", Sequence: 2, Modified: modified},
+		{Date: time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC), Title: "Wandsworth Mega Skip"},
+	}
+
+	var sb strings.Builder
+	if err := writeICalFeed(&sb, events); err != nil {
+		t.Fatalf("writeICalFeed() error = %v", err)
+	}
+	ical := sb.String()
+
+	for _, want := range []string{"SEQUENCE:2\r\nLAST-MODIFIED:20250302T093000Z\r\n", "SEQUENCE:0\r\n"} {
+		if !strings.Contains(ical, want) {
+			t.Errorf("Expected %q in:\n%s", want, ical)
+		}
+	}
+	if strings.Count(ical, "LAST-MODIFIED:") != 1 {
+		t.Errorf("Expected LAST-MODIFIED only where known, got:\n%s", ical)
+	}
+}
+
+func TestCalendarResponseRevisions(t *testing.T) {
+	locations := []SkipLocation{{
+		Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), Type: EventMegaSkip,
+	}}
+	assignIDs(locations)
+	useTestSkips(t, locations)
+
+	w := httptest.NewRecorder()
+	HandleSkipAPI(w, httptest.NewRequest(http.MethodGet, "/api/skips/"+locations[0].ID+".ics", nil))
+	if body := w.Body.String(); !strings.Contains(body, "SEQUENCE:0\r\nLAST-MODIFIED:") {
+		t.Errorf("Expected the event's revision, got:\n%s", body)
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import "net/http"
+
+// NewHandler returns the application's routes wrapped in the middleware
+// shared by every response. It is used by both the local server and the
+// Vercel entry point so the two can't drift apart.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", HandleIndex)
+	mux.HandleFunc("/api/skips", HandleSkipsAPI)
+	mux.HandleFunc("/api/skips.geojson", HandleSkipsGeoJSON)
+	mux.HandleFunc("/api/skips/geocodes", HandleGeocodesAPI)
+	mux.HandleFunc("/api/skips/nearest", HandleNearestAPI)
+	mux.HandleFunc("/api/skips/stream", HandleSkipsStream)
+	mux.HandleFunc("/api/skips/", HandleSkipAPI)
+	mux.HandleFunc("/skip/", HandleSkipPage)
+	mux.HandleFunc("/lite", HandleLite)
+	mux.HandleFunc("/postcode", HandleRememberPostcode)
+	mux.HandleFunc("/postcode/forget", HandleForgetPostcode)
+	mux.HandleFunc("/api/today", HandleTodayAPI)
+	mux.HandleFunc("/api/meta", HandleMetaAPI)
+	mux.HandleFunc("/api/notify/teams", HandleTeamsNotify)
+	mux.HandleFunc("/api/notify/webhooks", HandleWebhooksDeliver)
+	mux.HandleFunc("/api/openapi.json", HandleOpenAPI)
+	mux.HandleFunc("/api/docs", HandleAPIDocs)
+	mux.HandleFunc("/api/v1/skips", apiV1(HandleSkipsAPI))
+	mux.HandleFunc("/api/v1/skips/geocodes", apiV1(HandleGeocodesAPI))
+	mux.HandleFunc("/api/v1/skips/nearest", apiV1(HandleNearestAPI))
+	mux.HandleFunc("/api/v1/skips/", apiV1(HandleSkipAPI))
+	mux.HandleFunc("/api/v1/today", apiV1(HandleTodayAPI))
+	mux.HandleFunc("/api/v1/meta", apiV1(HandleMetaAPI))
+	mux.HandleFunc("/calendar.ics", HandleCalendarDefault)
+	mux.HandleFunc("/calendar/", HandleCalendarPostcode)
+	mux.HandleFunc("/calendar/invite", HandleCalendarInvite)
+	mux.HandleFunc("/subscribe/", HandleSubscribe)
+	mux.HandleFunc("/qr/calendar/", HandleCalendarQR)
+	mux.HandleFunc("/wallet/", HandleWalletPass)
+	mux.HandleFunc("/outlook/", HandleOutlookEvent)
+	mux.HandleFunc("/og.png", HandleOGImage)
+	mux.HandleFunc("/feed.rss", HandleRSSFeed)
+	mux.HandleFunc("/feed.atom", HandleAtomFeed)
+	mux.HandleFunc("/opendata", HandleOpenData)
+	mux.HandleFunc("/opendata/skips.csv", HandleOpenDataCSV)
+	mux.HandleFunc("/static/", HandleStatic)
+	mux.HandleFunc("/next.txt", HandleNextText)
+	mux.HandleFunc("/next.md", HandleNextText)
+	mux.HandleFunc("/nearest/", HandleNearestText)
+	mux.HandleFunc("/voice/dialogflow", HandleDialogflow)
+	mux.HandleFunc("/healthz/deep", HandleDeepHealth)
+	mux.HandleFunc("/version", HandleVersion)
+	mux.HandleFunc("/admin/refresh", audited(HandleAdminRefresh))
+	mux.HandleFunc("/admin/purge", audited(HandleAdminPurge))
+	mux.HandleFunc("/admin/archive", audited(HandleAdminArchive))
+	mux.HandleFunc("/admin/archive/", audited(HandleAdminArchive))
+	mux.HandleFunc("/admin/apikeys", audited(HandleAdminAPIKeys))
+	mux.HandleFunc("/admin/webhooks", audited(HandleAdminWebhooks))
+	mux.HandleFunc("/admin/audit", audited(HandleAdminAudit))
+
+	return requestIDs(requestLogging(recoverPanics(tracing(versionHeader(securityHeaders(cors(rateLimit(mux))))))))
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultCouncilURL is the council page listing upcoming mega skip days
+const defaultCouncilURL = "https://www.wandsworth.gov.uk/mega-skip-days"
+
+var (
+	// councilURL is the page scraped for skip locations, overridden with
+	// SCRAPE_URL to point at a mirror or test server
+	councilURL = defaultCouncilURL
+
+	// lambethURL is the Lambeth community skip days page, set with
+	// LAMBETH_SCRAPE_URL. Lambeth isn't scraped until it is set.
+	lambethURL string
+
+	// scrapeUserAgent identifies us to the council site
+	scrapeUserAgent = geocoderUserAgent
+
+	// scrapeClient fetches the council page. It shares httpClient's
+	// transport, and so its proxy settings, unless SCRAPE_TIMEOUT is set;
+	// tests can swap it out entirely.
+	scrapeClient = httpClient
+)
+
+// configureScraper applies SCRAPE_URL, LAMBETH_SCRAPE_URL, SCRAPE_TIMEOUT
+// and SCRAPE_USER_AGENT, and registers any scrapers described in the JSON
+// file at SCRAPER_CONFIG.
+// Proxies are taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+func configureScraper() {
+	if raw := os.Getenv("SCRAPE_URL"); raw != "" {
+		if !validScrapeURL(raw) {
+			slog.Warn("Ignoring invalid SCRAPE_URL", "url", raw)
+		} else {
+			councilURL = raw
+			slog.Info("Scraping skip locations from", "url", councilURL)
+		}
+	}
+	if raw := os.Getenv("LAMBETH_SCRAPE_URL"); raw != "" {
+		if !validScrapeURL(raw) {
+			slog.Warn("Ignoring invalid LAMBETH_SCRAPE_URL", "url", raw)
+		} else {
+			lambethURL = raw
+			slog.Info("Scraping Lambeth community skips from", "url", lambethURL)
+		}
+	}
+
+	if raw := os.Getenv("SCRAPE_TIMEOUT"); raw != "" {
+		if timeout, err := parseTimeout(raw); err != nil {
+			slog.Warn("Ignoring invalid SCRAPE_TIMEOUT", "timeout", raw, "err", err)
+		} else {
+			scrapeClient = newScrapeClient(timeout)
+		}
+	}
+
+	if agent := os.Getenv("SCRAPE_USER_AGENT"); agent != "" {
+		scrapeUserAgent = agent
+	}
+
+	if path := os.Getenv("SCRAPER_CONFIG"); path != "" {
+		configured, err := loadSelectorScrapers(path)
+		if err != nil {
+			slog.Warn("Failed to load scraper config", "path", path, "err", err)
+			return
+		}
+		for _, s := range configured {
+			if _, ok := scrapers[s.Name()]; ok {
+				slog.Warn("Ignoring configured scraper with a duplicate name", "name", s.Name())
+				continue
+			}
+			registerScraper(s)
+			slog.Info("Registered configured scraper", "name", s.Name(), "url", s.config.URL)
+		}
+	}
+}
+
+// validScrapeURL reports whether raw is an absolute http(s) URL
+func validScrapeURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// parseTimeout accepts either a Go duration such as "20s" or a number of
+// seconds
+func parseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(s)
+		if convErr != nil {
+			return 0, err
+		}
+		d = time.Duration(seconds) * time.Second
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive")
+	}
+	return d, nil
+}
+
+// newScrapeClient returns a client sharing httpClient's pooled transport
+// with its own overall timeout
+func newScrapeClient(timeout time.Duration) *http.Client {
+	client := *httpClient
+	client.Timeout = timeout
+	return &client
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"20", 20 * time.Second, false},
+		{"1m30s", 90 * time.Second, false},
+		{"500ms", 500 * time.Millisecond, false},
+		{"0", 0, true},
+		{"-5s", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeout(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTimeout(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigureScraper(t *testing.T) {
+	defer func(url, agent string) {
+		councilURL, scrapeUserAgent, scrapeClient = url, agent, httpClient
+	}(councilURL, scrapeUserAgent)
+
+	t.Setenv("SCRAPE_URL", "http://localhost:9000/mirror")
+	t.Setenv("SCRAPE_TIMEOUT", "7")
+	t.Setenv("SCRAPE_USER_AGENT", "test-agent")
+	configureScraper()
+
+	if councilURL != "http://localhost:9000/mirror" {
+		t.Errorf("councilURL = %q", councilURL)
+	}
+	if scrapeClient.Timeout != 7*time.Second || scrapeClient.Transport != httpClient.Transport {
+		t.Errorf("scrapeClient timeout = %v, want 7s on the shared transport", scrapeClient.Timeout)
+	}
+	if scrapeUserAgent != "test-agent" {
+		t.Errorf("scrapeUserAgent = %q", scrapeUserAgent)
+	}
+
+	t.Setenv("SCRAPE_URL", "ftp://example.com")
+	configureScraper()
+	if councilURL != "http://localhost:9000/mirror" {
+		t.Errorf("Invalid SCRAPE_URL should be ignored, got %q", councilURL)
+	}
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Retry and circuit breaker settings for scraping the council website
+const (
+	scrapeAttempts    = 3
+	scrapeBackoffBase = 500 * time.Millisecond
+	scrapeBackoffMax  = 5 * time.Second
+	breakerThreshold  = 5
+	breakerCooldown   = 5 * time.Minute
+)
+
+// errCircuitOpen is returned without contacting a source while it is
+// considered down
+var errCircuitOpen = errors.New("council website circuit breaker is open")
+
+// statusError is a non-200 response from the council website
+type statusError struct {
+	StatusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("bad status code: %d", e.StatusCode)
+}
+
+// retryable reports whether a scrape error might go away on its own.
+// Timeouts, connection failures, 5xx and 429 responses are retried; a page
+// that fetched fine but didn't parse is not.
+func retryable(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= 500 || status.StatusCode == http.StatusTooManyRequests
+	}
+	return errors.Is(err, errFetch)
+}
+
+// errFetch wraps failures to reach the council website at all
+var errFetch = errors.New("failed to fetch page")
+
+// circuitBreaker stops calls to a failing dependency for a cooldown after
+// enough consecutive failures, then lets a single trial call through
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+var (
+	scrapeBreakersMu sync.Mutex
+	// scrapeBreakers holds a breaker per source, so one council's outage
+	// doesn't stop the others being scraped
+	scrapeBreakers = map[string]*circuitBreaker{}
+)
+
+// scrapeBreaker returns the breaker for the named source
+func scrapeBreaker(source string) *circuitBreaker {
+	scrapeBreakersMu.Lock()
+	defer scrapeBreakersMu.Unlock()
+
+	b, ok := scrapeBreakers[source]
+	if !ok {
+		b = &circuitBreaker{threshold: breakerThreshold, cooldown: breakerCooldown, now: time.Now}
+		scrapeBreakers[source] = b
+	}
+	return b
+}
+
+// allow reports whether a call may go ahead
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.trial {
+		return false
+	}
+	// Half open: let one call test the water
+	b.trial = true
+	return true
+}
+
+// record notes the outcome of an allowed call
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// scrapeWithRetry scrapes a source, retrying transient failures with
+// jittered exponential backoff, behind the source's circuit breaker
+func scrapeWithRetry(ctx context.Context, s Scraper) ([]SkipLocation, error) {
+	breaker := scrapeBreaker(s.Name())
+	if !breaker.allow() {
+		return nil, errCircuitOpen
+	}
+
+	var err error
+	for attempt := 1; attempt <= scrapeAttempts; attempt++ {
+		var locations []SkipLocation
+		locations, err = s.Scrape(ctx)
+		if err == nil {
+			breaker.record(nil)
+			return locations, nil
+		}
+		if !retryable(err) || attempt == scrapeAttempts {
+			break
+		}
+
+		delay := backoff(attempt)
+		slog.WarnContext(ctx, "Scrape failed, retrying", "source", s.Name(), "attempt", attempt, "delay", delay, "err", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			breaker.record(err)
+			return nil, err
+		}
+	}
+
+	breaker.record(err)
+	return nil, err
+}
+
+// backoff returns a random delay of up to base*2^(attempt-1), capped, so
+// instances retrying at once spread out
+func backoff(attempt int) time.Duration {
+	ceiling := min(scrapeBackoffBase<<(attempt-1), scrapeBackoffMax)
+	return rand.N(ceiling) + 1
+}
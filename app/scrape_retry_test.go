@@ -0,0 +1,75 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	b := &circuitBreaker{threshold: 2, cooldown: time.Minute, now: func() time.Time { return now }}
+	failure := errors.New("down")
+
+	for i := range 2 {
+		if !b.allow() {
+			t.Fatalf("Breaker opened after %d failures", i)
+		}
+		b.record(failure)
+	}
+	if b.allow() {
+		t.Fatal("Breaker should be open after reaching the threshold")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("Breaker should allow a trial call after the cooldown")
+	}
+	if b.allow() {
+		t.Fatal("Breaker should allow only one trial call at a time")
+	}
+
+	b.record(failure)
+	if b.allow() {
+		t.Fatal("Failed trial should reopen the breaker")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("Breaker should allow a trial call after the cooldown")
+	}
+	b.record(nil)
+	if !b.allow() || !b.allow() {
+		t.Fatal("Successful trial should close the breaker")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		ceiling := min(scrapeBackoffBase<<(attempt-1), scrapeBackoffMax)
+		for range 100 {
+			if d := backoff(attempt); d <= 0 || d > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within (0, %v]", attempt, d, ceiling)
+			}
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&statusError{StatusCode: 503}, true},
+		{&statusError{StatusCode: 429}, true},
+		{&statusError{StatusCode: 404}, false},
+		{fmt.Errorf("%w: %w", errFetch, errors.New("connection reset")), true},
+		{errors.New("failed to parse HTML"), false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Scraper fetches the skip locations published by one council
+type Scraper interface {
+	// Name identifies the source. It matches the ID of the source's Borough
+	// and is used in its cache keys.
+	Name() string
+	// Scrape returns every location listed by the source, past and future,
+	// without coordinates
+	Scrape(ctx context.Context) ([]SkipLocation, error)
+}
+
+// scrapers holds every registered source by name. Each source registers
+// itself from an init function in its own file.
+var scrapers = map[string]Scraper{}
+
+// registerScraper adds a source to the registry
+func registerScraper(s Scraper) {
+	if _, ok := scrapers[s.Name()]; ok {
+		panic(fmt.Sprintf("scraper %q registered twice", s.Name()))
+	}
+	scrapers[s.Name()] = s
+}
+
+// registeredScrapers returns the registered sources in name order
+func registeredScrapers() []Scraper {
+	list := make([]Scraper, 0, len(scrapers))
+	for _, s := range scrapers {
+		list = append(list, s)
+	}
+	slices.SortFunc(list, func(a, b Scraper) int { return strings.Compare(a.Name(), b.Name()) })
+	return list
+}
+
+// sourceCacheKey is the cache key for one source's last scrape, kept so a
+// source that fails doesn't take the others' locations down with it
+func sourceCacheKey(source string) string {
+	return cacheKey + ":" + source
+}
+
+// scrapeSources scrapes every registered source, normalises and dedupes
+// the locations, applies any overrides and geocodes the upcoming ones. A
+// source that fails is replaced by its last cached scrape if there is one;
+// scraping only fails if no source has any data.
+func scrapeSources(ctx context.Context) ([]SkipLocation, error) {
+	now := time.Now()
+
+	var (
+		locations []SkipLocation
+		errs      []error
+		succeeded int
+	)
+	for _, s := range registeredScrapers() {
+		scraped, err := scrapeSource(ctx, s, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+			continue
+		}
+		succeeded++
+		locations = append(locations, scraped...)
+	}
+	if succeeded == 0 {
+		return nil, errors.Join(errs...)
+	}
+	locations = overrideLocations(ctx, normaliseLocations(locations))
+	assignIDs(locations)
+
+	// Filter to only upcoming dates
+	filtered := []SkipLocation{}
+	for _, loc := range locations {
+		if loc.Date.After(now) || loc.Date.Equal(now.Truncate(24*time.Hour)) {
+			filtered = append(filtered, loc)
+		}
+	}
+
+	// Anything not geocoded within the budget is finished in the background
+	geocodeCtx, cancel := context.WithTimeout(ctx, geocodeBudget)
+	defer cancel()
+	geocodeLocations(geocodeCtx, filtered)
+
+	return filtered, nil
+}
+
+// scrapeSource scrapes one source and caches the result under its own key,
+// falling back to that cached copy if scraping fails
+func scrapeSource(ctx context.Context, s Scraper, now time.Time) ([]SkipLocation, error) {
+	ctx, span := startSpan(ctx, "scrape")
+	defer span.End()
+	span.SetAttributes(attribute.String("source", s.Name()))
+
+	locations, err := scrapeWithRetry(ctx, s)
+	if err != nil {
+		recordSpanError(span, err)
+		// The failures that opened the breaker have already been reported
+		if !errors.Is(err, errCircuitOpen) {
+			reportError(ctx, fmt.Errorf("scraping %s failed: %w", s.Name(), err), map[string]string{"source": s.Name()})
+		}
+
+		cacheMu.RLock()
+		cached, cacheErr := activeCache.Get(ctx, sourceCacheKey(s.Name()))
+		cacheMu.RUnlock()
+		if cacheErr != nil || cached == nil {
+			return nil, err
+		}
+		slog.WarnContext(ctx, "Scrape failed, using last cached copy", "source", s.Name(), "err", err)
+		return cached, nil
+	}
+
+	for i := range locations {
+		locations[i].Borough = s.Name()
+	}
+	span.SetAttributes(attribute.Int("locations", len(locations)))
+
+	ttl := skipLocationsTTL(locations, now) + maxStaleness
+	cacheMu.Lock()
+	err = activeCache.Set(ctx, sourceCacheKey(s.Name()), locations, ttl)
+	cacheMu.Unlock()
+	if err != nil {
+		slog.WarnContext(ctx, "Cache set error", "source", s.Name(), "err", err)
+	}
+
+	return locations, nil
+}
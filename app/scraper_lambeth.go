@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func init() {
+	registerScraper(lambethScraper{})
+}
+
+// lambethScraper reads Lambeth's community skip days, which are published
+// as a table of dates and locations rather than headings and lists
+type lambethScraper struct{}
+
+func (lambethScraper) Name() string { return "lambeth" }
+
+func (lambethScraper) Scrape(ctx context.Context) ([]SkipLocation, error) {
+	if lambethURL == "" {
+		return nil, nil
+	}
+	return fetchCouncilPage(ctx, lambethURL, parseLambethPage, time.Now())
+}
+
+// parseLambethPage extracts community skips from table rows whose first
+// cell is the date, such as "Saturday 7 February, 9am to 1pm", and whose
+// second is the location, such as "Streatham Common car park, SW16 3BX"
+func parseLambethPage(doc *goquery.Document, now time.Time) []SkipLocation {
+	var locations []SkipLocation
+	pageHours := pageOpeningHours(doc)
+	accepted, prohibited := pageItems(doc)
+
+	doc.Find("tr").Each(func(i int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 2 {
+			return
+		}
+
+		// Opening hours or a cancellation follow the date
+		dateCell, dateStatus := cutStatus(strings.TrimSpace(cells.Eq(0).Text()))
+		dateText, _, _ := strings.Cut(dateCell, ",")
+		date, err := parseSkipDate(dateText, now.Year())
+		if err != nil {
+			return
+		}
+
+		locationCell, status := cutStatus(cells.Eq(1).Text())
+		loc := parseLocationLine(locationCell, date, dateText)
+		if loc.Address == "" {
+			return
+		}
+		loc.Type = EventCommunitySkip
+		loc = withOpeningHours(loc, locationCell, dateCell, pageHours)
+		loc = withStatus(withStatus(loc, status), dateStatus)
+		locations = append(locations, withItems(loc, accepted, prohibited))
+	})
+
+	return locations
+}
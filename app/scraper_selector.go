@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SelectorScraperConfig describes a council page laid out as date headings
+// each followed by a list of locations, so councils with pages like
+// Wandsworth's can be added without writing Go
+type SelectorScraperConfig struct {
+	// Name identifies the source in cache keys and the ?borough= filter
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Type is the event type of every location (default: megaskip)
+	Type EventType `json:"type"`
+	// DateSelector matches the headings holding each date (default: h3)
+	DateSelector string `json:"dateSelector"`
+	// LocationSelector matches each location within the elements between
+	// one date heading and the next (default: li)
+	LocationSelector string `json:"locationSelector"`
+	// DateFormats are Go time layouts for the date headings. Layouts
+	// without a year are taken to be in the current year. Defaults to the
+	// formats Wandsworth uses.
+	DateFormats []string `json:"dateFormats"`
+	// LocationPattern is a regular expression with "address" and
+	// "postcode" named groups. Defaults to "Address, POSTCODE" lines.
+	LocationPattern string `json:"locationPattern"`
+}
+
+// selectorScraper scrapes a page described by a SelectorScraperConfig
+type selectorScraper struct {
+	config          SelectorScraperConfig
+	locationPattern *regexp.Regexp
+}
+
+// newSelectorScraper validates a config and fills in its defaults
+func newSelectorScraper(config SelectorScraperConfig) (*selectorScraper, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("scraper has no name")
+	}
+	if !validScrapeURL(config.URL) {
+		return nil, fmt.Errorf("scraper %q has an invalid URL %q", config.Name, config.URL)
+	}
+
+	if config.Type == "" {
+		config.Type = EventMegaSkip
+	}
+	if _, ok := eventTypeLabels[config.Type]; !ok {
+		return nil, fmt.Errorf("scraper %q has unknown event type %q", config.Name, config.Type)
+	}
+	if config.DateSelector == "" {
+		config.DateSelector = "h3"
+	}
+	if config.LocationSelector == "" {
+		config.LocationSelector = "li"
+	}
+
+	s := &selectorScraper{config: config}
+	if config.LocationPattern != "" {
+		pattern, err := regexp.Compile(config.LocationPattern)
+		if err != nil {
+			return nil, fmt.Errorf("scraper %q has an invalid location pattern: %w", config.Name, err)
+		}
+		if pattern.SubexpIndex("address") < 0 || pattern.SubexpIndex("postcode") < 0 {
+			return nil, fmt.Errorf("scraper %q location pattern needs address and postcode groups", config.Name)
+		}
+		s.locationPattern = pattern
+	}
+	return s, nil
+}
+
+// loadSelectorScrapers reads a JSON array of SelectorScraperConfig
+func loadSelectorScrapers(path string) ([]*selectorScraper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []SelectorScraperConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+
+	scrapers := make([]*selectorScraper, 0, len(configs))
+	for _, config := range configs {
+		s, err := newSelectorScraper(config)
+		if err != nil {
+			return nil, err
+		}
+		scrapers = append(scrapers, s)
+	}
+	return scrapers, nil
+}
+
+func (s *selectorScraper) Name() string { return s.config.Name }
+
+func (s *selectorScraper) Scrape(ctx context.Context) ([]SkipLocation, error) {
+	return fetchCouncilPage(ctx, s.config.URL, s.parse, time.Now())
+}
+
+// parse extracts the locations listed under each date heading
+func (s *selectorScraper) parse(doc *goquery.Document, now time.Time) []SkipLocation {
+	var locations []SkipLocation
+	pageHours := pageOpeningHours(doc)
+	accepted, prohibited := pageItems(doc)
+
+	doc.Find(s.config.DateSelector).Each(func(i int, heading *goquery.Selection) {
+		dateText, dateStatus := cutStatus(strings.TrimSpace(heading.Text()))
+		date, err := s.parseDate(dateText, now.Year())
+		if err != nil {
+			return
+		}
+
+		section := heading.NextUntil(s.config.DateSelector)
+		items := section.Filter(s.config.LocationSelector).AddSelection(section.Find(s.config.LocationSelector))
+		items.Each(func(i int, item *goquery.Selection) {
+			text, status := cutStatus(item.Text())
+			if loc := s.parseLocation(text, date, dateText); loc.Address != "" {
+				loc = withOpeningHours(loc, text, dateText, pageHours)
+				loc = withStatus(withStatus(loc, status), dateStatus)
+				locations = append(locations, withItems(loc, accepted, prohibited))
+			}
+		})
+	})
+
+	return locations
+}
+
+// parseDate parses a date heading with the configured formats
+func (s *selectorScraper) parseDate(text string, year int) (time.Time, error) {
+	if len(s.config.DateFormats) == 0 {
+		return parseSkipDate(text, year)
+	}
+
+	for _, format := range s.config.DateFormats {
+		value := text
+		if !strings.Contains(format, "2006") {
+			format += " 2006"
+			value = fmt.Sprintf("%s %d", text, year)
+		}
+		if t, err := time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("not a valid date format")
+}
+
+// parseLocation parses a location line with the configured pattern
+func (s *selectorScraper) parseLocation(line string, date time.Time, dateStr string) SkipLocation {
+	if s.locationPattern == nil {
+		loc := parseLocationLine(line, date, dateStr)
+		loc.Type = s.config.Type
+		return loc
+	}
+
+	match := s.locationPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return SkipLocation{}
+	}
+	return SkipLocation{
+		Address:  strings.TrimSpace(match[s.locationPattern.SubexpIndex("address")]),
+		Postcode: strings.ToUpper(strings.TrimSpace(match[s.locationPattern.SubexpIndex("postcode")])),
+		Date:     date,
+		DateStr:  dateStr,
+		Type:     s.config.Type,
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const selectorTestPage = `<html><body>
+<h2>Bulky waste days</h2>
+<div class="event">
+  <h4>14/02</h4>
+  <p>Come along between 9am and 1pm.</p>
+  <ul><li>Morden Park (SM4 5QU)</li><li>Not a location</li></ul>
+  <h4>21/02</h4>
+  <ul><li>Mitcham Common (CR4 1HT)</li></ul>
+</div>
+</body></html>`
+
+func TestSelectorScraperParse(t *testing.T) {
+	s, err := newSelectorScraper(SelectorScraperConfig{
+		Name:            "merton",
+		URL:             "https://example.com/bulky-waste",
+		Type:            EventPopUpRecycling,
+		DateSelector:    "h4",
+		DateFormats:     []string{"02/01"},
+		LocationPattern: `^(?P<address>.+?)\s*\((?P<postcode>[A-Za-z0-9 ]+)\)$`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(selectorTestPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locations := s.parse(doc, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(locations) != 2 {
+		t.Fatalf("Expected 2 locations, got %+v", locations)
+	}
+	first := locations[0]
+	if first.Address != "Morden Park" || first.Postcode != "SM4 5QU" || first.Type != EventPopUpRecycling {
+		t.Errorf("Unexpected location %+v", first)
+	}
+	if want := time.Date(2026, 2, 14, 0, 0, 0, 0, time.UTC); !first.Date.Equal(want) {
+		t.Errorf("Date = %v, want %v", first.Date, want)
+	}
+	if locations[1].Address != "Mitcham Common" {
+		t.Errorf("Unexpected second location %+v", locations[1])
+	}
+}
+
+func TestSelectorScraperDefaults(t *testing.T) {
+	s, err := newSelectorScraper(SelectorScraperConfig{Name: "copy", URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(conditionalTestPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locations := s.parse(doc, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(locations) != 1 || locations[0].Postcode != "SW11 5TU" || locations[0].Type != EventMegaSkip {
+		t.Errorf("Expected Wandsworth-style parsing by default, got %+v", locations)
+	}
+}
+
+func TestLoadSelectorScrapersRejectsBadConfig(t *testing.T) {
+	tests := []string{
+		`[{"url": "https://example.com"}]`,
+		`[{"name": "x", "url": "ftp://example.com"}]`,
+		`[{"name": "x", "url": "https://example.com", "type": "fridges"}]`,
+		`[{"name": "x", "url": "https://example.com", "locationPattern": "(.+)"}]`,
+		`{"name": "x"}`,
+	}
+	for _, config := range tests {
+		path := filepath.Join(t.TempDir(), "scrapers.json")
+		if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadSelectorScrapers(path); err == nil {
+			t.Errorf("Expected an error for %s", config)
+		}
+	}
+}
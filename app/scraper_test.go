@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// fakeScraper returns fixed locations, or err if set
+type fakeScraper struct {
+	name      string
+	locations []SkipLocation
+	err       error
+}
+
+func (s *fakeScraper) Name() string { return s.name }
+
+func (s *fakeScraper) Scrape(ctx context.Context) ([]SkipLocation, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]SkipLocation(nil), s.locations...), nil
+}
+
+func TestRegisteredScrapers(t *testing.T) {
+	var names []string
+	for _, s := range registeredScrapers() {
+		names = append(names, s.Name())
+	}
+	if strings.Join(names, ",") != "lambeth,wandsworth" {
+		t.Errorf("Registered scrapers = %v, want Lambeth and Wandsworth in order", names)
+	}
+}
+
+func TestScrapeSourceFallsBackToCachedCopy(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+
+	ctx := context.Background()
+	now := time.Now()
+	s := &fakeScraper{name: "testborough", locations: []SkipLocation{{Address: "Test Road", Postcode: "SW18 1AA"}}}
+
+	got, err := scrapeSource(ctx, s, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Borough != "testborough" {
+		t.Fatalf("Expected locations tagged with their source, got %+v", got)
+	}
+
+	// A 404 isn't retried, so this fails straight away
+	s.err = &statusError{StatusCode: 404}
+	got, err = scrapeSource(ctx, s, now)
+	if err != nil {
+		t.Fatalf("Expected the cached copy, got error %v", err)
+	}
+	if len(got) != 1 || got[0].Address != "Test Road" {
+		t.Errorf("Expected the cached copy, got %+v", got)
+	}
+
+	activeCache = NewMemoryCache()
+	if _, err := scrapeSource(ctx, s, now); err == nil {
+		t.Error("Expected an error with nothing cached")
+	}
+}
+
+func TestParseLambethPage(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<table>
+<tr><th>Date</th><th>Location</th></tr>
+<tr><td>Saturday 7 February, 9am to 1pm</td><td>Streatham Common car park, SW16 3BX</td></tr>
+<tr><td>Sunday 8 February</td><td>Not a location</td></tr>
+<tr><td>To be confirmed</td><td>Brockwell Park, SE24 9BJ</td></tr>
+</table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locations := parseLambethPage(doc, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(locations) != 1 {
+		t.Fatalf("Expected 1 location, got %+v", locations)
+	}
+	loc := locations[0]
+	if loc.Address != "Streatham Common car park" || loc.Postcode != "SW16 3BX" || loc.Type != EventCommunitySkip {
+		t.Errorf("Unexpected location %+v", loc)
+	}
+	if want := time.Date(2026, 2, 7, 0, 0, 0, 0, time.UTC); !loc.Date.Equal(want) {
+		t.Errorf("Date = %v, want %v", loc.Date, want)
+	}
+}
@@ -0,0 +1,20 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+func init() {
+	registerScraper(wandsworthScraper{})
+}
+
+// wandsworthScraper reads the mega skip days page on the Wandsworth Council
+// website, or the mirror at SCRAPE_URL
+type wandsworthScraper struct{}
+
+func (wandsworthScraper) Name() string { return "wandsworth" }
+
+func (wandsworthScraper) Scrape(ctx context.Context) ([]SkipLocation, error) {
+	return fetchCouncilPage(ctx, councilURL, parseCouncilPage, time.Now())
+}
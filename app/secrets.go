@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vaultPrefix marks a secret value as a reference into Vault, in the form
+// vault:<path>#<key>, e.g. vault:secret/data/wheremegaskip#upstash_token
+const vaultPrefix = "vault:"
+
+// vaultSecretTTL is how long a secret read from Vault is reused, as
+// secrets are looked up on every request that needs one
+const vaultSecretTTL = 5 * time.Minute
+
+// vaultSecret is a secret read from Vault, until expires
+type vaultSecret struct {
+	value   string
+	expires time.Time
+}
+
+var (
+	vaultSecretsMu sync.Mutex
+	// vaultSecrets holds the secrets read from Vault by address and reference
+	vaultSecrets = make(map[string]vaultSecret)
+)
+
+// getSecret returns the secret configured for name. The value is read from
+// the file named by NAME_FILE if set (as with Docker and Kubernetes secrets),
+// otherwise from NAME itself. Either may hold a vault: reference, which is
+// resolved against VAULT_ADDR using VAULT_TOKEN.
+func getSecret(name string) (string, error) {
+	value := os.Getenv(name)
+
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading %s_FILE: %w", name, err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	}
+
+	if ref, ok := strings.CutPrefix(value, vaultPrefix); ok {
+		secret, err := cachedVaultSecret(ref, time.Now())
+		if err != nil {
+			return "", fmt.Errorf("resolving %s from Vault: %w", name, err)
+		}
+		return secret, nil
+	}
+
+	return value, nil
+}
+
+// cachedVaultSecret reads ref from Vault, reusing what was read within
+// vaultSecretTTL. Failures aren't cached, so they're retried next time.
+func cachedVaultSecret(ref string, now time.Time) (string, error) {
+	key := os.Getenv("VAULT_ADDR") + " " + ref
+
+	vaultSecretsMu.Lock()
+	cached, ok := vaultSecrets[key]
+	vaultSecretsMu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	value, err := readVaultSecret(ref)
+	if err != nil {
+		return "", err
+	}
+	vaultSecretsMu.Lock()
+	vaultSecrets[key] = vaultSecret{value: value, expires: now.Add(vaultSecretTTL)}
+	vaultSecretsMu.Unlock()
+	return value, nil
+}
+
+// readVaultSecret reads <path>#<key> from Vault's HTTP API, supporting both
+// KV v1 and v2 secret engines
+func readVaultSecret(ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("reference %q should be <path>#<key>", ref)
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+
+	// The Vault token itself may come from a file, but not from Vault
+	token := os.Getenv("VAULT_TOKEN")
+	if tokenFile := os.Getenv("VAULT_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("reading VAULT_TOKEN_FILE: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+
+	return vaultSecretValue(result.Data, key)
+}
+
+// vaultSecretValue extracts key from a Vault response's data object. KV v2
+// nests the secret under a second "data" object; KV v1 does not.
+func vaultSecretValue(data map[string]json.RawMessage, key string) (string, error) {
+	if nested, ok := data["data"]; ok {
+		var inner map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &inner); err == nil {
+			if _, ok := inner[key]; ok {
+				data = inner
+			}
+		}
+	}
+
+	raw, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found", key)
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("key %q is not a string", key)
+	}
+	return value, nil
+}
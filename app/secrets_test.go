@@ -0,0 +1,92 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetSecret(t *testing.T) {
+	t.Setenv("TEST_SECRET", "from-env")
+
+	got, err := getSecret("TEST_SECRET")
+	if err != nil || got != "from-env" {
+		t.Errorf("getSecret() = %q, %v, want %q", got, err, "from-env")
+	}
+
+	// A _FILE path takes precedence, with the trailing newline trimmed
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_SECRET_FILE", path)
+
+	got, err = getSecret("TEST_SECRET")
+	if err != nil || got != "from-file" {
+		t.Errorf("getSecret() = %q, %v, want %q", got, err, "from-file")
+	}
+
+	t.Setenv("TEST_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err := getSecret("TEST_SECRET"); err == nil {
+		t.Error("Expected error for a missing secret file")
+	}
+}
+
+func TestVaultSecretValue(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"kv v1", `{"token": "abc"}`, "abc"},
+		{"kv v2", `{"data": {"token": "abc"}, "metadata": {"version": 3}}`, "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.body), &data); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := vaultSecretValue(data, "token")
+			if err != nil || got != tt.want {
+				t.Errorf("vaultSecretValue() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSecretCachesVault(t *testing.T) {
+	var reads int
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads++
+		if r.URL.Path != "/v1/secret/data/app" || r.Header.Get("X-Vault-Token") != "root" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"token": "from-vault"}}}`))
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("TEST_SECRET", "vault:secret/data/app#token")
+
+	for i := 0; i < 3; i++ {
+		if got, err := getSecret("TEST_SECRET"); err != nil || got != "from-vault" {
+			t.Fatalf("getSecret() = %q, %v, want %q", got, err, "from-vault")
+		}
+	}
+	if reads != 1 {
+		t.Errorf("Expected one read from Vault, got %d", reads)
+	}
+
+	// Once expired, the secret is read again
+	if _, err := cachedVaultSecret("secret/data/app#token", time.Now().Add(vaultSecretTTL)); err != nil || reads != 2 {
+		t.Errorf("Expected an expired secret to be read again, got %d reads, %v", reads, err)
+	}
+}
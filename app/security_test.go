@@ -0,0 +1,81 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSecurityHeadersOnEveryRoute(t *testing.T) {
+	useTestSkips(t, []SkipLocation{})
+	handler := NewHandler()
+
+	for _, path := range []string{"/", script.url, "/static/missing.js"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+		for _, h := range []string{"X-Content-Type-Options", "X-Frame-Options", "Referrer-Policy", "Content-Security-Policy"} {
+			if rec.Header().Get(h) == "" {
+				t.Errorf("%s: missing %s header", path, h)
+			}
+		}
+
+		if strings.Contains(rec.Header().Get("Content-Security-Policy"), "'unsafe-inline'") {
+			t.Errorf("%s: CSP should not allow unsafe-inline", path)
+		}
+	}
+}
+
+func TestIndexScriptsAndStylesCarryRequestNonce(t *testing.T) {
+	useTestSkips(t, []SkipLocation{})
+	handler := NewHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	csp := rec.Header().Get("Content-Security-Policy")
+	start := strings.Index(csp, "'nonce-")
+	if start == -1 {
+		t.Fatalf("CSP should contain a nonce, got %q", csp)
+	}
+	nonce := csp[start+len("'nonce-"):]
+	nonce = nonce[:strings.Index(nonce, "'")]
+
+	page := rec.Body.String()
+	if got := strings.Count(page, `<script nonce="`+nonce+`"`); got != strings.Count(page, "<script") {
+		t.Errorf("Every <script> should carry the request nonce, %d of %d do", got, strings.Count(page, "<script"))
+	}
+	if got := strings.Count(page, `<style nonce="`+nonce+`"`); got != strings.Count(page, "<style") {
+		t.Errorf("Every <style> should carry the request nonce, %d of %d do", got, strings.Count(page, "<style"))
+	}
+	if strings.Contains(page, "onclick=") {
+		t.Error("Page should not use inline event handlers")
+	}
+	if strings.Contains(page, " style=") {
+		t.Error("Page should not use inline style attributes, which the CSP blocks")
+	}
+
+	// Each request gets a fresh nonce
+	rec2 := httptest.NewRecorder()
+	handler.ServeHTTP(rec2, httptest.NewRequest("GET", "/", nil))
+	if rec2.Header().Get("Content-Security-Policy") == csp {
+		t.Error("Nonce should differ between requests")
+	}
+}
+
+func TestNoncedPagesAreNotPubliclyCached(t *testing.T) {
+	useTestSkips(t, []SkipLocation{})
+	handler := NewHandler()
+
+	for _, path := range []string{"/", "/lite", "/api/docs"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+		if !strings.Contains(rec.Header().Get("Content-Security-Policy"), "'nonce-") {
+			t.Fatalf("%s: expected a CSP nonce", path)
+		}
+		if cc := rec.Header().Get("Cache-Control"); cc != "no-store" || rec.Header().Get("CDN-Cache-Control") != "" {
+			t.Errorf("%s carries a nonce, so shouldn't be cached, got Cache-Control %q", path, cc)
+		}
+	}
+}
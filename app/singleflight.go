@@ -0,0 +1,73 @@
+package app
+
+import (
+	"errors"
+	"sync"
+)
+
+// errFlightAborted is returned to callers waiting on a flight whose fn
+// exited without returning, such as by runtime.Goexit
+var errFlightAborted = errors.New("flight call exited without returning")
+
+// flightGroup coalesces concurrent calls with the same key into one, in the
+// manner of golang.org/x/sync/singleflight
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+type flightCall[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+	// panicked and panicValue record a panic in fn, so waiters panic too
+	// rather than taking the zero value for a result
+	panicked   bool
+	panicValue any
+}
+
+// Do runs fn unless a call for key is already in flight, in which case it
+// waits for that call and returns its result. shared reports whether the
+// result came from another caller's fn. If fn panics, the caller and every
+// waiting caller panic with the same value.
+func (g *flightGroup[T]) Do(key string, fn func() (T, error)) (value T, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall[T])
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		if c.panicked {
+			panic(c.panicValue)
+		}
+		return c.value, c.err, true
+	}
+	c := &flightCall[T]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	returned := false
+	defer func() {
+		if !returned {
+			if r := recover(); r != nil {
+				c.panicked, c.panicValue = true, r
+			} else {
+				c.err = errFlightAborted
+			}
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+
+		if c.panicked {
+			panic(c.panicValue)
+		}
+	}()
+
+	c.value, c.err = fn()
+	returned = true
+	return c.value, c.err, false
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupCoalescesConcurrentCalls(t *testing.T) {
+	var g flightGroup[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _, _ = g.Do("key", func() (int, error) {
+			calls.Add(1)
+			close(started)
+			<-release
+			return 42, nil
+		})
+	}()
+	<-started
+
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, _ = g.Do("key", func() (int, error) {
+				calls.Add(1)
+				return 0, nil
+			})
+		}(i)
+	}
+
+	// Give the followers time to join the flight before it lands
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected fn to run once, ran %d times", n)
+	}
+	for i, got := range results {
+		if got != 42 {
+			t.Errorf("Caller %d got %d, want 42", i, got)
+		}
+	}
+}
+
+func TestFlightGroupRunsAgainAfterCompletion(t *testing.T) {
+	var g flightGroup[int]
+	for i := 1; i <= 2; i++ {
+		got, err, shared := g.Do("key", func() (int, error) { return i, nil })
+		if err != nil || shared || got != i {
+			t.Errorf("Call %d: got %d, %v, shared=%v", i, got, err, shared)
+		}
+	}
+}
+
+func TestFlightGroupPropagatesPanics(t *testing.T) {
+	var g flightGroup[int]
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	// do calls g.Do, returning what it panicked with
+	do := func(fn func() (int, error)) (recovered any) {
+		defer func() { recovered = recover() }()
+		g.Do("key", fn)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	panics := make([]any, 2)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		panics[0] = do(func() (int, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		panics[1] = do(func() (int, error) { return 42, nil })
+	}()
+
+	// Give the follower time to join the flight before it panics
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, p := range panics {
+		if p != "boom" {
+			t.Errorf("Caller %d panicked with %v, want boom", i, p)
+		}
+	}
+
+	// The key is free for the next call
+	if got, err, _ := g.Do("key", func() (int, error) { return 1, nil }); got != 1 || err != nil {
+		t.Errorf("Got %d, %v after a panic", got, err)
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// slugify lower-cases text and joins its letters and digits with hyphens,
+// so "Pountney Road" is "pountney-road"
+func slugify(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+// skipID identifies a location by its date, address and postcode, such as
+// "2025-03-15-pountney-road-sw11-5tu", so it stays the same across
+// refreshes. As with calendar UIDs, types other than mega skips are
+// suffixed so a mega skip and a tree collection at the same place differ.
+func skipID(l SkipLocation) string {
+	id := l.Date.Format("2006-01-02") + "-" + slugify(l.Address) + "-" + slugify(l.Postcode)
+	if typ := l.eventType(); typ != EventMegaSkip {
+		id += "-" + string(typ)
+	}
+	return id
+}
+
+// assignIDs sets the ID of every location in place. Locations listed twice
+// are numbered, the second being "…-sw11-5tu-2".
+func assignIDs(locations []SkipLocation) {
+	seen := make(map[string]int)
+	for i := range locations {
+		id := skipID(locations[i])
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id = fmt.Sprintf("%s-%d", id, n)
+		}
+		locations[i].ID = id
+	}
+}
+
+// findSkip returns the location with the given ID
+func findSkip(locations []SkipLocation, id string) (SkipLocation, bool) {
+	for _, l := range locations {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return SkipLocation{}, false
+}
+
+// skipEventUID identifies a single location's calendar event
+func skipEventUID(l SkipLocation) string {
+	return l.ID + "@wheremegaskip.com"
+}
+
+// skipURL is the page for a location, which opens the map on it
+func skipURL(id string) string {
+	return currentTenant().SiteURL + "/skip/" + url.PathEscape(id)
+}
+
+// HandleSkipAPI handles requests to /api/skips/{id}, returning one
+// location as JSON, and /api/skips/{id}.ics, returning it as a calendar
+// event
+func HandleSkipAPI(w http.ResponseWriter, r *http.Request) {
+	id, ics := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/skips/"), ".ics")
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	skip, ok := findSkip(locations, id)
+	if !ok {
+		writeProblem(w, http.StatusNotFound, "Skip not found")
+		return
+	}
+
+	setSnapshotHeaders(w)
+	if ics {
+		calendarCaching.apply(w)
+		event := CalendarEvent{
+			UID:         skipEventUID(skip),
+			Date:        skip.Date,
+			Type:        skip.eventType(),
+			Title:       eventTitle(skip.eventType()),
+			Description: eventDescription(currentTenant().SiteURL, []SkipLocation{skip}),
+			Location:    skipEventLocation(&skip),
+			Cancelled:   skip.cancelled(),
+			Latitude:    skip.Latitude,
+			Longitude:   skip.Longitude,
+			URL:         skipURL(skip.ID),
+		}
+		event.OpensAt, event.ClosesAt = skip.hours()
+		writeCalendarResponse(w, r, []CalendarEvent{event})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	apiCaching.apply(w)
+	json.NewEncoder(w).Encode(skip)
+}
+
+// HandleSkipPage handles requests to /skip/{id}, the page opened on one
+// location, which calendar events link to. Clients preferring JSON get the
+// location as from /api/skips/{id}.
+func HandleSkipPage(w http.ResponseWriter, r *http.Request) {
+	id, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/skip/"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "Invalid skip ID encoding")
+		return
+	}
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+	skip, ok := findSkip(locations, id)
+	if !ok {
+		writeProblem(w, http.StatusNotFound, "Skip not found")
+		return
+	}
+
+	w.Header().Set("Vary", "Accept")
+	accept := r.Header.Get("Accept")
+	if acceptQuality(accept, "application/json") > acceptQuality(accept, "text/html") {
+		w.Header().Set("Content-Type", "application/json")
+		apiCaching.apply(w)
+		json.NewEncoder(w).Encode(skip)
+		return
+	}
+
+	writeIndexPage(w, r, indexPage{Skip: &skip})
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssignIDs(t *testing.T) {
+	date := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	locations := []SkipLocation{
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: date, Type: EventMegaSkip},
+		{Address: "St. John's Hill (car park)", Postcode: "SW11 1SA", Date: date},
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: date, Type: EventChristmasTrees},
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Date: date, Type: EventMegaSkip},
+	}
+
+	assignIDs(locations)
+	want := []string{
+		"2025-03-15-pountney-road-sw11-5tu",
+		"2025-03-15-st-john-s-hill-car-park-sw11-1sa",
+		"2025-03-15-pountney-road-sw11-5tu-christmas-trees",
+		"2025-03-15-pountney-road-sw11-5tu-2",
+	}
+	for i, loc := range locations {
+		if loc.ID != want[i] {
+			t.Errorf("Expected ID %q, got %q", want[i], loc.ID)
+		}
+	}
+}
+
+func TestHandleSkipAPI(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+
+	locations := []SkipLocation{{
+		Address:   "Pountney Road",
+		Postcode:  "SW11 5TU",
+		Date:      time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+		Latitude:  51.4655,
+		Longitude: -0.1612,
+		Type:      EventMegaSkip,
+	}}
+	assignIDs(locations)
+	activeCache.Set(context.Background(), cacheKey, locations, time.Hour)
+
+	w := httptest.NewRecorder()
+	HandleSkipAPI(w, httptest.NewRequest("GET", "/api/skips/2025-03-15-pountney-road-sw11-5tu", nil))
+	var got SkipLocation
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Expected the skip, got %d: %v", w.Code, err)
+	}
+	if got.Address != "Pountney Road" {
+		t.Errorf("Expected Pountney Road, got %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSkipAPI(w, httptest.NewRequest("GET", "/api/skips/2025-03-15-pountney-road-sw11-5tu.ics", nil))
+	if body := w.Body.String(); !strings.Contains(body, "UID:2025-03-15-pountney-road-sw11-5tu@wheremegaskip.com\r\n") {
+		t.Errorf("Expected a per-location UID, got:\n%s", body)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "URL:https://wheremegaskip.com/skip/2025-03-15-pountney-road-sw11-5tu\r\n") {
+		t.Errorf("Expected a link to the skip's page, got:\n%s", body)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSkipAPI(w, httptest.NewRequest("GET", "/api/skips/2025-03-15-nowhere", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown ID, got %d", w.Code)
+	}
+}
+
+func TestHandleSkipPage(t *testing.T) {
+	locations := []SkipLocation{{
+		Address:   "Pountney Road",
+		Postcode:  "SW11 5TU",
+		Date:      time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+		Latitude:  51.4655,
+		Longitude: -0.1612,
+		Type:      EventMegaSkip,
+	}}
+	assignIDs(locations)
+	useTestSkips(t, locations)
+
+	w := httptest.NewRecorder()
+	HandleSkipPage(w, httptest.NewRequest("GET", "/skip/2025-03-15-pountney-road-sw11-5tu", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, `data-skip-id="2025-03-15-pountney-road-sw11-5tu"`) {
+		t.Fatalf("Expected the page opened on the skip, got %d", w.Code)
+	}
+	if !strings.Contains(body, `<link rel="canonical" href="https://wheremegaskip.com/skip/2025-03-15-pountney-road-sw11-5tu">`) {
+		t.Error("Expected the skip page to give its canonical URL")
+	}
+
+	r := httptest.NewRequest("GET", "/skip/2025-03-15-pountney-road-sw11-5tu", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	HandleSkipPage(w, r)
+	var got SkipLocation
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil || got.Address != "Pountney Road" {
+		t.Errorf("Expected the skip as JSON, got %+v: %v", got, err)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSkipPage(w, httptest.NewRequest("GET", "/skip/2025-03-15-nowhere", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown ID, got %d", w.Code)
+	}
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// snapshotCacheKey holds the last successful scrape. It is kept for a
+	// year, which in practice means until it is replaced.
+	snapshotCacheKey = "skip_locations_snapshot"
+	// snapshotTimeKey holds when the snapshot was taken
+	snapshotTimeKey = "skip_locations_snapshot_at"
+	snapshotTTL     = 365 * 24 * time.Hour
+
+	// snapshotRetryInterval is how long a snapshot is served before the
+	// council website is tried again, so a failing site isn't hit (and
+	// waited on) by every request
+	snapshotRetryInterval = 5 * time.Minute
+)
+
+var (
+	// snapshotPath is an optional file the snapshot is also written to,
+	// set from SNAPSHOT_PATH
+	snapshotPath string
+
+	// snapshotServed is when the snapshot being served was taken, or nil
+	// while serving live data
+	snapshotServed atomic.Pointer[time.Time]
+)
+
+// snapshotFile is the on-disk form of a snapshot
+type snapshotFile struct {
+	TakenAt   time.Time      `json:"takenAt"`
+	Locations []SkipLocation `json:"locations"`
+}
+
+// saveSnapshot records locations as the last known good data
+func saveSnapshot(ctx context.Context, locations []SkipLocation, takenAt time.Time) {
+	if err := activeCache.Set(ctx, snapshotCacheKey, locations, snapshotTTL); err != nil {
+		slog.WarnContext(ctx, "Snapshot cache set error", "err", err)
+	} else if err := setCachedValue(ctx, snapshotTimeKey, takenAt, snapshotTTL); err != nil {
+		slog.WarnContext(ctx, "Snapshot cache set error", "err", err)
+	}
+
+	if snapshotPath != "" {
+		if err := writeSnapshotFile(snapshotPath, snapshotFile{TakenAt: takenAt, Locations: locations}); err != nil {
+			slog.WarnContext(ctx, "Snapshot write error", "err", err)
+		}
+	}
+}
+
+// loadSnapshot returns the last known good data from the cache, falling back
+// to the snapshot file
+func loadSnapshot(ctx context.Context) ([]SkipLocation, time.Time, bool) {
+	locations, err := activeCache.Get(ctx, snapshotCacheKey)
+	if err != nil {
+		slog.WarnContext(ctx, "Snapshot cache get error", "err", err)
+	}
+	if locations != nil {
+		return locations, snapshotTakenAt(ctx), true
+	}
+
+	if snapshotPath == "" {
+		return nil, time.Time{}, false
+	}
+	snapshot, err := readSnapshotFile(snapshotPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.WarnContext(ctx, "Snapshot read error", "err", err)
+		}
+		return nil, time.Time{}, false
+	}
+	return snapshot.Locations, snapshot.TakenAt, true
+}
+
+// snapshotTakenAt returns when the cached snapshot was taken, or the zero
+// time if that isn't known
+func snapshotTakenAt(ctx context.Context) time.Time {
+	var takenAt time.Time
+	if _, err := getCachedValue(ctx, snapshotTimeKey, &takenAt); err != nil {
+		slog.WarnContext(ctx, "Snapshot cache get error", "err", err)
+	}
+	return takenAt
+}
+
+// serveSnapshot falls back to the last known good data after a failed
+// refresh, and caches it briefly so the next retry waits a while
+func serveSnapshot(ctx context.Context, refreshErr error) ([]SkipLocation, error) {
+	locations, takenAt, ok := loadSnapshot(ctx)
+	if !ok {
+		return nil, refreshErr
+	}
+	slog.WarnContext(ctx, "Serving snapshot after refresh failed", "takenAt", takenAt, "err", refreshErr)
+
+	// Pick up coordinates geocoded since the snapshot was taken
+	applyCoordinates(locations, cachedCoordinates(ctx))
+
+	cacheMu.Lock()
+	err := setSkipLocations(ctx, locations, time.Now().Add(snapshotRetryInterval))
+	cacheMu.Unlock()
+	if err != nil {
+		slog.WarnContext(ctx, "Cache set error", "err", err)
+	}
+
+	snapshotServed.Store(&takenAt)
+	invalidateDerived()
+	return locations, nil
+}
+
+// servingSnapshot reports whether the data being served is a snapshot, and
+// when it was taken
+func servingSnapshot() (time.Time, bool) {
+	takenAt := snapshotServed.Load()
+	if takenAt == nil {
+		return time.Time{}, false
+	}
+	return *takenAt, true
+}
+
+// setSnapshotHeaders flags a response built from snapshot data as stale
+func setSnapshotHeaders(w http.ResponseWriter) {
+	takenAt, ok := servingSnapshot()
+	if !ok {
+		return
+	}
+	w.Header().Set("X-Data-Stale", "true")
+	if !takenAt.IsZero() {
+		w.Header().Set("X-Data-Snapshot-Time", takenAt.UTC().Format(time.RFC3339))
+	}
+}
+
+// writeSnapshotFile writes the snapshot atomically, so a crash mid-write
+// never leaves a truncated file behind
+func writeSnapshotFile(path string, snapshot snapshotFile) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("marshaling snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readSnapshotFile reads a snapshot written by writeSnapshotFile
+func readSnapshotFile(path string) (snapshotFile, error) {
+	var snapshot snapshotFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("parsing snapshot: %w", err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServeSnapshotFallsBackToLastGoodData(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() {
+		activeCache = previous
+		snapshotServed.Store(nil)
+	}()
+
+	ctx := context.Background()
+	takenAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	saveSnapshot(ctx, []SkipLocation{{Address: "Good Road", Postcode: "SW18 1AA"}}, takenAt)
+
+	got, err := serveSnapshot(ctx, errors.New("council website down"))
+	if err != nil {
+		t.Fatalf("serveSnapshot: %v", err)
+	}
+	if len(got) != 1 || got[0].Address != "Good Road" {
+		t.Errorf("Expected the snapshot, got %+v", got)
+	}
+
+	if cached, _ := activeCache.Get(ctx, cacheKey); len(cached) != 1 {
+		t.Errorf("Expected the snapshot to be cached until the next retry, got %+v", cached)
+	}
+
+	w := httptest.NewRecorder()
+	setSnapshotHeaders(w)
+	if w.Header().Get("X-Data-Stale") != "true" {
+		t.Error("Expected X-Data-Stale header")
+	}
+	if got := w.Header().Get("X-Data-Snapshot-Time"); got != "2025-03-01T12:00:00Z" {
+		t.Errorf("X-Data-Snapshot-Time = %q", got)
+	}
+}
+
+func TestServeSnapshotWithoutSnapshotReturnsError(t *testing.T) {
+	previous, previousPath := activeCache, snapshotPath
+	activeCache, snapshotPath = NewMemoryCache(), ""
+	defer func() { activeCache, snapshotPath = previous, previousPath }()
+
+	refreshErr := errors.New("council website down")
+	if _, err := serveSnapshot(context.Background(), refreshErr); err != refreshErr {
+		t.Errorf("Expected the refresh error, got %v", err)
+	}
+	if _, ok := servingSnapshot(); ok {
+		t.Error("Should not be serving a snapshot")
+	}
+}
+
+func TestSnapshotFileRoundTrip(t *testing.T) {
+	previous, previousPath := activeCache, snapshotPath
+	activeCache = NewMemoryCache()
+	snapshotPath = filepath.Join(t.TempDir(), "snapshot.json")
+	defer func() { activeCache, snapshotPath = previous, previousPath }()
+
+	ctx := context.Background()
+	takenAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	saveSnapshot(ctx, []SkipLocation{{Address: "Disk Road"}}, takenAt)
+
+	// A cold start with an empty cache still finds the file
+	activeCache = NewMemoryCache()
+	got, gotTime, ok := loadSnapshot(ctx)
+	if !ok || len(got) != 1 || got[0].Address != "Disk Road" || !gotTime.Equal(takenAt) {
+		t.Errorf("loadSnapshot = %+v, %v, %v", got, gotTime, ok)
+	}
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// kmPerDegree is the length of one degree of latitude in kilometers
+const kmPerDegree = 6371 * math.Pi / 180
+
+// skipIndex answers nearest-skip queries per date and event type. It is
+// built once per refresh so lookups don't recompute distances to every skip
+// per request.
+type skipIndex struct {
+	// dates lists every skip date in order, including dates with no
+	// geocoded locations
+	dates []time.Time
+	// types lists the event types on each date, in display order
+	types map[time.Time][]EventType
+	trees map[skipIndexKey]*kdTree
+	// cancelled marks the dates and types whose every skip is cancelled
+	cancelled map[skipIndexKey]bool
+}
+
+type skipIndexKey struct {
+	date time.Time
+	typ  EventType
+}
+
+// boroughSkipIndexes are spatial indexes over each borough's skip
+// locations, by borough ID, so postcodes only find skips run by their own
+// council
+var boroughSkipIndexes = newDerived(func(locations []SkipLocation) (map[string]*skipIndex, error) {
+	indexes := make(map[string]*skipIndex, len(boroughs))
+	for _, b := range boroughs {
+		indexes[b.ID] = newSkipIndex(filterByBorough(locations, []string{b.ID}))
+	}
+	return indexes, nil
+})
+
+// skipIndexFor returns the spatial index over the skips in borough
+func skipIndexFor(ctx context.Context, borough *Borough) (*skipIndex, error) {
+	indexes, err := boroughSkipIndexes.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return indexes[borough.ID], nil
+}
+
+// newSkipIndex builds a k-d tree of geocoded locations for each date and type
+func newSkipIndex(locations []SkipLocation) *skipIndex {
+	idx := &skipIndex{
+		types:     make(map[time.Time][]EventType),
+		trees:     make(map[skipIndexKey]*kdTree),
+		cancelled: make(map[skipIndexKey]bool),
+	}
+
+	for date, skips := range groupSkipsByDate(locations) {
+		idx.dates = append(idx.dates, date)
+
+		geocoded := make(map[EventType][]SkipLocation)
+		present := make(map[EventType]bool)
+		scheduled := make(map[EventType]bool)
+		for _, skip := range skips {
+			present[skip.eventType()] = true
+			if skip.cancelled() {
+				continue
+			}
+			scheduled[skip.eventType()] = true
+			if skip.hasCoordinates() {
+				geocoded[skip.eventType()] = append(geocoded[skip.eventType()], skip)
+			}
+		}
+
+		for _, t := range eventTypes {
+			if present[t] {
+				idx.types[date] = append(idx.types[date], t)
+				idx.cancelled[skipIndexKey{date, t}] = !scheduled[t]
+			}
+			if len(geocoded[t]) > 0 {
+				idx.trees[skipIndexKey{date, t}] = newKDTree(geocoded[t])
+			}
+		}
+	}
+
+	sort.Slice(idx.dates, func(i, j int) bool {
+		return idx.dates[i].Before(idx.dates[j])
+	})
+	return idx
+}
+
+// nearest returns the closest geocoded skip of type typ on date that is
+// going ahead, or nil if there is none
+func (idx *skipIndex) nearest(date time.Time, typ EventType, lat, lng float64) *SkipLocation {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	tree, ok := idx.trees[skipIndexKey{day, typ}]
+	if !ok {
+		return nil
+	}
+	return tree.nearest(lat, lng)
+}
+
+// nearestN returns up to n of the closest geocoded skips of type typ on
+// date that are going ahead, nearest first
+func (idx *skipIndex) nearestN(date time.Time, typ EventType, lat, lng float64, n int) []SkipLocation {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	tree, ok := idx.trees[skipIndexKey{day, typ}]
+	if !ok {
+		return nil
+	}
+	return tree.nearestN(lat, lng, n)
+}
+
+// isCancelled reports whether every skip of type typ on date is cancelled
+func (idx *skipIndex) isCancelled(date time.Time, typ EventType) bool {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return idx.cancelled[skipIndexKey{day, typ}]
+}
+
+// kdTree is a 2-d tree over skip locations. Points are projected onto a
+// local plane in kilometers, which is accurate to well under a meter at
+// borough scale.
+type kdTree struct {
+	root   *kdNode
+	cosLat float64
+}
+
+type kdNode struct {
+	skip        SkipLocation
+	point       [2]float64
+	axis        int
+	left, right *kdNode
+}
+
+// newKDTree builds a balanced tree over skips, which must be non-empty
+func newKDTree(skips []SkipLocation) *kdTree {
+	var sumLat float64
+	for _, skip := range skips {
+		sumLat += skip.Latitude
+	}
+	t := &kdTree{cosLat: math.Cos(sumLat / float64(len(skips)) * math.Pi / 180)}
+
+	nodes := make([]*kdNode, len(skips))
+	for i, skip := range skips {
+		nodes[i] = &kdNode{skip: skip, point: t.project(skip.Latitude, skip.Longitude)}
+	}
+	t.root = buildKDNode(nodes, 0)
+	return t
+}
+
+// project converts lat/lng to planar kilometers
+func (t *kdTree) project(lat, lng float64) [2]float64 {
+	return [2]float64{lng * kmPerDegree * t.cosLat, lat * kmPerDegree}
+}
+
+func buildKDNode(nodes []*kdNode, depth int) *kdNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	axis := depth % 2
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].point[axis] < nodes[j].point[axis]
+	})
+
+	mid := len(nodes) / 2
+	node := nodes[mid]
+	node.axis = axis
+	node.left = buildKDNode(nodes[:mid], depth+1)
+	node.right = buildKDNode(nodes[mid+1:], depth+1)
+	return node
+}
+
+// nearest returns the skip closest to lat/lng
+func (t *kdTree) nearest(lat, lng float64) *SkipLocation {
+	target := t.project(lat, lng)
+	best, _ := searchKDNode(t.root, target, nil, math.MaxFloat64)
+	if best == nil {
+		return nil
+	}
+	skip := best.skip
+	return &skip
+}
+
+func searchKDNode(node *kdNode, target [2]float64, best *kdNode, bestDist float64) (*kdNode, float64) {
+	if node == nil {
+		return best, bestDist
+	}
+
+	dx := node.point[0] - target[0]
+	dy := node.point[1] - target[1]
+	if dist := dx*dx + dy*dy; dist < bestDist {
+		best, bestDist = node, dist
+	}
+
+	diff := target[node.axis] - node.point[node.axis]
+	near, far := node.left, node.right
+	if diff > 0 {
+		near, far = far, near
+	}
+
+	best, bestDist = searchKDNode(near, target, best, bestDist)
+	// Only cross the splitting plane if it's closer than the best so far
+	if diff*diff < bestDist {
+		best, bestDist = searchKDNode(far, target, best, bestDist)
+	}
+	return best, bestDist
+}
+
+// kdMatch is a node found by a k-nearest search, with its squared distance
+type kdMatch struct {
+	node *kdNode
+	dist float64
+}
+
+// nearestN returns up to n skips closest to lat/lng, nearest first
+func (t *kdTree) nearestN(lat, lng float64, n int) []SkipLocation {
+	target := t.project(lat, lng)
+	var found []kdMatch
+	searchKDNodeN(t.root, target, n, &found)
+
+	skips := make([]SkipLocation, len(found))
+	for i, m := range found {
+		skips[i] = m.node.skip
+	}
+	return skips
+}
+
+// searchKDNodeN keeps found sorted by distance and at most n long
+func searchKDNodeN(node *kdNode, target [2]float64, n int, found *[]kdMatch) {
+	if node == nil {
+		return
+	}
+
+	dx := node.point[0] - target[0]
+	dy := node.point[1] - target[1]
+	if dist := dx*dx + dy*dy; len(*found) < n || dist < (*found)[len(*found)-1].dist {
+		i := sort.Search(len(*found), func(i int) bool { return (*found)[i].dist > dist })
+		*found = append(*found, kdMatch{})
+		copy((*found)[i+1:], (*found)[i:])
+		(*found)[i] = kdMatch{node, dist}
+		if len(*found) > n {
+			*found = (*found)[:n]
+		}
+	}
+
+	diff := target[node.axis] - node.point[node.axis]
+	near, far := node.left, node.right
+	if diff > 0 {
+		near, far = far, near
+	}
+
+	searchKDNodeN(near, target, n, found)
+	// As in searchKDNode, but against the furthest of the n found so far
+	if len(*found) < n || diff*diff < (*found)[len(*found)-1].dist {
+		searchKDNodeN(far, target, n, found)
+	}
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSkipIndexNearestMatchesLinearScan(t *testing.T) {
+	date := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(1))
+
+	// Scatter skips across roughly the extent of Wandsworth
+	var skips []SkipLocation
+	for i := 0; i < 200; i++ {
+		skips = append(skips, SkipLocation{
+			Address:   string(rune('A' + i%26)),
+			Postcode:  "SW11 1AA",
+			Date:      date,
+			Latitude:  51.42 + rng.Float64()*0.06,
+			Longitude: -0.25 + rng.Float64()*0.12,
+		})
+	}
+
+	idx := newSkipIndex(skips)
+
+	for i := 0; i < 500; i++ {
+		lat := 51.40 + rng.Float64()*0.10
+		lng := -0.28 + rng.Float64()*0.18
+
+		want := findNearestSkipForDate(skips, date, lat, lng)
+		got := idx.nearest(date, EventMegaSkip, lat, lng)
+		if got == nil {
+			t.Fatalf("Expected a nearest skip for %v,%v", lat, lng)
+		}
+
+		gotDist := haversineDistance(lat, lng, got.Latitude, got.Longitude)
+		wantDist := haversineDistance(lat, lng, want.Latitude, want.Longitude)
+		if gotDist-wantDist > 0.001 {
+			t.Errorf("nearest(%v, %v) is %.4fkm away, linear scan found %.4fkm", lat, lng, gotDist, wantDist)
+		}
+	}
+}
+
+func TestSkipIndexDates(t *testing.T) {
+	date1 := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	date2 := time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC)
+
+	skips := []SkipLocation{
+		{Address: "Not geocoded", Postcode: "SW11 1CC", Date: date2},
+		{Address: "Geocoded", Postcode: "SW11 1AA", Date: date1, Latitude: 51.457, Longitude: -0.191},
+	}
+
+	idx := newSkipIndex(skips)
+
+	if len(idx.dates) != 2 || !idx.dates[0].Equal(date1) || !idx.dates[1].Equal(date2) {
+		t.Errorf("Expected both dates in order, got %v", idx.dates)
+	}
+
+	if nearest := idx.nearest(date1, EventMegaSkip, 51.4567, -0.1910); nearest == nil || nearest.Address != "Geocoded" {
+		t.Errorf("Expected geocoded skip on date1, got %+v", nearest)
+	}
+
+	if nearest := idx.nearest(date2, EventMegaSkip, 51.4567, -0.1910); nearest != nil {
+		t.Errorf("Expected no nearest skip when none are geocoded, got %+v", nearest)
+	}
+}
+
+func TestSkipIndexNearestNMatchesLinearScan(t *testing.T) {
+	date := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(2))
+
+	var skips []SkipLocation
+	for i := 0; i < 200; i++ {
+		skips = append(skips, SkipLocation{
+			Address:   string(rune('A' + i%26)),
+			Postcode:  "SW11 1AA",
+			Date:      date,
+			Latitude:  51.42 + rng.Float64()*0.06,
+			Longitude: -0.25 + rng.Float64()*0.12,
+		})
+	}
+
+	idx := newSkipIndex(skips)
+
+	for i := 0; i < 200; i++ {
+		lat := 51.40 + rng.Float64()*0.10
+		lng := -0.28 + rng.Float64()*0.18
+
+		distances := make([]float64, len(skips))
+		for j, skip := range skips {
+			distances[j] = haversineDistance(lat, lng, skip.Latitude, skip.Longitude)
+		}
+		sort.Float64s(distances)
+
+		got := idx.nearestN(date, EventMegaSkip, lat, lng, 5)
+		if len(got) != 5 {
+			t.Fatalf("Expected 5 skips, got %d", len(got))
+		}
+		for j, skip := range got {
+			if d := haversineDistance(lat, lng, skip.Latitude, skip.Longitude); d-distances[j] > 0.001 {
+				t.Errorf("nearestN(%v, %v)[%d] is %.4fkm away, linear scan found %.4fkm", lat, lng, j, d, distances[j])
+			}
+		}
+	}
+
+	if got := idx.nearestN(date, EventMegaSkip, 51.45, -0.19, 500); len(got) != len(skips) {
+		t.Errorf("Expected every skip when asking for more than there are, got %d", len(got))
+	}
+}
@@ -0,0 +1,811 @@
+// The page lists the skips itself; the map and controls only work with
+// JavaScript, so they stay hidden until it runs
+document.documentElement.classList.remove('no-js');
+
+let skipLocations = [];
+let map, userMarker, markers = [];
+let userLocation = null;
+let nearestSkipIndex = null;
+let geocodedSkips = [];
+let routeLine = null;
+let selectedDate = null;
+let selectedType = null;
+
+const eventTypeLabels = {
+    'megaskip': 'Mega Skip',
+    'small-electricals': 'Small Electricals Collection',
+    'christmas-trees': 'Christmas Tree Collection',
+    'pop-up-recycling': 'Pop-up Recycling',
+    'community-skip': 'Community Skip'
+};
+
+async function fetchSkipData(retryCount = 0) {
+    try {
+        const response = await fetch('/api/skips');
+        if (!response.ok) throw new Error('Failed to fetch');
+        showStaleNotice(response.headers);
+        return await response.json();
+    } catch (err) {
+        if (retryCount < 2) {
+            return fetchSkipData(retryCount + 1);
+        }
+        throw err;
+    }
+}
+
+// The server falls back to its last successful scrape when the council
+// website can't be reached, and says so in the response headers
+function showStaleNotice(headers) {
+    if (headers.get('X-Data-Stale') !== 'true') return;
+
+    let message = 'The council website could not be reached, so this data may be out of date.';
+    const takenAt = headers.get('X-Data-Snapshot-Time');
+    if (takenAt) {
+        message += ' Last updated ' + new Date(takenAt).toLocaleString('en-GB', {
+            weekday: 'long', day: 'numeric', month: 'long', hour: '2-digit', minute: '2-digit'
+        }) + '.';
+    }
+
+    const notice = document.getElementById('stale-notice');
+    notice.textContent = message;
+    notice.hidden = false;
+}
+
+function showError(message) {
+    const container = document.getElementById('skip-items');
+    container.innerHTML = '<div class="error">' + escapeHtml(message) + '</div>';
+    document.getElementById('date-tabs').innerHTML = '<div class="error">Failed to load dates</div>';
+    document.getElementById('map-loading').classList.add('hidden');
+}
+
+// Show what can and can't be brought, from the first skip that lists it
+function renderItems() {
+    const skip = skipLocations.find(s => (s.acceptedItems || []).length || (s.prohibitedItems || []).length);
+    if (!skip) return;
+
+    renderItemList('accepted-items', skip.acceptedItems);
+    renderItemList('prohibited-items', skip.prohibitedItems);
+    document.getElementById('items-info').hidden = false;
+}
+
+function renderItemList(id, items) {
+    if (!items || items.length === 0) return;
+
+    const section = document.getElementById(id);
+    section.querySelector('ul').innerHTML = items.map(item => '<li>' + escapeHtml(item) + '</li>').join('');
+    section.hidden = false;
+}
+
+// Locations cached before event types were added are mega skips
+function skipType(skip) {
+    return skip.type || 'megaskip';
+}
+
+function matchesSelectedType(skip) {
+    return selectedType === null || skipType(skip) === selectedType;
+}
+
+// Label shown next to skips that aren't mega skips
+function eventTypeBadge(skip) {
+    const type = skipType(skip);
+    if (type === 'megaskip') return '';
+    return '<p class="event-type">♻️ ' + escapeHtml(eventTypeLabels[type] || type) + '</p>';
+}
+
+// Skips the council has cancelled or postponed have a status
+function statusBadge(skip) {
+    if (!skip.status) return '';
+    return '<p class="skip-status">⚠️ ' + escapeHtml(skip.status === 'postponed' ? 'Postponed' : 'Cancelled') + '</p>';
+}
+
+function getTypedSkips() {
+    return geocodedSkips.filter(matchesSelectedType);
+}
+
+function getUniqueDates() {
+    const seen = new Set();
+    return getTypedSkips().filter(s => {
+        if (seen.has(s.dateStr)) return false;
+        seen.add(s.dateStr);
+        return true;
+    }).sort((a, b) => a.date.localeCompare(b.date)).map(s => s.dateStr);
+}
+
+function getSkipsForDate(dateStr) {
+    const skips = getTypedSkips();
+    if (!dateStr) return skips;
+    return skips.filter(s => s.dateStr === dateStr);
+}
+
+function formatShortDate(dateStr) {
+    const parts = dateStr.split(' ');
+    if (parts.length >= 3) {
+        return parts[0].substring(0, 3) + ' ' + parts[1] + ' ' + parts[2].substring(0, 3);
+    }
+    return dateStr;
+}
+
+function renderDateTabs() {
+    const container = document.getElementById('date-tabs');
+    const dates = getUniqueDates();
+
+    let html = '';
+    dates.forEach(function(dateStr, index) {
+        const isActive = selectedDate === dateStr;
+        html += '<button class="date-tab' + (isActive ? ' active' : '') +
+                '" data-date-index="' + index + '">' +
+                escapeHtml(formatShortDate(dateStr)) + '</button>';
+    });
+
+    html += '<button class="date-tab' + (selectedDate === null ? ' active' : '') +
+            '" data-date-index="-1">All Dates</button>';
+
+    container.innerHTML = html;
+
+    // Add click handlers
+    container.querySelectorAll('.date-tab').forEach(function(btn) {
+        btn.addEventListener('click', function() {
+            const index = parseInt(this.getAttribute('data-date-index'));
+            if (index === -1) {
+                selectDate(null);
+            } else {
+                selectDate(dates[index]);
+            }
+        });
+    });
+}
+
+// Offer a type filter only when there is more than one kind of collection
+function renderTypeFilter() {
+    const select = document.getElementById('type-filter');
+    const types = Object.keys(eventTypeLabels).filter(type => geocodedSkips.some(s => skipType(s) === type));
+    select.hidden = types.length < 2;
+    if (select.hidden) return;
+
+    select.innerHTML = '<option value="">All collections</option>' + types.map(type =>
+        '<option value="' + type + '"' + (type === selectedType ? ' selected' : '') + '>' +
+        escapeHtml(eventTypeLabels[type]) + '</option>').join('');
+}
+
+function selectType(type) {
+    selectedType = type || null;
+    if (selectedDate !== null && !getUniqueDates().includes(selectedDate)) {
+        selectedDate = null;
+    }
+    selectDate(selectedDate);
+}
+
+function selectDate(dateStr) {
+    selectedDate = dateStr;
+    renderDateTabs();
+    updateMarkersForDate();
+    renderSkipList();
+    if (userLocation) {
+        updateWithUserLocation();
+    }
+}
+
+function updateMarkersForDate() {
+    markers.forEach(function(marker, index) {
+        const skip = geocodedSkips[index];
+        const isVisible = (selectedDate === null || skip.dateStr === selectedDate) && matchesSelectedType(skip);
+
+        if (isVisible) {
+            if (!map.hasLayer(marker)) {
+                marker.addTo(map);
+            }
+        } else {
+            if (map.hasLayer(marker)) {
+                map.removeLayer(marker);
+            }
+        }
+    });
+
+    const visibleSkips = getSkipsForDate(selectedDate);
+    if (visibleSkips.length > 0) {
+        const bounds = L.latLngBounds(visibleSkips.map(s => [s.lat, s.lng]));
+        if (userLocation) {
+            bounds.extend([userLocation.lat, userLocation.lng]);
+        }
+        map.fitBounds(bounds, { padding: [50, 50] });
+    }
+}
+
+// Read a theme color set by the page from the tenant config
+function themeColor(name) {
+    return getComputedStyle(document.documentElement).getPropertyValue(name).trim();
+}
+
+// Initialize map centered on the tenant's borough
+async function initMap() {
+    const mapEl = document.getElementById('map');
+    map = L.map('map').setView([parseFloat(mapEl.dataset.lat), parseFloat(mapEl.dataset.lng)], 13);
+    L.tileLayer('https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png', {
+        attribution: '© OpenStreetMap contributors',
+        maxZoom: 19
+    }).addTo(map);
+
+    // Fetch skip data from API
+    try {
+        skipLocations = await fetchSkipData();
+        renderInviteDates();
+        renderItems();
+        // Geocode all skips then add markers
+        geocodeAllSkips();
+    } catch (err) {
+        console.error('Failed to fetch skip data:', err);
+        showError('Failed to load skip locations. Please refresh the page to try again.');
+    }
+}
+
+async function geocodeAllSkips() {
+    showLoading();
+    disableControls();
+
+    // Check if server already geocoded the locations
+    const needsGeocoding = skipLocations.filter(skip => !skip.lat || !skip.lng);
+    const alreadyGeocoded = skipLocations.filter(skip => skip.lat && skip.lng);
+
+    // Show pre-geocoded skips straight away
+    alreadyGeocoded.forEach(skip => geocodedSkips.push(skip));
+
+    // Set default to first (soonest) date
+    const dates = getUniqueDates();
+    if (dates.length > 0) {
+        selectedDate = dates[0];
+    }
+
+    addSkipMarkers();
+    updateMarkersForDate();
+    renderDateTabs();
+    renderSkipList();
+    enableControls();
+    hideMapLoading();
+    focusLinkedSkip();
+    showServerNearest();
+
+    // Pick up coordinates the server is still resolving in the background
+    if (needsGeocoding.length > 0) {
+        await pollServerCoordinates(needsGeocoding);
+    }
+}
+
+// Server-side geocodes are keyed by address and postcode
+function geocodeKey(skip) {
+    return skip.address + ', ' + skip.postcode;
+}
+
+async function pollServerCoordinates(pending) {
+    for (let attempt = 0; attempt < 10 && pending.length > 0; attempt++) {
+        await new Promise(resolve => setTimeout(resolve, 2000));
+
+        let delta;
+        try {
+            const response = await fetch('/api/skips/geocodes');
+            if (!response.ok) break;
+            delta = await response.json();
+        } catch (err) {
+            break;
+        }
+
+        const resolved = pending.filter(skip => delta.coordinates[geocodeKey(skip)]);
+        resolved.forEach(function(skip) {
+            const coords = delta.coordinates[geocodeKey(skip)];
+            addGeocodedSkip({ ...skip, lat: coords.lat, lng: coords.lng });
+        });
+        if (resolved.length > 0) {
+            refreshSkipViews();
+        }
+
+        pending = pending.filter(skip => !delta.coordinates[geocodeKey(skip)]);
+        if (delta.pending.length === 0) break;
+    }
+    return pending;
+}
+
+function addGeocodedSkip(skip) {
+    geocodedSkips.push(skip);
+    addSkipMarker(skip);
+}
+
+function refreshSkipViews() {
+    renderTypeFilter();
+    renderDateTabs();
+    renderSkipList();
+    if (userLocation) {
+        updateWithUserLocation();
+    }
+}
+
+function hideMapLoading() {
+    document.getElementById('map-loading').classList.add('hidden');
+}
+
+function fitMapToSkips() {
+    if (geocodedSkips.length === 0) return;
+
+    // Create bounds that include all skip markers
+    const bounds = L.latLngBounds(geocodedSkips.map(skip => [skip.lat, skip.lng]));
+    map.fitBounds(bounds, { padding: [50, 50] });
+}
+
+function disableControls() {
+    document.getElementById('date-banner').classList.add('disabled');
+}
+
+function enableControls() {
+    document.getElementById('date-banner').classList.remove('disabled');
+}
+
+function showLoading() {
+    document.getElementById('skip-items').innerHTML = '<div class="loading">Loading...</div>';
+}
+
+function toTitleCase(str) {
+    return str.toLowerCase().split(' ').map(function(word) {
+        return word.charAt(0).toUpperCase() + word.slice(1);
+    }).join(' ');
+}
+
+// Format a "15:04" time as "3pm" or "3.30pm"
+function formatClock(hhmm) {
+    const parts = hhmm.split(':');
+    const hour = parseInt(parts[0], 10);
+    const minute = parts[1] || '00';
+    const suffix = hour < 12 ? 'am' : 'pm';
+    const h = hour % 12 === 0 ? 12 : hour % 12;
+    return h + (minute === '00' ? '' : '.' + minute) + suffix;
+}
+
+// Opening hours of a skip, defaulting to 9am to noon
+function skipHours(skip) {
+    if (!skip.opensAt || !skip.closesAt) return '9am - 12pm';
+    return formatClock(skip.opensAt) + ' - ' + formatClock(skip.closesAt);
+}
+
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+function renderSkipList() {
+    const container = document.getElementById('skip-items');
+    const skipsToShow = getSkipsForDate(selectedDate);
+
+    if (skipsToShow.length === 0) {
+        container.innerHTML = '<p class="empty-list">No skip locations for this date.</p>';
+        return;
+    }
+
+    let html = '';
+    const dates = getUniqueDates();
+
+    if (selectedDate === null && dates.length > 1) {
+        // Group by date when showing all
+        dates.forEach(function(dateStr) {
+            const skipsForDate = skipsToShow.filter(s => s.dateStr === dateStr);
+            if (skipsForDate.length === 0) return;
+
+            html += '<div class="date-group">';
+            html += '<div class="date-group-header">' + escapeHtml(dateStr) + '</div>';
+            html += '<div class="date-group-items">';
+
+            skipsForDate.forEach(function(skip) {
+                const index = geocodedSkips.indexOf(skip);
+                const isNearest = nearestSkipIndex === index;
+                html += '<div class="skip-item' + (isNearest ? ' nearest' : '') +
+                    '" data-skip-index="' + index + '" data-skip-id="' + escapeHtml(skip.id || '') + '">' +
+                    '<h4>' + (isNearest ? '🎯 ' : '📍 ') + escapeHtml(toTitleCase(skip.address)) + '</h4>' +
+                    '<p>📮 ' + escapeHtml(skip.postcode) + '</p>' +
+                    '<p>📅 ' + escapeHtml(skip.dateStr) + '</p>' +
+                    eventTypeBadge(skip) +
+                    statusBadge(skip) +
+                    '</div>';
+            });
+
+            html += '</div></div>';
+        });
+    } else {
+        // Single date view - still show header for consistency
+        html += '<div class="date-group">';
+        html += '<div class="date-group-header">' + escapeHtml(selectedDate) + '</div>';
+        html += '<div class="date-group-items">';
+        skipsToShow.forEach(function(skip) {
+            const index = geocodedSkips.indexOf(skip);
+            const isNearest = nearestSkipIndex === index;
+            html += '<div class="skip-item' + (isNearest ? ' nearest' : '') +
+                '" data-skip-index="' + index + '" data-skip-id="' + escapeHtml(skip.id || '') + '">' +
+                '<h4>' + (isNearest ? '🎯 ' : '📍 ') + escapeHtml(toTitleCase(skip.address)) + '</h4>' +
+                '<p>📮 ' + escapeHtml(skip.postcode) + '</p>' +
+                '<p>📅 ' + escapeHtml(skip.dateStr) + '</p>' +
+                eventTypeBadge(skip) +
+                statusBadge(skip) +
+                '</div>';
+        });
+        html += '</div></div>';
+    }
+
+    container.innerHTML = html;
+}
+
+function addSkipMarkers() {
+    geocodedSkips.forEach(addSkipMarker);
+}
+
+function addSkipMarker(skip) {
+    if (!skip.lat || !skip.lng) return; // Skip if not geocoded
+
+    const marker = L.marker([skip.lat, skip.lng], {
+        icon: L.icon({
+            iconUrl: 'data:image/svg+xml;base64,' + btoa('<svg xmlns="http://www.w3.org/2000/svg" width="30" height="40" viewBox="0 0 30 40"><path fill="' + themeColor('--primary') + '" d="M15 0C8.4 0 3 5.4 3 12c0 8.3 12 28 12 28s12-19.7 12-28c0-6.6-5.4-12-12-12z"/><circle cx="15" cy="12" r="5" fill="white"/></svg>'),
+            iconSize: [30, 40],
+            iconAnchor: [15, 40],
+            popupAnchor: [0, -40]
+        })
+    });
+
+    marker.bindPopup('<h4>' + escapeHtml(toTitleCase(skip.address)) + '</h4>' +
+        '<p><strong>📅 ' + skip.dateStr + '</strong></p>' +
+        '<p>🕘 Opens ' + skipHours(skip) + ' (or when full)</p>' +
+        '<p>📮 ' + skip.postcode + '</p>' +
+        eventTypeBadge(skip) +
+        statusBadge(skip) +
+        (skip.id ? '<p><a href="/api/skips/' + encodeURIComponent(skip.id) + '.ics">📆 Add to calendar</a></p>' : ''));
+
+    if ((selectedDate === null || skip.dateStr === selectedDate) && matchesSelectedType(skip)) {
+        marker.addTo(map);
+    }
+    marker.skipData = skip;
+    markers.push(marker);
+}
+
+function requestLocation() {
+    const btn = document.getElementById('useLocation');
+    btn.disabled = true;
+    btn.textContent = '⏳ Getting location...';
+
+    if (!navigator.geolocation) {
+        alert('Geolocation is not supported by your browser');
+        btn.disabled = false;
+        btn.innerHTML = '<span class="emoji">📍</span> Use My Location';
+        return;
+    }
+
+    navigator.geolocation.getCurrentPosition(
+        function(position) {
+            userLocation = {
+                lat: position.coords.latitude,
+                lng: position.coords.longitude
+            };
+            updateWithUserLocation();
+            btn.disabled = false;
+            btn.innerHTML = '<span class="emoji">✓</span> Location Set';
+        },
+        function(error) {
+            let message = 'Unable to get your location';
+            if (error.code === error.PERMISSION_DENIED) {
+                message = 'Location permission denied. Please enable location access or search by postcode.';
+            }
+            alert(message);
+            btn.disabled = false;
+            btn.innerHTML = '<span class="emoji">📍</span> Use My Location';
+        }
+    );
+}
+
+function updateWithUserLocation() {
+    // Add/update user marker
+    if (userMarker) {
+        map.removeLayer(userMarker);
+    }
+
+    userMarker = L.marker([userLocation.lat, userLocation.lng], {
+        icon: L.icon({
+            iconUrl: 'data:image/svg+xml;base64,' + btoa('<svg xmlns="http://www.w3.org/2000/svg" width="32" height="32" viewBox="0 0 32 32"><circle cx="16" cy="16" r="14" fill="' + themeColor('--accent') + '" stroke="white" stroke-width="4"/><circle cx="16" cy="16" r="6" fill="white"/></svg>'),
+            iconSize: [32, 32],
+            iconAnchor: [16, 16]
+        })
+    }).bindPopup('📍 You are here').addTo(map);
+
+    // Calculate distances and find nearest (respecting date filter)
+    let nearest = null;
+    let nearestDist = Infinity;
+    const skipsToConsider = getSkipsForDate(selectedDate);
+
+    skipsToConsider.forEach(function(skip) {
+        if (!skip.lat || !skip.lng || skip.status) return;
+        const dist = calculateDistance(userLocation.lat, userLocation.lng, skip.lat, skip.lng);
+        skip.distance = dist;
+        if (dist < nearestDist) {
+            nearestDist = dist;
+            nearest = skip;
+        }
+    });
+
+    if (nearest) {
+        showNearestSkip(nearest);
+
+        // Draw line from user to nearest skip
+        if (routeLine) {
+            map.removeLayer(routeLine);
+        }
+        routeLine = L.polyline([
+            [userLocation.lat, userLocation.lng],
+            [nearest.lat, nearest.lng]
+        ], {
+            color: themeColor('--accent'),
+            weight: 3,
+            opacity: 0.7,
+            dashArray: '10, 10'
+        }).addTo(map);
+
+        // Zoom to show both user and nearest skip
+        const bounds = L.latLngBounds([
+            [userLocation.lat, userLocation.lng],
+            [nearest.lat, nearest.lng]
+        ]);
+        map.fitBounds(bounds, { padding: [50, 50] });
+
+        // Highlight nearest marker
+        highlightNearest(nearest);
+    }
+}
+
+function highlightNearest(nearest) {
+    markers.forEach(function(marker) {
+        if (marker.skipData === nearest) {
+            marker.setIcon(L.icon({
+                iconUrl: 'data:image/svg+xml;base64,' + btoa('<svg xmlns="http://www.w3.org/2000/svg" width="36" height="48" viewBox="0 0 30 40"><path fill="' + themeColor('--accent') + '" d="M15 0C8.4 0 3 5.4 3 12c0 8.3 12 28 12 28s12-19.7 12-28c0-6.6-5.4-12-12-12z"/><circle cx="15" cy="12" r="5" fill="white"/></svg>'),
+                iconSize: [36, 48],
+                iconAnchor: [18, 48],
+                popupAnchor: [0, -48]
+            }));
+        }
+    });
+}
+
+function calculateDistance(lat1, lon1, lat2, lon2) {
+    // Haversine formula
+    const R = 6371; // km
+    const dLat = (lat2 - lat1) * Math.PI / 180;
+    const dLon = (lon2 - lon1) * Math.PI / 180;
+    const a = Math.sin(dLat/2) * Math.sin(dLat/2) +
+            Math.cos(lat1 * Math.PI / 180) * Math.cos(lat2 * Math.PI / 180) *
+            Math.sin(dLon/2) * Math.sin(dLon/2);
+    const c = 2 * Math.atan2(Math.sqrt(a), Math.sqrt(1-a));
+    return R * c;
+}
+
+function showNearestSkip(skip) {
+    // Find and store the index of the nearest skip
+    nearestSkipIndex = geocodedSkips.indexOf(skip);
+
+    // Show nearest info section
+    const nearestInfo = document.getElementById('nearest-info');
+    const nearestDetails = document.getElementById('nearest-details');
+
+    // Add click handler to nearest info
+    nearestInfo.onclick = function() {
+        if (nearestSkipIndex !== null) {
+            focusSkip(nearestSkipIndex);
+        }
+    };
+
+    nearestDetails.innerHTML =
+        '<div class="nearest-detail"><strong>📍 Location:</strong> ' + escapeHtml(toTitleCase(skip.address)) + '</div>' +
+        '<div class="nearest-detail"><strong>📮 Postcode:</strong> ' + escapeHtml(skip.postcode) + '</div>' +
+        '<div class="nearest-detail"><strong>📅 Available on:</strong> ' + escapeHtml(skip.dateStr) + '</div>';
+
+    nearestInfo.classList.add('visible');
+
+    // Re-render list with nearest highlighted
+    renderSkipList();
+
+    // Scroll to nearest info
+    nearestInfo.scrollIntoView({ behavior: 'smooth', block: 'nearest' });
+}
+
+// Links such as /skip/2025-03-15-pountney-road-sw11-5tu or
+// /#skip=2025-03-15-pountney-road-sw11-5tu open on that skip
+function focusLinkedSkip() {
+    let id = document.body.dataset.skipId;
+    if (!id) {
+        const match = location.hash.match(/^#skip=(.+)$/);
+        if (!match) return;
+        id = decodeURIComponent(match[1]);
+    }
+
+    const index = geocodedSkips.findIndex(skip => skip.id === id);
+    if (index === -1) return;
+
+    selectedDate = geocodedSkips[index].dateStr;
+    updateMarkersForDate();
+    renderDateTabs();
+    renderSkipList();
+    focusSkip(index);
+}
+
+// /?postcode= pages arrive with the nearest skip already found by the server
+function showServerNearest() {
+    const id = document.getElementById('nearest-info').dataset.skipId;
+    if (!id) return;
+
+    const skip = geocodedSkips.find(s => s.id === id);
+    if (!skip) return;
+
+    selectedDate = skip.dateStr;
+    updateMarkersForDate();
+    renderDateTabs();
+    showNearestSkip(skip);
+    highlightNearest(skip);
+    focusSkip(nearestSkipIndex);
+}
+
+function focusSkip(index) {
+    const skip = geocodedSkips[index];
+    const marker = markers[index];
+
+    if (skip && marker) {
+        // If user location exists, fit bounds to show both
+        if (userLocation) {
+            const bounds = L.latLngBounds(
+                [userLocation.lat, userLocation.lng],
+                [skip.lat, skip.lng]
+            );
+            map.fitBounds(bounds, {
+                padding: [50, 50],
+                animate: true,
+                duration: 0.5
+            });
+        } else {
+            // No user location, just pan to marker and zoom
+            map.setView([skip.lat, skip.lng], 15, {
+                animate: true,
+                duration: 0.5
+            });
+        }
+
+        // Open popup
+        marker.openPopup();
+    }
+}
+
+// Initialize on load
+initMap();
+
+// Set default calendar URL
+document.getElementById('default-calendar-url').value = window.location.origin + '/calendar.ics';
+
+// Wire up controls (inline handlers are blocked by the CSP)
+document.getElementById('useLocation').addEventListener('click', requestLocation);
+document.getElementById('copy-calendar-btn').addEventListener('click', copyDefaultCalendarUrl);
+document.getElementById('generate-calendar-btn').addEventListener('click', generatePostcodeCalendarUrl);
+document.getElementById('invite-form').addEventListener('submit', sendCalendarInvite);
+document.getElementById('type-filter').addEventListener('change', function() {
+    selectType(this.value);
+});
+
+// Focus a skip when its list item is clicked
+document.getElementById('skip-items').addEventListener('click', function(e) {
+    const item = e.target.closest('.skip-item');
+    if (item) {
+        focusSkip(parseInt(item.getAttribute('data-skip-index')));
+    }
+});
+
+// Allow Enter key in calendar postcode field
+document.getElementById('calendar-postcode').addEventListener('keypress', function(e) {
+    if (e.key === 'Enter') {
+        generatePostcodeCalendarUrl();
+    }
+});
+
+function copyDefaultCalendarUrl() {
+    var url = document.getElementById('default-calendar-url').value;
+    var btn = document.getElementById('copy-calendar-btn');
+    navigator.clipboard.writeText(url).then(function() {
+        var originalText = btn.textContent;
+        btn.textContent = 'Copied!';
+        btn.classList.add('copied');
+        setTimeout(function() {
+            btn.textContent = originalText;
+            btn.classList.remove('copied');
+        }, 2000);
+    }).catch(function() {
+        // Fallback for older browsers
+        var temp = document.createElement('input');
+        temp.value = url;
+        document.body.appendChild(temp);
+        temp.select();
+        document.execCommand('copy');
+        document.body.removeChild(temp);
+    });
+}
+
+function generatePostcodeCalendarUrl() {
+    var postcode = document.getElementById('calendar-postcode').value.trim();
+    if (!postcode) {
+        alert('Please enter a postcode');
+        return;
+    }
+    var url = window.location.origin + '/calendar/' + encodeURIComponent(postcode) + '.ics';
+    var btn = document.getElementById('generate-calendar-btn');
+
+    // Offer a QR code so the feed can be subscribed to on a phone
+    var qr = document.getElementById('calendar-qr');
+    qr.src = '/qr/calendar/' + encodeURIComponent(postcode) + '.png';
+    qr.hidden = false;
+
+    var subscribe = document.getElementById('calendar-subscribe-link');
+    subscribe.href = '/subscribe/' + encodeURIComponent(postcode);
+    subscribe.hidden = false;
+
+    navigator.clipboard.writeText(url).then(function() {
+        var originalText = btn.textContent;
+        btn.textContent = 'Copied!';
+        btn.classList.add('copied');
+        setTimeout(function() {
+            btn.textContent = originalText;
+            btn.classList.remove('copied');
+        }, 2000);
+    }).catch(function() {
+        // Fallback for older browsers
+        var temp = document.createElement('input');
+        temp.value = url;
+        document.body.appendChild(temp);
+        temp.select();
+        document.execCommand('copy');
+        document.body.removeChild(temp);
+    });
+}
+
+// Offer each upcoming skip day in the email invite form
+function renderInviteDates() {
+    const select = document.getElementById('invite-date');
+    const seen = new Set();
+    const options = skipLocations
+        .filter(s => skipType(s) === 'megaskip')
+        .filter(s => {
+            if (seen.has(s.dateStr)) return false;
+            seen.add(s.dateStr);
+            return true;
+        })
+        .sort((a, b) => a.date.localeCompare(b.date))
+        .map(s => '<option value="' + s.date.slice(0, 10) + '">' + escapeHtml(s.dateStr) + '</option>');
+    select.innerHTML = options.join('');
+}
+
+async function sendCalendarInvite(e) {
+    e.preventDefault();
+    const form = e.target;
+    const status = document.getElementById('invite-status');
+    const btn = document.getElementById('invite-btn');
+
+    btn.disabled = true;
+    status.classList.remove('error');
+    status.textContent = 'Sending...';
+    try {
+        const response = await fetch(form.action, {
+            method: 'POST',
+            body: new URLSearchParams(new FormData(form))
+        });
+        if (!response.ok) {
+            // Errors are application/problem+json; detail says what went wrong
+            const problem = await response.json();
+            status.classList.add('error');
+            status.textContent = problem.detail || problem.title;
+            return;
+        }
+        status.textContent = (await response.text()).trim();
+    } catch (err) {
+        status.classList.add('error');
+        status.textContent = 'Failed to send invite';
+    } finally {
+        btn.disabled = false;
+        // Turnstile responses are single use
+        if (window.turnstile) {
+            window.turnstile.reset();
+        }
+    }
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// streamKeepAlive is how often an idle change stream sends a comment, so
+// proxies don't close it
+var streamKeepAlive = 30 * time.Second
+
+// streamRetry is how long EventSource clients wait before reconnecting
+const streamRetry = 10 * time.Second
+
+// HandleSkipsStream handles GET /api/skips/stream, a Server-Sent Events
+// stream with an event for each skip a scrape adds, removes or cancels.
+// Each event is named after its change kind and carries a SkipChange.
+// Changes are found by polling the shared cache, so a stream hears about
+// scrapes made by every instance.
+func HandleSkipsStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	changes := newChangeWatcher(r.Context())
+
+	rc := http.NewResponseController(w)
+	// The stream outlives any server write timeout
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	poll := time.NewTicker(changePollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-poll.C:
+			for _, c := range changes.poll(r.Context(), time.Now()) {
+				data, err := json.Marshal(c)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", c.Kind, data)
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleSkipsStream(t *testing.T) {
+	previous, previousPoll := streamKeepAlive, changePollInterval
+	streamKeepAlive, changePollInterval = 20*time.Millisecond, 10*time.Millisecond
+	defer func() { streamKeepAlive, changePollInterval = previous, previousPoll }()
+	useTestSkips(t, []SkipLocation{})
+	ctx := context.Background()
+	saveSnapshot(ctx, []SkipLocation{}, time.Now().Add(-time.Hour))
+
+	server := httptest.NewServer(NewHandler())
+	defer server.Close()
+
+	res, err := server.Client().Get(server.URL + "/api/skips/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.Header.Get("Content-Type") != "text/event-stream" || res.Header.Get("Cache-Control") != "no-store" {
+		t.Fatalf("Unexpected headers %v", res.Header)
+	}
+
+	// The headers arrive once the stream has read the snapshot, so a scrape
+	// on any instance saving a new one is streamed
+	saveSnapshot(ctx, []SkipLocation{{ID: "a", Address: "Pountney Road", Date: startOfDay(time.Now()).AddDate(0, 0, 1)}}, time.Now())
+
+	lines := bufio.NewScanner(res.Body)
+	var event, data string
+	keepAlives := 0
+	for lines.Scan() && data == "" {
+		line := lines.Text()
+		switch {
+		case line == ": keep-alive":
+			keepAlives++
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	var change SkipChange
+	if err := json.Unmarshal([]byte(data), &change); err != nil {
+		t.Fatalf("Expected a JSON change, got %q: %v", data, err)
+	}
+	if event != ChangeAdded || change.Skip.Address != "Pountney Road" {
+		t.Errorf("Unexpected event %q %+v", event, change)
+	}
+
+	// Idle streams are kept alive
+	for keepAlives == 0 && lines.Scan() {
+		if lines.Text() == ": keep-alive" {
+			keepAlives++
+		}
+	}
+	if keepAlives == 0 {
+		t.Error("Expected a keep-alive comment")
+	}
+
+	w := httptest.NewRecorder()
+	HandleSkipsStream(w, httptest.NewRequest(http.MethodPost, "/api/skips/stream", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST to be refused, got %d", w.Code)
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// schemaEvent is a schema.org Event, embedded in the index page as JSON-LD
+// so search engines can show skip days in results
+type schemaEvent struct {
+	Context             string        `json:"@context"`
+	Type                string        `json:"@type"`
+	Name                string        `json:"name"`
+	Description         string        `json:"description,omitempty"`
+	StartDate           string        `json:"startDate"`
+	EndDate             string        `json:"endDate"`
+	EventStatus         string        `json:"eventStatus"`
+	EventAttendanceMode string        `json:"eventAttendanceMode"`
+	IsAccessibleForFree bool          `json:"isAccessibleForFree"`
+	URL                 string        `json:"url"`
+	Location            []schemaPlace `json:"location"`
+	Organizer           schemaOrg     `json:"organizer"`
+}
+
+type schemaPlace struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Address schemaAddress `json:"address"`
+	Geo     *schemaGeo    `json:"geo,omitempty"`
+}
+
+type schemaAddress struct {
+	Type           string `json:"@type"`
+	StreetAddress  string `json:"streetAddress"`
+	PostalCode     string `json:"postalCode"`
+	AddressCountry string `json:"addressCountry"`
+}
+
+type schemaGeo struct {
+	Type      string  `json:"@type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type schemaOrg struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// skipDayEvents describes each upcoming skip day as an Event at its
+// locations, from the earliest opening to the latest closing
+func skipDayEvents(locations []SkipLocation, now time.Time) []schemaEvent {
+	tenant := currentTenant()
+
+	var events []schemaEvent
+	for _, day := range upcomingSkipDays(locations, now) {
+		status, skips := "https://schema.org/EventScheduled", scheduledSkips(day.Skips)
+		if allCancelled(day.Skips) {
+			status, skips = "https://schema.org/EventCancelled", day.Skips
+		}
+		opens, closes := dayHours(skips)
+
+		event := schemaEvent{
+			Context:             "https://schema.org",
+			Type:                "Event",
+			Name:                tenant.EventTitle + " day",
+			Description:         itemsDescription(skipItems(skips)),
+			StartDate:           atClock(day.Date, opens).Format(time.RFC3339),
+			EndDate:             atClock(day.Date, closes).Format(time.RFC3339),
+			EventStatus:         status,
+			EventAttendanceMode: "https://schema.org/OfflineEventAttendanceMode",
+			IsAccessibleForFree: true,
+			URL:                 strings.TrimSuffix(tenant.SiteURL, "/") + "/",
+			Organizer:           schemaOrg{Type: "Organization", Name: tenant.CouncilName, URL: tenant.CouncilURL},
+		}
+		for _, skip := range skips {
+			place := schemaPlace{
+				Type: "Place",
+				Name: skip.Address,
+				Address: schemaAddress{
+					Type:           "PostalAddress",
+					StreetAddress:  skip.Address,
+					PostalCode:     skip.Postcode,
+					AddressCountry: "GB",
+				},
+			}
+			if skip.hasCoordinates() {
+				place.Geo = &schemaGeo{Type: "GeoCoordinates", Latitude: skip.Latitude, Longitude: skip.Longitude}
+			}
+			event.Location = append(event.Location, place)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// indexStructuredData returns the Events for the index page, or none if
+// the skips can't be loaded, as the page works without them
+func indexStructuredData(ctx context.Context) []schemaEvent {
+	locations, err := getSkipLocations(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Rendering index without structured data", "err", err)
+		return nil
+	}
+	return skipDayEvents(locations, time.Now())
+}
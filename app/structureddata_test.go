@@ -0,0 +1,67 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSkipDayEvents(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	events := skipDayEvents([]SkipLocation{
+		{Address: "Old Road", Postcode: "SW11 1AA", Date: time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)},
+		{Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612, Date: time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)},
+		{Address: "Lindsay Court", Postcode: "SW11 3HZ", Date: time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC), Status: StatusCancelled},
+		{Address: "Tooting Road", Postcode: "SW17 0AA", Date: time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC), Status: StatusCancelled},
+	}, now)
+
+	if len(events) != 2 {
+		t.Fatalf("Expected an event per upcoming skip day, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.Type != "Event" || event.EventStatus != "https://schema.org/EventScheduled" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	// London is on summer time by April
+	if event.StartDate != "2026-04-04T09:00:00+01:00" {
+		t.Errorf("Unexpected start %s", event.StartDate)
+	}
+	if len(event.Location) != 1 || event.Location[0].Address.PostalCode != "SW11 5TU" || event.Location[0].Geo == nil {
+		t.Errorf("Expected only the skip going ahead as the location, got %+v", event.Location)
+	}
+
+	if events[1].EventStatus != "https://schema.org/EventCancelled" || len(events[1].Location) != 1 {
+		t.Errorf("Expected a called-off day to be cancelled at its locations, got %+v", events[1])
+	}
+}
+
+func TestIndexEmbedsStructuredData(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{
+		Address: "Pountney Road <b>", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: startOfDay(time.Now()).AddDate(0, 0, 7), Type: EventMegaSkip,
+	}})
+
+	rec := httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/", nil))
+	page := rec.Body.String()
+
+	_, block, ok := strings.Cut(page, `type="application/ld+json">`)
+	if !ok {
+		t.Fatal("Expected a JSON-LD block in the index page")
+	}
+	block, _, _ = strings.Cut(block, "</script>")
+
+	var events []schemaEvent
+	if err := json.Unmarshal([]byte(block), &events); err != nil {
+		t.Fatalf("JSON-LD isn't valid JSON: %v\n%s", err, block)
+	}
+	if len(events) != 1 || events[0].Location[0].Address.StreetAddress != "Pountney Road <b>" {
+		t.Errorf("Unexpected events %+v", events)
+	}
+	if strings.Contains(block, "<b>") {
+		t.Error("Expected markup in the data to be escaped")
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// calendarFeedURL is the canonical address of a calendar feed: the default
+// feed without a postcode, and otherwise the postcode's feed with the
+// postcode written out in full. Only the query parameters that change the
+// feed are kept: ?types=, ?alarm= and ?allday=, with ?borough= on the
+// default feed and ?nearest= on postcode feeds.
+func calendarFeedURL(postcode string, query url.Values) string {
+	u, err := url.Parse(currentTenant().SiteURL)
+	if err != nil {
+		u = &url.URL{}
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if postcode == "" {
+		u.Path += "/calendar.ics"
+	} else {
+		u.Path += "/calendar/" + canonicalPostcode(postcode) + ".ics"
+	}
+
+	kept := url.Values{}
+	if types := query.Get("types"); types != "" {
+		kept.Set("types", types)
+	}
+	if borough := query.Get("borough"); borough != "" && postcode == "" {
+		kept.Set("borough", borough)
+	}
+	if nearest := query.Get("nearest"); nearest != "" && nearest != "1" && postcode != "" {
+		kept.Set("nearest", nearest)
+	}
+	if alarms := query["alarm"]; len(alarms) > 0 {
+		kept["alarm"] = alarms
+	}
+	if allDay := query.Get("allday"); allDay != "" {
+		kept.Set("allday", allDay)
+	}
+	u.RawQuery = kept.Encode()
+	return u.String()
+}
+
+// webcalURL is the webcal:// address of a postcode's calendar feed, which
+// phones open in their calendar app to subscribe
+func webcalURL(postcode string, query url.Values) string {
+	u, _ := url.Parse(calendarFeedURL(postcode, query))
+	u.Scheme = "webcal"
+	return u.String()
+}
+
+// setCanonicalLink points a calendar response at its canonical feed URL, so
+// clients that subscribed through a variant of it can tell they're the same
+func setCanonicalLink(w http.ResponseWriter, postcode string, query url.Values) {
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="canonical"`, calendarFeedURL(postcode, query)))
+}
+
+// opensWebcal reports whether a browser hands webcal:// links to a calendar
+// app. Apple's platforms do; elsewhere the link often goes nowhere.
+func opensWebcal(userAgent string) bool {
+	for _, platform := range []string{"iPhone", "iPad", "Macintosh"} {
+		if strings.Contains(userAgent, platform) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribePage is what the subscribe instructions need
+type subscribePage struct {
+	Title   string
+	FeedURL string
+	// Webcal is trusted, as html/template would otherwise filter out the
+	// webcal: scheme
+	Webcal  template.URL
+	Google  string
+	Outlook string
+}
+
+// subscribeTemplate explains how to subscribe in the common calendar apps
+var subscribeTemplate = mustParsePage("subscribe.html")
+
+// HandleSubscribe handles requests to /subscribe/{postcode}, sending Apple
+// devices straight to the webcal:// feed and showing everyone else how to
+// subscribe. Without a postcode it subscribes to the default feed. It takes
+// the feed's ?types= filter and ?alarm= reminders too.
+func HandleSubscribe(w http.ResponseWriter, r *http.Request) {
+	postcode, err := url.QueryUnescape(strings.TrimPrefix(r.URL.Path, "/subscribe/"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "Invalid postcode encoding")
+		return
+	}
+	postcode = strings.ToUpper(strings.TrimSpace(postcode))
+	if postcode != "" && !postcodePattern.MatchString(postcode) && !isOutcode(postcode) {
+		writeProblem(w, http.StatusBadRequest, "Invalid postcode format")
+		return
+	}
+
+	query := url.Values{}
+	if types := r.URL.Query().Get("types"); types != "" {
+		if _, err := parseEventTypes(types); err != nil {
+			writeProblem(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		query.Set("types", types)
+	}
+	alarms, err := parseAlarms(r.URL.Query()["alarm"])
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query["alarm"] = alarms
+
+	webcal := webcalURL(postcode, query)
+	w.Header().Set("Vary", "User-Agent")
+	pageCaching.apply(w)
+	if opensWebcal(r.UserAgent()) {
+		http.Redirect(w, r, webcal, http.StatusFound)
+		return
+	}
+
+	tenant := currentTenant()
+	title := tenant.SiteTitle
+	if postcode != "" {
+		title += " near " + canonicalPostcode(postcode)
+	}
+	page := subscribePage{
+		Title:   title,
+		FeedURL: calendarFeedURL(postcode, query),
+		Webcal:  template.URL(webcal),
+		Google:  "https://calendar.google.com/calendar/render?" + url.Values{"cid": {webcal}}.Encode(),
+		Outlook: "https://" + outlookLiveHost + "/calendar/0/addfromweb?" + url.Values{"url": {calendarFeedURL(postcode, query)}, "name": {title}}.Encode(),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := subscribeTemplate.Execute(w, page); err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
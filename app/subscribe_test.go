@@ -0,0 +1,62 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCalendarFeedURL(t *testing.T) {
+	tests := []struct {
+		postcode string
+		query    url.Values
+		want     string
+	}{
+		{"", nil, "https://wheremegaskip.com/calendar.ics"},
+		{"", url.Values{"borough": {"lambeth"}, "utm_source": {"x"}}, "https://wheremegaskip.com/calendar.ics?borough=lambeth"},
+		{"sw115tu", nil, "https://wheremegaskip.com/calendar/SW11%205TU.ics"},
+		{"SW11 5TU", url.Values{"types": {"megaskip"}, "borough": {"lambeth"}}, "https://wheremegaskip.com/calendar/SW11%205TU.ics?types=megaskip"},
+		{"sw17", nil, "https://wheremegaskip.com/calendar/SW17.ics"},
+		{"SW17", url.Values{"nearest": {"3"}}, "https://wheremegaskip.com/calendar/SW17.ics?nearest=3"},
+		{"", url.Values{"nearest": {"3"}}, "https://wheremegaskip.com/calendar.ics"},
+		{"SW17", url.Values{"alarm": {"P1D", "PT2H"}}, "https://wheremegaskip.com/calendar/SW17.ics?alarm=P1D&alarm=PT2H"},
+	}
+	for _, tt := range tests {
+		if got := calendarFeedURL(tt.postcode, tt.query); got != tt.want {
+			t.Errorf("calendarFeedURL(%q, %v) = %q, want %q", tt.postcode, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHandleSubscribe(t *testing.T) {
+	iPhone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15"
+	android := "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+
+	r := httptest.NewRequest(http.MethodGet, "/subscribe/sw115tu?types=megaskip", nil)
+	r.Header.Set("User-Agent", iPhone)
+	w := httptest.NewRecorder()
+	HandleSubscribe(w, r)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "webcal://wheremegaskip.com/calendar/SW11%205TU.ics?types=megaskip" {
+		t.Errorf("Expected a redirect to the webcal feed, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/subscribe/SW11%205TU", nil)
+	r.Header.Set("User-Agent", android)
+	w = httptest.NewRecorder()
+	HandleSubscribe(w, r)
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, `href="webcal://wheremegaskip.com/calendar/SW11%205TU.ics"`) {
+		t.Errorf("Expected instructions with a webcal link, got %d %s", w.Code, body)
+	}
+	if !strings.Contains(body, "calendar.google.com") || w.Header().Get("Vary") != "User-Agent" {
+		t.Errorf("Expected Google Calendar instructions varying by client, got %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSubscribe(w, httptest.NewRequest(http.MethodGet, "/subscribe/not-a-postcode", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid postcode, got %d", w.Code)
+	}
+}
@@ -0,0 +1,309 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// teamsAnnouncedKey prefixes the cache keys of the skips already announced
+// to Teams, so each new date is only posted once. Each webhook has its own,
+// under teamsAnnouncedKeyFor, so one failing doesn't hold the others back.
+// They need a shared cache (Redis) to survive serverless cold starts.
+const teamsAnnouncedKey = "teams_announced"
+
+// teamsAnnouncedTTL keeps announced dates for longer than the council
+// publishes ahead
+const teamsAnnouncedTTL = 180 * 24 * time.Hour
+
+// teamsMessage is an incoming webhook message carrying an Adaptive Card
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string        `json:"$schema"`
+	Type    string        `json:"type"`
+	Version string        `json:"version"`
+	Body    []cardElement `json:"body"`
+	Actions []cardAction  `json:"actions,omitempty"`
+}
+
+type cardElement struct {
+	Type   string     `json:"type"`
+	Text   string     `json:"text,omitempty"`
+	Size   string     `json:"size,omitempty"`
+	Weight string     `json:"weight,omitempty"`
+	Wrap   bool       `json:"wrap,omitempty"`
+	Facts  []cardFact `json:"facts,omitempty"`
+}
+
+type cardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type cardAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// TeamsNotifyResult reports what a notification run posted
+type TeamsNotifyResult struct {
+	Reminder    bool     `json:"reminder"`
+	NewDates    []string `json:"newDates"`
+	WebhookURLs int      `json:"webhooks"`
+	// Failed counts the webhooks that couldn't be posted to; they are
+	// tried again next time
+	Failed int `json:"failed"`
+}
+
+// newAdaptiveCard wraps body in a card linking back to the site
+func newAdaptiveCard(body ...cardElement) adaptiveCard {
+	return adaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    body,
+		Actions: []cardAction{
+			{Type: "Action.OpenUrl", Title: "View map", URL: currentTenant().SiteURL},
+		},
+	}
+}
+
+// scheduleUpdateCard announces newly published skip days
+func scheduleUpdateCard(days []skipDay) adaptiveCard {
+	facts := make([]cardFact, 0, len(days))
+	for _, day := range days {
+		var counts []string
+		for _, typ := range typesOf(day.Skips) {
+			n := len(filterByType(day.Skips, []EventType{typ}))
+			counts = append(counts, fmt.Sprintf("%s: %d locations", typ.label(), n))
+		}
+		facts = append(facts, cardFact{
+			Title: formatSkipDate(day.Date),
+			Value: strings.Join(counts, "; "),
+		})
+	}
+
+	return newAdaptiveCard(
+		cardElement{Type: "TextBlock", Text: fmt.Sprintf("New %s days announced", currentTenant().EventTitle), Size: "Large", Weight: "Bolder", Wrap: true},
+		cardElement{Type: "FactSet", Facts: facts},
+	)
+}
+
+// reminderCard lists every location of one event type for tomorrow
+func reminderCard(typ EventType, day skipDay) adaptiveCard {
+	facts := make([]cardFact, 0, len(day.Skips))
+	for _, skip := range day.Skips {
+		facts = append(facts, cardFact{Title: skip.Address, Value: skip.Postcode})
+	}
+
+	return newAdaptiveCard(
+		cardElement{Type: "TextBlock", Text: fmt.Sprintf("%s tomorrow", eventTitle(typ)), Size: "Large", Weight: "Bolder", Wrap: true},
+		cardElement{Type: "TextBlock", Text: fmt.Sprintf("%s, %s", formatSkipDate(day.Date), formatHours(dayHours(day.Skips))), Wrap: true},
+		cardElement{Type: "FactSet", Facts: facts},
+	)
+}
+
+// unannouncedDays returns upcoming skip days in current with no skips in
+// announced, in date order
+func unannouncedDays(current, announced []SkipLocation, now time.Time) []skipDay {
+	seen := groupSkipsByDate(announced)
+	today := startOfDay(now)
+
+	var days []skipDay
+	for date, skips := range groupSkipsByDate(current) {
+		if _, ok := seen[date]; ok || date.Before(today) {
+			continue
+		}
+		days = append(days, skipDay{Date: date, Skips: skips})
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days
+}
+
+// teamsAnnouncedKeyFor is the cache key of the skips announced to one
+// webhook. URLs are hashed, as they carry the webhook's secret.
+func teamsAnnouncedKeyFor(webhookURL string) string {
+	sum := sha256.Sum256([]byte(webhookURL))
+	return fmt.Sprintf("%s_%x", teamsAnnouncedKey, sum[:8])
+}
+
+// teamsAnnounced returns the skips already announced to a webhook
+func teamsAnnounced(ctx context.Context, webhookURL string) []SkipLocation {
+	announced, err := activeCache.Get(ctx, teamsAnnouncedKeyFor(webhookURL))
+	if err != nil {
+		slog.WarnContext(ctx, "Cache get error", "err", err)
+	}
+	return announced
+}
+
+// teamsWebhookURLs returns the configured incoming webhook URLs
+func teamsWebhookURLs() ([]string, error) {
+	value, err := getSecret("TEAMS_WEBHOOK_URLS")
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, u := range strings.Split(value, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls, nil
+}
+
+// postTeamsCard sends card to a Teams incoming webhook
+func postTeamsCard(ctx context.Context, webhookURL string, card adaptiveCard) error {
+	body, err := json.Marshal(teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{
+			{ContentType: "application/vnd.microsoft.card.adaptive", Content: card},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("teams webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// cronAuthorized reports whether r carries the CRON_SECRET bearer token that
+// Vercel Cron sends. Requests are refused when no secret is configured.
+func cronAuthorized(r *http.Request) bool {
+	return bearerAuthorized(r, "CRON_SECRET")
+}
+
+// HandleTeamsNotify handles scheduled requests to /api/notify/teams, posting
+// newly announced skip days and a reminder on the day before a skip day
+func HandleTeamsNotify(w http.ResponseWriter, r *http.Request) {
+	if !cronAuthorized(r) {
+		writeProblem(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	urls, err := teamsWebhookURLs()
+	if err != nil {
+		slog.WarnContext(r.Context(), "Failed to load Teams webhooks", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to load Teams webhooks")
+		return
+	}
+	if len(urls) == 0 {
+		writeProblem(w, http.StatusServiceUnavailable, "Teams notifications are not configured")
+		return
+	}
+
+	// TEAMS_EVENT_TYPES limits notifications to some event types
+	types, err := parseEventTypes(os.Getenv("TEAMS_EVENT_TYPES"))
+	if err != nil {
+		slog.WarnContext(r.Context(), "Invalid TEAMS_EVENT_TYPES", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Invalid Teams event types")
+		return
+	}
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+	locations = filterByType(locations, types)
+
+	ctx := r.Context()
+	now := time.Now()
+	result := TeamsNotifyResult{NewDates: []string{}, WebhookURLs: len(urls)}
+
+	var reminders []adaptiveCard
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+	skips := groupSkipsByDate(scheduledSkips(locations))[tomorrow]
+	for _, typ := range typesOf(skips) {
+		day := skipDay{Date: tomorrow, Skips: filterByType(skips, []EventType{typ})}
+		reminders = append(reminders, reminderCard(typ, day))
+		result.Reminder = true
+	}
+
+	// Post to every webhook, keeping track of what each has been told
+	newDates := make(map[string]bool)
+	for i, u := range urls {
+		cards := reminders
+		days := unannouncedDays(locations, teamsAnnounced(ctx, u), now)
+		if len(days) > 0 {
+			cards = append(cards[:len(cards):len(cards)], scheduleUpdateCard(days))
+		}
+
+		if err := postTeamsCards(ctx, u, cards); err != nil {
+			slog.WarnContext(ctx, "Failed to post to Teams webhook", "webhook", i, "err", err)
+			result.Failed++
+			continue
+		}
+
+		for _, day := range days {
+			newDates[day.Date.Format("2006-01-02")] = true
+		}
+		if len(days) > 0 {
+			if err := activeCache.Set(ctx, teamsAnnouncedKeyFor(u), locations, teamsAnnouncedTTL); err != nil {
+				slog.WarnContext(ctx, "Cache set error", "err", err)
+			}
+		}
+	}
+	for date := range newDates {
+		result.NewDates = append(result.NewDates, date)
+	}
+	sort.Strings(result.NewDates)
+
+	if result.Failed == len(urls) {
+		writeProblem(w, http.StatusBadGateway, "Failed to post to Teams")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if result.Failed > 0 {
+		w.WriteHeader(http.StatusMultiStatus)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// postTeamsCards posts each card to a webhook, stopping at the first failure
+func postTeamsCards(ctx context.Context, webhookURL string, cards []adaptiveCard) error {
+	for _, card := range cards {
+		if err := postTeamsCard(ctx, webhookURL, card); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUnannouncedDays(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 0, 0, 0, 0, time.UTC) }
+	current := []SkipLocation{
+		{Postcode: "SW18 1AA", Date: day(4)},
+		{Postcode: "SW18 1AB", Date: day(18)},
+		{Postcode: "SW18 1AC", Date: day(25)},
+		{Postcode: "SW18 1AD", Date: day(25)},
+		{Postcode: "SW18 1AE", Date: day(21)},
+	}
+	announced := []SkipLocation{{Postcode: "SW18 1AB", Date: day(18)}}
+
+	days := unannouncedDays(current, announced, day(10))
+	if len(days) != 2 {
+		t.Fatalf("Got %d new days, want 2", len(days))
+	}
+	if !days[0].Date.Equal(day(21)) || !days[1].Date.Equal(day(25)) || len(days[1].Skips) != 2 {
+		t.Errorf("Unexpected new days: %+v", days)
+	}
+}
+
+func TestPostTeamsCard(t *testing.T) {
+	var got teamsMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Invalid webhook body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	day := skipDay{
+		Date:  time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+		Skips: []SkipLocation{{Address: "Garratt Lane", Postcode: "SW18 4AA"}},
+	}
+	if err := postTeamsCard(context.Background(), server.URL, reminderCard(EventMegaSkip, day)); err != nil {
+		t.Fatalf("postTeamsCard: %v", err)
+	}
+
+	if len(got.Attachments) != 1 || got.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("Expected one Adaptive Card attachment, got %+v", got)
+	}
+	card := got.Attachments[0].Content
+	if card.Type != "AdaptiveCard" || len(card.Body) != 3 {
+		t.Errorf("Unexpected card: %+v", card)
+	}
+	if facts := card.Body[2].Facts; len(facts) != 1 || facts[0].Title != "Garratt Lane" {
+		t.Errorf("Reminder should list each location, got %+v", facts)
+	}
+}
+
+func TestCronAuthorized(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/notify/teams", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+
+	t.Setenv("CRON_SECRET", "")
+	if cronAuthorized(req) {
+		t.Error("Requests should be refused when no secret is configured")
+	}
+
+	t.Setenv("CRON_SECRET", "s3cret")
+	if !cronAuthorized(req) {
+		t.Error("Request with the right token should be authorized")
+	}
+
+	req.Header.Set("Authorization", "Bearer wrong")
+	if cronAuthorized(req) {
+		t.Error("Request with the wrong token should be refused")
+	}
+}
+
+func TestHandleTeamsNotifyPartialFailure(t *testing.T) {
+	useTestSkips(t, []SkipLocation{{Address: "Garratt Lane", Postcode: "SW18 4AA", Type: EventMegaSkip, Date: startOfDay(time.Now()).AddDate(0, 0, 10)}})
+	t.Setenv("CRON_SECRET", "cron")
+	t.Setenv("TEAMS_EVENT_TYPES", "")
+
+	posts := map[string]int{}
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts[r.URL.Path]++
+		if r.URL.Path == "/down" && failing {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("TEAMS_WEBHOOK_URLS", server.URL+"/down,"+server.URL+"/up")
+
+	notify := func() (int, TeamsNotifyResult) {
+		req := httptest.NewRequest(http.MethodPost, "/api/notify/teams", nil)
+		req.Header.Set("Authorization", "Bearer cron")
+		rec := httptest.NewRecorder()
+		HandleTeamsNotify(rec, req)
+		var result TeamsNotifyResult
+		json.NewDecoder(rec.Body).Decode(&result)
+		return rec.Code, result
+	}
+
+	code, result := notify()
+	if code != http.StatusMultiStatus || result.Failed != 1 || len(result.NewDates) != 1 {
+		t.Fatalf("Got %d %+v, want 207 with one failure and one new date", code, result)
+	}
+	if posts["/up"] != 1 {
+		t.Errorf("The working webhook should still be posted to, got %d posts", posts["/up"])
+	}
+
+	// The failed webhook is told next time; the working one isn't told again
+	failing = false
+	if code, result := notify(); code != http.StatusOK || result.Failed != 0 {
+		t.Fatalf("Got %d %+v, want 200", code, result)
+	}
+	if posts["/down"] != 2 || posts["/up"] != 1 {
+		t.Errorf("Expected only the failed webhook to be posted to again, got %v", posts)
+	}
+}
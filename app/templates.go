@@ -0,0 +1,52 @@
+package app
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+//go:embed index.html subscribe.html docs.html lite.html
+var templateFiles embed.FS
+
+// templateDir is set by TEMPLATE_DIR to reload the pages from disk on every
+// render, so edits to them show up without rebuilding. It is for local
+// development; the embedded copies are used otherwise.
+var templateDir string
+
+// pageFuncs are the helpers available to every page
+var pageFuncs = template.FuncMap{
+	"titleCase": titleCase,
+}
+
+// pageTemplate is an HTML page embedded in the binary and parsed once
+type pageTemplate struct {
+	name string
+	tmpl *template.Template
+}
+
+// mustParsePage parses an embedded page, panicking if it is invalid
+func mustParsePage(name string) *pageTemplate {
+	return &pageTemplate{
+		name: name,
+		tmpl: template.Must(template.New(name).Funcs(pageFuncs).ParseFS(templateFiles, name)),
+	}
+}
+
+// Execute renders the page, re-reading it from templateDir if that is set
+func (p *pageTemplate) Execute(w io.Writer, data any) error {
+	tmpl := p.tmpl
+	if templateDir != "" {
+		src, err := os.ReadFile(filepath.Join(templateDir, p.name))
+		if err != nil {
+			return fmt.Errorf("reading template %s: %w", p.name, err)
+		}
+		if tmpl, err = template.New(p.name).Funcs(pageFuncs).Parse(string(src)); err != nil {
+			return fmt.Errorf("parsing template %s: %w", p.name, err)
+		}
+	}
+	return tmpl.Execute(w, data)
+}
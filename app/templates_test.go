@@ -0,0 +1,42 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPageTemplateEmbedded(t *testing.T) {
+	var sb strings.Builder
+	if err := subscribeTemplate.Execute(&sb, subscribePage{Title: "Megaskips"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(sb.String(), "<h1>Subscribe to Megaskips</h1>") {
+		t.Errorf("Expected the embedded page, got:\n%s", sb.String())
+	}
+}
+
+func TestPageTemplateReloadsFromDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "subscribe.html"), []byte("<p>{{.Title}} from disk</p>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templateDir = dir
+	t.Cleanup(func() { templateDir = "" })
+
+	var sb strings.Builder
+	if err := subscribeTemplate.Execute(&sb, subscribePage{Title: "Megaskips"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if sb.String() != "<p>Megaskips from disk</p>" {
+		t.Errorf("Expected the page from disk, got %q", sb.String())
+	}
+
+	if err := os.Remove(filepath.Join(dir, "subscribe.html")); err != nil {
+		t.Fatal(err)
+	}
+	if err := subscribeTemplate.Execute(&sb, subscribePage{}); err == nil {
+		t.Error("Expected an error when the page is missing from disk")
+	}
+}
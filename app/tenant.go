@@ -0,0 +1,96 @@
+package app
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Tenant holds the site copy and theme for one deployment, so variants for
+// other councils can be run from the same code without forking templates
+type Tenant struct {
+	SiteTitle       string      `json:"siteTitle"`
+	Subtitle        string      `json:"subtitle"`
+	Description     string      `json:"description"`
+	CouncilName     string      `json:"councilName"`
+	CouncilLinkText string      `json:"councilLinkText"`
+	CouncilURL      string      `json:"councilUrl"`
+	FooterText      string      `json:"footerText"`
+	EventTitle      string      `json:"eventTitle"`
+	SiteURL         string      `json:"siteUrl"`
+	FileSlug        string      `json:"fileSlug"`
+	MapCenter       [2]float64  `json:"mapCenter"`
+	Colors          TenantTheme `json:"colors"`
+}
+
+// TenantTheme holds the colors used by the page and map markers
+type TenantTheme struct {
+	Primary      string `json:"primary"`
+	PrimaryLight string `json:"primaryLight"`
+	PrimaryDark  string `json:"primaryDark"`
+	Accent       string `json:"accent"`
+}
+
+// defaultTenant is the original Wandsworth site
+var defaultTenant = Tenant{
+	SiteTitle:       "Where Mega Skip?",
+	Subtitle:        "Find your nearest Wandsworth Mega Skip",
+	Description:     "Find your nearest Wandsworth Mega Skip location with live map",
+	CouncilName:     "Wandsworth Council",
+	CouncilLinkText: "Wandsworth Council Mega Skip Days",
+	CouncilURL:      defaultCouncilURL,
+	FooterText:      "This page is provided on a best-effort basis to help make it easier to find your nearest Mega Skip. This page is not affiliated with Wandsworth Council in any way.",
+	EventTitle:      "Wandsworth Mega Skip",
+	SiteURL:         "https://wheremegaskip.com",
+	FileSlug:        "wandsworth-megaskip",
+	MapCenter:       [2]float64{51.4567, -0.1910},
+	Colors: TenantTheme{
+		Primary:      "#0074A2",
+		PrimaryLight: "#00A1C9",
+		PrimaryDark:  "#005580",
+		Accent:       "#FF7043",
+	},
+}
+
+var (
+	tenant     Tenant
+	tenantOnce sync.Once
+)
+
+// currentTenant returns the tenant for this deployment. TENANT_CONFIG may
+// name a JSON file whose fields override the Wandsworth defaults.
+func currentTenant() Tenant {
+	tenantOnce.Do(func() {
+		tenant = defaultTenant
+
+		path := os.Getenv("TENANT_CONFIG")
+		if path == "" {
+			return
+		}
+
+		t, err := loadTenant(path)
+		if err != nil {
+			slog.Warn("Failed to load tenant config, using defaults", "err", err)
+			return
+		}
+		tenant = t
+		slog.Info("Using tenant config", "council", tenant.CouncilName)
+	})
+	return tenant
+}
+
+// loadTenant reads a tenant from a JSON file, keeping defaults for any
+// fields it leaves out
+func loadTenant(path string) (Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Tenant{}, err
+	}
+
+	t := defaultTenant
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Tenant{}, err
+	}
+	return t, nil
+}
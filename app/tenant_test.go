@@ -0,0 +1,65 @@
+package app
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTenantOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenant.json")
+	config := `{"siteTitle": "Where Big Bin?", "councilName": "Lambeth Council", "colors": {"primary": "#123456"}}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tenant, err := loadTenant(path)
+	if err != nil {
+		t.Fatalf("loadTenant: %v", err)
+	}
+
+	if tenant.SiteTitle != "Where Big Bin?" || tenant.CouncilName != "Lambeth Council" {
+		t.Errorf("Overrides not applied: %+v", tenant)
+	}
+	if tenant.Colors.Primary != "#123456" {
+		t.Errorf("Primary color = %q, want #123456", tenant.Colors.Primary)
+	}
+	if tenant.Colors.Accent != defaultTenant.Colors.Accent || tenant.EventTitle != defaultTenant.EventTitle {
+		t.Errorf("Fields missing from the config should keep their defaults: %+v", tenant)
+	}
+}
+
+func TestLoadTenantRejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenant.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadTenant(path); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+	if _, err := loadTenant(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestIndexUsesTenantCopy(t *testing.T) {
+	useTestSkips(t, []SkipLocation{})
+	rec := httptest.NewRecorder()
+	HandleIndex(rec, httptest.NewRequest("GET", "/", nil))
+
+	page := rec.Body.String()
+	tenant := currentTenant()
+	for _, want := range []string{
+		"<title>" + tenant.SiteTitle + "</title>",
+		tenant.Subtitle,
+		"--primary: " + tenant.Colors.Primary,
+		`href="` + tenant.CouncilURL + `"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Index should contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// textFormat is a plain-text response flavour, chosen by file extension
+type textFormat struct {
+	ext         string
+	contentType string
+}
+
+var (
+	formatText     = textFormat{".txt", "text/plain; charset=utf-8"}
+	formatMarkdown = textFormat{".md", "text/markdown; charset=utf-8"}
+)
+
+// textFormatFor returns the format for a path ending in .txt or .md and the
+// path with the extension removed
+func textFormatFor(path string) (textFormat, string, bool) {
+	for _, f := range []textFormat{formatText, formatMarkdown} {
+		if strings.HasSuffix(path, f.ext) {
+			return f, strings.TrimSuffix(path, f.ext), true
+		}
+	}
+	return textFormat{}, "", false
+}
+
+// HandleNextText handles requests to /next.txt and /next.md
+func HandleNextText(w http.ResponseWriter, r *http.Request) {
+	format, _, _ := textFormatFor(r.URL.Path)
+
+	day, err := queryNextSkipDay(r.Context(), time.Now())
+	if err != nil {
+		writeTextError(w, r, err)
+		return
+	}
+
+	writeText(w, r, format, func(buf io.Writer) error {
+		if format == formatMarkdown {
+			return writeNextSkipDayMarkdown(buf, day)
+		}
+		_, err := fmt.Fprintln(buf, describeNextSkipDay(day))
+		return err
+	})
+}
+
+// HandleNearestText handles requests to /nearest/{postcode}.txt and .md
+func HandleNearestText(w http.ResponseWriter, r *http.Request) {
+	format, path, ok := textFormatFor(r.URL.Path)
+	if !ok {
+		writeTextProblem(w, r, http.StatusNotFound, "Not found")
+		return
+	}
+
+	postcode, err := url.PathUnescape(strings.TrimPrefix(path, "/nearest/"))
+	if err != nil {
+		writeTextProblem(w, r, http.StatusBadRequest, "Invalid postcode encoding")
+		return
+	}
+
+	nearest, err := queryNearestSkip(r.Context(), postcode, time.Now())
+	if err != nil {
+		writeTextError(w, r, err)
+		return
+	}
+
+	writeText(w, r, format, func(buf io.Writer) error {
+		if format == formatMarkdown {
+			return writeNearestSkipMarkdown(buf, nearest)
+		}
+		_, err := fmt.Fprintln(buf, describeNearestSkip(nearest))
+		return err
+	})
+}
+
+// writeText renders a plain-text or markdown answer
+func writeText(w http.ResponseWriter, r *http.Request, format textFormat, render func(io.Writer) error) {
+	w.Header().Set("Content-Type", format.contentType)
+	if err := writeRendered(w, render); err != nil {
+		slog.ErrorContext(r.Context(), "Error writing text response", "err", err)
+		writeTextProblem(w, r, http.StatusInternalServerError, "Failed to generate response")
+	}
+}
+
+// writeTextError answers a failed query with a one-line explanation
+func writeTextError(w http.ResponseWriter, r *http.Request, err error) {
+	status := queryErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "Error answering text query", "err", err)
+	}
+	writeTextProblem(w, r, status, describeQueryError(err))
+}
+
+// writeTextProblem answers a plain-text endpoint's error with a one-line
+// text/plain body, so curl and Shortcuts show something readable, unless
+// the client asks for application/problem+json
+func writeTextProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	if acceptQuality(r.Header.Get("Accept"), problemContentType) > acceptQuality(r.Header.Get("Accept"), "text/plain") {
+		writeProblem(w, status, detail)
+		return
+	}
+
+	h := w.Header()
+	for _, name := range []string{"Content-Length", "Content-Encoding", "ETag", "Last-Modified"} {
+		h.Del(name)
+	}
+	h.Set("Content-Type", formatText.contentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Cache-Control", "no-store")
+	h.Del("CDN-Cache-Control")
+
+	w.WriteHeader(status)
+	fmt.Fprintln(w, detail)
+}
+
+// writeNextSkipDayMarkdown lists every location on the next skip day
+func writeNextSkipDayMarkdown(w io.Writer, day skipDay) error {
+	if _, err := fmt.Fprintf(w, "**Next %s day:** %s\n\n", currentTenant().EventTitle, formatSkipDate(day.Date)); err != nil {
+		return err
+	}
+	for _, skip := range day.Skips {
+		if _, err := fmt.Fprintf(w, "- %s, %s\n", skip.Address, skip.Postcode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNearestSkipMarkdown links the nearest skip to OpenStreetMap
+func writeNearestSkipMarkdown(w io.Writer, n nearestSkip) error {
+	_, err := fmt.Fprintf(w, "**Nearest %s:** [%s, %s](https://www.openstreetmap.org/?mlat=%.6f&mlon=%.6f) on %s (%.1f km away)\n",
+		currentTenant().EventTitle, n.Skip.Address, n.Skip.Postcode,
+		n.Skip.Latitude, n.Skip.Longitude, formatSkipDate(n.Date), n.DistanceKm)
+	return err
+}
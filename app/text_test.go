@@ -0,0 +1,77 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextFormatFor(t *testing.T) {
+	tests := []struct {
+		path   string
+		format textFormat
+		base   string
+		ok     bool
+	}{
+		{"/nearest/SW18%204AA.txt", formatText, "/nearest/SW18%204AA", true},
+		{"/nearest/SW18%204AA.md", formatMarkdown, "/nearest/SW18%204AA", true},
+		{"/nearest/SW18%204AA", textFormat{}, "", false},
+	}
+
+	for _, tt := range tests {
+		format, base, ok := textFormatFor(tt.path)
+		if format != tt.format || base != tt.base || ok != tt.ok {
+			t.Errorf("textFormatFor(%q) = %v, %q, %v", tt.path, format, base, ok)
+		}
+	}
+}
+
+func TestWriteNextSkipDayMarkdown(t *testing.T) {
+	var b strings.Builder
+	day := skipDay{
+		Date: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+		Skips: []SkipLocation{
+			{Address: "Garratt Lane", Postcode: "SW18 4AA"},
+			{Address: "Tooting Broadway", Postcode: "SW17 0RL"},
+		},
+	}
+	if err := writeNextSkipDayMarkdown(&b, day); err != nil {
+		t.Fatal(err)
+	}
+
+	got := b.String()
+	for _, want := range []string{"Sunday 18 October", "- Garratt Lane, SW18 4AA\n", "- Tooting Broadway, SW17 0RL\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestHandleNearestTextErrors(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HandleNearestText(rec, httptest.NewRequest("GET", "/nearest/nowhere.txt", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for an invalid postcode, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != formatText.contentType {
+		t.Errorf("Expected a plain-text error, got %q", ct)
+	}
+	if body := rec.Body.String(); strings.Count(body, "\n") != 1 || !strings.HasSuffix(body, "\n") {
+		t.Errorf("Expected a one-line error, got %q", body)
+	}
+
+	// Clients that ask for problem details get them
+	r := httptest.NewRequest("GET", "/nearest/nowhere.md", nil)
+	r.Header.Set("Accept", problemContentType)
+	rec = httptest.NewRecorder()
+	HandleNearestText(rec, r)
+	var problem Problem
+	if ct := rec.Header().Get("Content-Type"); ct != problemContentType {
+		t.Errorf("Expected problem+json when asked for, got %q", ct)
+	} else if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil || problem.Status != http.StatusBadRequest {
+		t.Errorf("Expected a 400 problem, got %+v, %v", problem, err)
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+	_ "time/tzdata" // serverless runtimes may not ship a zoneinfo database
+)
+
+// closingSoonWindow is how long before closing a skip is "closing soon"
+const closingSoonWindow = 30 * time.Minute
+
+// Day-of statuses reported by /api/today
+const (
+	statusOpeningLater = "opening-later"
+	statusOpen         = "open"
+	statusClosingSoon  = "closing-soon"
+	statusClosed       = "closed"
+	statusCancelled    = "cancelled"
+)
+
+// londonTime is the timezone skip days and opening hours are given in
+var londonTime = mustLoadLocation("Europe/London")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// TodaySkip is a skip location annotated with its status right now
+type TodaySkip struct {
+	SkipLocation
+	Status           string `json:"status"`
+	MinutesRemaining int    `json:"minutesRemaining"`
+}
+
+// TodayResponse is the response served from /api/today
+type TodayResponse struct {
+	Date      string      `json:"date"`
+	IsSkipDay bool        `json:"isSkipDay"`
+	OpensAt   *time.Time  `json:"opensAt,omitempty"`
+	ClosesAt  *time.Time  `json:"closesAt,omitempty"`
+	Locations []TodaySkip `json:"locations"`
+}
+
+// openingStatus returns the status of a skip open from opens to closes and
+// the minutes until it opens (before opening) or closes (while open)
+func openingStatus(now, opens, closes time.Time) (string, int) {
+	switch {
+	case now.Before(opens):
+		return statusOpeningLater, int(opens.Sub(now).Minutes())
+	case !now.Before(closes):
+		return statusClosed, 0
+	case closes.Sub(now) <= closingSoonWindow:
+		return statusClosingSoon, int(closes.Sub(now).Minutes())
+	default:
+		return statusOpen, int(closes.Sub(now).Minutes())
+	}
+}
+
+// todaySkips builds the day-of view of locations at now
+func todaySkips(locations []SkipLocation, now time.Time) TodayResponse {
+	local := now.In(londonTime)
+	today := startOfDay(now)
+	resp := TodayResponse{
+		Date:      today.Format("2006-01-02"),
+		Locations: []TodaySkip{},
+	}
+
+	skips := groupSkipsByDate(locations)[today]
+	if len(skips) == 0 {
+		return resp
+	}
+
+	dayOpens, dayCloses := dayHours(scheduledSkips(skips))
+	opens, closes := atClock(local, dayOpens), atClock(local, dayCloses)
+
+	resp.IsSkipDay = true
+	resp.OpensAt = &opens
+	resp.ClosesAt = &closes
+	for _, skip := range skips {
+		skipOpens, skipCloses := skip.hours()
+		status, minutes := openingStatus(now, atClock(local, skipOpens), atClock(local, skipCloses))
+		if skip.cancelled() {
+			status, minutes = statusCancelled, 0
+		}
+		resp.Locations = append(resp.Locations, TodaySkip{
+			SkipLocation:     skip,
+			Status:           status,
+			MinutesRemaining: minutes,
+		})
+	}
+	return resp
+}
+
+// HandleTodayAPI handles requests to /api/today. On skip days it returns
+// that day's locations with their open/closing-soon/closed status.
+func HandleTodayAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	types, err := parseEventTypes(r.URL.Query().Get("type"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	locations, err := getSkipLocations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting skip locations", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to fetch skip locations")
+		return
+	}
+
+	setSnapshotHeaders(w)
+	json.NewEncoder(w).Encode(todaySkips(filterByType(locations, types), time.Now()))
+}
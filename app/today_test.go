@@ -0,0 +1,57 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOpeningStatus(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2026, time.October, 18, hour, minute, 0, 0, londonTime)
+	}
+	opens, closes := day(9, 0), day(12, 0)
+
+	tests := []struct {
+		now     time.Time
+		status  string
+		minutes int
+	}{
+		{day(8, 15), statusOpeningLater, 45},
+		{day(9, 0), statusOpen, 180},
+		{day(11, 29), statusOpen, 31},
+		{day(11, 30), statusClosingSoon, 30},
+		{day(11, 59), statusClosingSoon, 1},
+		{day(12, 0), statusClosed, 0},
+		{day(15, 0), statusClosed, 0},
+	}
+
+	for _, tt := range tests {
+		status, minutes := openingStatus(tt.now, opens, closes)
+		if status != tt.status || minutes != tt.minutes {
+			t.Errorf("openingStatus(%s) = %s, %d; want %s, %d",
+				tt.now.Format("15:04"), status, minutes, tt.status, tt.minutes)
+		}
+	}
+}
+
+func TestTodaySkipsUsesLondonDate(t *testing.T) {
+	date := time.Date(2026, time.June, 20, 0, 0, 0, 0, time.UTC)
+	locations := []SkipLocation{
+		{Address: "Today", Date: date},
+		{Address: "Tomorrow", Date: date.AddDate(0, 0, 1)},
+	}
+
+	// 00:30 BST on the 20th is still the 19th in UTC
+	resp := todaySkips(locations, time.Date(2026, time.June, 19, 23, 30, 0, 0, time.UTC))
+	if !resp.IsSkipDay || len(resp.Locations) != 1 || resp.Locations[0].Address != "Today" {
+		t.Fatalf("Expected today's skip, got %+v", resp)
+	}
+	if resp.Locations[0].Status != statusOpeningLater {
+		t.Errorf("Status = %s, want %s", resp.Locations[0].Status, statusOpeningLater)
+	}
+
+	resp = todaySkips(locations, time.Date(2026, time.June, 25, 10, 0, 0, 0, time.UTC))
+	if resp.IsSkipDay || len(resp.Locations) != 0 {
+		t.Errorf("Expected no skips on a non-skip day, got %+v", resp)
+	}
+}
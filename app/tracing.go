@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the app's spans
+const tracerName = "github.com/JosephSalisbury/wheremegaskip/app"
+
+// tracerProvider exports spans, or is nil when tracing is off
+var tracerProvider *sdktrace.TracerProvider
+
+// startSpan starts a span with the global tracer provider, as a child of
+// any span in ctx. Spans go nowhere until configureTracing installs an
+// exporting provider.
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// recordSpanError marks the span as failed. A nil err is ignored.
+func recordSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// tracing wraps a handler so each request gets a server span, continuing the
+// caller's trace when it is propagated in the headers
+func tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tracerProvider == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := startSpan(ctx, r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method), semconv.URLPath(r.URL.Path)))
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rec.status))
+		if rec.status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", rec.status))
+		}
+	})
+}
+
+// tracingTransport records a client span for each outgoing request and
+// propagates the trace to the server
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if tracerProvider == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, span := startSpan(req.Context(), req.Method+" "+req.URL.Host,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(req.Method), semconv.ServerAddress(req.URL.Host)))
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		recordSpanError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	return resp, nil
+}
+
+// tracedCache records a span for each cache operation
+type tracedCache struct {
+	Cacher
+}
+
+func (c tracedCache) Get(ctx context.Context, key string) ([]SkipLocation, error) {
+	ctx, span := startSpan(ctx, "cache.get", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+
+	locations, err := c.Cacher.Get(ctx, key)
+	span.SetAttributes(attribute.Bool("cache.hit", locations != nil))
+	recordSpanError(span, err)
+	return locations, err
+}
+
+func (c tracedCache) Set(ctx context.Context, key string, data []SkipLocation, ttl time.Duration) error {
+	ctx, span := startSpan(ctx, "cache.set", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+
+	err := c.Cacher.Set(ctx, key, data, ttl)
+	recordSpanError(span, err)
+	return err
+}
+
+func (c tracedCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	ctx, span := startSpan(ctx, "cache.get", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+
+	data, err := c.Cacher.GetBytes(ctx, key)
+	span.SetAttributes(attribute.Bool("cache.hit", data != nil))
+	recordSpanError(span, err)
+	return data, err
+}
+
+func (c tracedCache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	ctx, span := startSpan(ctx, "cache.set", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+
+	err := c.Cacher.SetBytes(ctx, key, data, ttl)
+	recordSpanError(span, err)
+	return err
+}
+
+func (c tracedCache) Delete(ctx context.Context, key string) error {
+	ctx, span := startSpan(ctx, "cache.delete", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+
+	err := c.Cacher.Delete(ctx, key)
+	recordSpanError(span, err)
+	return err
+}
+
+// configureTracing exports traces over OTLP/HTTP when an OTLP endpoint is
+// configured. The exporter, batching, sampler and resource follow the
+// standard OTEL_* environment variables; OTEL_EXPORTER_OTLP_HEADERS is
+// also read with getSecret, as it usually holds an API key.
+func configureTracing() {
+	if os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" {
+		return
+	}
+
+	var opts []otlptracehttp.Option
+	headers, err := getSecret("OTEL_EXPORTER_OTLP_HEADERS")
+	if err != nil {
+		slog.Warn("Failed to load OTLP headers", "err", err)
+	} else if headers != "" {
+		opts = append(opts, otlptracehttp.WithHeaders(parseOTLPHeaders(headers)))
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		slog.Warn("Failed to create trace exporter", "err", err)
+		return
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default
+	res, err := resource.Merge(
+		resource.NewSchemaless(semconv.ServiceName("wheremegaskip")),
+		resource.Environment(),
+	)
+	if err != nil {
+		slog.Warn("Invalid OTEL_RESOURCE_ATTRIBUTES", "err", err)
+		res = resource.NewSchemaless(semconv.ServiceName("wheremegaskip"))
+	}
+
+	tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	slog.Info("Exporting traces")
+}
+
+// parseOTLPHeaders parses OTEL_EXPORTER_OTLP_HEADERS, a comma-separated list
+// of key=value pairs
+func parseOTLPHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(key) != "" {
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+	return headers
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useTestTracer records spans in memory instead of exporting them
+func useTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous, previousProvider, previousPropagator := tracerProvider, otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		tracerProvider = previous
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestStartSpanWithoutTracerIsNoop(t *testing.T) {
+	ctx, s := startSpan(context.Background(), "noop")
+	if s.IsRecording() {
+		t.Fatal("Expected a non-recording span when tracing is off")
+	}
+	s.SetAttributes(attribute.String("key", "value"))
+	recordSpanError(s, errors.New("boom"))
+	s.End()
+	if ctx == nil {
+		t.Error("Expected the context back")
+	}
+}
+
+func TestSpansShareTraceWithParent(t *testing.T) {
+	recorder := useTestTracer(t)
+
+	ctx, parent := startSpan(context.Background(), "parent")
+	_, child := startSpan(ctx, "child")
+	child.SetAttributes(attribute.Int("locations", 3))
+	recordSpanError(child, errors.New("scrape failed"))
+	child.End()
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	got, want := spans[0], spans[1]
+	if got.Name() != "child" || got.Status().Code != codes.Error {
+		t.Errorf("Unexpected child span %+v", got)
+	}
+	if got.Parent().SpanID() != want.SpanContext().SpanID() || got.SpanContext().TraceID() != want.SpanContext().TraceID() {
+		t.Error("Child should belong to the parent's trace")
+	}
+}
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	recorder := useTestTracer(t)
+
+	handler := tracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	req := httptest.NewRequest("GET", "/api/skips", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected a server span, got %d spans", len(spans))
+	}
+	got := spans[0]
+	if got.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || got.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected the incoming trace to continue, got %+v", got)
+	}
+	if got.SpanKind() != trace.SpanKindServer || got.Name() != "GET /api/skips" {
+		t.Errorf("Unexpected server span %+v", got)
+	}
+}
+
+func TestTracingTransportPropagatesTrace(t *testing.T) {
+	recorder := useTestTracer(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: tracingTransport{http.DefaultTransport}}
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].SpanKind() != trace.SpanKindClient {
+		t.Fatalf("Expected a client span, got %d spans", len(spans))
+	}
+	sc := spans[0].SpanContext()
+	if want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"; traceparent != want {
+		t.Errorf("traceparent = %q, want %q", traceparent, want)
+	}
+}
+
+func TestConfigureTracingExportsOTLP(t *testing.T) {
+	exported := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case exported <- r:
+		default:
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "X-Api-Key=secret")
+	previous, previousProvider, previousPropagator := tracerProvider, otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		tracerProvider = previous
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	configureTracing()
+	if tracerProvider == nil {
+		t.Fatal("Expected tracing to be enabled")
+	}
+	_, span := startSpan(context.Background(), "scrape")
+	span.End()
+	if err := tracerProvider.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-exported
+	if r.URL.Path != "/v1/traces" || r.Header.Get("X-Api-Key") != "secret" {
+		t.Errorf("Expected an OTLP export with the configured headers, got %s %v", r.URL.Path, r.Header)
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set at build time with, for example:
+//
+//	go build -ldflags "-X github.com/JosephSalisbury/wheremegaskip/app.version=v1.2.0 \
+//	  -X github.com/JosephSalisbury/wheremegaskip/app.commit=$(git rev-parse HEAD) \
+//	  -X github.com/JosephSalisbury/wheremegaskip/app.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Anything left unset falls back to the Go build info, then to Vercel's
+// deployment environment.
+var (
+	version   string
+	commit    string
+	buildTime string
+)
+
+// BuildInfo is the response served from /version
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"buildTime,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// currentBuild is resolved once, as build info can't change at runtime
+var currentBuild = sync.OnceValue(func() BuildInfo {
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = os.Getenv("VERCEL_GIT_COMMIT_SHA")
+	}
+	if info.Version == "" {
+		info.Version = shortCommit(info.Commit)
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+})
+
+// shortCommit abbreviates a commit hash the way git does
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// versionHeader identifies the deployed version on every response
+func versionHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-App-Version", currentBuild().Version)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HandleVersion handles requests to /version
+func HandleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(currentBuild())
+}
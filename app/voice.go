@@ -0,0 +1,95 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Dialogflow intent display names answered by the fulfillment webhook
+const (
+	intentNextSkip    = "next-skip"
+	intentNearestSkip = "nearest-skip"
+)
+
+// dialogflowRequest is the subset of a Dialogflow ES webhook request we use
+type dialogflowRequest struct {
+	QueryResult struct {
+		Intent struct {
+			DisplayName string `json:"displayName"`
+		} `json:"intent"`
+		Parameters map[string]any `json:"parameters"`
+	} `json:"queryResult"`
+}
+
+// dialogflowResponse is a Dialogflow ES webhook response
+type dialogflowResponse struct {
+	FulfillmentText string `json:"fulfillmentText"`
+}
+
+// postcode returns the postcode parameter, accepting either a custom
+// "postcode" entity or the built-in @sys.zip-code
+func (req dialogflowRequest) postcode() string {
+	for _, name := range []string{"postcode", "zip-code"} {
+		if v, ok := req.QueryResult.Parameters[name].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// HandleDialogflow handles Google Assistant fulfillment requests to
+// /voice/dialogflow. Dialogflow is configured to send DIALOGFLOW_TOKEN as a
+// bearer token in a custom Authorization header; requests are refused when
+// no token is configured.
+func HandleDialogflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !bearerAuthorized(r, "DIALOGFLOW_TOKEN") {
+		writeProblem(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req dialogflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dialogflowResponse{
+		FulfillmentText: answerDialogflow(r, req),
+	})
+}
+
+// answerDialogflow returns the spoken answer for a fulfillment request.
+// Dialogflow reads errors out to the user too, so every failure becomes text.
+func answerDialogflow(r *http.Request, req dialogflowRequest) string {
+	now := time.Now()
+
+	switch req.QueryResult.Intent.DisplayName {
+	case intentNextSkip:
+		day, err := queryNextSkipDay(r.Context(), now)
+		if err != nil {
+			return describeQueryError(err)
+		}
+		return describeNextSkipDay(day)
+
+	case intentNearestSkip:
+		postcode := req.postcode()
+		if postcode == "" {
+			return "What's your postcode?"
+		}
+		nearest, err := queryNearestSkip(r.Context(), postcode, now)
+		if err != nil {
+			return describeQueryError(err)
+		}
+		return describeNearestSkip(nearest)
+
+	default:
+		return "You can ask when the next skip day is, or where the nearest skip to your postcode is."
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleDialogflowRequiresToken(t *testing.T) {
+	useTestSkips(t, nil)
+	body := `{"queryResult":{"intent":{"displayName":"nearest-skip"},"parameters":{}}}`
+
+	post := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/voice/dialogflow", strings.NewReader(body))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		HandleDialogflow(rec, req)
+		return rec
+	}
+
+	t.Setenv("DIALOGFLOW_TOKEN", "")
+	if rec := post("Bearer anything"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Without a configured token, status = %d, want 401", rec.Code)
+	}
+
+	t.Setenv("DIALOGFLOW_TOKEN", "voice-secret")
+	for _, bad := range []string{"", "Bearer wrong"} {
+		if rec := post(bad); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want 401", bad, rec.Code)
+		}
+	}
+
+	rec := post("Bearer voice-secret")
+	var resp dialogflowResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("Status = %d, %v", rec.Code, err)
+	}
+	if resp.FulfillmentText != "What's your postcode?" {
+		t.Errorf("FulfillmentText = %q", resp.FulfillmentText)
+	}
+}
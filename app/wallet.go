@@ -0,0 +1,423 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// walletSigner signs Apple Wallet passes with a Pass Type ID certificate
+type walletSigner struct {
+	passTypeID string
+	teamID     string
+	cert       *x509.Certificate
+	key        crypto.Signer
+	// wwdr is Apple's WWDR intermediate certificate, which Wallet needs to
+	// check the pass certificate
+	wwdr *x509.Certificate
+}
+
+// wallet signs passes, or is nil when WALLET_* isn't configured
+var wallet *walletSigner
+
+// mapTileURL is the OpenStreetMap tile server pass thumbnails are cut from,
+// by zoom, x and y
+var mapTileURL = "https://tile.openstreetmap.org/%d/%d/%d.png"
+
+// Wallet pass map thumbnails are 90 points square, drawn at 2x
+const (
+	walletMapZoom      = 16
+	walletMapTileSize  = 256
+	walletThumbnailPts = 90
+)
+
+// walletMapTTL is how long a skip's map thumbnail is cached. Maps of a
+// street rarely change, and the OpenStreetMap tile policy asks that tiles
+// aren't fetched over and over.
+const walletMapTTL = 30 * 24 * time.Hour
+
+// configureWallet loads the pass signing settings. WALLET_CERT, WALLET_KEY
+// and WALLET_WWDR_CERT are PEM, read with getSecret.
+func configureWallet() {
+	passTypeID, teamID := os.Getenv("WALLET_PASS_TYPE_ID"), os.Getenv("WALLET_TEAM_ID")
+	if passTypeID == "" || teamID == "" {
+		return
+	}
+
+	signer, err := loadWalletSigner(passTypeID, teamID)
+	if err != nil {
+		slog.Warn("Wallet passes disabled", "err", err)
+		return
+	}
+	wallet = signer
+	slog.Info("Wallet passes enabled", "passTypeId", passTypeID)
+}
+
+// loadWalletSigner reads and parses the signing certificates and key
+func loadWalletSigner(passTypeID, teamID string) (*walletSigner, error) {
+	pems := make(map[string][]byte)
+	for _, name := range []string{"WALLET_CERT", "WALLET_KEY", "WALLET_WWDR_CERT"} {
+		value, err := getSecret(name)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode([]byte(value))
+		if block == nil {
+			return nil, fmt.Errorf("%s is not PEM", name)
+		}
+		pems[name] = block.Bytes
+	}
+
+	cert, err := x509.ParseCertificate(pems["WALLET_CERT"])
+	if err != nil {
+		return nil, fmt.Errorf("parsing WALLET_CERT: %w", err)
+	}
+	wwdr, err := x509.ParseCertificate(pems["WALLET_WWDR_CERT"])
+	if err != nil {
+		return nil, fmt.Errorf("parsing WALLET_WWDR_CERT: %w", err)
+	}
+	key, err := parsePrivateKey(pems["WALLET_KEY"])
+	if err != nil {
+		return nil, fmt.Errorf("parsing WALLET_KEY: %w", err)
+	}
+	return &walletSigner{passTypeID: passTypeID, teamID: teamID, cert: cert, key: key, wwdr: wwdr}, nil
+}
+
+// parsePrivateKey parses a PKCS #8, PKCS #1 or SEC 1 DER private key
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unrecognised private key")
+}
+
+// walletField is a label and value shown on a pass
+type walletField struct {
+	Key   string `json:"key"`
+	Label string `json:"label,omitempty"`
+	Value string `json:"value"`
+}
+
+type walletLocation struct {
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	RelevantText string  `json:"relevantText,omitempty"`
+}
+
+type walletEventTicket struct {
+	PrimaryFields   []walletField `json:"primaryFields"`
+	SecondaryFields []walletField `json:"secondaryFields"`
+	AuxiliaryFields []walletField `json:"auxiliaryFields"`
+	BackFields      []walletField `json:"backFields"`
+}
+
+// walletPass is a pass.json, as described by Apple's PassKit reference
+type walletPass struct {
+	FormatVersion      int               `json:"formatVersion"`
+	PassTypeIdentifier string            `json:"passTypeIdentifier"`
+	SerialNumber       string            `json:"serialNumber"`
+	TeamIdentifier     string            `json:"teamIdentifier"`
+	OrganizationName   string            `json:"organizationName"`
+	Description        string            `json:"description"`
+	LogoText           string            `json:"logoText"`
+	ForegroundColor    string            `json:"foregroundColor"`
+	BackgroundColor    string            `json:"backgroundColor"`
+	LabelColor         string            `json:"labelColor"`
+	RelevantDate       string            `json:"relevantDate"`
+	ExpirationDate     string            `json:"expirationDate"`
+	Voided             bool              `json:"voided,omitempty"`
+	Locations          []walletLocation  `json:"locations,omitempty"`
+	EventTicket        walletEventTicket `json:"eventTicket"`
+}
+
+// cssColor formats a tenant colour as the rgb() Wallet expects
+func cssColor(hex string, def color.RGBA) string {
+	c := parseHexColor(hex, def)
+	return fmt.Sprintf("rgb(%d, %d, %d)", c.R, c.G, c.B)
+}
+
+// newWalletPass describes the nearest skip on the next skip day. The pass
+// expires when the skip closes, and is voided if it's called off.
+func (s *walletSigner) newWalletPass(n nearestSkip) walletPass {
+	tenant := currentTenant()
+	opens, closes := n.Skip.hours()
+
+	distance := fmt.Sprintf("%.1f km", n.DistanceKm)
+	if n.Approximate {
+		distance = "About " + distance
+	}
+
+	back := []walletField{{Key: "address", Label: "Address", Value: n.Skip.Address + ", " + n.Skip.Postcode}}
+	if items := itemsDescription(n.Skip.AcceptedItems, n.Skip.ProhibitedItems); items != "" {
+		back = append(back, walletField{Key: "items", Label: "What to bring", Value: items})
+	}
+	back = append(back,
+		walletField{Key: "info", Label: "More information", Value: tenant.SiteURL},
+		walletField{Key: "attribution", Label: "Map", Value: "© OpenStreetMap contributors"},
+	)
+
+	pass := walletPass{
+		FormatVersion:      1,
+		PassTypeIdentifier: s.passTypeID,
+		SerialNumber:       n.Skip.ID + "-" + strings.ReplaceAll(n.Postcode, " ", ""),
+		TeamIdentifier:     s.teamID,
+		OrganizationName:   tenant.SiteTitle,
+		Description:        tenant.EventTitle,
+		LogoText:           tenant.SiteTitle,
+		ForegroundColor:    "rgb(255, 255, 255)",
+		BackgroundColor:    cssColor(tenant.Colors.Primary, color.RGBA{0x00, 0x74, 0xa2, 0xff}),
+		LabelColor:         cssColor(tenant.Colors.PrimaryLight, color.RGBA{0x00, 0xa1, 0xc9, 0xff}),
+		RelevantDate:       atClock(n.Date, opens).Format(time.RFC3339),
+		ExpirationDate:     atClock(n.Date, closes).Format(time.RFC3339),
+		Voided:             n.Skip.cancelled(),
+		EventTicket: walletEventTicket{
+			PrimaryFields:   []walletField{{Key: "date", Label: "Date", Value: formatSkipDate(n.Date)}},
+			SecondaryFields: []walletField{{Key: "location", Label: "Nearest skip", Value: n.Skip.Address}},
+			AuxiliaryFields: []walletField{
+				{Key: "hours", Label: "Open", Value: opens + "–" + closes},
+				{Key: "distance", Label: "Distance", Value: distance},
+			},
+			BackFields: back,
+		},
+	}
+	if n.Skip.hasCoordinates() {
+		pass.Locations = []walletLocation{{
+			Latitude: n.Skip.Latitude, Longitude: n.Skip.Longitude,
+			RelevantText: tenant.EventTitle + " at " + n.Skip.Address + " today",
+		}}
+	}
+	return pass
+}
+
+// writePKPass zips files with pass.json, a manifest of their SHA-1
+// hashes and a signature of the manifest
+func (s *walletSigner) writePKPass(w io.Writer, pass walletPass, files map[string][]byte, now time.Time) error {
+	passJSON, err := json.Marshal(pass)
+	if err != nil {
+		return err
+	}
+	files["pass.json"] = passJSON
+
+	manifest := make(map[string]string, len(files))
+	for name, data := range files {
+		sum := sha1.Sum(data)
+		manifest[name] = hex.EncodeToString(sum[:])
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	signature, err := signDetached(manifestJSON, s.cert, s.key, []*x509.Certificate{s.wwdr}, now)
+	if err != nil {
+		return err
+	}
+	files["manifest.json"] = manifestJSON
+	files["signature"] = signature
+
+	zw := zip.NewWriter(w)
+	for _, name := range sortedKeys(files) {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// sortedKeys returns a map's keys in order, so archives are reproducible
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// walletIcon draws a skip in the tenant's colours, size pixels square
+func walletIcon(size int) []byte {
+	tenant := currentTenant()
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{
+		parseHexColor(tenant.Colors.Primary, color.RGBA{0x00, 0x74, 0xa2, 0xff}),
+		color.White,
+	})
+
+	// A skip is a trapezoid, wider at the top
+	top, bottom := size*3/10, size*8/10
+	for y := top; y < bottom; y++ {
+		inset := size/8 + (y-top)*size/8/(bottom-top)
+		fillRect(img, image.Rect(inset, y, size-inset, y+1), 1)
+	}
+
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// mapThumbnail cuts a square around a point from an OpenStreetMap tile
+// and marks the point, size pixels square
+func mapThumbnail(ctx context.Context, lat, lng float64, size int) ([]byte, error) {
+	n := math.Exp2(walletMapZoom)
+	x := (lng + 180) / 360 * n
+	latRad := lat * math.Pi / 180
+	y := (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n
+	tileX, tileY := int(x), int(y)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(mapTileURL, walletMapZoom, tileX, tileY), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", geocoderUserAgent)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, &statusError{StatusCode: res.StatusCode}
+	}
+	tile, err := png.Decode(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding map tile: %w", err)
+	}
+
+	// Centre the point, unless that would run off the tile
+	px := int((x - float64(tileX)) * walletMapTileSize)
+	py := int((y - float64(tileY)) * walletMapTileSize)
+	x0 := min(max(px-size/2, 0), walletMapTileSize-size)
+	y0 := min(max(py-size/2, 0), walletMapTileSize-size)
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), tile, tile.Bounds().Min.Add(image.Pt(x0, y0)), draw.Src)
+
+	accent := parseHexColor(currentTenant().Colors.Accent, color.RGBA{0xff, 0x70, 0x43, 0xff})
+	cx, cy := px-x0, py-y0
+	for dy := -10; dy <= 10; dy++ {
+		for dx := -10; dx <= 10; dx++ {
+			switch d := dx*dx + dy*dy; {
+			case d <= 7*7:
+				img.Set(cx+dx, cy+dy, accent)
+			case d <= 10*10:
+				img.Set(cx+dx, cy+dy, color.White)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// walletThumbnail returns the map thumbnail for a skip, drawing it at most
+// once per walletMapTTL. Thumbnails are cached by skip ID and position, so
+// a skip that is geocoded again gets a new map.
+func walletThumbnail(ctx context.Context, skip SkipLocation) ([]byte, error) {
+	key := fmt.Sprintf("wallet_map_%s_%.5f_%.5f", skip.ID, skip.Latitude, skip.Longitude)
+	if activeCache != nil {
+		if data, err := activeCache.GetBytes(ctx, key); err != nil {
+			slog.WarnContext(ctx, "Wallet map cache get error", "err", err)
+		} else if data != nil {
+			return data, nil
+		}
+	}
+
+	data, err := mapThumbnail(ctx, skip.Latitude, skip.Longitude, 2*walletThumbnailPts)
+	if err != nil {
+		return nil, err
+	}
+	if activeCache != nil {
+		if err := activeCache.SetBytes(ctx, key, data, walletMapTTL); err != nil {
+			slog.WarnContext(ctx, "Wallet map cache set error", "err", err)
+		}
+	}
+	return data, nil
+}
+
+// HandleWalletPass handles requests to /wallet/{postcode}.pkpass, a Wallet
+// pass for the nearest skip to a postcode on the next skip day
+func HandleWalletPass(w http.ResponseWriter, r *http.Request) {
+	if wallet == nil {
+		writeTextProblem(w, r, http.StatusNotFound, "Wallet passes are not configured")
+		return
+	}
+
+	path := r.URL.Path
+	if !strings.HasPrefix(path, "/wallet/") || !strings.HasSuffix(path, ".pkpass") {
+		writeTextProblem(w, r, http.StatusNotFound, "Not found")
+		return
+	}
+	postcode, err := url.QueryUnescape(strings.TrimSuffix(strings.TrimPrefix(path, "/wallet/"), ".pkpass"))
+	if err != nil {
+		writeTextProblem(w, r, http.StatusBadRequest, "Invalid postcode encoding")
+		return
+	}
+
+	nearest, err := queryNearestSkip(r.Context(), postcode, time.Now())
+	if err != nil {
+		writeTextError(w, r, err)
+		return
+	}
+
+	files := map[string][]byte{
+		"icon.png":    walletIcon(29),
+		"icon@2x.png": walletIcon(58),
+		"logo.png":    walletIcon(50),
+		"logo@2x.png": walletIcon(100),
+	}
+	// A pass without a map is better than no pass
+	if nearest.Skip.hasCoordinates() {
+		thumbnail, err := walletThumbnail(r.Context(), nearest.Skip)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Leaving map out of Wallet pass", "err", err)
+		} else {
+			files["thumbnail@2x.png"] = thumbnail
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.pkpass")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.pkpass\"", currentTenant().FileSlug))
+	w.Header().Set("Cache-Control", "private, no-store")
+	err = writeRendered(w, func(buf io.Writer) error {
+		return wallet.writePKPass(buf, wallet.newWalletPass(nearest), files, time.Now())
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error writing Wallet pass", "err", err)
+		reportError(r.Context(), err, map[string]string{"handler": "wallet"})
+		writeTextProblem(w, r, http.StatusInternalServerError, "Failed to generate pass")
+	}
+}
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testWalletSigner makes a signer with a throwaway WWDR CA and pass
+// certificate
+func testWalletSigner(t *testing.T) *walletSigner {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test WWDR"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Pass Type ID: pass.test.skip"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, key.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(leafDER)
+
+	return &walletSigner{passTypeID: "pass.test.skip", teamID: "TEAM123", cert: cert, key: key, wwdr: ca}
+}
+
+// pkcs7SignerInfo is enough of a SignerInfo to check a signature
+type pkcs7SignerInfo struct {
+	Version            int
+	IssuerAndSerial    asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttributes   asn1.RawValue `asn1:"tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue     `asn1:"tag:0"`
+	SignerInfos      []pkcs7SignerInfo `asn1:"set"`
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     pkcs7SignedData `asn1:"explicit,tag:0"`
+}
+
+func TestSignDetached(t *testing.T) {
+	s := testWalletSigner(t)
+	content := []byte(`{"pass.json":"abc"}`)
+
+	der, err := signDetached(content, s.cert, s.key, []*x509.Certificate{s.wwdr}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var info pkcs7ContentInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) > 0 {
+		t.Fatalf("Failed to parse signature: %v", err)
+	}
+	if !info.ContentType.Equal(oidSignedData) {
+		t.Errorf("Content type = %v, want signedData", info.ContentType)
+	}
+	certs, err := x509.ParseCertificates(info.Content.Certificates.Bytes)
+	if err != nil || len(certs) != 2 {
+		t.Fatalf("Expected the pass and WWDR certificates, got %d: %v", len(certs), err)
+	}
+	if len(info.Content.SignerInfos) != 1 {
+		t.Fatalf("Expected one signer, got %d", len(info.Content.SignerInfos))
+	}
+
+	signer := info.Content.SignerInfos[0]
+	// The signature covers the attributes with their SET tag
+	attributes := append([]byte{derSet}, signer.SignedAttributes.FullBytes[1:]...)
+	digest := sha256.Sum256(attributes)
+	if !ecdsa.VerifyASN1(&s.key.(*ecdsa.PrivateKey).PublicKey, digest[:], signer.Signature) {
+		t.Error("Signature doesn't verify")
+	}
+
+	contentDigest := sha256.Sum256(content)
+	if !bytes.Contains(attributes, contentDigest[:]) {
+		t.Error("Signed attributes don't carry the content digest")
+	}
+}
+
+func TestWritePKPass(t *testing.T) {
+	s := testWalletSigner(t)
+	pass := s.newWalletPass(nearestSkip{
+		Postcode:   "SW18 1AA",
+		Date:       time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+		Skip:       SkipLocation{ID: "skip-1", Address: "Garratt Lane", Postcode: "SW18 4DJ", Latitude: 51.45, Longitude: -0.19, OpensAt: "09:00", ClosesAt: "13:00"},
+		DistanceKm: 1.24,
+	})
+
+	var buf bytes.Buffer
+	files := map[string][]byte{"icon.png": walletIcon(29)}
+	if err := s.writePKPass(&buf, pass, files, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		contents[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	for _, name := range []string{"pass.json", "manifest.json", "signature", "icon.png"} {
+		if _, ok := contents[name]; !ok {
+			t.Errorf("Pass is missing %s", name)
+		}
+	}
+
+	var manifest map[string]string
+	if err := json.Unmarshal(contents["manifest.json"], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest) != 2 {
+		t.Errorf("Manifest should list pass.json and icon.png, got %v", manifest)
+	}
+	for name, hash := range manifest {
+		sum := sha1.Sum(contents[name])
+		if hash != hex.EncodeToString(sum[:]) {
+			t.Errorf("Manifest hash for %s doesn't match", name)
+		}
+	}
+
+	var got walletPass
+	if err := json.Unmarshal(contents["pass.json"], &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.PassTypeIdentifier != "pass.test.skip" || got.SerialNumber != "skip-1-SW181AA" {
+		t.Errorf("Unexpected pass identifiers %q %q", got.PassTypeIdentifier, got.SerialNumber)
+	}
+	if got.RelevantDate != "2025-03-15T09:00:00Z" || got.ExpirationDate != "2025-03-15T13:00:00Z" {
+		t.Errorf("Unexpected pass dates %q %q", got.RelevantDate, got.ExpirationDate)
+	}
+	if len(got.Locations) != 1 || got.EventTicket.AuxiliaryFields[1].Value != "1.2 km" {
+		t.Errorf("Unexpected pass location details %+v", got)
+	}
+}
+
+func TestMapThumbnail(t *testing.T) {
+	tile := image.NewRGBA(image.Rect(0, 0, walletMapTileSize, walletMapTileSize))
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		png.Encode(w, tile)
+	}))
+	defer server.Close()
+
+	previous := mapTileURL
+	mapTileURL = server.URL + "/%d/%d/%d.png"
+	defer func() { mapTileURL = previous }()
+
+	data, err := mapThumbnail(context.Background(), 51.4571, -0.1932, 180)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requested != "/16/32732/21807.png" {
+		t.Errorf("Requested tile %s", requested)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 180 || img.Bounds().Dy() != 180 {
+		t.Errorf("Thumbnail is %v", img.Bounds())
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r != 0 {
+		t.Error("Thumbnail corner should be the tile")
+	}
+	marked := false
+	for y := 0; y < 180 && !marked; y++ {
+		for x := 0; x < 180; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == color.Color(color.RGBA{0xff, 0x70, 0x43, 0xff}) {
+				marked = true
+				break
+			}
+		}
+	}
+	if !marked {
+		t.Error("Thumbnail doesn't mark the skip")
+	}
+}
+
+func TestWalletThumbnailCached(t *testing.T) {
+	useTestSkips(t, nil)
+	tile := image.NewRGBA(image.Rect(0, 0, walletMapTileSize, walletMapTileSize))
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		png.Encode(w, tile)
+	}))
+	defer server.Close()
+
+	previous := mapTileURL
+	mapTileURL = server.URL + "/%d/%d/%d.png"
+	defer func() { mapTileURL = previous }()
+
+	skip := SkipLocation{ID: "garratt-lane", Latitude: 51.4571, Longitude: -0.1932}
+	for i := 0; i < 2; i++ {
+		if data, err := walletThumbnail(context.Background(), skip); err != nil || len(data) == 0 {
+			t.Fatalf("walletThumbnail = %d bytes, %v", len(data), err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected one tile request, got %d", requests)
+	}
+
+	skip.Latitude = 51.46
+	walletThumbnail(context.Background(), skip)
+	if requests != 2 {
+		t.Errorf("A moved skip should get a new map, got %d requests", requests)
+	}
+}
+
+func TestHandleWalletPassNotConfigured(t *testing.T) {
+	previous := wallet
+	wallet = nil
+	defer func() { wallet = previous }()
+
+	rec := httptest.NewRecorder()
+	HandleWalletPass(rec, httptest.NewRequest(http.MethodGet, "/wallet/SW18%201AA.pkpass", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without signing settings, got %d", rec.Code)
+	}
+}
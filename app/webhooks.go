@@ -0,0 +1,409 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhookSignatureHeader carries the hex HMAC-SHA256 of the request body,
+// keyed with the webhook's secret, as "sha256=<hex>"
+const webhookSignatureHeader = "X-Webhook-Signature"
+
+// Retry settings for delivering webhooks. webhookBackoffBase is the wait
+// before the first retry, doubling after each failed attempt. Retries are
+// made by the next drain of the outbox once they're due.
+const (
+	webhookAttempts    = 4
+	webhookBackoffBase = 15 * time.Minute
+)
+
+// webhook is a URL told about every change to the skips
+type webhook struct {
+	URL string `json:"url"`
+	// Secret signs deliveries, so receivers can check they came from us
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks
+type WebhookPayload struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Changes   []SkipChange `json:"changes"`
+}
+
+// adminWebhooksKey holds the webhooks registered with the admin API in the
+// shared cache, so every instance delivers to them and they survive
+// restarts. They're kept for adminWebhooksTTL after the last change.
+const adminWebhooksKey = "admin_webhooks"
+
+const adminWebhooksTTL = 365 * 24 * time.Hour
+
+// webhookOutboxKey holds the deliveries not yet made in the shared cache,
+// so they're retried by whichever instance next drains it, even if the
+// one that found the changes has since been frozen or stopped
+const webhookOutboxKey = "webhook_outbox"
+
+const webhookOutboxTTL = 7 * 24 * time.Hour
+
+// webhookDelivery is a payload waiting to be POSTed to one webhook
+type webhookDelivery struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Body        json.RawMessage `json:"body"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+}
+
+var (
+	webhooksMu sync.Mutex
+	// webhooks maps each URL in WEBHOOKS to its webhook
+	webhooks = make(map[string]webhook)
+	// adminWebhooksMu serialises read-modify-write updates of the
+	// registered webhooks
+	adminWebhooksMu sync.Mutex
+	// webhookOutboxMu serialises updates of the outbox
+	webhookOutboxMu sync.Mutex
+)
+
+// configureWebhooks loads WEBHOOKS, a JSON list of webhooks. It is read
+// with getSecret, as it holds their secrets. More can be registered with
+// the admin API.
+func configureWebhooks() {
+	value, err := getSecret("WEBHOOKS")
+	if err != nil {
+		slog.Warn("Failed to load webhooks", "err", err)
+		return
+	}
+	if value == "" {
+		return
+	}
+
+	var list []webhook
+	if err := json.Unmarshal([]byte(value), &list); err != nil {
+		slog.Warn("Ignoring invalid WEBHOOKS", "err", err)
+		return
+	}
+	webhooksMu.Lock()
+	defer webhooksMu.Unlock()
+	for _, hook := range list {
+		if err := validateWebhook(hook); err != nil {
+			slog.Warn("Ignoring invalid webhook", "url", hook.URL, "err", err)
+			continue
+		}
+		webhooks[hook.URL] = hook
+	}
+	slog.Info("Webhooks loaded", "webhooks", len(list))
+}
+
+// validateWebhook checks a webhook has an absolute http(s) URL
+func validateWebhook(hook webhook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// adminWebhooks returns the webhooks registered with the admin API
+func adminWebhooks(ctx context.Context) ([]webhook, error) {
+	var list []webhook
+	_, err := getCachedValue(ctx, adminWebhooksKey, &list)
+	return list, err
+}
+
+// registerWebhook adds or replaces a webhook in the shared cache
+func registerWebhook(ctx context.Context, hook webhook) error {
+	if err := validateWebhook(hook); err != nil {
+		return err
+	}
+
+	adminWebhooksMu.Lock()
+	defer adminWebhooksMu.Unlock()
+
+	list, err := adminWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	list = slices.DeleteFunc(list, func(h webhook) bool { return h.URL == hook.URL })
+	return setCachedValue(ctx, adminWebhooksKey, append(list, hook), adminWebhooksTTL)
+}
+
+// unregisterWebhook removes a webhook registered with the admin API,
+// reporting whether it was registered
+func unregisterWebhook(ctx context.Context, rawURL string) (bool, error) {
+	adminWebhooksMu.Lock()
+	defer adminWebhooksMu.Unlock()
+
+	list, err := adminWebhooks(ctx)
+	if err != nil {
+		return false, err
+	}
+	kept := slices.DeleteFunc(slices.Clone(list), func(h webhook) bool { return h.URL == rawURL })
+	if len(kept) == len(list) {
+		return false, nil
+	}
+	return true, setCachedValue(ctx, adminWebhooksKey, kept, adminWebhooksTTL)
+}
+
+// registeredWebhooks lists the webhooks from WEBHOOKS and the admin API by
+// URL. A registered webhook replaces one from WEBHOOKS with the same URL.
+func registeredWebhooks(ctx context.Context) ([]webhook, error) {
+	stored, err := adminWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooksMu.Lock()
+	byURL := maps.Clone(webhooks)
+	webhooksMu.Unlock()
+	for _, hook := range stored {
+		byURL[hook.URL] = hook
+	}
+
+	list := slices.Collect(maps.Values(byURL))
+	sort.Slice(list, func(i, j int) bool { return list[i].URL < list[j].URL })
+	return list, nil
+}
+
+// signWebhook returns the signature header value for body
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// queueWebhooks adds a delivery of changes for every webhook to the outbox
+func queueWebhooks(ctx context.Context, changes []SkipChange, now time.Time) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(WebhookPayload{Timestamp: now.UTC(), Changes: changes})
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+	hooks, err := registeredWebhooks(ctx)
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	webhookOutboxMu.Lock()
+	defer webhookOutboxMu.Unlock()
+
+	var outbox []webhookDelivery
+	if _, err := getCachedValue(ctx, webhookOutboxKey, &outbox); err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		outbox = append(outbox, webhookDelivery{ID: newRequestID(), URL: hook.URL, Body: body, NextAttempt: now})
+	}
+	return setCachedValue(ctx, webhookOutboxKey, outbox, webhookOutboxTTL)
+}
+
+// deliverQueuedWebhooks makes every delivery in the outbox that is due,
+// concurrently, and returns how many were made and how many are still
+// waiting to be retried. Deliveries to webhooks that have been removed,
+// that fail with a client error other than 429 or that run out of
+// attempts are dropped.
+func deliverQueuedWebhooks(ctx context.Context, now time.Time) (delivered, pending int, err error) {
+	webhookOutboxMu.Lock()
+	defer webhookOutboxMu.Unlock()
+
+	var outbox []webhookDelivery
+	if _, err := getCachedValue(ctx, webhookOutboxKey, &outbox); err != nil {
+		return 0, 0, err
+	}
+	if len(outbox) == 0 {
+		return 0, 0, nil
+	}
+	hooks, err := registeredWebhooks(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	byURL := make(map[string]webhook, len(hooks))
+	for _, hook := range hooks {
+		byURL[hook.URL] = hook
+	}
+
+	// Each delivery that is attempted ends up done, or with its next retry
+	done := make(map[string]bool)
+	retries := make(map[string]webhookDelivery)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, d := range outbox {
+		if d.NextAttempt.After(now) {
+			continue
+		}
+		hook, ok := byURL[d.URL]
+		if !ok {
+			done[d.ID] = true
+			continue
+		}
+		wg.Add(1)
+		goSafe(ctx, "webhook delivery", func() {
+			defer wg.Done()
+			retry, err := postWebhook(ctx, hook, d.Body)
+
+			mu.Lock()
+			defer mu.Unlock()
+			d.Attempts++
+			switch {
+			case err == nil:
+				delivered++
+				done[d.ID] = true
+			case !retry || d.Attempts >= webhookAttempts:
+				slog.WarnContext(ctx, "Webhook delivery failed", "url", hook.URL, "attempts", d.Attempts, "err", err)
+				done[d.ID] = true
+			default:
+				d.NextAttempt = now.Add(webhookBackoffBase << (d.Attempts - 1))
+				retries[d.ID] = d
+			}
+		})
+	}
+	wg.Wait()
+
+	// Other instances may have queued deliveries in the meantime
+	var latest []webhookDelivery
+	if _, err := getCachedValue(ctx, webhookOutboxKey, &latest); err != nil {
+		return delivered, 0, err
+	}
+	kept := []webhookDelivery{}
+	for _, d := range latest {
+		if done[d.ID] {
+			continue
+		}
+		if retry, ok := retries[d.ID]; ok {
+			d = retry
+		}
+		kept = append(kept, d)
+	}
+	return delivered, len(kept), setCachedValue(ctx, webhookOutboxKey, kept, webhookOutboxTTL)
+}
+
+// WebhooksDeliverResult is the JSON response from /api/notify/webhooks
+type WebhooksDeliverResult struct {
+	Delivered int `json:"delivered"`
+	Pending   int `json:"pending"`
+}
+
+// HandleWebhooksDeliver handles scheduled requests to /api/notify/webhooks,
+// retrying the webhook deliveries that are due
+func HandleWebhooksDeliver(w http.ResponseWriter, r *http.Request) {
+	if !cronAuthorized(r) {
+		writeProblem(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	delivered, pending, err := deliverQueuedWebhooks(r.Context(), time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to deliver webhooks", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to deliver webhooks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(WebhooksDeliverResult{Delivered: delivered, Pending: pending})
+}
+
+// postWebhook makes a single delivery, reporting whether a failure is worth
+// retrying
+func postWebhook(ctx context.Context, hook webhook, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hook.Secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhook(hook.Secret, body))
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err := &statusError{StatusCode: res.StatusCode}
+		return res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests, err
+	}
+	return false, nil
+}
+
+// HandleAdminWebhooks handles /admin/webhooks: GET lists the webhooks,
+// POST registers one from a JSON body and DELETE ?url= removes one.
+// Registered webhooks are kept in the shared cache; those from WEBHOOKS
+// can't be removed here.
+func HandleAdminWebhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodPost, http.MethodDelete:
+	default:
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodDelete}, ", "))
+		writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !bearerAuthorized(r, "ADMIN_TOKEN") {
+		writeProblem(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var hook webhook
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&hook); err != nil {
+			writeProblem(w, http.StatusBadRequest, "Invalid webhook")
+			return
+		}
+		if err := validateWebhook(hook); err != nil {
+			writeProblem(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := registerWebhook(r.Context(), hook); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to register webhook", "err", err)
+			writeProblem(w, http.StatusInternalServerError, "Failed to register webhook")
+			return
+		}
+		slog.Info("Webhook registered", "url", hook.URL)
+		w.WriteHeader(http.StatusCreated)
+		return
+	case http.MethodDelete:
+		ok, err := unregisterWebhook(r.Context(), r.URL.Query().Get("url"))
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to remove webhook", "err", err)
+			writeProblem(w, http.StatusInternalServerError, "Failed to remove webhook")
+			return
+		}
+		if !ok {
+			writeProblem(w, http.StatusNotFound, "No such webhook")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	list, err := registeredWebhooks(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to load webhooks", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Failed to load webhooks")
+		return
+	}
+	// Secrets aren't given back out
+	for i := range list {
+		list[i].Secret = ""
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(list)
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeliverQueuedWebhooks(t *testing.T) {
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+	ctx := context.Background()
+
+	var attempts int
+	var payload WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhookSignatureHeader) != signWebhook("s3cret", body) {
+			t.Errorf("Bad signature %q", r.Header.Get(webhookSignatureHeader))
+		}
+		// Fail once to be retried
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.Unmarshal(body, &payload)
+	}))
+	defer server.Close()
+	if err := registerWebhook(ctx, webhook{URL: server.URL + "/hook", Secret: "s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	changes := []SkipChange{{Kind: ChangeAdded, Skip: SkipLocation{ID: "abc", Address: "Pountney Road"}}}
+	if err := queueWebhooks(ctx, changes, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// The failed delivery is kept in the outbox until its retry is due
+	if delivered, pending, err := deliverQueuedWebhooks(ctx, now); delivered != 0 || pending != 1 || err != nil {
+		t.Fatalf("Got %d delivered, %d pending, %v; want the delivery kept for a retry", delivered, pending, err)
+	}
+	if delivered, _, _ := deliverQueuedWebhooks(ctx, now.Add(webhookBackoffBase-time.Second)); delivered != 0 || attempts != 1 {
+		t.Errorf("Expected no retry before the backoff, got %d attempts", attempts)
+	}
+	if delivered, pending, err := deliverQueuedWebhooks(ctx, now.Add(webhookBackoffBase)); delivered != 1 || pending != 0 || err != nil {
+		t.Fatalf("Got %d delivered, %d pending, %v; want the retry delivered", delivered, pending, err)
+	}
+	if len(payload.Changes) != 1 || payload.Changes[0].Skip.ID != "abc" || !payload.Timestamp.Equal(now) {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+
+	// Client errors aren't retried
+	unregisterWebhook(ctx, server.URL+"/hook")
+	registerWebhook(ctx, webhook{URL: server.URL + "/gone"})
+	queueWebhooks(ctx, changes, now)
+	attempts = 0
+	if delivered, pending, err := deliverQueuedWebhooks(ctx, now); delivered != 0 || pending != 0 || err != nil || attempts != 1 {
+		t.Errorf("Got %d delivered, %d pending, %v after %d attempts; want the delivery dropped", delivered, pending, err, attempts)
+	}
+}
+
+func TestHandleWebhooksDeliver(t *testing.T) {
+	t.Setenv("CRON_SECRET", "secret")
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+
+	w := httptest.NewRecorder()
+	HandleWebhooksDeliver(w, httptest.NewRequest(http.MethodGet, "/api/notify/webhooks", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected unauthorized requests to be refused, got %d", w.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/notify/webhooks", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	HandleWebhooksDeliver(w, r)
+	var result WebhooksDeliverResult
+	if err := json.NewDecoder(w.Body).Decode(&result); w.Code != http.StatusOK || err != nil {
+		t.Errorf("Got %d, %v", w.Code, err)
+	}
+}
+
+func TestAdminWebhooks(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	previous := activeCache
+	activeCache = NewMemoryCache()
+	defer func() { activeCache = previous }()
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		HandleAdminWebhooks(w, r)
+		return w
+	}
+
+	if w := serve(http.MethodPost, "/admin/webhooks", `{"url":"ftp://example.com"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a bad URL to be refused, got %d", w.Code)
+	}
+	if w := serve(http.MethodPost, "/admin/webhooks", `{"url":"https://example.com/hook","secret":"s3cret"}`); w.Code != http.StatusCreated {
+		t.Fatalf("Expected the webhook to be registered, got %d", w.Code)
+	}
+
+	w := serve(http.MethodGet, "/admin/webhooks", "")
+	var list []webhook
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].URL != "https://example.com/hook" || list[0].Secret != "" {
+		t.Errorf("Unexpected webhooks %+v", list)
+	}
+
+	// Other instances deliver to it too
+	hooks, err := registeredWebhooks(context.Background())
+	if err != nil || len(hooks) != 1 || hooks[0].Secret != "s3cret" {
+		t.Errorf("Expected the webhook to be stored with its secret, got %+v, %v", hooks, err)
+	}
+
+	if w := serve(http.MethodDelete, "/admin/webhooks?url=https://example.com/hook", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected the webhook to be removed, got %d", w.Code)
+	}
+	if w := serve(http.MethodDelete, "/admin/webhooks?url=https://example.com/hook", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown webhook to be a 404, got %d", w.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/webhooks", nil)
+	w = httptest.NewRecorder()
+	HandleAdminWebhooks(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected unauthorized requests to be refused, got %d", w.Code)
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// websubHub is the WebSub hub feeds are advertised on and published to,
+// set from WEBSUB_HUB. WebSub is off without one.
+var websubHub string
+
+// configureWebSub reads WEBSUB_HUB and, if set, pings the hub whenever a
+// scrape changes the skips
+func configureWebSub() {
+	websubHub = os.Getenv("WEBSUB_HUB")
+	if websubHub == "" {
+		return
+	}
+
+	changes, _ := skipChanges.subscribe()
+	goSafe(context.Background(), "WebSub", func() {
+		for range changes {
+			runSafe(context.Background(), "WebSub publish", func() {
+				if err := publishWebSub(context.Background()); err != nil {
+					slog.Warn("WebSub publish failed", "hub", websubHub, "err", err)
+				}
+			})
+		}
+	})
+	slog.Info("Publishing feed updates to WebSub hub", "hub", websubHub)
+}
+
+// websubTopicPaths are the feeds advertised and published to the hub.
+// Filtered and postcode feeds are left out, as there's no knowing which
+// ones have subscribers to ping.
+var websubTopicPaths = []string{"/calendar.ics", "/feed.rss", "/feed.atom"}
+
+// websubTopic is the canonical URL of the feed at path
+func websubTopic(path string) string {
+	return strings.TrimSuffix(currentTenant().SiteURL, "/") + path
+}
+
+// setWebSubLinks advertises the hub on the response for a topic
+func setWebSubLinks(w http.ResponseWriter, r *http.Request) {
+	if websubHub == "" || !slices.Contains(websubTopicPaths, r.URL.Path) || r.URL.RawQuery != "" {
+		return
+	}
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="hub"`, websubHub))
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="self"`, websubTopic(r.URL.Path)))
+}
+
+// publishWebSub tells the hub every topic has changed, so it fetches the
+// feeds and pushes them to subscribers
+func publishWebSub(ctx context.Context) error {
+	var errs []error
+	for _, path := range websubTopicPaths {
+		if err := publishWebSubTopic(ctx, websubTopic(path)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// publishWebSubTopic pings the hub for one topic
+func publishWebSubTopic(ctx context.Context, topic string) error {
+	form := url.Values{"hub.mode": {"publish"}, "hub.url": {topic}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, websubHub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("hub responded %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublishWebSub(t *testing.T) {
+	var published []string
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.URL.Path != "/" || r.Form.Get("hub.mode") != "publish" {
+			http.Error(w, "bad mode", http.StatusBadRequest)
+			return
+		}
+		published = append(published, r.Form.Get("hub.url"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hub.Close()
+
+	previous := websubHub
+	websubHub = hub.URL + "/"
+	defer func() { websubHub = previous }()
+
+	if err := publishWebSub(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"https://wheremegaskip.com/calendar.ics",
+		"https://wheremegaskip.com/feed.rss",
+		"https://wheremegaskip.com/feed.atom",
+	}
+	if !slices.Equal(published, want) {
+		t.Errorf("Expected every topic published, got %v", published)
+	}
+
+	websubHub = hub.URL + "/missing"
+	if err := publishWebSub(context.Background()); err == nil {
+		t.Error("Expected a failed publish to be reported")
+	}
+}
+
+func TestWebSubLinks(t *testing.T) {
+	previousHub, previousCache := websubHub, activeCache
+	websubHub = "https://hub.example.com/"
+	activeCache = NewMemoryCache()
+	invalidateDerived()
+	defer func() {
+		websubHub, activeCache = previousHub, previousCache
+		invalidateDerived()
+	}()
+	activeCache.Set(context.Background(), cacheKey, []SkipLocation{{
+		Address: "Pountney Road", Postcode: "SW11 5TU", Latitude: 51.4655, Longitude: -0.1612,
+		Date: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), Type: EventMegaSkip,
+	}}, time.Hour)
+
+	w := httptest.NewRecorder()
+	HandleCalendarDefault(w, httptest.NewRequest(http.MethodGet, "/calendar.ics", nil))
+	links := w.Header().Values("Link")
+	if len(links) != 3 || links[0] != `<https://hub.example.com/>; rel="hub"` || links[1] != `<https://wheremegaskip.com/calendar.ics>; rel="self"` {
+		t.Errorf("Unexpected links %v", links)
+	}
+
+	for _, path := range []string{"/feed.rss", "/feed.atom"} {
+		w = httptest.NewRecorder()
+		NewHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if links := w.Header().Values("Link"); !slices.Contains(links, `<https://wheremegaskip.com`+path+`>; rel="self"`) {
+			t.Errorf("Expected %s to link to itself, got %v", path, links)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, `href="https://hub.example.com/" rel="hub"`) || !strings.Contains(body, `href="https://wheremegaskip.com`+path+`" rel="self"`) {
+			t.Errorf("Expected %s to contain hub and self links:\n%s", path, body)
+		}
+	}
+
+	// Filtered feeds aren't published, so only their canonical URL is given
+	w = httptest.NewRecorder()
+	HandleCalendarDefault(w, httptest.NewRequest(http.MethodGet, "/calendar.ics?types=megaskip", nil))
+	if links := w.Header().Values("Link"); len(links) != 1 || !strings.HasSuffix(links[0], `rel="canonical"`) {
+		t.Errorf("Expected only a canonical link on a filtered feed, got %v", links)
+	}
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 
@@ -11,18 +11,25 @@ import (
 func main() {
 	app.InitCache()
 
-	http.HandleFunc("/", app.HandleIndex)
-	http.HandleFunc("/api/skips", app.HandleSkipsAPI)
-	http.HandleFunc("/calendar.ics", app.HandleCalendarDefault)
-	http.HandleFunc("/calendar/", app.HandleCalendarPostcode)
-
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8000"
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatal(err)
+	// SkipService is served to gRPC clients on its own port
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		go func() {
+			slog.Info("gRPC server starting", "port", grpcPort)
+			if err := app.ListenAndServeGRPC(":" + grpcPort); err != nil {
+				slog.Error("gRPC server stopped", "err", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	slog.Info("Server starting", "port", port)
+	if err := http.ListenAndServe(":"+port, app.NewHandler()); err != nil {
+		slog.Error("Server stopped", "err", err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,716 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: wheremegaskip/v1/skips.proto
+
+package skipsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SkipChange_Kind int32
+
+const (
+	SkipChange_KIND_UNSPECIFIED SkipChange_Kind = 0
+	SkipChange_KIND_ADDED       SkipChange_Kind = 1
+	SkipChange_KIND_REMOVED     SkipChange_Kind = 2
+	SkipChange_KIND_CANCELLED   SkipChange_Kind = 3
+)
+
+// Enum value maps for SkipChange_Kind.
+var (
+	SkipChange_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_ADDED",
+		2: "KIND_REMOVED",
+		3: "KIND_CANCELLED",
+	}
+	SkipChange_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_ADDED":       1,
+		"KIND_REMOVED":     2,
+		"KIND_CANCELLED":   3,
+	}
+)
+
+func (x SkipChange_Kind) Enum() *SkipChange_Kind {
+	p := new(SkipChange_Kind)
+	*p = x
+	return p
+}
+
+func (x SkipChange_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SkipChange_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_wheremegaskip_v1_skips_proto_enumTypes[0].Descriptor()
+}
+
+func (SkipChange_Kind) Type() protoreflect.EnumType {
+	return &file_wheremegaskip_v1_skips_proto_enumTypes[0]
+}
+
+func (x SkipChange_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SkipChange_Kind.Descriptor instead.
+func (SkipChange_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{7, 0}
+}
+
+// Skip mirrors a location in /api/skips
+type Skip struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address  string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Postcode string                 `protobuf:"bytes,3,opt,name=postcode,proto3" json:"postcode,omitempty"`
+	// YYYY-MM-DD
+	Date string `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	// The date as written by the council
+	DateStr string `protobuf:"bytes,5,opt,name=date_str,json=dateStr,proto3" json:"date_str,omitempty"`
+	// 0 until geocoded
+	Lat float64 `protobuf:"fixed64,6,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng float64 `protobuf:"fixed64,7,opt,name=lng,proto3" json:"lng,omitempty"`
+	// megaskip, small-electricals, christmas-trees, pop-up-recycling or
+	// community-skip
+	Type    string `protobuf:"bytes,8,opt,name=type,proto3" json:"type,omitempty"`
+	Borough string `protobuf:"bytes,9,opt,name=borough,proto3" json:"borough,omitempty"`
+	// HH:MM, London time
+	OpensAt         string   `protobuf:"bytes,10,opt,name=opens_at,json=opensAt,proto3" json:"opens_at,omitempty"`
+	ClosesAt        string   `protobuf:"bytes,11,opt,name=closes_at,json=closesAt,proto3" json:"closes_at,omitempty"`
+	AcceptedItems   []string `protobuf:"bytes,12,rep,name=accepted_items,json=acceptedItems,proto3" json:"accepted_items,omitempty"`
+	ProhibitedItems []string `protobuf:"bytes,13,rep,name=prohibited_items,json=prohibitedItems,proto3" json:"prohibited_items,omitempty"`
+	// cancelled or postponed, or empty
+	Status string `protobuf:"bytes,14,opt,name=status,proto3" json:"status,omitempty"`
+	// street, postcode or outcode
+	GeocodeAccuracy string `protobuf:"bytes,15,opt,name=geocode_accuracy,json=geocodeAccuracy,proto3" json:"geocode_accuracy,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Skip) Reset() {
+	*x = Skip{}
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Skip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Skip) ProtoMessage() {}
+
+func (x *Skip) ProtoReflect() protoreflect.Message {
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Skip.ProtoReflect.Descriptor instead.
+func (*Skip) Descriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Skip) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Skip) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Skip) GetPostcode() string {
+	if x != nil {
+		return x.Postcode
+	}
+	return ""
+}
+
+func (x *Skip) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Skip) GetDateStr() string {
+	if x != nil {
+		return x.DateStr
+	}
+	return ""
+}
+
+func (x *Skip) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Skip) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+func (x *Skip) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Skip) GetBorough() string {
+	if x != nil {
+		return x.Borough
+	}
+	return ""
+}
+
+func (x *Skip) GetOpensAt() string {
+	if x != nil {
+		return x.OpensAt
+	}
+	return ""
+}
+
+func (x *Skip) GetClosesAt() string {
+	if x != nil {
+		return x.ClosesAt
+	}
+	return ""
+}
+
+func (x *Skip) GetAcceptedItems() []string {
+	if x != nil {
+		return x.AcceptedItems
+	}
+	return nil
+}
+
+func (x *Skip) GetProhibitedItems() []string {
+	if x != nil {
+		return x.ProhibitedItems
+	}
+	return nil
+}
+
+func (x *Skip) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Skip) GetGeocodeAccuracy() string {
+	if x != nil {
+		return x.GeocodeAccuracy
+	}
+	return ""
+}
+
+type ListSkipsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Event types to include, or all if empty
+	Types []string `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	// Borough IDs to include, or all if empty
+	Boroughs      []string `protobuf:"bytes,2,rep,name=boroughs,proto3" json:"boroughs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSkipsRequest) Reset() {
+	*x = ListSkipsRequest{}
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSkipsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSkipsRequest) ProtoMessage() {}
+
+func (x *ListSkipsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSkipsRequest.ProtoReflect.Descriptor instead.
+func (*ListSkipsRequest) Descriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListSkipsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *ListSkipsRequest) GetBoroughs() []string {
+	if x != nil {
+		return x.Boroughs
+	}
+	return nil
+}
+
+type ListSkipsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Skips         []*Skip                `protobuf:"bytes,1,rep,name=skips,proto3" json:"skips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSkipsResponse) Reset() {
+	*x = ListSkipsResponse{}
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSkipsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSkipsResponse) ProtoMessage() {}
+
+func (x *ListSkipsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSkipsResponse.ProtoReflect.Descriptor instead.
+func (*ListSkipsResponse) Descriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListSkipsResponse) GetSkips() []*Skip {
+	if x != nil {
+		return x.Skips
+	}
+	return nil
+}
+
+type GetNearestRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A full postcode, or just the outcode
+	Postcode      string `protobuf:"bytes,1,opt,name=postcode,proto3" json:"postcode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNearestRequest) Reset() {
+	*x = GetNearestRequest{}
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNearestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNearestRequest) ProtoMessage() {}
+
+func (x *GetNearestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNearestRequest.ProtoReflect.Descriptor instead.
+func (*GetNearestRequest) Descriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetNearestRequest) GetPostcode() string {
+	if x != nil {
+		return x.Postcode
+	}
+	return ""
+}
+
+type GetNearestResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Postcode string                 `protobuf:"bytes,1,opt,name=postcode,proto3" json:"postcode,omitempty"`
+	// YYYY-MM-DD
+	Date       string  `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	Skip       *Skip   `protobuf:"bytes,3,opt,name=skip,proto3" json:"skip,omitempty"`
+	DistanceKm float64 `protobuf:"fixed64,4,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
+	// Set when only an outcode was given
+	Approximate   bool `protobuf:"varint,5,opt,name=approximate,proto3" json:"approximate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNearestResponse) Reset() {
+	*x = GetNearestResponse{}
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNearestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNearestResponse) ProtoMessage() {}
+
+func (x *GetNearestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNearestResponse.ProtoReflect.Descriptor instead.
+func (*GetNearestResponse) Descriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetNearestResponse) GetPostcode() string {
+	if x != nil {
+		return x.Postcode
+	}
+	return ""
+}
+
+func (x *GetNearestResponse) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *GetNearestResponse) GetSkip() *Skip {
+	if x != nil {
+		return x.Skip
+	}
+	return nil
+}
+
+func (x *GetNearestResponse) GetDistanceKm() float64 {
+	if x != nil {
+		return x.DistanceKm
+	}
+	return 0
+}
+
+func (x *GetNearestResponse) GetApproximate() bool {
+	if x != nil {
+		return x.Approximate
+	}
+	return false
+}
+
+type StreamChangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamChangesRequest) Reset() {
+	*x = StreamChangesRequest{}
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamChangesRequest) ProtoMessage() {}
+
+func (x *StreamChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamChangesRequest.ProtoReflect.Descriptor instead.
+func (*StreamChangesRequest) Descriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{5}
+}
+
+type StreamChangesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Change        *SkipChange            `protobuf:"bytes,1,opt,name=change,proto3" json:"change,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamChangesResponse) Reset() {
+	*x = StreamChangesResponse{}
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamChangesResponse) ProtoMessage() {}
+
+func (x *StreamChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamChangesResponse.ProtoReflect.Descriptor instead.
+func (*StreamChangesResponse) Descriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StreamChangesResponse) GetChange() *SkipChange {
+	if x != nil {
+		return x.Change
+	}
+	return nil
+}
+
+type SkipChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          SkipChange_Kind        `protobuf:"varint,1,opt,name=kind,proto3,enum=wheremegaskip.v1.SkipChange_Kind" json:"kind,omitempty"`
+	Skip          *Skip                  `protobuf:"bytes,2,opt,name=skip,proto3" json:"skip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkipChange) Reset() {
+	*x = SkipChange{}
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkipChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkipChange) ProtoMessage() {}
+
+func (x *SkipChange) ProtoReflect() protoreflect.Message {
+	mi := &file_wheremegaskip_v1_skips_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkipChange.ProtoReflect.Descriptor instead.
+func (*SkipChange) Descriptor() ([]byte, []int) {
+	return file_wheremegaskip_v1_skips_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SkipChange) GetKind() SkipChange_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return SkipChange_KIND_UNSPECIFIED
+}
+
+func (x *SkipChange) GetSkip() *Skip {
+	if x != nil {
+		return x.Skip
+	}
+	return nil
+}
+
+var File_wheremegaskip_v1_skips_proto protoreflect.FileDescriptor
+
+const file_wheremegaskip_v1_skips_proto_rawDesc = "" +
+	"\n" +
+	"\x1cwheremegaskip/v1/skips.proto\x12\x10wheremegaskip.v1\"\x9a\x03\n" +
+	"\x04Skip\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x1a\n" +
+	"\bpostcode\x18\x03 \x01(\tR\bpostcode\x12\x12\n" +
+	"\x04date\x18\x04 \x01(\tR\x04date\x12\x19\n" +
+	"\bdate_str\x18\x05 \x01(\tR\adateStr\x12\x10\n" +
+	"\x03lat\x18\x06 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lng\x18\a \x01(\x01R\x03lng\x12\x12\n" +
+	"\x04type\x18\b \x01(\tR\x04type\x12\x18\n" +
+	"\aborough\x18\t \x01(\tR\aborough\x12\x19\n" +
+	"\bopens_at\x18\n" +
+	" \x01(\tR\aopensAt\x12\x1b\n" +
+	"\tcloses_at\x18\v \x01(\tR\bclosesAt\x12%\n" +
+	"\x0eaccepted_items\x18\f \x03(\tR\racceptedItems\x12)\n" +
+	"\x10prohibited_items\x18\r \x03(\tR\x0fprohibitedItems\x12\x16\n" +
+	"\x06status\x18\x0e \x01(\tR\x06status\x12)\n" +
+	"\x10geocode_accuracy\x18\x0f \x01(\tR\x0fgeocodeAccuracy\"D\n" +
+	"\x10ListSkipsRequest\x12\x14\n" +
+	"\x05types\x18\x01 \x03(\tR\x05types\x12\x1a\n" +
+	"\bboroughs\x18\x02 \x03(\tR\bboroughs\"A\n" +
+	"\x11ListSkipsResponse\x12,\n" +
+	"\x05skips\x18\x01 \x03(\v2\x16.wheremegaskip.v1.SkipR\x05skips\"/\n" +
+	"\x11GetNearestRequest\x12\x1a\n" +
+	"\bpostcode\x18\x01 \x01(\tR\bpostcode\"\xb3\x01\n" +
+	"\x12GetNearestResponse\x12\x1a\n" +
+	"\bpostcode\x18\x01 \x01(\tR\bpostcode\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12*\n" +
+	"\x04skip\x18\x03 \x01(\v2\x16.wheremegaskip.v1.SkipR\x04skip\x12\x1f\n" +
+	"\vdistance_km\x18\x04 \x01(\x01R\n" +
+	"distanceKm\x12 \n" +
+	"\vapproximate\x18\x05 \x01(\bR\vapproximate\"\x16\n" +
+	"\x14StreamChangesRequest\"M\n" +
+	"\x15StreamChangesResponse\x124\n" +
+	"\x06change\x18\x01 \x01(\v2\x1c.wheremegaskip.v1.SkipChangeR\x06change\"\xc3\x01\n" +
+	"\n" +
+	"SkipChange\x125\n" +
+	"\x04kind\x18\x01 \x01(\x0e2!.wheremegaskip.v1.SkipChange.KindR\x04kind\x12*\n" +
+	"\x04skip\x18\x02 \x01(\v2\x16.wheremegaskip.v1.SkipR\x04skip\"R\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"KIND_ADDED\x10\x01\x12\x10\n" +
+	"\fKIND_REMOVED\x10\x02\x12\x12\n" +
+	"\x0eKIND_CANCELLED\x10\x032\xa0\x02\n" +
+	"\vSkipService\x12T\n" +
+	"\tListSkips\x12\".wheremegaskip.v1.ListSkipsRequest\x1a#.wheremegaskip.v1.ListSkipsResponse\x12W\n" +
+	"\n" +
+	"GetNearest\x12#.wheremegaskip.v1.GetNearestRequest\x1a$.wheremegaskip.v1.GetNearestResponse\x12b\n" +
+	"\rStreamChanges\x12&.wheremegaskip.v1.StreamChangesRequest\x1a'.wheremegaskip.v1.StreamChangesResponse0\x01BIZGgithub.com/JosephSalisbury/wheremegaskip/proto/wheremegaskip/v1;skipsv1b\x06proto3"
+
+var (
+	file_wheremegaskip_v1_skips_proto_rawDescOnce sync.Once
+	file_wheremegaskip_v1_skips_proto_rawDescData []byte
+)
+
+func file_wheremegaskip_v1_skips_proto_rawDescGZIP() []byte {
+	file_wheremegaskip_v1_skips_proto_rawDescOnce.Do(func() {
+		file_wheremegaskip_v1_skips_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_wheremegaskip_v1_skips_proto_rawDesc), len(file_wheremegaskip_v1_skips_proto_rawDesc)))
+	})
+	return file_wheremegaskip_v1_skips_proto_rawDescData
+}
+
+var file_wheremegaskip_v1_skips_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_wheremegaskip_v1_skips_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_wheremegaskip_v1_skips_proto_goTypes = []any{
+	(SkipChange_Kind)(0),          // 0: wheremegaskip.v1.SkipChange.Kind
+	(*Skip)(nil),                  // 1: wheremegaskip.v1.Skip
+	(*ListSkipsRequest)(nil),      // 2: wheremegaskip.v1.ListSkipsRequest
+	(*ListSkipsResponse)(nil),     // 3: wheremegaskip.v1.ListSkipsResponse
+	(*GetNearestRequest)(nil),     // 4: wheremegaskip.v1.GetNearestRequest
+	(*GetNearestResponse)(nil),    // 5: wheremegaskip.v1.GetNearestResponse
+	(*StreamChangesRequest)(nil),  // 6: wheremegaskip.v1.StreamChangesRequest
+	(*StreamChangesResponse)(nil), // 7: wheremegaskip.v1.StreamChangesResponse
+	(*SkipChange)(nil),            // 8: wheremegaskip.v1.SkipChange
+}
+var file_wheremegaskip_v1_skips_proto_depIdxs = []int32{
+	1, // 0: wheremegaskip.v1.ListSkipsResponse.skips:type_name -> wheremegaskip.v1.Skip
+	1, // 1: wheremegaskip.v1.GetNearestResponse.skip:type_name -> wheremegaskip.v1.Skip
+	8, // 2: wheremegaskip.v1.StreamChangesResponse.change:type_name -> wheremegaskip.v1.SkipChange
+	0, // 3: wheremegaskip.v1.SkipChange.kind:type_name -> wheremegaskip.v1.SkipChange.Kind
+	1, // 4: wheremegaskip.v1.SkipChange.skip:type_name -> wheremegaskip.v1.Skip
+	2, // 5: wheremegaskip.v1.SkipService.ListSkips:input_type -> wheremegaskip.v1.ListSkipsRequest
+	4, // 6: wheremegaskip.v1.SkipService.GetNearest:input_type -> wheremegaskip.v1.GetNearestRequest
+	6, // 7: wheremegaskip.v1.SkipService.StreamChanges:input_type -> wheremegaskip.v1.StreamChangesRequest
+	3, // 8: wheremegaskip.v1.SkipService.ListSkips:output_type -> wheremegaskip.v1.ListSkipsResponse
+	5, // 9: wheremegaskip.v1.SkipService.GetNearest:output_type -> wheremegaskip.v1.GetNearestResponse
+	7, // 10: wheremegaskip.v1.SkipService.StreamChanges:output_type -> wheremegaskip.v1.StreamChangesResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_wheremegaskip_v1_skips_proto_init() }
+func file_wheremegaskip_v1_skips_proto_init() {
+	if File_wheremegaskip_v1_skips_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wheremegaskip_v1_skips_proto_rawDesc), len(file_wheremegaskip_v1_skips_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_wheremegaskip_v1_skips_proto_goTypes,
+		DependencyIndexes: file_wheremegaskip_v1_skips_proto_depIdxs,
+		EnumInfos:         file_wheremegaskip_v1_skips_proto_enumTypes,
+		MessageInfos:      file_wheremegaskip_v1_skips_proto_msgTypes,
+	}.Build()
+	File_wheremegaskip_v1_skips_proto = out.File
+	file_wheremegaskip_v1_skips_proto_goTypes = nil
+	file_wheremegaskip_v1_skips_proto_depIdxs = nil
+}
@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: wheremegaskip/v1/skips.proto
+
+package skipsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SkipService_ListSkips_FullMethodName     = "/wheremegaskip.v1.SkipService/ListSkips"
+	SkipService_GetNearest_FullMethodName    = "/wheremegaskip.v1.SkipService/GetNearest"
+	SkipService_StreamChanges_FullMethodName = "/wheremegaskip.v1.SkipService/StreamChanges"
+)
+
+// SkipServiceClient is the client API for SkipService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SkipService serves the skip data over gRPC for native and backend clients
+type SkipServiceClient interface {
+	// ListSkips returns the upcoming skips, optionally filtered
+	ListSkips(ctx context.Context, in *ListSkipsRequest, opts ...grpc.CallOption) (*ListSkipsResponse, error)
+	// GetNearest returns the nearest upcoming mega skip to a postcode
+	GetNearest(ctx context.Context, in *GetNearestRequest, opts ...grpc.CallOption) (*GetNearestResponse, error)
+	// StreamChanges sends skips added, removed or cancelled by each scrape
+	// until the client goes away
+	StreamChanges(ctx context.Context, in *StreamChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamChangesResponse], error)
+}
+
+type skipServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSkipServiceClient(cc grpc.ClientConnInterface) SkipServiceClient {
+	return &skipServiceClient{cc}
+}
+
+func (c *skipServiceClient) ListSkips(ctx context.Context, in *ListSkipsRequest, opts ...grpc.CallOption) (*ListSkipsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSkipsResponse)
+	err := c.cc.Invoke(ctx, SkipService_ListSkips_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skipServiceClient) GetNearest(ctx context.Context, in *GetNearestRequest, opts ...grpc.CallOption) (*GetNearestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNearestResponse)
+	err := c.cc.Invoke(ctx, SkipService_GetNearest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skipServiceClient) StreamChanges(ctx context.Context, in *StreamChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamChangesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SkipService_ServiceDesc.Streams[0], SkipService_StreamChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamChangesRequest, StreamChangesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SkipService_StreamChangesClient = grpc.ServerStreamingClient[StreamChangesResponse]
+
+// SkipServiceServer is the server API for SkipService service.
+// All implementations must embed UnimplementedSkipServiceServer
+// for forward compatibility.
+//
+// SkipService serves the skip data over gRPC for native and backend clients
+type SkipServiceServer interface {
+	// ListSkips returns the upcoming skips, optionally filtered
+	ListSkips(context.Context, *ListSkipsRequest) (*ListSkipsResponse, error)
+	// GetNearest returns the nearest upcoming mega skip to a postcode
+	GetNearest(context.Context, *GetNearestRequest) (*GetNearestResponse, error)
+	// StreamChanges sends skips added, removed or cancelled by each scrape
+	// until the client goes away
+	StreamChanges(*StreamChangesRequest, grpc.ServerStreamingServer[StreamChangesResponse]) error
+	mustEmbedUnimplementedSkipServiceServer()
+}
+
+// UnimplementedSkipServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSkipServiceServer struct{}
+
+func (UnimplementedSkipServiceServer) ListSkips(context.Context, *ListSkipsRequest) (*ListSkipsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSkips not implemented")
+}
+func (UnimplementedSkipServiceServer) GetNearest(context.Context, *GetNearestRequest) (*GetNearestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetNearest not implemented")
+}
+func (UnimplementedSkipServiceServer) StreamChanges(*StreamChangesRequest, grpc.ServerStreamingServer[StreamChangesResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamChanges not implemented")
+}
+func (UnimplementedSkipServiceServer) mustEmbedUnimplementedSkipServiceServer() {}
+func (UnimplementedSkipServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeSkipServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SkipServiceServer will
+// result in compilation errors.
+type UnsafeSkipServiceServer interface {
+	mustEmbedUnimplementedSkipServiceServer()
+}
+
+func RegisterSkipServiceServer(s grpc.ServiceRegistrar, srv SkipServiceServer) {
+	// If the following call panics, it indicates UnimplementedSkipServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SkipService_ServiceDesc, srv)
+}
+
+func _SkipService_ListSkips_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSkipsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkipServiceServer).ListSkips(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkipService_ListSkips_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkipServiceServer).ListSkips(ctx, req.(*ListSkipsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkipService_GetNearest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNearestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkipServiceServer).GetNearest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkipService_GetNearest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkipServiceServer).GetNearest(ctx, req.(*GetNearestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkipService_StreamChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SkipServiceServer).StreamChanges(m, &grpc.GenericServerStream[StreamChangesRequest, StreamChangesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SkipService_StreamChangesServer = grpc.ServerStreamingServer[StreamChangesResponse]
+
+// SkipService_ServiceDesc is the grpc.ServiceDesc for SkipService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SkipService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "wheremegaskip.v1.SkipService",
+	HandlerType: (*SkipServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSkips",
+			Handler:    _SkipService_ListSkips_Handler,
+		},
+		{
+			MethodName: "GetNearest",
+			Handler:    _SkipService_GetNearest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamChanges",
+			Handler:       _SkipService_StreamChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "wheremegaskip/v1/skips.proto",
+}